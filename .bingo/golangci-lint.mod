@@ -2,4 +2,4 @@ module _ // Auto generated by https://github.com/bwplotka/bingo. DO NOT EDIT
 
 go 1.14
 
-require github.com/golangci/golangci-lint v1.26.0 // cmd/golangci-lint
+require github.com/golangci/golangci-lint v1.55.2 // cmd/golangci-lint
@@ -0,0 +1,154 @@
+// Copyright (c) Bartłomiej Płotka @bwplotka
+// Licensed under the Apache License 2.0.
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/bwplotka/bingo/internal/getter"
+	"github.com/bwplotka/bingo/pkg/bingo"
+	"github.com/pkg/errors"
+)
+
+// shimsDir is a directory inside mod directory with unversioned tool names linked to pinned binaries.
+// It's ignored by generated .gitignore.
+const shimsDir = "shims"
+
+// lazyInstall describes how lazy shims install missing tools: by running given bingo binary with given mod directory
+// (as given by user) from given working directory, as it was run by bingo activate.
+type lazyInstall struct {
+	bingo, wd, relModDir string
+}
+
+// activate (re)creates shims directory in given mod directory and writes shell script that prepends it to PATH. If lazy
+// is not nil, shims are scripts installing missing tools first.
+func activate(logger *slog.Logger, modDir, shell string, lazy *lazyInstall, w io.Writer) error {
+	script, err := activateScript(shell, filepath.Join(modDir, shimsDir))
+	if err != nil {
+		return err
+	}
+
+	pkgs, err := bingo.ListPinnedMainPackages(logger, modDir, false)
+	if err != nil {
+		return errors.Wrap(err, "list pinned")
+	}
+	gobinPath, err := filepath.Abs(getter.BinDir(modDir))
+	if err != nil {
+		return errors.Wrap(err, "abs gobin")
+	}
+	if err := linkShims(logger, filepath.Join(modDir, shimsDir), gobinPath, pkgs, lazy); err != nil {
+		return errors.Wrap(err, "link shims")
+	}
+	_, err = fmt.Fprint(w, script)
+	return err
+}
+
+// linkShims recreates shims directory with links to pinned binaries in gobin. Tools pinned in one version are linked under
+// their name and aliases, tools pinned in many versions under <name>-<version> names. If lazy is not nil, shims are
+// scripts executing pinned binary, which run bingo get for the tool first if the binary is missing.
+func linkShims(logger *slog.Logger, dir, gobinPath string, pkgs bingo.PackageRenderables, lazy *lazyInstall) error {
+	if err := os.RemoveAll(dir); err != nil {
+		return errors.Wrap(err, "rm")
+	}
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return errors.Wrap(err, "create")
+	}
+
+	for _, p := range pkgs {
+		if p.Wasm() {
+			// WebAssembly modules are not executable on their own.
+			continue
+		}
+		for _, v := range p.Versions {
+			bin := bingo.BinaryName(p.Name, v.Version)
+			// Link adds executable suffix (e.g .exe on Windows) to shim name itself.
+			shim := p.Name + "-" + v.Version
+			if len(p.Versions) == 1 {
+				shim = p.Name
+			}
+			binPath := filepath.Join(p.InstallDir(gobinPath), bin)
+			link := func(path string) error { return getter.LinkBinary(getter.AutoLinkMode, binPath, path) }
+			if lazy != nil {
+				link = func(path string) error { return writeLazyShim(runtime.GOOS, path, binPath, p.Name, *lazy) }
+			} else if _, err := os.Stat(binPath); err != nil {
+				if !os.IsNotExist(err) {
+					return err
+				}
+				logger.Warn(fmt.Sprintf("%s is not installed; run 'bingo get %s' to install it", bin, p.Name), "gobin", gobinPath)
+			}
+			if err := link(filepath.Join(dir, shim)); err != nil {
+				return errors.Wrap(err, "link")
+			}
+			if len(p.Versions) > 1 {
+				continue
+			}
+			for _, a := range p.Aliases {
+				if err := link(filepath.Join(dir, a.Name)); err != nil {
+					return errors.Wrapf(err, "link alias %v", a.Name)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// writeLazyShim writes script for given OS under given path (<path>.cmd on Windows) that executes given binary with all
+// arguments, running bingo get for given tool first if the binary does not exist.
+func writeLazyShim(goos, path, binPath, name string, lazy lazyInstall) error {
+	if goos == "windows" {
+		return errors.Wrap(ioutil.WriteFile(path+".cmd", []byte(fmt.Sprintf(`@echo off
+if not exist "%[1]s" (
+  pushd "%[2]s"
+  "%[3]s" get -moddir="%[4]s" %[5]s 1>&2
+  if errorlevel 1 (
+    popd
+    exit /b 1
+  )
+  popd
+)
+"%[1]s" %%*
+`, binPath, lazy.wd, lazy.bingo, lazy.relModDir, name)), 0755), "write shim")
+	}
+
+	quote := func(s string) string { return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'" }
+	return errors.Wrap(ioutil.WriteFile(path, []byte(fmt.Sprintf(`#!/bin/sh
+# Generated by bingo activate -lazy. Installs %[5]s with bingo get on first use.
+if [ ! -x %[1]s ]; then
+  (cd %[2]s && %[3]s get -moddir=%[4]s %[5]s) >&2 || exit $?
+fi
+exec %[1]s "$@"
+`, quote(binPath), quote(lazy.wd), quote(lazy.bingo), quote(lazy.relModDir), name)), 0755), "write shim")
+}
+
+// activateScript returns script for given shell that prepends shims directory to PATH if not already there.
+func activateScript(shell, dir string) (string, error) {
+	switch shell {
+	case "bash", "zsh":
+	case "powershell", "pwsh":
+		return fmt.Sprintf(`# Generated by bingo activate. Use it as: bingo activate %s | Out-String | Invoke-Expression
+$env:BINGO_SHIMS_DIR = %s
+if (($env:PATH -split [IO.Path]::PathSeparator) -notcontains $env:BINGO_SHIMS_DIR) {
+  $env:PATH = $env:BINGO_SHIMS_DIR + [IO.Path]::PathSeparator + $env:PATH
+}
+`, shell, "'"+strings.ReplaceAll(dir, "'", "''")+"'"), nil
+	default:
+		return "", errors.Errorf("unsupported shell %q; expected one of bash, zsh, powershell", shell)
+	}
+
+	quoted := "'" + strings.ReplaceAll(dir, "'", `'\''`) + "'"
+	return fmt.Sprintf(`# Generated by bingo activate. Use it as: eval "$(bingo activate %s)"
+export BINGO_SHIMS_DIR=%s
+case ":${PATH}:" in
+  *":${BINGO_SHIMS_DIR}:"*) ;;
+  *) export PATH="${BINGO_SHIMS_DIR}:${PATH}" ;;
+esac
+`, shell, quoted), nil
+}
@@ -0,0 +1,104 @@
+// Copyright (c) Bartłomiej Płotka @bwplotka
+// Licensed under the Apache License 2.0.
+
+package main
+
+import (
+	"io/ioutil"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/bwplotka/bingo/pkg/bingo"
+	"github.com/efficientgo/tools/core/pkg/testutil"
+)
+
+func TestActivateScript(t *testing.T) {
+	_, err := activateScript("fish", "/a/b")
+	testutil.NotOk(t, err)
+
+	s, err := activateScript("powershell", `C:\it's`)
+	testutil.Ok(t, err)
+	testutil.Equals(t, `# Generated by bingo activate. Use it as: bingo activate powershell | Out-String | Invoke-Expression
+$env:BINGO_SHIMS_DIR = 'C:\it''s'
+if (($env:PATH -split [IO.Path]::PathSeparator) -notcontains $env:BINGO_SHIMS_DIR) {
+  $env:PATH = $env:BINGO_SHIMS_DIR + [IO.Path]::PathSeparator + $env:PATH
+}
+`, s)
+
+	s, err = activateScript("bash", "/a/it's")
+	testutil.Ok(t, err)
+	testutil.Equals(t, `# Generated by bingo activate. Use it as: eval "$(bingo activate bash)"
+export BINGO_SHIMS_DIR='/a/it'\''s'
+case ":${PATH}:" in
+  *":${BINGO_SHIMS_DIR}:"*) ;;
+  *) export PATH="${BINGO_SHIMS_DIR}:${PATH}" ;;
+esac
+`, s)
+}
+
+func TestLinkShims(t *testing.T) {
+	tmpDir, err := ioutil.TempDir(os.TempDir(), "bingo-activate")
+	testutil.Ok(t, err)
+	t.Cleanup(func() { testutil.Ok(t, os.RemoveAll(tmpDir)) })
+
+	dir := filepath.Join(tmpDir, shimsDir)
+	testutil.Ok(t, os.MkdirAll(dir, os.ModePerm))
+	testutil.Ok(t, ioutil.WriteFile(filepath.Join(dir, "stale"), nil, os.ModePerm))
+
+	testutil.Ok(t, linkShims(slog.New(slog.NewTextHandler(ioutil.Discard, nil)), dir, "/gobin", bingo.PackageRenderables{
+		{Name: "faillint", Versions: []bingo.PackageVersionRenderable{{Version: "v1.5.0"}}, Aliases: []bingo.AliasRenderable{{Name: "fl"}}},
+		{Name: "buf", Versions: []bingo.PackageVersionRenderable{{Version: "v0.1.0"}, {Version: "v0.2.0"}}, Aliases: []bingo.AliasRenderable{{Name: "b"}}},
+	}, nil))
+
+	files, err := ioutil.ReadDir(dir)
+	testutil.Ok(t, err)
+	links := map[string]string{}
+	for _, f := range files {
+		l, err := os.Readlink(filepath.Join(dir, f.Name()))
+		testutil.Ok(t, err)
+		links[f.Name()] = l
+	}
+	testutil.Equals(t, map[string]string{
+		"faillint":   "/gobin/faillint-v1.5.0",
+		"fl":         "/gobin/faillint-v1.5.0",
+		"buf-v0.1.0": "/gobin/buf-v0.1.0",
+		"buf-v0.2.0": "/gobin/buf-v0.2.0",
+	}, links)
+}
+
+func TestLinkShims_Lazy(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("POSIX shell shims")
+	}
+	tmpDir := t.TempDir()
+	gobin := filepath.Join(tmpDir, "gobin")
+	testutil.Ok(t, os.MkdirAll(gobin, os.ModePerm))
+
+	// Fake bingo installs the tool as a script printing its arguments.
+	fakeBingo := filepath.Join(tmpDir, "bingo")
+	testutil.Ok(t, ioutil.WriteFile(fakeBingo, []byte(`#!/bin/sh
+echo "$PWD $@" >> `+filepath.Join(tmpDir, "bingo.log")+`
+printf '#!/bin/sh\necho "faillint $@"\n' > `+filepath.Join(gobin, "faillint-v1.5.0")+`
+chmod +x `+filepath.Join(gobin, "faillint-v1.5.0")+`
+`), 0755))
+
+	dir := filepath.Join(tmpDir, shimsDir)
+	lazy := &lazyInstall{bingo: fakeBingo, wd: tmpDir, relModDir: ".bingo"}
+	testutil.Ok(t, linkShims(slog.New(slog.NewTextHandler(ioutil.Discard, nil)), dir, gobin, bingo.PackageRenderables{
+		{Name: "faillint", Versions: []bingo.PackageVersionRenderable{{Version: "v1.5.0"}}, Aliases: []bingo.AliasRenderable{{Name: "fl"}}},
+	}, lazy))
+
+	for i := 0; i < 2; i++ {
+		out, err := exec.Command(filepath.Join(dir, "fl"), "-a", "b").Output()
+		testutil.Ok(t, err)
+		testutil.Equals(t, "faillint -a b\n", string(out))
+	}
+	// Installed only on first use.
+	b, err := ioutil.ReadFile(filepath.Join(tmpDir, "bingo.log"))
+	testutil.Ok(t, err)
+	testutil.Equals(t, tmpDir+" get -moddir=.bingo faillint\n", string(b))
+}
@@ -0,0 +1,199 @@
+// Copyright (c) Bartłomiej Płotka @bwplotka
+// Licensed under the Apache License 2.0.
+
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/bwplotka/bingo/pkg/runner"
+	"github.com/pkg/errors"
+)
+
+// maxAddVersions is a maximum number of newest versions bingo add offers to choose from.
+const maxAddVersions = 10
+
+// addChoice is what user chose in bingo add wizard.
+type addChoice struct {
+	// Target is <package>@<version> to get.
+	Target string
+	// Name is a name of the tool, empty for default one.
+	Name string
+	// GoFlags are GOFLAGS persisted for the tool, nil if none.
+	GoFlags []string
+}
+
+// addWizard interactively asks questions on given output and reads answers, one per line, from given input.
+type addWizard struct {
+	in  *bufio.Reader
+	out io.Writer
+
+	// search returns main packages matching given term.
+	search func(term string) ([]searchResult, error)
+	// versions returns all versions of given module, from oldest.
+	versions func(modPath string) ([]string, error)
+}
+
+// ask prints given question and returns trimmed answer, or given default if answer is empty.
+func (w *addWizard) ask(question, def string) (string, error) {
+	if def != "" {
+		question += " [" + def + "]"
+	}
+	if _, err := fmt.Fprint(w.out, question+": "); err != nil {
+		return "", err
+	}
+	a, err := w.in.ReadString('\n')
+	if err != nil && (err != io.EOF || a == "") {
+		return "", errors.Wrap(err, "read answer")
+	}
+	if a = strings.TrimSpace(a); a == "" {
+		return def, nil
+	}
+	return a, nil
+}
+
+// choose prints numbered options and returns index of chosen one, or -1 if answer is not a number (returned as well).
+func (w *addWizard) choose(question string, options []string) (int, string, error) {
+	for i, o := range options {
+		if _, err := fmt.Fprintf(w.out, "  %d) %s\n", i+1, o); err != nil {
+			return 0, "", err
+		}
+	}
+	for {
+		a, err := w.ask(question, "1")
+		if err != nil {
+			return 0, "", err
+		}
+		i, err := strconv.Atoi(a)
+		if err != nil {
+			return -1, a, nil
+		}
+		if i >= 1 && i <= len(options) {
+			return i - 1, a, nil
+		}
+		if _, err := fmt.Fprintf(w.out, "Choose number from 1 to %d.\n", len(options)); err != nil {
+			return 0, "", err
+		}
+	}
+}
+
+// run asks for tool to pin, starting with given search term (asked for if empty), its version, name and GOFLAGS.
+func (w *addWizard) run(term string) (addChoice, error) {
+	var pkg searchResult
+	for {
+		if term == "" {
+			t, err := w.ask("Search for tool", "")
+			if err != nil {
+				return addChoice{}, err
+			}
+			term = t
+			continue
+		}
+		results, err := w.search(term)
+		if err != nil {
+			return addChoice{}, err
+		}
+		if len(results) == 0 {
+			if _, err := fmt.Fprintf(w.out, "No main packages found for %q.\n", term); err != nil {
+				return addChoice{}, err
+			}
+			term = ""
+			continue
+		}
+
+		options := make([]string, 0, len(results))
+		for _, r := range results {
+			o := r.PackagePath + " " + r.Version
+			if r.Synopsis != "" {
+				o += " - " + r.Synopsis
+			}
+			options = append(options, o)
+		}
+		i, a, err := w.choose("Package", options)
+		if err != nil {
+			return addChoice{}, err
+		}
+		if i < 0 {
+			// Not a number; search again.
+			term = a
+			continue
+		}
+		pkg = results[i]
+		break
+	}
+
+	versions, err := w.versions(pkg.ModulePath)
+	if err != nil {
+		return addChoice{}, errors.Wrapf(err, "list versions of %s", pkg.ModulePath)
+	}
+	// Newest first.
+	options := []string{"latest"}
+	for i := len(versions) - 1; i >= 0 && len(options) <= maxAddVersions; i-- {
+		options = append(options, versions[i])
+	}
+	i, a, err := w.choose("Version (number or any version, e.g commit sha)", options)
+	if err != nil {
+		return addChoice{}, err
+	}
+	version := a
+	if i >= 0 {
+		version = options[i]
+	}
+
+	c := addChoice{Target: pkg.PackagePath + "@" + version}
+	def := path.Base(pkg.PackagePath)
+	if c.Name, err = w.ask("Name", def); err != nil {
+		return addChoice{}, err
+	}
+	if c.Name == def {
+		c.Name = ""
+	}
+
+	for {
+		goflags, err := w.ask("GOFLAGS for building the tool, e.g -tags=netgo (empty for none)", "")
+		if err != nil {
+			return addChoice{}, err
+		}
+		if err := runner.ValidateToolGoFlags(goflags); err != nil {
+			if _, err := fmt.Fprintln(w.out, err.Error()); err != nil {
+				return addChoice{}, err
+			}
+			continue
+		}
+		if goflags != "" {
+			c.GoFlags = strings.Fields(goflags)
+		}
+		break
+	}
+
+	cmd := "bingo get"
+	if c.Name != "" {
+		cmd += " -n " + c.Name
+	}
+	if len(c.GoFlags) > 0 {
+		cmd += " -goflags '" + strings.Join(c.GoFlags, " ") + "'"
+	}
+	a, err = w.ask(fmt.Sprintf("Run '%s %s'? (y/n)", cmd, c.Target), "y")
+	if err != nil {
+		return addChoice{}, err
+	}
+	if !strings.EqualFold(a, "y") && !strings.EqualFold(a, "yes") {
+		return addChoice{}, errors.New("aborted")
+	}
+	return c, nil
+}
+
+// moduleVersions returns all released versions of given module, from oldest, as reported by go list.
+func moduleVersions(ctx context.Context, r *runner.Runner, modPath string) ([]string, error) {
+	out, err := r.With(ctx, "", "", nil).List(runner.NoUpdatePolicy, "-m", "-versions", "-f={{range .Versions}}{{.}} {{end}}", modPath)
+	if err != nil {
+		return nil, err
+	}
+	return strings.Fields(out), nil
+}
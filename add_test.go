@@ -0,0 +1,59 @@
+// Copyright (c) Bartłomiej Płotka @bwplotka
+// Licensed under the Apache License 2.0.
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/efficientgo/tools/core/pkg/testutil"
+)
+
+func TestAddWizard(t *testing.T) {
+	newWizard := func(input string) (*addWizard, *bytes.Buffer) {
+		out := &bytes.Buffer{}
+		return &addWizard{
+			in:  bufio.NewReader(strings.NewReader(input)),
+			out: out,
+			search: func(term string) ([]searchResult, error) {
+				if term != "lint" {
+					return nil, nil
+				}
+				return []searchResult{
+					{PackagePath: "github.com/golangci/golangci-lint/cmd/golangci-lint", ModulePath: "github.com/golangci/golangci-lint", Version: "v1.55.2"},
+					{PackagePath: "github.com/fatih/faillint", ModulePath: "github.com/fatih/faillint", Version: "v1.5.0", Synopsis: "Report unwanted import path."},
+				}, nil
+			},
+			versions: func(modPath string) ([]string, error) {
+				testutil.Equals(t, "github.com/fatih/faillint", modPath)
+				return []string{"v1.3.0", "v1.4.0", "v1.5.0"}, nil
+			},
+		}, out
+	}
+
+	t.Run("defaults", func(t *testing.T) {
+		w, _ := newWizard("lint\n2\n\n\n\n\n")
+		c, err := w.run("lints")
+		testutil.Ok(t, err)
+		testutil.Equals(t, addChoice{Target: "github.com/fatih/faillint@latest"}, c)
+	})
+	t.Run("custom", func(t *testing.T) {
+		w, out := newWizard("5\n2\n3\nfl\n-mod=vendor\n-tags=netgo -trimpath\nyes\n")
+		c, err := w.run("lint")
+		testutil.Ok(t, err)
+		testutil.Equals(t, addChoice{Target: "github.com/fatih/faillint@v1.4.0", Name: "fl", GoFlags: []string{"-tags=netgo", "-trimpath"}}, c)
+		testutil.Assert(t, strings.Contains(out.String(), "  2) github.com/fatih/faillint v1.5.0 - Report unwanted import path.\n"), out.String())
+		testutil.Assert(t, strings.Contains(out.String(), "  1) latest\n  2) v1.5.0\n  3) v1.4.0\n  4) v1.3.0\n"), out.String())
+		testutil.Assert(t, strings.Contains(out.String(), "Choose number from 1 to 2.\n"), out.String())
+		testutil.Assert(t, strings.Contains(out.String(), "Run 'bingo get -n fl -goflags '-tags=netgo -trimpath' github.com/fatih/faillint@v1.4.0'? (y/n) [y]: "), out.String())
+	})
+	t.Run("version sha and abort", func(t *testing.T) {
+		w, _ := newWizard("2\n4ac5a8f\n\n\nn\n")
+		_, err := w.run("lint")
+		testutil.NotOk(t, err)
+		testutil.Equals(t, "aborted", err.Error())
+	})
+}
@@ -0,0 +1,146 @@
+// Copyright (c) Bartłomiej Płotka @bwplotka
+// Licensed under the Apache License 2.0.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"runtime"
+	"runtime/debug"
+
+	"github.com/bwplotka/bingo/pkg/version"
+)
+
+// features lists capabilities of this bingo version that wrapper scripts might want to check before relying on them.
+// Add a new entry for every new command, output format or behaviour scripts can depend on.
+var features = []string{
+	"activate",
+	"activate-lazy",
+	"activate-powershell",
+	"add",
+	"bootstrap",
+	"ca-file",
+	"cachekey",
+	"check",
+	"check-binaries",
+	"completion",
+	"config",
+	"constraints",
+	"dedupe",
+	"descriptions",
+	"detect",
+	"diff",
+	"env-config",
+	"fmt",
+	"freeze",
+	"get-alias",
+	"get-bin-path-mode",
+	"get-bin-path-mode-local",
+	"get-bindir",
+	"get-changed-exit-code",
+	"get-commit",
+	"get-gen-policy",
+	"get-generate",
+	"get-gobin",
+	"get-goflags",
+	"get-isolate-env",
+	"get-link-mode",
+	"get-offline-build",
+	"get-output-json",
+	"get-replace",
+	"get-report",
+	"get-self",
+	"get-split",
+	"get-store",
+	"get-timeout",
+	"get-var-prefix",
+	"gha-env",
+	"gitignore-bin-dirs",
+	"goproxy-diagnostics",
+	"govcs",
+	"labels",
+	"licenses",
+	"list-all-workspaces",
+	"list-buildinfo",
+	"list-filter",
+	"list-format",
+	"list-output-csv",
+	"list-output-json",
+	"list-output-yaml",
+	"list-page",
+	"list-sort",
+	"list-sum",
+	"list-summary",
+	"log-format-json",
+	"merge",
+	"merge-array",
+	"mise",
+	"moddir-discovery",
+	"moddir-flag",
+	"moddir-migrate",
+	"module-policy",
+	"no-color",
+	"path",
+	"plugins",
+	"print-commands",
+	"registry",
+	"rename",
+	"report",
+	"search",
+	"self-update",
+	"split",
+	"stats",
+	"templates",
+	"ui",
+	"variables-go",
+	"version-json",
+	"vscode",
+	"wasm",
+}
+
+// buildInfo describes bingo binary, printed by bingo version -json.
+type buildInfo struct {
+	Version string `json:"version"`
+	// Commit is a VCS revision bingo was built from, if known.
+	Commit string `json:"commit,omitempty"`
+	// Modified is true if bingo was built from a modified working tree.
+	Modified  bool     `json:"modified,omitempty"`
+	GoVersion string   `json:"goVersion"`
+	Platform  string   `json:"platform"`
+	Features  []string `json:"features"`
+}
+
+func newBuildInfo() buildInfo {
+	b := buildInfo{
+		Version:   version.Version,
+		GoVersion: runtime.Version(),
+		Platform:  runtime.GOOS + "/" + runtime.GOARCH,
+		Features:  features,
+	}
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return b
+	}
+	for _, s := range info.Settings {
+		switch s.Key {
+		case "vcs.revision":
+			b.Commit = s.Value
+		case "vcs.modified":
+			b.Modified = s.Value == "true"
+		}
+	}
+	return b
+}
+
+// printVersion prints bingo version, or full build info in JSON if asJSON is true.
+func printVersion(w io.Writer, asJSON bool) error {
+	if !asJSON {
+		_, err := fmt.Fprintln(w, version.Version)
+		return err
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(newBuildInfo())
+}
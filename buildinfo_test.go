@@ -0,0 +1,31 @@
+// Copyright (c) Bartłomiej Płotka @bwplotka
+// Licensed under the Apache License 2.0.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"runtime"
+	"sort"
+	"testing"
+
+	"github.com/bwplotka/bingo/pkg/version"
+	"github.com/efficientgo/tools/core/pkg/testutil"
+)
+
+func TestPrintVersion(t *testing.T) {
+	b := &bytes.Buffer{}
+	testutil.Ok(t, printVersion(b, false))
+	testutil.Equals(t, version.Version+"\n", b.String())
+
+	b.Reset()
+	testutil.Ok(t, printVersion(b, true))
+	info := buildInfo{}
+	testutil.Ok(t, json.Unmarshal(b.Bytes(), &info))
+	testutil.Equals(t, version.Version, info.Version)
+	testutil.Equals(t, runtime.Version(), info.GoVersion)
+	testutil.Equals(t, runtime.GOOS+"/"+runtime.GOARCH, info.Platform)
+	testutil.Equals(t, features, info.Features)
+	testutil.Assert(t, sort.StringsAreSorted(features), "features should be sorted")
+}
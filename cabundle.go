@@ -0,0 +1,47 @@
+// Copyright (c) Bartłomiej Płotka @bwplotka
+// Licensed under the Apache License 2.0.
+
+package main
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"io/ioutil"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+)
+
+// caBundleEnv verifies given CA bundle file contains valid PEM certificates and returns environment variables making go
+// and git (used by go to fetch modules directly from VCS) trust them, e.g for private module hosts using internal CA.
+// Both variables replace system roots, so the bundle is used as is, and only go and git get them; bingo HTTP clients don't.
+func caBundleEnv(caFile string) ([]string, error) {
+	abs, err := filepath.Abs(caFile)
+	if err != nil {
+		return nil, errors.Wrap(err, "abs")
+	}
+	b, err := ioutil.ReadFile(abs)
+	if err != nil {
+		return nil, errors.Wrap(err, "read CA bundle")
+	}
+
+	certs := 0
+	for {
+		var block *pem.Block
+		block, b = pem.Decode(b)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		if _, err := x509.ParseCertificate(block.Bytes); err != nil {
+			return nil, errors.Wrapf(err, "parse certificate %d in CA bundle %v", certs+1, abs)
+		}
+		certs++
+	}
+	if certs == 0 {
+		return nil, errors.Errorf("no PEM certificates found in CA bundle %v", abs)
+	}
+	return []string{"SSL_CERT_FILE=" + abs, "GIT_SSL_CAINFO=" + abs}, nil
+}
@@ -0,0 +1,56 @@
+// Copyright (c) Bartłomiej Płotka @bwplotka
+// Licensed under the Apache License 2.0.
+
+package main
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/efficientgo/tools/core/pkg/testutil"
+)
+
+func TestCABundleEnv(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	testutil.Ok(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "Internal CA"},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+		IsCA:         true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	testutil.Ok(t, err)
+
+	dir := t.TempDir()
+	bundle := filepath.Join(dir, "ca.pem")
+	testutil.Ok(t, ioutil.WriteFile(bundle, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), os.ModePerm))
+	env, err := caBundleEnv(bundle)
+	testutil.Ok(t, err)
+	testutil.Equals(t, []string{"SSL_CERT_FILE=" + bundle, "GIT_SSL_CAINFO=" + bundle}, env)
+
+	testutil.Ok(t, ioutil.WriteFile(bundle, []byte("not a certificate\n"), os.ModePerm))
+	_, err = caBundleEnv(bundle)
+	testutil.NotOk(t, err)
+	testutil.Assert(t, strings.Contains(err.Error(), "no PEM certificates found"), err.Error())
+
+	testutil.Ok(t, ioutil.WriteFile(bundle, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: []byte("garbage")}), os.ModePerm))
+	_, err = caBundleEnv(bundle)
+	testutil.NotOk(t, err)
+	testutil.Assert(t, strings.Contains(err.Error(), "parse certificate 1"), err.Error())
+
+	_, err = caBundleEnv(filepath.Join(dir, "missing.pem"))
+	testutil.NotOk(t, err)
+}
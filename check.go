@@ -0,0 +1,216 @@
+// Copyright (c) Bartłomiej Płotka @bwplotka
+// Licensed under the Apache License 2.0.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/bwplotka/bingo/internal/getter"
+	"github.com/bwplotka/bingo/pkg/bingo"
+	"github.com/bwplotka/bingo/pkg/runner"
+	"github.com/pkg/errors"
+)
+
+// checkModFiles checks that all tool mod files in given mod directory are in canonical form (see bingo.FormatModFile),
+// e.g after manual edits. Non canonical files are printed with dropped or added content. If fix is true, they are
+// rewritten in canonical form; otherwise error is returned if any of them is not canonical. Malformed files cannot be
+// fixed and are always reported as error.
+func checkModFiles(w io.Writer, modDir string, fix bool) error {
+	modFiles, err := filepath.Glob(filepath.Join(modDir, "*.mod"))
+	if err != nil {
+		return err
+	}
+	sort.Strings(modFiles)
+
+	var notCanonical, malformed []string
+	for _, f := range modFiles {
+		base := filepath.Base(f)
+		if base == bingo.ConstraintsModFileName {
+			// Constraints file is not canonicalized, as it has no tool; it only has to be valid.
+			if _, err := bingo.ReadConstraints(modDir); err != nil {
+				malformed = append(malformed, base)
+				if _, err := fmt.Fprintf(w, "%s: malformed: %v\n", base, err); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		if !bingo.IsToolModFile(f) {
+			continue
+		}
+		b, err := ioutil.ReadFile(f)
+		if err != nil {
+			return errors.Wrapf(err, "read %v", f)
+		}
+		canonical, changes, err := bingo.FormatModFile(f, b)
+		if err != nil {
+			malformed = append(malformed, base)
+			if _, err := fmt.Fprintf(w, "%s: malformed: %v\n", base, err); err != nil {
+				return err
+			}
+			continue
+		}
+		if bytes.Equal(b, canonical) {
+			continue
+		}
+
+		status := "not canonical"
+		if fix {
+			st, err := os.Stat(f)
+			if err != nil {
+				return err
+			}
+			if err := ioutil.WriteFile(f, canonical, st.Mode()); err != nil {
+				return errors.Wrapf(err, "write %v", f)
+			}
+			status = "fixed"
+		} else {
+			notCanonical = append(notCanonical, base)
+		}
+		if _, err := fmt.Fprintf(w, "%s: %s\n", base, status); err != nil {
+			return err
+		}
+		for _, c := range changes {
+			if _, err := fmt.Fprintf(w, "  - %s\n", c); err != nil {
+				return err
+			}
+		}
+	}
+
+	if len(malformed) > 0 {
+		return errors.Errorf("malformed mod files: %s; fix them manually or remove and get the tools again", strings.Join(malformed, ", "))
+	}
+	if len(notCanonical) > 0 {
+		return errors.Errorf("mod files not in canonical form: %s; run 'bingo check -fix' to rewrite them", strings.Join(notCanonical, ", "))
+	}
+	return nil
+}
+
+// readModulePolicy reads module policy from given file, or returns nil if path is empty.
+func readModulePolicy(path string) (*bingo.ModulePolicy, error) {
+	if path == "" {
+		return nil, nil
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+
+	p, err := bingo.ParseModulePolicy(f)
+	if err != nil {
+		return nil, errors.Wrap(err, path)
+	}
+	return &p, nil
+}
+
+// checkModDirPolicy checks that no tool pinned in given mod directory depends on modules disallowed by given policy (see
+// getter.ModulePolicyViolations). Violations are printed per mod file and returned as error.
+func checkModDirPolicy(ctx context.Context, w io.Writer, r *runner.Runner, modDir string, p bingo.ModulePolicy) error {
+	modFiles, err := filepath.Glob(filepath.Join(modDir, "*.mod"))
+	if err != nil {
+		return err
+	}
+	sort.Strings(modFiles)
+
+	var violating []string
+	for _, f := range modFiles {
+		if !bingo.IsToolModFile(f) || filepath.Base(f) == bingo.ConstraintsModFileName {
+			continue
+		}
+		violations, err := getter.ModulePolicyViolations(ctx, r, modDir, p, f)
+		if err != nil {
+			return errors.Wrapf(err, "check module policy of %v", filepath.Base(f))
+		}
+		if len(violations) == 0 {
+			continue
+		}
+		violating = append(violating, filepath.Base(f))
+		if _, err := fmt.Fprintf(w, "%s: violates module policy\n", filepath.Base(f)); err != nil {
+			return err
+		}
+		for _, v := range violations {
+			if _, err := fmt.Fprintf(w, "  - %s\n", v); err != nil {
+				return err
+			}
+		}
+	}
+	if len(violating) > 0 {
+		return errors.Wrapf(bingo.ErrPolicyViolation, "tools depending on disallowed modules: %s", strings.Join(violating, ", "))
+	}
+	return nil
+}
+
+// checkBinaries verifies that installed binaries of given tools in given gobin (or tools' bin directories) were built from
+// pinned package and version, according to their build info. Both versioned binaries and links (tool names and aliases) are
+// checked, catching binaries overwritten outside of bingo, e.g by 'go install <package>@latest'. Not installed binaries and
+// shim scripts are skipped. Mismatches are printed and returned as error.
+func checkBinaries(w io.Writer, gobin string, pkgs bingo.PackageRenderables) error {
+	var mismatched []string
+	verify := func(binPath, pkgPath, modPath string, versions ...string) error {
+		if shim, err := isScript(binPath); err != nil || shim {
+			// Shim scripts (see getter.ShimLinkMode) run versioned binary, which is verified itself.
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if verr := bingo.VerifyBinary(binPath, pkgPath, modPath, versions...); verr != nil {
+			mismatched = append(mismatched, filepath.Base(binPath))
+			_, err := fmt.Fprintf(w, "%s: %v\n", binPath, verr)
+			return err
+		}
+		return nil
+	}
+
+	for _, p := range pkgs {
+		dir := p.InstallDir(gobin)
+		versions := make([]string, 0, len(p.Versions))
+		for _, v := range p.Versions {
+			versions = append(versions, v.Version)
+			if err := verify(filepath.Join(dir, p.ArtifactName(v.Version)), p.PackagePath, p.ModPath, v.Version); err != nil {
+				return err
+			}
+		}
+		if p.Wasm() {
+			// WebAssembly modules are never linked.
+			continue
+		}
+		// Links point to one of pinned versions.
+		names := []string{p.Name}
+		for _, a := range p.Aliases {
+			names = append(names, a.Name)
+		}
+		for _, n := range names {
+			if err := verify(filepath.Join(dir, n+bingo.GOEXE()), p.PackagePath, p.ModPath, versions...); err != nil {
+				return err
+			}
+		}
+	}
+	if len(mismatched) > 0 {
+		return errors.Errorf("installed binaries not built from pinned versions: %s; run 'bingo get' to reinstall them", strings.Join(mismatched, ", "))
+	}
+	return nil
+}
+
+// isScript returns true if file at given path starts with shebang.
+func isScript(path string) (bool, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return false, err
+	}
+	defer func() { _ = f.Close() }()
+
+	b := make([]byte, 2)
+	n, _ := io.ReadFull(f, b)
+	return string(b[:n]) == "#!", nil
+}
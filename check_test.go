@@ -0,0 +1,85 @@
+// Copyright (c) Bartłomiej Płotka @bwplotka
+// Licensed under the Apache License 2.0.
+
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime/debug"
+	"testing"
+
+	"github.com/bwplotka/bingo/pkg/bingo"
+	"github.com/efficientgo/tools/core/pkg/testutil"
+)
+
+func TestCheckModFiles(t *testing.T) {
+	modDir := t.TempDir()
+	canonical := "module _ // Auto generated by https://github.com/bwplotka/bingo. DO NOT EDIT\n\ngo 1.14\n\nrequire github.com/fatih/faillint v1.5.0\n"
+	testutil.Ok(t, ioutil.WriteFile(filepath.Join(modDir, "faillint.mod"), []byte(canonical), os.ModePerm))
+	testutil.Ok(t, ioutil.WriteFile(filepath.Join(modDir, "go.mod"), []byte("module _ // Fake go.mod.\n"), os.ModePerm))
+
+	out := &bytes.Buffer{}
+	testutil.Ok(t, checkModFiles(out, modDir, false))
+	testutil.Equals(t, "", out.String())
+
+	edited := "module _\n\ngo 1.14\n\nrequire github.com/fatih/faillint v1.5.0 //  -tags=x\n\nrequire github.com/fatih/faillint v1.6.0\n"
+	testutil.Ok(t, ioutil.WriteFile(filepath.Join(modDir, "faillint.mod"), []byte(edited), os.ModePerm))
+	out.Reset()
+	testutil.NotOk(t, checkModFiles(out, modDir, false))
+	testutil.Equals(t, "faillint.mod: not canonical\n  - added bingo meta comment to module statement\n  - dropped require github.com/fatih/faillint@v1.6.0\n", out.String())
+	b, err := ioutil.ReadFile(filepath.Join(modDir, "faillint.mod"))
+	testutil.Ok(t, err)
+	testutil.Equals(t, edited, string(b))
+
+	out.Reset()
+	testutil.Ok(t, checkModFiles(out, modDir, true))
+	testutil.Equals(t, "faillint.mod: fixed\n  - added bingo meta comment to module statement\n  - dropped require github.com/fatih/faillint@v1.6.0\n", out.String())
+	b, err = ioutil.ReadFile(filepath.Join(modDir, "faillint.mod"))
+	testutil.Ok(t, err)
+	testutil.Equals(t, "module _ // Auto generated by https://github.com/bwplotka/bingo. DO NOT EDIT\n\ngo 1.14\n\nrequire github.com/fatih/faillint v1.5.0 // -tags=x\n", string(b))
+
+	out.Reset()
+	testutil.Ok(t, checkModFiles(out, modDir, false))
+	testutil.Equals(t, "", out.String())
+
+	// Constraints file is only validated.
+	testutil.Ok(t, ioutil.WriteFile(filepath.Join(modDir, "constraints.mod"), []byte("module _\n\nrequire golang.org/x/net v0.17.0\n"), os.ModePerm))
+	testutil.Ok(t, checkModFiles(out, modDir, false))
+	testutil.Equals(t, "", out.String())
+
+	testutil.Ok(t, ioutil.WriteFile(filepath.Join(modDir, "broken.mod"), []byte("require ("), os.ModePerm))
+	testutil.NotOk(t, checkModFiles(out, modDir, true))
+}
+
+func TestCheckBinaries(t *testing.T) {
+	testBinary, err := os.Executable()
+	testutil.Ok(t, err)
+	info, ok := debug.ReadBuildInfo()
+	testutil.Assert(t, ok)
+	b, err := ioutil.ReadFile(testBinary)
+	testutil.Ok(t, err)
+
+	gobin := t.TempDir()
+	pkgs := bingo.PackageRenderables{{
+		Name:        "tool",
+		PackagePath: info.Path,
+		ModPath:     info.Main.Path,
+		Versions:    []bingo.PackageVersionRenderable{{Version: info.Main.Version}},
+		Aliases:     []bingo.AliasRenderable{{Name: "t"}},
+	}}
+	testutil.Ok(t, ioutil.WriteFile(filepath.Join(gobin, bingo.BinaryName("tool", info.Main.Version)), b, 0755))
+	testutil.Ok(t, ioutil.WriteFile(filepath.Join(gobin, "t"+bingo.GOEXE()), []byte("#!/bin/sh\nexec tool \"$@\"\n"), 0755))
+
+	out := &bytes.Buffer{}
+	testutil.Ok(t, checkBinaries(out, gobin, pkgs))
+	testutil.Equals(t, "", out.String())
+
+	// Link overwritten by 'go install <package>@latest'.
+	pkgs[0].Versions[0].Version = "v0.0.1"
+	testutil.Ok(t, os.Rename(filepath.Join(gobin, bingo.BinaryName("tool", info.Main.Version)), filepath.Join(gobin, "tool"+bingo.GOEXE())))
+	testutil.NotOk(t, checkBinaries(out, gobin, pkgs))
+	testutil.Equals(t, filepath.Join(gobin, "tool"+bingo.GOEXE())+": built from "+info.Main.Path+"@"+info.Main.Version+", pinned v0.0.1\n", out.String())
+}
@@ -0,0 +1,233 @@
+// Copyright (c) Bartłomiej Płotka @bwplotka
+// Licensed under the Apache License 2.0.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/bwplotka/bingo/internal/getter"
+	"github.com/bwplotka/bingo/pkg/logging"
+	"github.com/pkg/errors"
+)
+
+// defaultCommitMessage is a default template of commit message created by bingo get -commit.
+const defaultCommitMessage = `bingo: {{ .Summary }}{{ if gt (len .Changes) 1 }}
+
+{{ range .Changes }}* {{ . }}
+{{ end }}{{ end }}`
+
+// commitChange describes change of a single tool version made by bingo get.
+type commitChange struct {
+	Name string
+	// From is a version before get. Empty if tool was added.
+	From string
+	// To is a version after get. Empty if tool was removed.
+	To string
+
+	modFile string
+}
+
+func (c commitChange) String() string {
+	switch {
+	case c.From == "":
+		return fmt.Sprintf("add %s %s", c.Name, c.To)
+	case c.To == "":
+		return fmt.Sprintf("remove %s %s", c.Name, c.From)
+	default:
+		return fmt.Sprintf("bump %s %s → %s", c.Name, c.From, c.To)
+	}
+}
+
+// commitMessageData is passed to commit message template.
+type commitMessageData struct {
+	// Summary is a one line description of all changes, e.g "bump golangci-lint v1.55.2 → v1.59.0" or "update 3 tools".
+	Summary string
+	Changes []commitChange
+}
+
+// commitChanges returns changes of tool versions from get summary, sorted by tool name. Results that did not change
+// version (e.g rebuilds) are skipped.
+func commitChanges(s *getter.Summary) []commitChange {
+	var changes []commitChange
+	for _, r := range s.Results {
+		c := commitChange{Name: r.Name, From: r.PreviousVersion, To: r.Version, modFile: r.ModFile}
+		if r.Removed {
+			c.To = ""
+		}
+		if c.From == c.To {
+			continue
+		}
+		changes = append(changes, c)
+	}
+	sort.SliceStable(changes, func(i, j int) bool { return changes[i].Name < changes[j].Name })
+	return changes
+}
+
+// renderCommitMessage renders commit message for given changes using given Go template or defaultCommitMessage if empty.
+// If summary is empty, it's generated from changes.
+func renderCommitMessage(tmpl, summary string, changes []commitChange) (string, error) {
+	if tmpl == "" {
+		tmpl = defaultCommitMessage
+	}
+	t, err := template.New("commit-message").Parse(tmpl)
+	if err != nil {
+		return "", errors.Wrap(err, "parse commit message template")
+	}
+
+	data := commitMessageData{Summary: summary, Changes: changes}
+	switch {
+	case summary != "":
+	case len(changes) == 1:
+		data.Summary = changes[0].String()
+	default:
+		data.Summary = fmt.Sprintf("update %d tools", len(changes))
+	}
+
+	b := &bytes.Buffer{}
+	if err := t.Execute(b, data); err != nil {
+		return "", errors.Wrap(err, "execute commit message template")
+	}
+	msg := strings.TrimSpace(b.String())
+	if msg == "" {
+		return "", errors.New("commit message template rendered empty message")
+	}
+	return msg, nil
+}
+
+// gitCommit stages and commits given paths only. Changes already staged outside of those paths are not committed.
+func gitCommit(ctx context.Context, logger logging.Logger, msg string, paths ...string) error {
+	if _, err := git(ctx, append([]string{"add", "-A", "--"}, paths...)...); err != nil {
+		return err
+	}
+	if _, err := git(ctx, append([]string{"commit", "-m", msg, "--"}, paths...)...); err != nil {
+		return err
+	}
+	logger.Info("committed changes", "message", strings.SplitN(msg, "\n", 2)[0])
+	return nil
+}
+
+// gitPaths returns given paths git can stage: existing files not ignored by git and tracked ones (including removed).
+// Files git does not know of, e.g ignored tmp files or removed untracked files, would fail git add.
+func gitPaths(ctx context.Context, paths []string) ([]string, error) {
+	if len(paths) == 0 {
+		return nil, nil
+	}
+	out, err := git(ctx, append([]string{"ls-files", "-z", "--cached", "--others", "--exclude-standard", "--"}, paths...)...)
+	if err != nil {
+		return nil, err
+	}
+	known := map[string]struct{}{}
+	for _, f := range strings.Split(string(out), "\x00") {
+		if f == "" {
+			continue
+		}
+		abs, err := filepath.Abs(f)
+		if err != nil {
+			return nil, err
+		}
+		known[abs] = struct{}{}
+	}
+	var ret []string
+	for _, p := range paths {
+		abs, err := filepath.Abs(p)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := known[abs]; ok {
+			ret = append(ret, p)
+		}
+	}
+	return ret, nil
+}
+
+// commit creates git commit with given files written by get (e.g mod files in given mod directory and regenerated
+// helpers), if get changed any tool version. Other changes, even in mod directory, are not committed. If split is true,
+// separate commit is created for each changed tool with its mod files only, followed by commit with remaining written
+// files (e.g regenerated Variables.mk), so each tool bump can be reviewed, reverted or cherry-picked alone.
+func commit(ctx context.Context, logger logging.Logger, s *getter.Summary, tmpl string, split bool, relModDir string, written ...string) error {
+	changes := commitChanges(s)
+	if len(changes) == 0 {
+		logger.Info("no tool versions changed, nothing to commit")
+		return nil
+	}
+	paths, err := gitPaths(ctx, written)
+	if err != nil {
+		return err
+	}
+	if len(paths) == 0 {
+		logger.Info("no files written, nothing to commit")
+		return nil
+	}
+
+	if !split {
+		msg, err := renderCommitMessage(tmpl, "", changes)
+		if err != nil {
+			return err
+		}
+		return gitCommit(ctx, logger, msg, paths...)
+	}
+
+	staged, committed := map[string]struct{}{}, map[string]struct{}{}
+	for _, p := range paths {
+		staged[p] = struct{}{}
+	}
+	for i := 0; i < len(changes); {
+		j := i + 1
+		for j < len(changes) && changes[j].Name == changes[i].Name {
+			j++
+		}
+		// Array tool can have more than one version changed.
+		summary := ""
+		if j-i > 1 {
+			summary = "update " + changes[i].Name
+		}
+		msg, err := renderCommitMessage(tmpl, summary, changes[i:j])
+		if err != nil {
+			return err
+		}
+		var modFiles []string
+		for _, c := range changes[i:j] {
+			f := filepath.Join(relModDir, c.modFile)
+			if _, ok := staged[f]; ok {
+				modFiles = append(modFiles, f)
+				committed[f] = struct{}{}
+			}
+		}
+		i = j
+		if len(modFiles) == 0 {
+			continue
+		}
+		if err := gitCommit(ctx, logger, msg, modFiles...); err != nil {
+			return errors.Wrapf(err, "commit %s", changes[i-1].Name)
+		}
+	}
+
+	var rest []string
+	for _, p := range paths {
+		if _, ok := committed[p]; !ok {
+			rest = append(rest, p)
+		}
+	}
+	if len(rest) == 0 {
+		return nil
+	}
+	out, err := git(ctx, append([]string{"status", "--porcelain", "--"}, rest...)...)
+	if err != nil {
+		return err
+	}
+	if len(bytes.TrimSpace(out)) == 0 {
+		return nil
+	}
+	msg, err := renderCommitMessage(tmpl, "update generated files", nil)
+	if err != nil {
+		return err
+	}
+	return gitCommit(ctx, logger, msg, rest...)
+}
@@ -0,0 +1,144 @@
+// Copyright (c) Bartłomiej Płotka @bwplotka
+// Licensed under the Apache License 2.0.
+
+package main
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/bwplotka/bingo/internal/getter"
+	"github.com/bwplotka/bingo/pkg/logging"
+	"github.com/efficientgo/tools/core/pkg/testutil"
+)
+
+func TestRenderCommitMessage(t *testing.T) {
+	s := &getter.Summary{Results: []getter.Result{
+		{Name: "golangci-lint", PreviousVersion: "v1.55.2", Version: "v1.59.0"},
+		{Name: "faillint", PreviousVersion: "v1.5.0", Version: "v1.5.0", Rebuilt: true},
+	}}
+	changes := commitChanges(s)
+	msg, err := renderCommitMessage("", "", changes)
+	testutil.Ok(t, err)
+	testutil.Equals(t, "bingo: bump golangci-lint v1.55.2 → v1.59.0", msg)
+
+	s.Results = append(s.Results,
+		getter.Result{Name: "buf", PreviousVersion: "v0.1.0", Removed: true},
+		getter.Result{Name: "goimports", Version: "v0.1.0"},
+	)
+	changes = commitChanges(s)
+	msg, err = renderCommitMessage("", "", changes)
+	testutil.Ok(t, err)
+	testutil.Equals(t, "bingo: update 3 tools\n\n"+
+		"* remove buf v0.1.0\n"+
+		"* add goimports v0.1.0\n"+
+		"* bump golangci-lint v1.55.2 → v1.59.0", msg)
+
+	msg, err = renderCommitMessage("", "update generated files", nil)
+	testutil.Ok(t, err)
+	testutil.Equals(t, "bingo: update generated files", msg)
+
+	msg, err = renderCommitMessage("chore(deps): {{ range .Changes }}{{ .Name }}@{{ .To }} {{ end }}", "", changes)
+	testutil.Ok(t, err)
+	testutil.Equals(t, "chore(deps): buf@ goimports@v0.1.0 golangci-lint@v1.59.0", msg)
+
+	_, err = renderCommitMessage("{{ .Nope }}", "", changes)
+	testutil.NotOk(t, err)
+	_, err = renderCommitMessage(" ", "", changes)
+	testutil.NotOk(t, err)
+}
+
+func TestCommit_Git(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not found")
+	}
+	wd, err := os.Getwd()
+	testutil.Ok(t, err)
+	repo := t.TempDir()
+	testutil.Ok(t, os.Chdir(repo))
+	t.Cleanup(func() { _ = os.Chdir(wd) })
+	for _, k := range []string{"GIT_AUTHOR_NAME", "GIT_COMMITTER_NAME"} {
+		t.Setenv(k, "test")
+	}
+	for _, k := range []string{"GIT_AUTHOR_EMAIL", "GIT_COMMITTER_EMAIL"} {
+		t.Setenv(k, "test@example.com")
+	}
+
+	ctx := context.Background()
+	_, err = git(ctx, "init", "-q")
+	testutil.Ok(t, err)
+	testutil.Ok(t, os.MkdirAll(".bingo", os.ModePerm))
+	testutil.Ok(t, ioutil.WriteFile(filepath.Join(".bingo", "faillint.mod"), []byte("module _\n\nrequire github.com/fatih/faillint v1.6.0\n"), os.ModePerm))
+	testutil.Ok(t, ioutil.WriteFile("other.txt", []byte("not a tool"), os.ModePerm))
+	_, err = git(ctx, "add", "other.txt")
+	testutil.Ok(t, err)
+	// Unrelated edit in mod directory, not written by get.
+	testutil.Ok(t, ioutil.WriteFile(filepath.Join(".bingo", "README.md"), []byte("edited"), os.ModePerm))
+
+	// Nothing changed, no commit.
+	testutil.Ok(t, commit(ctx, logging.Discard, &getter.Summary{}, "", false, ".bingo"))
+	_, err = git(ctx, "rev-parse", "HEAD")
+	testutil.NotOk(t, err)
+
+	testutil.Ok(t, commit(ctx, logging.Discard, &getter.Summary{Results: []getter.Result{{Name: "faillint", ModFile: "faillint.mod", Version: "v1.6.0"}}}, "", false, ".bingo",
+		filepath.Join(".bingo", "faillint.mod"), filepath.Join(".bingo", "faillint.tmp.sum")))
+	out, err := git(ctx, "log", "--format=%s")
+	testutil.Ok(t, err)
+	testutil.Equals(t, "bingo: add faillint v1.6.0", strings.TrimSpace(string(out)))
+	out, err = git(ctx, "show", "--name-only", "--format=", "HEAD")
+	testutil.Ok(t, err)
+	testutil.Equals(t, ".bingo/faillint.mod", strings.TrimSpace(string(out)))
+
+	// Other staged changes are left staged and other changes are left as they are.
+	out, err = git(ctx, "diff", "--cached", "--name-only")
+	testutil.Ok(t, err)
+	testutil.Equals(t, "other.txt", strings.TrimSpace(string(out)))
+	out, err = git(ctx, "status", "--porcelain", "--", ".bingo")
+	testutil.Ok(t, err)
+	testutil.Equals(t, "?? .bingo/README.md", strings.TrimSpace(string(out)))
+}
+
+func TestCommit_GitSplit(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not found")
+	}
+	wd, err := os.Getwd()
+	testutil.Ok(t, err)
+	repo := t.TempDir()
+	testutil.Ok(t, os.Chdir(repo))
+	t.Cleanup(func() { _ = os.Chdir(wd) })
+	for _, k := range []string{"GIT_AUTHOR_NAME", "GIT_COMMITTER_NAME"} {
+		t.Setenv(k, "test")
+	}
+	for _, k := range []string{"GIT_AUTHOR_EMAIL", "GIT_COMMITTER_EMAIL"} {
+		t.Setenv(k, "test@example.com")
+	}
+
+	ctx := context.Background()
+	_, err = git(ctx, "init", "-q")
+	testutil.Ok(t, err)
+	testutil.Ok(t, os.MkdirAll(".bingo", os.ModePerm))
+	var written []string
+	for _, f := range []string{"faillint.mod", "buf.mod", "buf.1.mod", "Variables.mk"} {
+		testutil.Ok(t, ioutil.WriteFile(filepath.Join(".bingo", f), []byte(f), os.ModePerm))
+		written = append(written, filepath.Join(".bingo", f))
+	}
+	testutil.Ok(t, ioutil.WriteFile(filepath.Join(".bingo", "README.md"), []byte("edited"), os.ModePerm))
+
+	testutil.Ok(t, commit(ctx, logging.Discard, &getter.Summary{Results: []getter.Result{
+		{Name: "faillint", ModFile: "faillint.mod", Version: "v1.6.0"},
+		{Name: "buf", ModFile: "buf.mod", Version: "v0.1.0"},
+		{Name: "buf", ModFile: "buf.1.mod", Version: "v0.2.0"},
+	}}, "deps: {{ .Summary }}", true, ".bingo", written...))
+
+	out, err := git(ctx, "log", "--reverse", "--format=%s", "--name-only")
+	testutil.Ok(t, err)
+	testutil.Equals(t, "deps: update buf\n\n.bingo/buf.1.mod\n.bingo/buf.mod\n"+
+		"deps: add faillint v1.6.0\n\n.bingo/faillint.mod\n"+
+		"deps: update generated files\n\n.bingo/Variables.mk", strings.TrimSpace(string(out)))
+}
@@ -0,0 +1,136 @@
+// Copyright (c) Bartłomiej Płotka @bwplotka
+// Licensed under the Apache License 2.0.
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"sort"
+	"strings"
+
+	"github.com/bwplotka/bingo/pkg/bingo"
+	"github.com/pkg/errors"
+)
+
+// completionCmd is a bingo command with its flags, as completed by shell completion scripts.
+type completionCmd struct {
+	name  string
+	flags []string
+	// withTools is true if command accepts pinned tool (or package) as an argument.
+	withTools bool
+}
+
+func newCompletionCmd(name string, flags *flag.FlagSet, withTools bool) completionCmd {
+	c := completionCmd{name: name, withTools: withTools}
+	if flags != nil {
+		flags.VisitAll(func(f *flag.Flag) { c.flags = append(c.flags, "-"+f.Name) })
+	}
+	return c
+}
+
+// completionTools prints names of all pinned tools and <name>@<version> for all their pinned versions, one per line.
+func completionTools(logger *slog.Logger, modDir string, w io.Writer) error {
+	pkgs, err := bingo.ListPinnedMainPackages(logger, modDir, false)
+	if err != nil {
+		return err
+	}
+	var lines []string
+	for _, p := range pkgs {
+		lines = append(lines, p.Name)
+		for _, v := range p.Versions {
+			lines = append(lines, fmt.Sprintf("%s@%s", p.Name, v.Version))
+		}
+	}
+	sort.Strings(lines)
+	_, err = fmt.Fprintln(w, strings.Join(lines, "\n"))
+	return err
+}
+
+// completionScript returns completion script for given shell. Scripts complete commands and their flags; pinned tools are
+// completed by invoking 'bingo completion -tools'.
+func completionScript(shell string, cmds []completionCmd) (string, error) {
+	var names, toolCmds []string
+	for _, c := range cmds {
+		names = append(names, c.name)
+		if c.withTools {
+			toolCmds = append(toolCmds, c.name)
+		}
+	}
+
+	b := &strings.Builder{}
+	switch shell {
+	case "bash":
+		_, _ = fmt.Fprintf(b, `# bash completion for bingo. Use it as: source <(bingo completion bash)
+_bingo() {
+	local cur="${COMP_WORDS[COMP_CWORD]}"
+	if [ "${COMP_CWORD}" -eq 1 ]; then
+		COMPREPLY=($(compgen -W "%s" -- "${cur}"))
+		return
+	fi
+	case "${cur}" in
+	-*)
+		case "${COMP_WORDS[1]}" in
+`, strings.Join(names, " "))
+		for _, c := range cmds {
+			_, _ = fmt.Fprintf(b, "\t\t%s) COMPREPLY=($(compgen -W \"%s\" -- \"${cur}\")) ;;\n", c.name, strings.Join(c.flags, " "))
+		}
+		_, _ = fmt.Fprintf(b, `		esac
+		return
+		;;
+	esac
+	case "${COMP_WORDS[1]}" in
+	%s) COMPREPLY=($(compgen -W "$(bingo completion -tools 2>/dev/null)" -- "${cur}")) ;;
+	esac
+}
+complete -F _bingo bingo
+`, strings.Join(toolCmds, "|"))
+	case "zsh":
+		_, _ = fmt.Fprintf(b, `#compdef bingo
+# zsh completion for bingo. Use it as: source <(bingo completion zsh)
+_bingo() {
+	local -a opts
+	if (( CURRENT == 2 )); then
+		opts=(%s)
+		compadd -a opts
+		return
+	fi
+	case "${words[CURRENT]}" in
+	-*)
+		case "${words[2]}" in
+`, strings.Join(names, " "))
+		for _, c := range cmds {
+			_, _ = fmt.Fprintf(b, "\t\t%s) opts=(%s) ;;\n", c.name, strings.Join(c.flags, " "))
+		}
+		_, _ = fmt.Fprintf(b, `		esac
+		compadd -a opts
+		return
+		;;
+	esac
+	case "${words[2]}" in
+	%s)
+		opts=(${(f)"$(bingo completion -tools 2>/dev/null)"})
+		compadd -a opts
+		;;
+	esac
+}
+compdef _bingo bingo
+`, strings.Join(toolCmds, "|"))
+	case "fish":
+		_, _ = fmt.Fprintf(b, `# fish completion for bingo. Use it as: bingo completion fish | source
+complete -c bingo -f
+complete -c bingo -n "__fish_use_subcommand" -a "%s"
+`, strings.Join(names, " "))
+		for _, c := range cmds {
+			for _, f := range c.flags {
+				_, _ = fmt.Fprintf(b, "complete -c bingo -n \"__fish_seen_subcommand_from %s\" -o %s\n", c.name, strings.TrimPrefix(f, "-"))
+			}
+		}
+		_, _ = fmt.Fprintf(b, "complete -c bingo -n \"__fish_seen_subcommand_from %s\" -a \"(bingo completion -tools 2>/dev/null)\"\n", strings.Join(toolCmds, " "))
+	default:
+		return "", errors.Errorf("unsupported shell %q; expected one of bash, zsh, fish", shell)
+	}
+	return b.String(), nil
+}
@@ -0,0 +1,48 @@
+// Copyright (c) Bartłomiej Płotka @bwplotka
+// Licensed under the Apache License 2.0.
+
+package main
+
+import (
+	"flag"
+	"strings"
+	"testing"
+
+	"github.com/efficientgo/tools/core/pkg/testutil"
+)
+
+func TestCompletionScript(t *testing.T) {
+	getFlags := flag.NewFlagSet("get", flag.ContinueOnError)
+	_ = getFlags.String("moddir", "", "")
+	_ = getFlags.Bool("u", false, "")
+	cmds := []completionCmd{newCompletionCmd("get", getFlags, true), newCompletionCmd("version", nil, false)}
+
+	_, err := completionScript("powershell", cmds)
+	testutil.NotOk(t, err)
+
+	for shell, expected := range map[string][]string{
+		"bash": {
+			`COMPREPLY=($(compgen -W "get version" -- "${cur}"))`,
+			`get) COMPREPLY=($(compgen -W "-moddir -u" -- "${cur}")) ;;`,
+			`get) COMPREPLY=($(compgen -W "$(bingo completion -tools 2>/dev/null)" -- "${cur}")) ;;`,
+		},
+		"zsh": {
+			`opts=(get version)`,
+			`get) opts=(-moddir -u) ;;`,
+			`opts=(${(f)"$(bingo completion -tools 2>/dev/null)"})`,
+		},
+		"fish": {
+			`complete -c bingo -n "__fish_use_subcommand" -a "get version"`,
+			`complete -c bingo -n "__fish_seen_subcommand_from get" -o moddir`,
+			`complete -c bingo -n "__fish_seen_subcommand_from get" -a "(bingo completion -tools 2>/dev/null)"`,
+		},
+	} {
+		t.Run(shell, func(t *testing.T) {
+			s, err := completionScript(shell, cmds)
+			testutil.Ok(t, err)
+			for _, e := range expected {
+				testutil.Assert(t, strings.Contains(s, e), "%q not found in %v", e, s)
+			}
+		})
+	}
+}
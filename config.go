@@ -0,0 +1,214 @@
+// Copyright (c) Bartłomiej Płotka @bwplotka
+// Licensed under the Apache License 2.0.
+
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/efficientgo/tools/core/pkg/errcapture"
+	"github.com/pkg/errors"
+)
+
+// configFile is a path of project configuration file relative to the project root.
+var configFile = filepath.Join(".bingo", "config")
+
+// configEntry is a single key and value from project configuration file or environment variable.
+type configEntry struct {
+	// source is where entry comes from, e.g "line 3" or "BINGO_TIMEOUT", used in errors.
+	source     string
+	key, value string
+}
+
+// projectConfig holds defaults from project configuration file.
+type projectConfig struct {
+	// flags are default values of flags. Keys are flag names (e.g "moddir"), applied to all commands with such flag, or
+	// flag names scoped with command name (e.g "get.link-mode").
+	flags []configEntry
+	// env are environment variables (upper case keys, e.g "GOFLAGS") set for bingo and commands it runs, unless they
+	// are already set.
+	env []configEntry
+}
+
+// parseConfig parses project configuration file. It accepts the flat subset of both TOML and YAML: one 'key = value'
+// or 'key: value' per line, optionally quoted values, '#' comments and TOML '[command]' tables scoping following keys
+// to given command.
+func parseConfig(r io.Reader) (projectConfig, error) {
+	var (
+		cfg     projectConfig
+		section string
+	)
+	s := bufio.NewScanner(r)
+	for line := 1; s.Scan(); line++ {
+		l := strings.TrimSpace(s.Text())
+		if l == "" || strings.HasPrefix(l, "#") {
+			continue
+		}
+		if strings.HasPrefix(l, "[") {
+			if !strings.HasSuffix(l, "]") || strings.TrimSpace(l[1:len(l)-1]) == "" {
+				return projectConfig{}, errors.Errorf("line %d: malformed table %q", line, l)
+			}
+			section = strings.TrimSpace(l[1:len(l)-1]) + "."
+			continue
+		}
+
+		i := strings.IndexAny(l, "=:")
+		if i <= 0 {
+			return projectConfig{}, errors.Errorf("line %d: expected 'key = value' or 'key: value', got %q", line, l)
+		}
+		key := strings.TrimSpace(l[:i])
+		value, err := configValue(strings.TrimSpace(l[i+1:]))
+		if err != nil {
+			return projectConfig{}, errors.Wrapf(err, "line %d", line)
+		}
+		if key == strings.ToUpper(key) && !strings.Contains(key, ".") {
+			cfg.env = append(cfg.env, configEntry{source: fmt.Sprintf("line %d", line), key: key, value: value})
+			continue
+		}
+		cfg.flags = append(cfg.flags, configEntry{source: fmt.Sprintf("line %d", line), key: section + key, value: value})
+	}
+	return cfg, s.Err()
+}
+
+// configValue returns unquoted value, without trailing comment if not quoted.
+func configValue(v string) (string, error) {
+	switch {
+	case strings.HasPrefix(v, `"`):
+		return strconv.Unquote(v)
+	case strings.HasPrefix(v, `'`):
+		if len(v) < 2 || !strings.HasSuffix(v, `'`) {
+			return "", errors.Errorf("unterminated quoted value %s", v)
+		}
+		return v[1 : len(v)-1], nil
+	}
+	if i := strings.Index(v, " #"); i >= 0 {
+		v = v[:i]
+	}
+	return strings.TrimSpace(v), nil
+}
+
+// apply sets environment variables that are not set yet and sets flags to configured values. It has to be used before
+// flags are parsed, so flags given on command line take precedence. Main bingo flags can be configured only without
+// command scope.
+func (c projectConfig) apply(mainFlags *flag.FlagSet, cmdFlags ...*flag.FlagSet) error {
+	for _, e := range c.env {
+		if _, ok := os.LookupEnv(e.key); ok {
+			continue
+		}
+		if err := os.Setenv(e.key, e.value); err != nil {
+			return errors.Wrapf(err, "%s: set %v", e.source, e.key)
+		}
+	}
+
+	for _, e := range c.flags {
+		fss := append([]*flag.FlagSet{mainFlags}, cmdFlags...)
+		name := e.key
+		if cmd, n, ok := strings.Cut(e.key, "."); ok {
+			name = n
+			fss = nil
+			for _, fs := range cmdFlags {
+				if fs.Name() == "bingo "+cmd {
+					fss = append(fss, fs)
+				}
+			}
+			if len(fss) == 0 {
+				return errors.Errorf("%s: unknown command %q", e.source, cmd)
+			}
+		}
+
+		found := false
+		for _, fs := range fss {
+			if fs.Lookup(name) == nil {
+				continue
+			}
+			found = true
+			if err := fs.Set(name, e.value); err != nil {
+				return errors.Errorf("%s: invalid value %q for %v: %v", e.source, e.value, e.key, err)
+			}
+		}
+		if !found {
+			return errors.Errorf("%s: unknown flag %q", e.source, e.key)
+		}
+	}
+	return nil
+}
+
+// loadConfig applies project configuration file (.bingo/config) found in the current directory or closest parent
+// directory with .bingo directory, if any. It returns path of applied file or empty string if there is none.
+func loadConfig(mainFlags *flag.FlagSet, cmdFlags ...*flag.FlagSet) (_ string, err error) {
+	wd, err := os.Getwd()
+	if err != nil {
+		return "", errors.Wrap(err, "getwd")
+	}
+	root, ok := findProjectRoot(wd, filepath.Dir(configFile))
+	if !ok {
+		return "", nil
+	}
+	path := filepath.Join(root, configFile)
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", nil
+		}
+		return "", err
+	}
+	defer errcapture.Do(&err, f.Close, "close")
+
+	cfg, err := parseConfig(f)
+	if err != nil {
+		return "", errors.Wrap(err, path)
+	}
+	return path, errors.Wrap(cfg.apply(mainFlags, cmdFlags...), path)
+}
+
+// envFlags maps BINGO_* environment variables to flags they set, in the same form as project configuration file keys.
+var envFlags = []struct{ env, key string }{
+	{env: "BINGO_MODDIR", key: "moddir"},
+	{env: "BINGO_LINK", key: "get.l"},
+	{env: "BINGO_LINK_MODE", key: "get.link-mode"},
+	{env: "BINGO_STORE", key: "get.store"},
+	{env: "BINGO_TIMEOUT", key: "get.timeout"},
+	{env: "BINGO_REGISTRY", key: "registry"},
+}
+
+// envVars maps BINGO_* environment variables to environment variables they override for bingo and commands it runs.
+var envVars = []struct{ env, key string }{
+	{env: "BINGO_GOBIN", key: "GOBIN"},
+	{env: "BINGO_GOFLAGS", key: "GOFLAGS"},
+}
+
+// envConfig returns configuration from non-empty BINGO_* environment variables (see envFlags and envVars).
+func envConfig(lookupEnv func(string) (string, bool)) projectConfig {
+	var cfg projectConfig
+	for _, e := range envFlags {
+		if v, ok := lookupEnv(e.env); ok && v != "" {
+			cfg.flags = append(cfg.flags, configEntry{source: e.env, key: e.key, value: v})
+		}
+	}
+	for _, e := range envVars {
+		if v, ok := lookupEnv(e.env); ok && v != "" {
+			cfg.env = append(cfg.env, configEntry{source: e.env, key: e.key, value: v})
+		}
+	}
+	return cfg
+}
+
+// loadEnvConfig applies configuration from BINGO_* environment variables. It has to be used after loadConfig and
+// before flags are parsed, so it overrides project configuration file, but not flags given on command line.
+func loadEnvConfig(mainFlags *flag.FlagSet, cmdFlags ...*flag.FlagSet) error {
+	cfg := envConfig(os.LookupEnv)
+	for _, e := range cfg.env {
+		if err := os.Setenv(e.key, e.value); err != nil {
+			return errors.Wrapf(err, "%s: set %v", e.source, e.key)
+		}
+	}
+	cfg.env = nil
+	return cfg.apply(mainFlags, cmdFlags...)
+}
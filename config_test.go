@@ -0,0 +1,155 @@
+// Copyright (c) Bartłomiej Płotka @bwplotka
+// Licensed under the Apache License 2.0.
+
+package main
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/efficientgo/tools/core/pkg/testutil"
+)
+
+func TestParseConfig(t *testing.T) {
+	for _, tcase := range []struct {
+		name     string
+		config   string
+		expected projectConfig
+	}{
+		{
+			name: "toml",
+			config: `# Project defaults.
+moddir = ".bingo"
+GOFLAGS = "-mod=mod"
+
+[get]
+link-mode = 'symlink'
+timeout = 10m # Slow CI.
+`,
+			expected: projectConfig{
+				flags: []configEntry{{source: "line 2", key: "moddir", value: ".bingo"}, {source: "line 6", key: "get.link-mode", value: "symlink"}, {source: "line 7", key: "get.timeout", value: "10m"}},
+				env:   []configEntry{{source: "line 3", key: "GOFLAGS", value: "-mod=mod"}},
+			},
+		},
+		{
+			name: "yaml",
+			config: `moddir: .bingo
+get.gen-policy: README.md=if-missing
+GOFLAGS: -mod=mod
+`,
+			expected: projectConfig{
+				flags: []configEntry{{source: "line 1", key: "moddir", value: ".bingo"}, {source: "line 2", key: "get.gen-policy", value: "README.md=if-missing"}},
+				env:   []configEntry{{source: "line 3", key: "GOFLAGS", value: "-mod=mod"}},
+			},
+		},
+	} {
+		t.Run(tcase.name, func(t *testing.T) {
+			cfg, err := parseConfig(strings.NewReader(tcase.config))
+			testutil.Ok(t, err)
+			testutil.Equals(t, tcase.expected, cfg)
+		})
+	}
+
+	for _, invalid := range []string{"moddir", "[]", "[get", "moddir = \"unterminated", "= value"} {
+		_, err := parseConfig(strings.NewReader(invalid))
+		testutil.NotOk(t, err, invalid)
+	}
+}
+
+func TestProjectConfigApply(t *testing.T) {
+	newFlags := func() (main, get, list *flag.FlagSet) {
+		main = flag.NewFlagSet("bingo", flag.ContinueOnError)
+		main.Bool("v", false, "")
+		get = flag.NewFlagSet("bingo get", flag.ContinueOnError)
+		get.String("moddir", ".bingo", "")
+		get.Bool("v", false, "")
+		get.Duration("timeout", time.Minute, "")
+		list = flag.NewFlagSet("bingo list", flag.ContinueOnError)
+		list.String("moddir", ".bingo", "")
+		return main, get, list
+	}
+
+	t.Setenv("BINGO_TEST_SET", "env")
+	testutil.Ok(t, os.Unsetenv("BINGO_TEST_UNSET"))
+	t.Cleanup(func() { _ = os.Unsetenv("BINGO_TEST_UNSET") })
+
+	cfg, err := parseConfig(strings.NewReader(`moddir = tools/.bingo
+v = true
+BINGO_TEST_SET = config
+BINGO_TEST_UNSET = config
+
+[get]
+timeout = 10m
+`))
+	testutil.Ok(t, err)
+
+	mainFlags, getFlags, listFlags := newFlags()
+	testutil.Ok(t, cfg.apply(mainFlags, getFlags, listFlags))
+	testutil.Equals(t, "true", mainFlags.Lookup("v").Value.String())
+	testutil.Equals(t, "true", getFlags.Lookup("v").Value.String())
+	testutil.Equals(t, "tools/.bingo", getFlags.Lookup("moddir").Value.String())
+	testutil.Equals(t, "tools/.bingo", listFlags.Lookup("moddir").Value.String())
+	testutil.Equals(t, "10m0s", getFlags.Lookup("timeout").Value.String())
+	testutil.Equals(t, "env", os.Getenv("BINGO_TEST_SET"))
+	testutil.Equals(t, "config", os.Getenv("BINGO_TEST_UNSET"))
+
+	// Command line takes precedence.
+	testutil.Ok(t, getFlags.Parse([]string{"-timeout", "1s"}))
+	testutil.Equals(t, "1s", getFlags.Lookup("timeout").Value.String())
+
+	for _, invalid := range []string{"not-existing = 1", "up.moddir = .bingo", "list.timeout = 1m", "get.timeout = soon"} {
+		cfg, err := parseConfig(strings.NewReader(invalid))
+		testutil.Ok(t, err)
+		mainFlags, getFlags, listFlags := newFlags()
+		testutil.NotOk(t, cfg.apply(mainFlags, getFlags, listFlags), invalid)
+	}
+}
+
+func TestLoadConfig(t *testing.T) {
+	wd, err := os.Getwd()
+	testutil.Ok(t, err)
+	t.Cleanup(func() { _ = os.Chdir(wd) })
+
+	root, err := filepath.EvalSymlinks(t.TempDir())
+	testutil.Ok(t, err)
+	sub := filepath.Join(root, "a")
+	testutil.Ok(t, os.MkdirAll(sub, os.ModePerm))
+	testutil.Ok(t, os.MkdirAll(filepath.Join(root, ".bingo"), os.ModePerm))
+
+	fs := flag.NewFlagSet("bingo get", flag.ContinueOnError)
+	modDir := fs.String("moddir", ".bingo", "")
+	testutil.Ok(t, os.Chdir(sub))
+
+	// No configuration file.
+	path, err := loadConfig(flag.NewFlagSet("bingo", flag.ContinueOnError), fs)
+	testutil.Ok(t, err)
+	testutil.Equals(t, "", path)
+
+	testutil.Ok(t, os.WriteFile(filepath.Join(root, configFile), []byte("moddir = tools\n"), os.ModePerm))
+	path, err = loadConfig(flag.NewFlagSet("bingo", flag.ContinueOnError), fs)
+	testutil.Ok(t, err)
+	testutil.Equals(t, filepath.Join(root, configFile), path)
+	testutil.Equals(t, "tools", *modDir)
+}
+
+func TestEnvConfig(t *testing.T) {
+	env := map[string]string{
+		"BINGO_MODDIR":  "tools/.bingo",
+		"BINGO_TIMEOUT": "10m",
+		"BINGO_LINK":    "",
+		"BINGO_GOFLAGS": "-mod=mod",
+		"GOFLAGS":       "-mod=readonly",
+	}
+	cfg := envConfig(func(k string) (string, bool) {
+		v, ok := env[k]
+		return v, ok
+	})
+	testutil.Equals(t, projectConfig{
+		flags: []configEntry{{source: "BINGO_MODDIR", key: "moddir", value: "tools/.bingo"}, {source: "BINGO_TIMEOUT", key: "get.timeout", value: "10m"}},
+		env:   []configEntry{{source: "BINGO_GOFLAGS", key: "GOFLAGS", value: "-mod=mod"}},
+	}, cfg)
+}
@@ -0,0 +1,208 @@
+// Copyright (c) Bartłomiej Płotka @bwplotka
+// Licensed under the Apache License 2.0.
+
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/bwplotka/bingo/pkg/bingo"
+	"github.com/efficientgo/tools/core/pkg/errcapture"
+	"github.com/pkg/errors"
+	"golang.org/x/mod/modfile"
+)
+
+// knownTools maps binary names of well-known Go tools to their main packages. Bingo detect reports their invocations.
+var knownTools = map[string]string{
+	"buf":                "github.com/bufbuild/buf/cmd/buf",
+	"controller-gen":     "sigs.k8s.io/controller-tools/cmd/controller-gen",
+	"faillint":           "github.com/fatih/faillint",
+	"gofumpt":            "mvdan.cc/gofumpt",
+	"goimports":          "golang.org/x/tools/cmd/goimports",
+	"golangci-lint":      "github.com/golangci/golangci-lint/cmd/golangci-lint",
+	"goreleaser":         "github.com/goreleaser/goreleaser",
+	"gotestsum":          "gotest.tools/gotestsum",
+	"govulncheck":        "golang.org/x/vuln/cmd/govulncheck",
+	"jsonnet":            "github.com/google/go-jsonnet/cmd/jsonnet",
+	"mdox":               "github.com/bwplotka/mdox",
+	"misspell":           "github.com/client9/misspell/cmd/misspell",
+	"mockgen":            "go.uber.org/mock/mockgen",
+	"protoc-gen-go":      "google.golang.org/protobuf/cmd/protoc-gen-go",
+	"protoc-gen-go-grpc": "google.golang.org/grpc/cmd/protoc-gen-go-grpc",
+	"revive":             "github.com/mgechev/revive",
+	"staticcheck":        "honnef.co/go/tools/cmd/staticcheck",
+	"stringer":           "golang.org/x/tools/cmd/stringer",
+	"swag":               "github.com/swaggo/swag/cmd/swag",
+}
+
+var (
+	goRunOrInstallRe = regexp.MustCompile(`\bgo\s+(?:run|install)\s+(?:-\S+\s+)*([^\s@;&|)"'` + "`" + `]+)(?:@([^\s;&|)"'` + "`" + `]+))?`)
+	knownToolRe      = regexp.MustCompile(`(?:^|[\s;&|(/@"'` + "`" + `])(` + knownToolNames() + `)(?:$|[\s;&|)"'` + "`" + `])`)
+)
+
+func knownToolNames() string {
+	names := make([]string, 0, len(knownTools))
+	for n := range knownTools {
+		names = append(names, regexp.QuoteMeta(n))
+	}
+	// Longest first, so e.g protoc-gen-go-grpc is not matched as protoc-gen-go.
+	sort.Slice(names, func(i, j int) bool { return len(names[i]) > len(names[j]) })
+	return strings.Join(names, "|")
+}
+
+// unpinnedTool is an invocation of Go tool not pinned by bingo, found by bingo detect.
+type unpinnedTool struct {
+	File    string `json:"file"`
+	Line    int    `json:"line"`
+	Tool    string `json:"tool"`
+	Package string `json:"package"`
+	// Command is a suggested command pinning the tool.
+	Command string `json:"command"`
+}
+
+// isScanned returns true if file with given path relative to the scanned directory can invoke Go tools: Makefiles,
+// shell scripts, Dockerfiles and GitHub workflows.
+func isScanned(rel string) bool {
+	base := filepath.Base(rel)
+	switch {
+	case base == "Makefile", base == "makefile", base == "GNUmakefile", strings.HasPrefix(base, "Dockerfile"):
+		return true
+	}
+	switch filepath.Ext(base) {
+	case ".mk", ".sh", ".bash", ".dockerfile":
+		return true
+	case ".yml", ".yaml":
+		return filepath.ToSlash(filepath.Dir(rel)) == ".github/workflows"
+	}
+	return false
+}
+
+// detectUnpinned scans files in given directory (except mod directory, vendor and hidden directories other than .github) for
+// invocations of well-known Go tools and 'go run' or 'go install' of packages outside of the project module, which are not
+// pinned by any of given tools.
+func detectUnpinned(dir, modDir string, pkgs bingo.PackageRenderables) ([]unpinnedTool, error) {
+	pinned := map[string]struct{}{}
+	for _, p := range pkgs {
+		pinned[p.Name] = struct{}{}
+		pinned[p.PackagePath] = struct{}{}
+		for _, a := range p.AliasNames() {
+			pinned[a] = struct{}{}
+		}
+	}
+	// Packages of the project itself are built from its sources, no need to pin them.
+	var projectModule string
+	if b, err := ioutil.ReadFile(filepath.Join(dir, "go.mod")); err == nil {
+		projectModule = modfile.ModulePath(b)
+	}
+
+	var found []unpinnedTool
+	err := filepath.Walk(dir, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			if rel != "." && (p == modDir || info.Name() == "vendor" || info.Name() == "node_modules" ||
+				strings.HasPrefix(info.Name(), ".") && info.Name() != ".github") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !isScanned(rel) {
+			return nil
+		}
+		f, err := scanFile(p, rel, projectModule, pinned)
+		found = append(found, f...)
+		return err
+	})
+	return found, err
+}
+
+func scanFile(p, rel, projectModule string, pinned map[string]struct{}) (_ []unpinnedTool, err error) {
+	f, err := os.Open(p)
+	if err != nil {
+		return nil, err
+	}
+	defer errcapture.Do(&err, f.Close, "close")
+
+	var found []unpinnedTool
+	s := bufio.NewScanner(f)
+	for line := 1; s.Scan(); line++ {
+		l := strings.TrimSpace(s.Text())
+		if strings.HasPrefix(l, "#") {
+			continue
+		}
+		seen := map[string]struct{}{}
+		add := func(tool, pkg, version string) {
+			if _, ok := pinned[tool]; ok {
+				return
+			}
+			if _, ok := pinned[pkg]; ok {
+				return
+			}
+			if _, ok := seen[pkg]; ok {
+				return
+			}
+			seen[pkg] = struct{}{}
+			cmd := "bingo get " + pkg
+			if version != "" && version != "latest" {
+				cmd += "@" + version
+			}
+			found = append(found, unpinnedTool{File: filepath.ToSlash(rel), Line: line, Tool: tool, Package: pkg, Command: cmd})
+		}
+
+		for _, m := range goRunOrInstallRe.FindAllStringSubmatch(l, -1) {
+			pkg := m[1]
+			// Only remote packages, e.g not ./cmd/tool or std ones.
+			if !strings.Contains(strings.SplitN(pkg, "/", 2)[0], ".") {
+				continue
+			}
+			if projectModule != "" && (pkg == projectModule || strings.HasPrefix(pkg, projectModule+"/")) {
+				continue
+			}
+			add(path.Base(pkg), pkg, m[2])
+		}
+		for _, m := range knownToolRe.FindAllStringSubmatch(l, -1) {
+			add(m[1], knownTools[m[1]], "")
+		}
+	}
+	return found, s.Err()
+}
+
+// printUnpinned prints unpinned tools as aligned table or, if asJSON is true, as JSON.
+func printUnpinned(w io.Writer, found []unpinnedTool, asJSON bool) error {
+	if asJSON {
+		if found == nil {
+			found = []unpinnedTool{}
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(found)
+	}
+	if len(found) == 0 {
+		_, err := fmt.Fprintln(w, "No unpinned tools found.")
+		return err
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "File\tTool\tSuggested Command")
+	_, _ = fmt.Fprintln(tw, "----\t----\t-----------------")
+	for _, f := range found {
+		_, _ = fmt.Fprintf(tw, "%s:%d\t%s\t%s\n", f.File, f.Line, f.Tool, f.Command)
+	}
+	return errors.Wrap(tw.Flush(), "flush")
+}
@@ -0,0 +1,68 @@
+// Copyright (c) Bartłomiej Płotka @bwplotka
+// Licensed under the Apache License 2.0.
+
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bwplotka/bingo/pkg/bingo"
+	"github.com/efficientgo/tools/core/pkg/testutil"
+)
+
+func TestDetectUnpinned(t *testing.T) {
+	dir := t.TempDir()
+	modDir := filepath.Join(dir, ".bingo")
+	for f, content := range map[string]string{
+		"go.mod": "module github.com/org/project\n",
+		"Makefile": "lint:\n\t# golangci-lint is run below.\n\tgolangci-lint run ./...\n\t$(FAILLINT) -paths fmt\n" +
+			"\tgo run github.com/org/project/cmd/gen\n\tgo run -mod=mod github.com/x/tool/cmd/tool@v1.2.0 && $(GOBIN)/protoc-gen-go-grpc\n",
+		"scripts/gen.sh":            "#!/bin/sh\ngo install golang.org/x/tools/cmd/goimports@latest\ngoimports -w .\n",
+		"Dockerfile":                "FROM golang\nRUN go install github.com/bufbuild/buf/cmd/buf@v1.0.0\n",
+		".github/workflows/ci.yaml": "jobs:\n  lint:\n    steps:\n      - run: make lint && mdox fmt\n",
+		".github/dependabot.yml":    "mdox\n",
+		"docs/README.md":            "golangci-lint\n",
+		"vendor/a/Makefile":         "golangci-lint\n",
+		".bingo/Variables.mk":       "golangci-lint\n",
+		".bingo/golangci-lint.mod":  "module _\n\nrequire github.com/golangci/golangci-lint v1.55.2 // cmd/golangci-lint\n",
+	} {
+		testutil.Ok(t, os.MkdirAll(filepath.Dir(filepath.Join(dir, f)), os.ModePerm))
+		testutil.Ok(t, ioutil.WriteFile(filepath.Join(dir, f), []byte(content), os.ModePerm))
+	}
+
+	found, err := detectUnpinned(dir, modDir, nil)
+	testutil.Ok(t, err)
+	testutil.Equals(t, []unpinnedTool{
+		{File: ".github/workflows/ci.yaml", Line: 4, Tool: "mdox", Package: "github.com/bwplotka/mdox", Command: "bingo get github.com/bwplotka/mdox"},
+		{File: "Dockerfile", Line: 2, Tool: "buf", Package: "github.com/bufbuild/buf/cmd/buf", Command: "bingo get github.com/bufbuild/buf/cmd/buf@v1.0.0"},
+		{File: "Makefile", Line: 3, Tool: "golangci-lint", Package: "github.com/golangci/golangci-lint/cmd/golangci-lint", Command: "bingo get github.com/golangci/golangci-lint/cmd/golangci-lint"},
+		{File: "Makefile", Line: 6, Tool: "tool", Package: "github.com/x/tool/cmd/tool", Command: "bingo get github.com/x/tool/cmd/tool@v1.2.0"},
+		{File: "Makefile", Line: 6, Tool: "protoc-gen-go-grpc", Package: "google.golang.org/grpc/cmd/protoc-gen-go-grpc", Command: "bingo get google.golang.org/grpc/cmd/protoc-gen-go-grpc"},
+		{File: "scripts/gen.sh", Line: 2, Tool: "goimports", Package: "golang.org/x/tools/cmd/goimports", Command: "bingo get golang.org/x/tools/cmd/goimports"},
+		{File: "scripts/gen.sh", Line: 3, Tool: "goimports", Package: "golang.org/x/tools/cmd/goimports", Command: "bingo get golang.org/x/tools/cmd/goimports"},
+	}, found)
+
+	pkgs, err := bingo.ListPinnedMainPackages(nil, modDir, false)
+	testutil.Ok(t, err)
+	found, err = detectUnpinned(dir, modDir, pkgs)
+	testutil.Ok(t, err)
+	testutil.Equals(t, 6, len(found))
+	for _, f := range found {
+		testutil.Assert(t, f.Tool != "golangci-lint", "golangci-lint is pinned")
+	}
+
+	b := &bytes.Buffer{}
+	testutil.Ok(t, printUnpinned(b, found[:1], false))
+	testutil.Equals(t, `File                         Tool  Suggested Command
+----                         ----  -----------------
+.github/workflows/ci.yaml:4  mdox  bingo get github.com/bwplotka/mdox
+`, b.String())
+
+	b.Reset()
+	testutil.Ok(t, printUnpinned(b, nil, false))
+	testutil.Equals(t, "No unpinned tools found.\n", b.String())
+}
@@ -0,0 +1,144 @@
+// Copyright (c) Bartłomiej Płotka @bwplotka
+// Licensed under the Apache License 2.0.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/bwplotka/bingo/pkg/bingo"
+	"github.com/bwplotka/bingo/pkg/logging"
+	"github.com/pkg/errors"
+)
+
+// git runs git command in current directory and returns its stdout.
+func git(ctx context.Context, args ...string) ([]byte, error) {
+	stderr := &bytes.Buffer{}
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Stderr = stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, errors.Wrapf(err, "git %s: %s", strings.Join(args, " "), strings.TrimSpace(stderr.String()))
+	}
+	return out, nil
+}
+
+// pinnedAt returns tools pinned in given mod directory at given git ref, or in the working tree if ref is empty.
+func pinnedAt(ctx context.Context, logger logging.Logger, relModDir, ref string) (bingo.PackageRenderables, error) {
+	if ref == "" {
+		return bingo.ListPinnedMainPackages(logger, relModDir, false)
+	}
+
+	out, err := git(ctx, "ls-tree", "--name-only", ref, "--", filepath.ToSlash(filepath.Clean(relModDir))+"/")
+	if err != nil {
+		return nil, err
+	}
+	tmpDir, err := ioutil.TempDir("", "bingo-diff")
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = os.RemoveAll(tmpDir) }()
+
+	for _, p := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+		if !strings.HasSuffix(p, ".mod") {
+			continue
+		}
+		b, err := git(ctx, "show", ref+":./"+p)
+		if err != nil {
+			return nil, err
+		}
+		if err := ioutil.WriteFile(filepath.Join(tmpDir, filepath.Base(p)), b, os.ModePerm); err != nil {
+			return nil, err
+		}
+	}
+	return bingo.ListPinnedMainPackages(logger, tmpDir, false)
+}
+
+// diffPinned returns human readable changes between two sets of pinned tools, sorted by tool name:
+// "+" for added tools, "-" for removed ones and "~" for changed versions, package, build env vars or flags.
+func diffPinned(from, to bingo.PackageRenderables) []string {
+	fromByName := map[string]bingo.PackageRenderable{}
+	for _, p := range from {
+		fromByName[p.Name] = p
+	}
+	toByName := map[string]bingo.PackageRenderable{}
+	for _, p := range to {
+		toByName[p.Name] = p
+	}
+
+	var names []string
+	for n := range fromByName {
+		names = append(names, n)
+	}
+	for n := range toByName {
+		if _, ok := fromByName[n]; !ok {
+			names = append(names, n)
+		}
+	}
+	sort.Strings(names)
+
+	var changes []string
+	for _, n := range names {
+		f, inFrom := fromByName[n]
+		t, inTo := toByName[n]
+		switch {
+		case !inFrom:
+			changes = append(changes, fmt.Sprintf("+ %s: %s@%s", n, t.PackagePath, versions(t)))
+		case !inTo:
+			changes = append(changes, fmt.Sprintf("- %s: %s@%s", n, f.PackagePath, versions(f)))
+		default:
+			if fv, tv := versions(f), versions(t); fv != tv {
+				changes = append(changes, fmt.Sprintf("~ %s: %s -> %s", n, fv, tv))
+			}
+			if f.PackagePath != t.PackagePath {
+				changes = append(changes, fmt.Sprintf("~ %s: package %s -> %s", n, f.PackagePath, t.PackagePath))
+			}
+			if fe, te := strings.Join(f.BuildEnvVars, " "), strings.Join(t.BuildEnvVars, " "); fe != te {
+				changes = append(changes, fmt.Sprintf("~ %s: build env vars %q -> %q", n, fe, te))
+			}
+			if ff, tf := strings.Join(f.BuildFlags, " "), strings.Join(t.BuildFlags, " "); ff != tf {
+				changes = append(changes, fmt.Sprintf("~ %s: build flags %q -> %q", n, ff, tf))
+			}
+		}
+	}
+	return changes
+}
+
+func versions(p bingo.PackageRenderable) string {
+	v := make([]string, 0, len(p.Versions))
+	for _, pv := range p.Versions {
+		v = append(v, pv.Version)
+	}
+	return strings.Join(v, ",")
+}
+
+// diff prints changes of tools pinned in given mod directory between two git refs. Empty ref means the working tree.
+func diff(ctx context.Context, logger logging.Logger, relModDir, fromRef, toRef string, w io.Writer) error {
+	from, err := pinnedAt(ctx, logger, relModDir, fromRef)
+	if err != nil {
+		return errors.Wrapf(err, "list pinned tools at %q", fromRef)
+	}
+	to, err := pinnedAt(ctx, logger, relModDir, toRef)
+	if err != nil {
+		return errors.Wrapf(err, "list pinned tools at %q", toRef)
+	}
+	bingo.SortRenderables(from)
+	bingo.SortRenderables(to)
+
+	changes := diffPinned(from, to)
+	if len(changes) == 0 {
+		_, err := fmt.Fprintln(w, "No changes in pinned tools.")
+		return err
+	}
+	_, err = fmt.Fprintln(w, strings.Join(changes, "\n"))
+	return err
+}
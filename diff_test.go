@@ -0,0 +1,71 @@
+// Copyright (c) Bartłomiej Płotka @bwplotka
+// Licensed under the Apache License 2.0.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/bwplotka/bingo/pkg/bingo"
+	"github.com/bwplotka/bingo/pkg/logging"
+	"github.com/efficientgo/tools/core/pkg/testutil"
+)
+
+func TestDiffPinned(t *testing.T) {
+	from := bingo.PackageRenderables{
+		{Name: "buf", PackagePath: "github.com/bufbuild/buf/cmd/buf", Versions: []bingo.PackageVersionRenderable{{Version: "v0.1.0"}}},
+		{Name: "faillint", PackagePath: "github.com/fatih/faillint", Versions: []bingo.PackageVersionRenderable{{Version: "v1.5.0"}}},
+		{Name: "hugo", PackagePath: "github.com/gohugoio/hugo", BuildEnvVars: []string{"CGO_ENABLED=1"}, Versions: []bingo.PackageVersionRenderable{{Version: "v0.83.1"}}},
+	}
+	to := bingo.PackageRenderables{
+		{Name: "faillint", PackagePath: "github.com/fatih/faillint", Versions: []bingo.PackageVersionRenderable{{Version: "v1.5.0"}, {Version: "v1.6.0"}}},
+		{Name: "goimports", PackagePath: "golang.org/x/tools/cmd/goimports", Versions: []bingo.PackageVersionRenderable{{Version: "v0.1.0"}}},
+		{Name: "hugo", PackagePath: "github.com/gohugoio/hugo", BuildFlags: []string{"-tags=extended"}, Versions: []bingo.PackageVersionRenderable{{Version: "v0.83.1"}}},
+	}
+	testutil.Equals(t, []string{
+		"- buf: github.com/bufbuild/buf/cmd/buf@v0.1.0",
+		"~ faillint: v1.5.0 -> v1.5.0,v1.6.0",
+		"+ goimports: golang.org/x/tools/cmd/goimports@v0.1.0",
+		`~ hugo: build env vars "CGO_ENABLED=1" -> ""`,
+		`~ hugo: build flags "" -> "-tags=extended"`,
+	}, diffPinned(from, to))
+	testutil.Equals(t, 0, len(diffPinned(from, from)))
+}
+
+func TestDiff_Git(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not found")
+	}
+	wd, err := os.Getwd()
+	testutil.Ok(t, err)
+	repo := t.TempDir()
+	testutil.Ok(t, os.Chdir(repo))
+	t.Cleanup(func() { _ = os.Chdir(wd) })
+
+	ctx := context.Background()
+	gitT := func(args ...string) {
+		t.Helper()
+		_, err := git(ctx, append([]string{"-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+		testutil.Ok(t, err)
+	}
+	gitT("init", "-q")
+	testutil.Ok(t, os.MkdirAll(".bingo", os.ModePerm))
+	testutil.Ok(t, ioutil.WriteFile(filepath.Join(".bingo", "faillint.mod"), []byte("module _\n\nrequire github.com/fatih/faillint v1.5.0\n"), os.ModePerm))
+	gitT("add", "-A")
+	gitT("commit", "-q", "-m", "first")
+	testutil.Ok(t, ioutil.WriteFile(filepath.Join(".bingo", "faillint.mod"), []byte("module _\n\nrequire github.com/fatih/faillint v1.6.0\n"), os.ModePerm))
+
+	b := &bytes.Buffer{}
+	testutil.Ok(t, diff(ctx, logging.Discard, ".bingo", "HEAD", "", b))
+	testutil.Equals(t, "~ faillint: v1.5.0 -> v1.6.0\n", b.String())
+
+	b.Reset()
+	testutil.Ok(t, diff(ctx, logging.Discard, ".bingo", "HEAD", "HEAD", b))
+	testutil.Equals(t, "No changes in pinned tools.\n", b.String())
+}
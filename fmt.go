@@ -0,0 +1,153 @@
+// Copyright (c) Bartłomiej Płotka @bwplotka
+// Licensed under the Apache License 2.0.
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/bwplotka/bingo/internal/getter"
+	"github.com/bwplotka/bingo/pkg/bingo"
+	"github.com/bwplotka/bingo/pkg/logging"
+	"github.com/pkg/errors"
+)
+
+// formatModDir rewrites all mod files in given mod directory in canonical form (as check -fix does) and regenerates
+// helper files with given config, so the mod directory content does not depend on who and how got the tools. It prints
+// paths of changed files. If check is true, nothing is written: files are rendered in memory and paths of files that
+// would change are printed and returned as error, if there are any.
+func formatModDir(w io.Writer, logger logging.Logger, relModDir string, cfg bingo.HelpersConfig, varPrefix, varSuffix string, check bool) error {
+	var changed []string
+	if check {
+		var err error
+		if changed, err = unformattedFiles(logger, relModDir, cfg, varPrefix, varSuffix); err != nil {
+			return err
+		}
+	} else {
+		var extra []string
+		if cfg.GoOutFile != "" {
+			extra = append(extra, cfg.GoOutFile)
+		}
+		before, err := snapshotFiles(relModDir, extra...)
+		if err != nil {
+			return err
+		}
+		if err := checkModFiles(ioutil.Discard, relModDir, true); err != nil {
+			return err
+		}
+		if err := getter.GenHelpers(logger, relModDir, cfg, varPrefix, varSuffix); err != nil {
+			return errors.Wrap(err, "generate helpers")
+		}
+		after, err := snapshotFiles(relModDir, extra...)
+		if err != nil {
+			return err
+		}
+		changed = changedFiles(before, after)
+	}
+
+	for _, f := range changed {
+		if _, err := fmt.Fprintln(w, f); err != nil {
+			return err
+		}
+	}
+	if !check || len(changed) == 0 {
+		return nil
+	}
+	return errors.Errorf("%d files are not formatted; run 'bingo fmt' to format them", len(changed))
+}
+
+// unformattedFiles returns sorted paths of files in given mod directory (and of generated helpers outside of it) that
+// formatModDir would change: mod files not in canonical form and helpers different from rendered ones. Files are
+// compared in memory, nothing is written.
+func unformattedFiles(logger logging.Logger, relModDir string, cfg bingo.HelpersConfig, varPrefix, varSuffix string) ([]string, error) {
+	modFiles, err := filepath.Glob(filepath.Join(relModDir, "*.mod"))
+	if err != nil {
+		return nil, err
+	}
+	var changed []string
+	for _, f := range modFiles {
+		if !bingo.IsToolModFile(f) {
+			continue
+		}
+		b, err := ioutil.ReadFile(f)
+		if err != nil {
+			return nil, errors.Wrapf(err, "read %v", f)
+		}
+		canonical, _, err := bingo.FormatModFile(f, b)
+		if err != nil {
+			return nil, errors.Wrapf(err, "malformed mod file %v; fix it manually or remove and get the tool again", f)
+		}
+		if !bytes.Equal(b, canonical) {
+			changed = append(changed, f)
+		}
+	}
+
+	rendered, err := getter.RenderHelpers(logger, relModDir, cfg, varPrefix, varSuffix)
+	if err != nil {
+		return nil, errors.Wrap(err, "render helpers")
+	}
+	for f, content := range rendered {
+		b, err := ioutil.ReadFile(f)
+		if err != nil && !os.IsNotExist(err) {
+			return nil, errors.Wrapf(err, "read %v", f)
+		}
+		exists := err == nil
+		if (content == nil && exists) || (content != nil && (!exists || !bytes.Equal(b, content))) {
+			changed = append(changed, f)
+		}
+	}
+	sort.Strings(changed)
+	return changed, nil
+}
+
+// snapshotFiles returns content of regular files directly in given directory and of given extra files, by path.
+// Not existing files are skipped.
+func snapshotFiles(dir string, extra ...string) (map[string][]byte, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*"))
+	if err != nil {
+		return nil, err
+	}
+	snapshot := map[string][]byte{}
+	for _, f := range append(files, extra...) {
+		st, err := os.Stat(f)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, err
+		}
+		if !st.Mode().IsRegular() || strings.HasSuffix(f, "tmp.mod") {
+			continue
+		}
+		b, err := ioutil.ReadFile(f)
+		if err != nil {
+			return nil, err
+		}
+		snapshot[f] = b
+	}
+	return snapshot, nil
+}
+
+// changedFiles returns sorted paths of files created, removed or modified between two snapshots.
+func changedFiles(before, after map[string][]byte) []string {
+	var changed []string
+	for f, b := range before {
+		if a, ok := after[f]; !ok || !bytes.Equal(a, b) {
+			changed = append(changed, f)
+		}
+	}
+	for f := range after {
+		if _, ok := before[f]; !ok {
+			changed = append(changed, f)
+		}
+	}
+	sort.Strings(changed)
+	return changed
+}
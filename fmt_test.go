@@ -0,0 +1,61 @@
+// Copyright (c) Bartłomiej Płotka @bwplotka
+// Licensed under the Apache License 2.0.
+
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/bwplotka/bingo/pkg/bingo"
+	"github.com/efficientgo/tools/core/pkg/testutil"
+)
+
+func TestFormatModDir(t *testing.T) {
+	modDir := t.TempDir()
+	logger := slog.New(slog.NewTextHandler(ioutil.Discard, nil))
+	cfg := bingo.HelpersConfig{GOBIN: "/gobin"}
+	edited := "module _\n\ngo 1.14\n\nrequire github.com/fatih/faillint v1.5.0 //  -tags=x\n"
+	testutil.Ok(t, ioutil.WriteFile(filepath.Join(modDir, "faillint.mod"), []byte(edited), os.ModePerm))
+
+	out := &bytes.Buffer{}
+	testutil.NotOk(t, formatModDir(out, logger, modDir, cfg, "", "", true))
+	testutil.Assert(t, bytes.Contains(out.Bytes(), []byte(filepath.Join(modDir, "faillint.mod")+"\n")), out.String())
+	b, err := ioutil.ReadFile(filepath.Join(modDir, "faillint.mod"))
+	testutil.Ok(t, err)
+	testutil.Equals(t, edited, string(b))
+	_, err = os.Stat(filepath.Join(modDir, "Variables.mk"))
+	testutil.Assert(t, os.IsNotExist(err), "helpers should not be generated on check")
+
+	out.Reset()
+	testutil.Ok(t, formatModDir(out, logger, modDir, cfg, "", "", false))
+	testutil.Assert(t, bytes.Contains(out.Bytes(), []byte(filepath.Join(modDir, "Variables.mk"))), out.String())
+	b, err = ioutil.ReadFile(filepath.Join(modDir, "faillint.mod"))
+	testutil.Ok(t, err)
+	testutil.Equals(t, "module _ // Auto generated by https://github.com/bwplotka/bingo. DO NOT EDIT\n\ngo 1.14\n\nrequire github.com/fatih/faillint v1.5.0 // -tags=x\n", string(b))
+
+	// Formatted mod directory is left as is.
+	out.Reset()
+	testutil.Ok(t, formatModDir(out, logger, modDir, cfg, "", "", true))
+	testutil.Equals(t, "", out.String())
+
+	// Stale helper is reported, but not touched.
+	stale := filepath.Join(modDir, "variables.env")
+	testutil.Ok(t, ioutil.WriteFile(stale, []byte("stale\n"), os.ModePerm))
+	past := time.Now().Add(-time.Hour)
+	testutil.Ok(t, os.Chtimes(stale, past, past))
+	out.Reset()
+	testutil.NotOk(t, formatModDir(out, logger, modDir, cfg, "", "", true))
+	testutil.Equals(t, stale+"\n", out.String())
+	st, err := os.Stat(stale)
+	testutil.Ok(t, err)
+	testutil.Assert(t, st.ModTime().Equal(past), "helper should not be written on check")
+	b, err = ioutil.ReadFile(stale)
+	testutil.Ok(t, err)
+	testutil.Equals(t, "stale\n", string(b))
+}
@@ -0,0 +1,62 @@
+// Copyright (c) Bartłomiej Płotka @bwplotka
+// Licensed under the Apache License 2.0.
+
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/bwplotka/bingo/internal/getter"
+	"github.com/bwplotka/bingo/pkg/bingo"
+	"github.com/efficientgo/tools/core/pkg/errcapture"
+	"github.com/pkg/errors"
+)
+
+// ghaEnv links pinned tools to shims directory (as activate does) and appends this directory to GitHub Actions path file
+// and each tool's variable to GitHub Actions environment file, so next workflow steps can use them.
+// See https://docs.github.com/en/actions/using-workflows/workflow-commands-for-github-actions#environment-files.
+func ghaEnv(logger *slog.Logger, modDir string, pkgs bingo.PackageRenderables, githubEnvFile, githubPathFile string) error {
+	if githubEnvFile == "" || githubPathFile == "" {
+		return errors.New("GITHUB_ENV or GITHUB_PATH is not set; gha-env is expected to be run inside GitHub Actions workflow step")
+	}
+	if err := pkgs.ValidateEnvVarNames(); err != nil {
+		return err
+	}
+
+	gobinPath, err := filepath.Abs(getter.BinDir(modDir))
+	if err != nil {
+		return errors.Wrap(err, "abs gobin")
+	}
+	dir := filepath.Join(modDir, shimsDir)
+	if err := linkShims(logger, dir, gobinPath, pkgs, nil); err != nil {
+		return errors.Wrap(err, "link shims")
+	}
+	if err := appendToFile(githubPathFile, dir+"\n"); err != nil {
+		return errors.Wrap(err, "append to GITHUB_PATH file")
+	}
+
+	env := &strings.Builder{}
+	for _, p := range pkgs {
+		bins := make([]string, 0, len(p.Versions))
+		for _, v := range p.Versions {
+			bins = append(bins, filepath.Join(p.InstallDir(gobinPath), p.ArtifactName(v.Version)))
+		}
+		_, _ = fmt.Fprintf(env, "%s=%s\n", p.EnvVarName, strings.Join(bins, " "))
+	}
+	return errors.Wrap(appendToFile(githubEnvFile, env.String()), "append to GITHUB_ENV file")
+}
+
+func appendToFile(file, content string) (err error) {
+	f, err := os.OpenFile(file, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0666)
+	if err != nil {
+		return err
+	}
+	defer errcapture.Do(&err, f.Close, "close")
+
+	_, err = f.WriteString(content)
+	return err
+}
@@ -0,0 +1,46 @@
+// Copyright (c) Bartłomiej Płotka @bwplotka
+// Licensed under the Apache License 2.0.
+
+package main
+
+import (
+	"io/ioutil"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bwplotka/bingo/internal/getter"
+	"github.com/bwplotka/bingo/pkg/bingo"
+	"github.com/efficientgo/tools/core/pkg/testutil"
+)
+
+func TestGHAEnv(t *testing.T) {
+	tmpDir, err := ioutil.TempDir(os.TempDir(), "bingo-gha-env")
+	testutil.Ok(t, err)
+	t.Cleanup(func() { testutil.Ok(t, os.RemoveAll(tmpDir)) })
+
+	logger := slog.New(slog.NewTextHandler(ioutil.Discard, nil))
+	pkgs := bingo.PackageRenderables{
+		{Name: "faillint", EnvVarName: "FAILLINT", Versions: []bingo.PackageVersionRenderable{{Version: "v1.5.0"}}},
+		{Name: "buf", EnvVarName: "BUF_ARRAY", Versions: []bingo.PackageVersionRenderable{{Version: "v0.1.0"}, {Version: "v0.2.0"}}},
+	}
+	envFile, pathFile := filepath.Join(tmpDir, "env"), filepath.Join(tmpDir, "path")
+	testutil.NotOk(t, ghaEnv(logger, tmpDir, pkgs, "", ""))
+
+	testutil.Ok(t, ioutil.WriteFile(envFile, []byte("EXISTING=1\n"), os.ModePerm))
+	testutil.Ok(t, ghaEnv(logger, tmpDir, pkgs, envFile, pathFile))
+
+	gobinPath, err := filepath.Abs(getter.GOBIN())
+	testutil.Ok(t, err)
+
+	b, err := ioutil.ReadFile(pathFile)
+	testutil.Ok(t, err)
+	testutil.Equals(t, filepath.Join(tmpDir, shimsDir)+"\n", string(b))
+
+	b, err = ioutil.ReadFile(envFile)
+	testutil.Ok(t, err)
+	testutil.Equals(t, "EXISTING=1\n"+
+		"FAILLINT="+filepath.Join(gobinPath, "faillint-v1.5.0")+"\n"+
+		"BUF_ARRAY="+filepath.Join(gobinPath, "buf-v0.1.0")+" "+filepath.Join(gobinPath, "buf-v0.2.0")+"\n", string(b))
+}
@@ -1,6 +1,6 @@
 module github.com/bwplotka/bingo
 
-go 1.14
+go 1.21
 
 require (
 	github.com/Masterminds/semver v1.5.0
@@ -8,5 +8,16 @@ require (
 	github.com/oklog/run v1.1.0
 	github.com/pkg/errors v0.9.1
 	golang.org/x/mod v0.3.0
+	golang.org/x/term v0.15.0
 	mvdan.cc/sh/v3 v3.2.4
 )
+
+require (
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	go.uber.org/goleak v1.1.10 // indirect
+	golang.org/x/sync v0.0.0-20201020160332-67f06af15bc9 // indirect
+	golang.org/x/sys v0.15.0 // indirect
+	golang.org/x/tools v0.0.0-20191119224855-298f0cb1881e // indirect
+	golang.org/x/xerrors v0.0.0-20191011141410-1b5146add898 // indirect
+)
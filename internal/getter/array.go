@@ -0,0 +1,334 @@
+// Copyright (c) Bartłomiej Płotka @bwplotka
+// Licensed under the Apache License 2.0.
+
+package getter
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/bwplotka/bingo/pkg/bingo"
+	"github.com/bwplotka/bingo/pkg/logging"
+	"github.com/pkg/errors"
+	"golang.org/x/mod/semver"
+)
+
+// arrayPin is a single pinned version of a tool: one of its array mod files.
+type arrayPin struct {
+	modFile string
+	content []byte
+	pkg     bingo.Package
+	// dir is a directory the tool is installed in.
+	dir string
+}
+
+// binPath returns path of the binary of the pinned version installed under given tool name.
+func (p arrayPin) binPath(name string) string {
+	return filepath.Join(p.dir, bingo.ArtifactName(name, p.pkg.Module.Version, p.pkg.BuildEnvs))
+}
+
+// readArrayPins returns all pinned versions of given tool in the order of its array mod files (<tool>.mod, <tool>.1.mod, ...),
+// installed in given gobin, unless the tool has its own bin directory.
+func readArrayPins(logger logging.Logger, modDir, gobin, name string) ([]arrayPin, error) {
+	modFiles, err := existingModFiles(modDir, name)
+	if err != nil {
+		return nil, err
+	}
+	if len(modFiles) == 0 {
+		return nil, newSentinelError(bingo.ErrNotInstalled, "tool %v is not installed%s", name, didYouMean(logger, modDir, name))
+	}
+	index := func(f string) int {
+		i, _ := bingo.ModFileNumber(name, f)
+		return i
+	}
+	sort.SliceStable(modFiles, func(i, j int) bool { return index(modFiles[i]) < index(modFiles[j]) })
+
+	pins := make([]arrayPin, 0, len(modFiles))
+	for _, f := range modFiles {
+		b, err := ioutil.ReadFile(f)
+		if err != nil {
+			return nil, err
+		}
+		mf, err := bingo.ReadModFile(f)
+		if err != nil {
+			return nil, errors.Wrapf(err, "read %v", f)
+		}
+		pkg := mf.DirectPackage()
+		if pkg == nil {
+			return nil, errors.Errorf("no direct package found in %v", f)
+		}
+		if len(pins) > 0 && pkg.Path() != pins[0].pkg.Path() {
+			return nil, errors.Errorf("found array mod file %v that has different package path %q than previous in array %q", f, pkg.Path(), pins[0].pkg.Path())
+		}
+		binDir, _ := mf.Command(bingo.BinDirCommand)
+		pins = append(pins, arrayPin{modFile: f, content: b, pkg: *pkg, dir: bingo.ToolBinDir(modDir, gobin, binDir)})
+	}
+	return pins, nil
+}
+
+// writeArrayModFiles writes mod files of given pinned versions as array mod files of given tool, numbered in the given
+// order. Aliases, variable name and tool it was split from, shared by all versions of the tool, are set to given ones.
+func writeArrayModFiles(modDir, name string, pins []arrayPin, aliases, varName, splitFrom string) error {
+	for i, p := range pins {
+		f := filepath.Join(modDir, name+".mod")
+		if i > 0 {
+			f = filepath.Join(modDir, fmt.Sprintf("%s.%d.mod", name, i))
+		}
+		if err := ioutil.WriteFile(f, p.content, 0666); err != nil {
+			return err
+		}
+		if err := setCommand(f, bingo.AliasesCommand, aliases); err != nil {
+			return errors.Wrap(err, f)
+		}
+		if err := setCommand(f, bingo.VarNameCommand, varName); err != nil {
+			return errors.Wrap(err, f)
+		}
+		if err := setCommand(f, bingo.SplitFromCommand, splitFrom); err != nil {
+			return errors.Wrap(err, f)
+		}
+	}
+	return nil
+}
+
+// moveBinary renames installed binary (if any) and recorded build stats of given pinned version from given tool name
+// to the new one.
+func moveBinary(modDir string, p arrayPin, name, newName string) error {
+	if err := os.Rename(p.binPath(name), p.binPath(newName)); err != nil && !os.IsNotExist(err) {
+		return errors.Wrap(err, "rename binary")
+	}
+	return errors.Wrap(bingo.RenameBuildStats(modDir, name, newName, p.pkg.Module.Version), "rename build stats")
+}
+
+func removeModFiles(pins []arrayPin) error {
+	for _, p := range pins {
+		if err := os.Remove(p.modFile); err != nil {
+			return errors.Wrap(err, "remove mod file")
+		}
+	}
+	return nil
+}
+
+// Split moves given pinned versions of array tool (e.g tool@v1,v2) to separate tool with given new name (e.g tool-legacy).
+// If no versions are given, all but the highest version are moved. Mod files of both tools are renumbered and binaries of
+// moved versions renamed. Symbolic links (get -l) of the tool and its aliases pointing to moved versions are pointed at its
+// last kept version, and if the tool is linked, the new name is linked to the last moved version in given mode.
+func Split(logger logging.Logger, modDir, gobin string, linkMode LinkMode, name, newName string, versions []string) error {
+	if newName == name {
+		return errors.Errorf("new name cannot be the same as the current one %v", name)
+	}
+	if err := validateTargetName(newName); err != nil {
+		return err
+	}
+	newExisting, err := existingModFiles(modDir, newName)
+	if err != nil {
+		return errors.Wrapf(err, "existing mod files for %v", newName)
+	}
+	if len(newExisting) > 0 {
+		return errors.Errorf("tool %v is already pinned (%v); remove it with 'bingo get %v@none' first or use different name", newName, strings.Join(newExisting, ","), newName)
+	}
+	pins, err := readArrayPins(logger, modDir, gobin, name)
+	if err != nil {
+		return err
+	}
+	if len(pins) < 2 {
+		return errors.Errorf("tool %v pins only one version %v; nothing to split", name, pins[0].pkg.Module.Version)
+	}
+	pinned := make([]string, 0, len(pins))
+	highest := pins[0].pkg.Module.Version
+	for _, p := range pins {
+		pinned = append(pinned, p.pkg.Module.Version)
+		if semver.Compare(p.pkg.Module.Version, highest) > 0 {
+			highest = p.pkg.Module.Version
+		}
+	}
+	if len(versions) == 0 {
+		versions = missing([]string{highest}, pinned)
+	}
+	if unknown := missing(pinned, versions); len(unknown) > 0 {
+		return errors.Errorf("versions %v are not pinned by tool %v (pinned: %v)", strings.Join(unknown, ","), name, strings.Join(pinned, ","))
+	}
+
+	var kept, moved []arrayPin
+	movedBins := map[string]struct{}{}
+	for _, p := range pins {
+		if !contains(versions, p.pkg.Module.Version) {
+			kept = append(kept, p)
+			continue
+		}
+		moved = append(moved, p)
+		movedBins[filepath.Base(p.binPath(name))] = struct{}{}
+	}
+	if len(kept) == 0 {
+		return errors.Errorf("at least one version of tool %v has to stay", name)
+	}
+
+	mf, err := bingo.ReadModFile(pins[0].modFile)
+	if err != nil {
+		return errors.Wrapf(err, "read %v", pins[0].modFile)
+	}
+	aliasesArg, _ := mf.Command(bingo.AliasesCommand)
+	varName, _ := mf.Command(bingo.VarNameCommand)
+	splitFrom, _ := mf.Command(bingo.SplitFromCommand)
+
+	for _, p := range moved {
+		if err := moveBinary(modDir, p, name, newName); err != nil {
+			return err
+		}
+	}
+	if err := removeModFiles(pins); err != nil {
+		return err
+	}
+	if err := writeArrayModFiles(modDir, name, kept, aliasesArg, varName, splitFrom); err != nil {
+		return err
+	}
+	// Mark new tool, so it's not reported as duplicate pin of the same package to merge.
+	if err := writeArrayModFiles(modDir, newName, moved, "", "", name); err != nil {
+		return err
+	}
+
+	if !bingo.IsWasm(pins[0].pkg.BuildEnvs) {
+		dir := pins[0].dir
+		aliases, err := bingo.ParseAliases(aliasesArg)
+		if err != nil {
+			return errors.Wrap(err, "aliases")
+		}
+		for _, n := range append([]string{name}, aliases...) {
+			if linked, ok := linkedBinary(filepath.Join(dir, n+bingo.GOEXE())); ok {
+				if _, ok := movedBins[filepath.Base(linked)]; ok {
+					if err := LinkBinary(linkMode, kept[len(kept)-1].binPath(name), filepath.Join(dir, n)); err != nil {
+						return errors.Wrapf(err, "link %v", n)
+					}
+				}
+			}
+		}
+		if linkExists(filepath.Join(dir, name)) {
+			if err := LinkBinary(linkMode, moved[len(moved)-1].binPath(newName), filepath.Join(dir, newName)); err != nil {
+				return errors.Wrapf(err, "link %v", newName)
+			}
+		}
+	}
+	logger.Info("split tool", "tool", name, "new", newName, "versions", strings.Join(versions, ","))
+	return nil
+}
+
+// MergeArray merges pins of given duplicate tools (the same package pinned under different names) into array of versions
+// of given tool: versions pinned only by duplicates are appended to the tool's versions (their binaries are renamed) and
+// mod files of duplicates are removed. Aliases of duplicates are added to aliases of the tool. Symbolic links of aliases
+// pointing to renamed binaries are pointed at their new paths, while links of duplicate names are removed.
+func MergeArray(logger logging.Logger, modDir, gobin, name string, duplicates []string) error {
+	pins, err := readArrayPins(logger, modDir, gobin, name)
+	if err != nil {
+		return err
+	}
+	mf, err := bingo.ReadModFile(pins[0].modFile)
+	if err != nil {
+		return errors.Wrapf(err, "read %v", pins[0].modFile)
+	}
+	arg, _ := mf.Command(bingo.AliasesCommand)
+	varName, _ := mf.Command(bingo.VarNameCommand)
+	splitFrom, _ := mf.Command(bingo.SplitFromCommand)
+
+	var (
+		merged  = append([]arrayPin{}, pins...)
+		removed []arrayPin
+		// renamed maps names of previous binaries to paths of renamed ones.
+		renamed = map[string]string{}
+	)
+	for _, d := range duplicates {
+		if d == name {
+			return errors.Errorf("cannot merge tool %v into itself", name)
+		}
+		dupPins, err := readArrayPins(logger, modDir, gobin, d)
+		if err != nil {
+			return err
+		}
+		if dupPins[0].pkg.Path() != pins[0].pkg.Path() {
+			return errors.Errorf("tool %v pins package %v, not %v pinned by %v; only pins of the same package can be merged", d, dupPins[0].pkg.Path(), pins[0].pkg.Path(), name)
+		}
+		dmf, err := bingo.ReadModFile(dupPins[0].modFile)
+		if err != nil {
+			return errors.Wrapf(err, "read %v", dupPins[0].modFile)
+		}
+		dupAliases, _ := dmf.Command(bingo.AliasesCommand)
+		arg = strings.Join([]string{arg, dupAliases}, ",")
+
+		for _, p := range dupPins {
+			renamed[filepath.Base(p.binPath(d))] = p.binPath(name)
+			if pinnedVersion(merged, p.pkg.Module.Version) {
+				continue
+			}
+			if err := moveBinary(modDir, p, d, name); err != nil {
+				return err
+			}
+			merged = append(merged, p)
+		}
+		removed = append(removed, dupPins...)
+	}
+
+	aliases, err := bingo.ParseAliases(arg)
+	if err != nil {
+		return errors.Wrap(err, "aliases")
+	}
+	if contains(duplicates, splitFrom) {
+		// Merged back into single array, e.g after split.
+		splitFrom = ""
+	}
+	if err := removeModFiles(append(pins, removed...)); err != nil {
+		return err
+	}
+	if err := writeArrayModFiles(modDir, name, merged, strings.Join(aliases, ","), varName, splitFrom); err != nil {
+		return err
+	}
+
+	dir := pins[0].dir
+	for _, n := range append(aliases, duplicates...) {
+		path := filepath.Join(dir, n)
+		linked, ok := linkedBinary(path + bingo.GOEXE())
+		if !ok {
+			continue
+		}
+		to, ok := renamed[filepath.Base(linked)]
+		if !ok {
+			continue
+		}
+		if !contains(aliases, n) {
+			if err := removeLink(path); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := LinkBinary(SymlinkLinkMode, to, path); err != nil {
+			return errors.Wrapf(err, "link alias %v", n)
+		}
+	}
+
+	versions := make([]string, 0, len(merged))
+	for _, p := range merged {
+		versions = append(versions, p.pkg.Module.Version)
+	}
+	logger.Info("merged pins into array of versions", "tool", name, "versions", strings.Join(versions, ","))
+	return nil
+}
+
+// pinnedVersion returns true if given version is pinned by any of given pins.
+func pinnedVersion(pins []arrayPin, version string) bool {
+	for _, p := range pins {
+		if p.pkg.Module.Version == version {
+			return true
+		}
+	}
+	return false
+}
+
+func contains(s []string, e string) bool {
+	for _, x := range s {
+		if x == e {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,211 @@
+// Copyright (c) Bartłomiej Płotka @bwplotka
+// Licensed under the Apache License 2.0.
+
+package getter
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/bwplotka/bingo/pkg/bingo"
+	"github.com/bwplotka/bingo/pkg/logging"
+	"github.com/bwplotka/bingo/pkg/runner"
+	"github.com/efficientgo/tools/core/pkg/errcapture"
+	"github.com/pkg/errors"
+	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/module"
+	"golang.org/x/mod/semver"
+)
+
+// DedupeChange is a change of version of dependency in tool's mod file, made by Dedupe.
+type DedupeChange struct {
+	ModFile string
+	Module  string
+	From    string
+	To      string
+}
+
+// Dedupe aligns versions of dependencies shared by pinned tools in given mod directory to the highest version any of
+// them uses, with version specific replace statements marked with bingo.DedupeCommand (kept by bingo get), so tools
+// share downloaded modules. Versions are only upgraded, which is compatible for modules following semantic import
+// versioning. Tools' own modules, frozen tools and dependencies replaced otherwise are never changed. If modules are
+// given, only these dependencies are aligned, otherwise all shared ones are. If dryRun is true, mod files are not
+// changed. Tools are isolated by default; Dedupe is strictly opt-in, use RemoveDedupe to undo it.
+func Dedupe(ctx context.Context, logger logging.Logger, r *runner.Runner, modDir string, modules []string, dryRun bool) ([]DedupeChange, error) {
+	modFiles, err := toolModFiles(modDir)
+	if err != nil {
+		return nil, err
+	}
+
+	deps, own := map[string]map[string]string{}, map[string]string{}
+	for _, f := range modFiles {
+		mf, err := bingo.ReadModFile(f)
+		if err != nil {
+			return nil, errors.Wrapf(err, "read %v", f)
+		}
+		if mf.DirectPackage() == nil {
+			continue
+		}
+		if _, frozen := mf.Command(bingo.FrozenCommand); frozen {
+			logger.Debug("skipping frozen tool", "file", filepath.Base(f))
+			continue
+		}
+		own[f] = mf.DirectPackage().Module.Path
+		if deps[f], err = toolDependencies(ctx, r, modDir, f); err != nil {
+			return nil, errors.Wrapf(err, "list dependencies of %v", filepath.Base(f))
+		}
+	}
+
+	latest := map[string]string{}
+	users := map[string]int{}
+	for _, d := range deps {
+		for m, v := range d {
+			users[m]++
+			if semver.Compare(v, latest[m]) > 0 {
+				latest[m] = v
+			}
+		}
+	}
+	selected := func(m string) bool {
+		if len(modules) == 0 {
+			return true
+		}
+		for _, s := range modules {
+			if s == m {
+				return true
+			}
+		}
+		return false
+	}
+
+	var changes []DedupeChange
+	for _, f := range modFiles {
+		d, ok := deps[f]
+		if !ok {
+			continue
+		}
+		var replaces []*modfile.Replace
+		for m, v := range d {
+			if m == own[f] || users[m] < 2 || !selected(m) || semver.Compare(v, latest[m]) >= 0 {
+				continue
+			}
+			replaces = append(replaces, &modfile.Replace{Old: module.Version{Path: m, Version: v}, New: module.Version{Path: m, Version: latest[m]}})
+			changes = append(changes, DedupeChange{ModFile: filepath.Base(f), Module: m, From: v, To: latest[m]})
+		}
+		if dryRun {
+			continue
+		}
+		if err := setDedupeReplaces(f, replaces, func(r *modfile.Replace) bool { return !selected(r.Old.Path) }); err != nil {
+			return nil, errors.Wrap(err, f)
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		if changes[i].ModFile != changes[j].ModFile {
+			return changes[i].ModFile < changes[j].ModFile
+		}
+		return changes[i].Module < changes[j].Module
+	})
+	return changes, nil
+}
+
+// RemoveDedupe removes all replace statements added by Dedupe from mod files in given mod directory, so tools use their
+// own dependency versions again.
+func RemoveDedupe(modDir string) error {
+	modFiles, err := toolModFiles(modDir)
+	if err != nil {
+		return err
+	}
+	for _, f := range modFiles {
+		if err := setDedupeReplaces(f, nil, func(*modfile.Replace) bool { return false }); err != nil {
+			return errors.Wrap(err, f)
+		}
+	}
+	return nil
+}
+
+// toolModFiles returns sorted mod files of all pinned tools in given mod directory.
+func toolModFiles(modDir string) ([]string, error) {
+	modFiles, err := filepath.Glob(filepath.Join(modDir, "*.mod"))
+	if err != nil {
+		return nil, err
+	}
+	var tools []string
+	for _, f := range modFiles {
+		if bingo.IsToolModFile(f) {
+			tools = append(tools, f)
+		}
+	}
+	sort.Strings(tools)
+	return tools, nil
+}
+
+// toolDependencies returns versions of all modules (including tool's own one) in build list of the tool pinned in given
+// mod file, as they would be without replaces added by Dedupe. Modules replaced otherwise are skipped. It works on
+// temporary copy of mod file, as listing modules can change it.
+func toolDependencies(ctx context.Context, r *runner.Runner, modDir, modFile string) (_ map[string]string, err error) {
+	b, err := ioutil.ReadFile(modFile)
+	if err != nil {
+		return nil, err
+	}
+	tmpModFile := strings.TrimSuffix(modFile, ".mod") + ".dedupe.tmp.mod"
+	if err := ioutil.WriteFile(tmpModFile, b, os.ModePerm); err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = os.Remove(strings.TrimSuffix(tmpModFile, ".mod") + ".sum")
+		errcapture.Do(&err, func() error { return os.Remove(tmpModFile) }, "remove tmp mod file")
+	}()
+	if err := setDedupeReplaces(tmpModFile, nil, func(*modfile.Replace) bool { return false }); err != nil {
+		return nil, err
+	}
+
+	out, err := r.With(ctx, tmpModFile, modDir, nil).List(
+		runner.NoUpdatePolicy, "-mod=mod", "-m", "-f={{.Path}} {{.Version}}{{with .Replace}} {{.Path}} {{.Version}}{{end}}", "all",
+	)
+	if err != nil {
+		return nil, err
+	}
+	deps := map[string]string{}
+	for _, l := range strings.Split(out, "\n") {
+		// Main module has no version; replaced modules have replacement in extra fields.
+		f := strings.Fields(l)
+		if len(f) != 2 {
+			continue
+		}
+		deps[f[0]] = f[1]
+	}
+	return deps, nil
+}
+
+// setDedupeReplaces sets given replaces added by Dedupe in given mod file, keeping existing ones for which keep returns
+// true. File is not rewritten if it has no such replaces and none are given.
+func setDedupeReplaces(modFile string, replaces []*modfile.Replace, keep func(*modfile.Replace) bool) (err error) {
+	mf, err := bingo.ReadModFile(modFile)
+	if err != nil {
+		return err
+	}
+	existing := mf.DedupeReplaces()
+	for _, r := range existing {
+		if keep(r) {
+			replaces = append(replaces, r)
+		}
+	}
+	if len(existing) == 0 && len(replaces) == 0 {
+		return nil
+	}
+	sort.Slice(replaces, func(i, j int) bool { return replaces[i].Old.Path < replaces[j].Old.Path })
+
+	f, err := bingo.OpenModFile(modFile)
+	if err != nil {
+		return err
+	}
+	defer errcapture.Do(&err, f.Close, "close")
+	if err := f.SetDedupeReplaces(replaces...); err != nil {
+		return err
+	}
+	return f.Flush()
+}
@@ -0,0 +1,69 @@
+// Copyright (c) Bartłomiej Płotka @bwplotka
+// Licensed under the Apache License 2.0.
+
+package getter
+
+import (
+	"os"
+
+	"github.com/bwplotka/bingo/pkg/bingo"
+	"github.com/bwplotka/bingo/pkg/logging"
+	"github.com/efficientgo/tools/core/pkg/errcapture"
+	"github.com/pkg/errors"
+)
+
+// DefaultFreezeReason is used if tool is frozen without reason.
+const DefaultFreezeReason = "held at exact version"
+
+// frozenVersion returns pinned version and freeze reason of the tool from given mod file, if it's frozen. It returns
+// false if mod file does not exist.
+func frozenVersion(modFile string) (version, reason string, ok bool, err error) {
+	mf, err := bingo.ReadModFile(modFile)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", "", false, nil
+		}
+		return "", "", false, errors.Wrapf(err, "read %v", modFile)
+	}
+
+	reason, ok = mf.Command(bingo.FrozenCommand)
+	if !ok || mf.DirectPackage() == nil {
+		return "", "", false, nil
+	}
+	return mf.DirectPackage().Module.Version, reason, true, nil
+}
+
+// Freeze marks all pinned versions of given tool as frozen with given reason (DefaultFreezeReason if empty), so
+// updates (e.g bingo get -u) skip it. If frozen is false, the mark is removed instead.
+func Freeze(logger logging.Logger, modDir, name, reason string, frozen bool) error {
+	modFiles, err := existingModFiles(modDir, name)
+	if err != nil {
+		return err
+	}
+	if len(modFiles) == 0 {
+		return newSentinelError(bingo.ErrNotInstalled, "tool %v is not installed%s", name, didYouMean(logger, modDir, name))
+	}
+	if reason == "" {
+		reason = DefaultFreezeReason
+	}
+	if !frozen {
+		reason = ""
+	}
+	for _, f := range modFiles {
+		if err := setCommand(f, bingo.FrozenCommand, reason); err != nil {
+			return errors.Wrap(err, f)
+		}
+	}
+	return nil
+}
+
+func setCommand(modFile, cmd, arg string) (err error) {
+	mf, err := bingo.OpenModFile(modFile)
+	if err != nil {
+		return err
+	}
+	defer errcapture.Do(&err, mf.Close, "close")
+
+	mf.SetCommand(cmd, arg)
+	return mf.Flush()
+}
@@ -0,0 +1,128 @@
+// Copyright (c) Bartłomiej Płotka @bwplotka
+// Licensed under the Apache License 2.0.
+
+package getter
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/bwplotka/bingo/pkg/bingo"
+	"github.com/bwplotka/bingo/pkg/envars"
+	"github.com/bwplotka/bingo/pkg/runner"
+	"github.com/efficientgo/tools/core/pkg/merrors"
+	"github.com/pkg/errors"
+	"golang.org/x/mod/modfile"
+)
+
+// prepareGenerate prepares build of the tool that has to run go generate before build (see bingo.GenerateCommand). Module
+// cache is read-only, so it copies source of the tool's module to temporary writable directory and runs go generate for
+// packages given in the command there (the tool's package by default). It returns temporary copy of given mod file,
+// replacing the tool's module with generated copy, to build the tool with, and function removing all temporary files.
+func prepareGenerate(ctx context.Context, r *runner.Runner, modDir, name string, modFile *bingo.ModFile, env envars.EnvSlice) (tmpModFile string, cleanup func() error, err error) {
+	pkg := modFile.DirectPackage()
+	modSrcDir, err := r.With(ctx, modFile.FileName(), modDir, env).List(runner.NoUpdatePolicy, "-mod=mod", "-m", "-f={{.Dir}}", pkg.Module.Path)
+	if err != nil {
+		return "", nil, errors.Wrap(err, "find module source")
+	}
+	if modSrcDir == "" {
+		return "", nil, errors.Errorf("source of module %v not found", pkg.Module.Path)
+	}
+
+	tmpDir, err := ioutil.TempDir("", "bingo-generate")
+	if err != nil {
+		return "", nil, errors.Wrap(err, "create tmp dir")
+	}
+	tmpModFile = strings.TrimSuffix(modFile.FileName(), ".mod") + ".generate.tmp.mod"
+	cleanup = func() error {
+		errs := merrors.New()
+		errs.Add(os.RemoveAll(tmpDir))
+		for _, f := range []string{tmpModFile, strings.TrimSuffix(tmpModFile, ".mod") + ".sum"} {
+			if err := os.Remove(f); err != nil && !os.IsNotExist(err) {
+				errs.Add(err)
+			}
+		}
+		return errs.Err()
+	}
+	defer func() {
+		if err != nil {
+			_ = cleanup()
+		}
+	}()
+
+	srcDir := filepath.Join(tmpDir, filepath.Base(modSrcDir))
+	if err := copyWritableTree(modSrcDir, srcDir); err != nil {
+		return "", nil, errors.Wrap(err, "copy module source")
+	}
+	if _, err := os.Stat(filepath.Join(srcDir, "go.mod")); os.IsNotExist(err) {
+		// Pre module package; go generate needs module.
+		if err := ioutil.WriteFile(filepath.Join(srcDir, "go.mod"), []byte("module "+pkg.Module.Path+"\n"), os.ModePerm); err != nil {
+			return "", nil, err
+		}
+	}
+
+	patterns := []string{"./" + pkg.RelPath}
+	if pkg.RelPath == "" {
+		patterns = []string{"."}
+	}
+	if arg, _ := modFile.Command(bingo.GenerateCommand); arg != "" {
+		patterns = strings.Fields(arg)
+	}
+	if err := r.With(ctx, "", srcDir, env).WithPrefix(name).Generate(patterns...); err != nil {
+		return "", nil, errors.Wrap(err, "go generate")
+	}
+
+	b, err := ioutil.ReadFile(modFile.FileName())
+	if err != nil {
+		return "", nil, err
+	}
+	mf, err := modfile.Parse(modFile.FileName(), b, nil)
+	if err != nil {
+		return "", nil, errors.Wrap(err, "parse mod file")
+	}
+	if err := mf.AddReplace(pkg.Module.Path, "", srcDir, ""); err != nil {
+		return "", nil, errors.Wrap(err, "add replace")
+	}
+	if b, err = mf.Format(); err != nil {
+		return "", nil, errors.Wrap(err, "format mod file")
+	}
+	if err := ioutil.WriteFile(tmpModFile, b, os.ModePerm); err != nil {
+		return "", nil, err
+	}
+	if sum, err := ioutil.ReadFile(strings.TrimSuffix(modFile.FileName(), ".mod") + ".sum"); err == nil {
+		if err := ioutil.WriteFile(strings.TrimSuffix(tmpModFile, ".mod")+".sum", sum, os.ModePerm); err != nil {
+			return "", nil, err
+		}
+	} else if !os.IsNotExist(err) {
+		return "", nil, err
+	}
+	return tmpModFile, cleanup, nil
+}
+
+// copyWritableTree copies given directory recursively, making copy writable (files in module cache are read-only).
+func copyWritableTree(src, dst string) error {
+	return filepath.Walk(src, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, p)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+		if info.IsDir() {
+			return os.MkdirAll(target, os.ModePerm)
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		b, err := ioutil.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		return ioutil.WriteFile(target, b, info.Mode().Perm()|0200)
+	})
+}
@@ -0,0 +1,1751 @@
+// Copyright (c) Bartłomiej Płotka @bwplotka
+// Licensed under the Apache License 2.0.
+
+// Package getter implements bingo get: resolving, pinning and installing tools in separate modules of mod directory.
+// It's internal; use github.com/bwplotka/bingo/pkg/bingo/api for the supported Go API.
+package getter
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"go/build"
+	"io"
+	"io/ioutil"
+	"log/slog"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/bwplotka/bingo/pkg/bingo"
+	"github.com/bwplotka/bingo/pkg/envars"
+	"github.com/bwplotka/bingo/pkg/logging"
+	"github.com/bwplotka/bingo/pkg/runner"
+	"github.com/bwplotka/bingo/pkg/version"
+	"github.com/efficientgo/tools/core/pkg/errcapture"
+	"github.com/pkg/errors"
+	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/module"
+)
+
+var (
+	goModVersionRegexp = regexp.MustCompile("^v[0-9]*$")
+)
+
+func parseTarget(rawTarget string) (name string, pkgPath string, versions []string, err error) {
+	if rawTarget == "" {
+		return "", "", nil, errors.New("target is empty, this should be filtered earlier")
+	}
+
+	s := strings.Split(rawTarget, "@")
+	nameOrPackage := s[0]
+	if len(s) > 1 {
+		versions = strings.Split(s[1], ",")
+	} else {
+		versions = []string{""}
+	}
+
+	if len(versions) > 1 {
+		// Check for duplicates or/and none.
+		dup := map[string]struct{}{}
+		for _, v := range versions {
+			if _, ok := dup[v]; ok {
+				return "", "", nil, errors.Errorf("version duplicates are not allowed, got: %v", versions)
+			}
+			dup[v] = struct{}{}
+			if v == "none" {
+				return "", "", nil, errors.Errorf("none is not allowed when there are more than one specified Version, got: %v", versions)
+			}
+		}
+	}
+
+	name = nameOrPackage
+	if strings.Contains(nameOrPackage, "/") {
+		// Binary referenced by path, get default name from package path.
+		pkgPath = nameOrPackage
+		name = path.Base(pkgPath)
+		if pkgSplit := strings.Split(pkgPath, "/"); len(pkgSplit) > 3 && goModVersionRegexp.MatchString(name) {
+			// It's common pattern to name urls with versions in go modules. Exclude that.
+			name = pkgSplit[len(pkgSplit)-2]
+		}
+	}
+	return strings.ToLower(name), pkgPath, versions, nil
+}
+
+// Result describes outcome of bingo get for a single tool version.
+type Result struct {
+	Name            string  `json:"name"`
+	ModFile         string  `json:"modFile"`
+	PackagePath     string  `json:"packagePath"`
+	PreviousVersion string  `json:"previousVersion,omitempty"`
+	Version         string  `json:"version,omitempty"`
+	Rebuilt         bool    `json:"rebuilt"`
+	Removed         bool    `json:"removed"`
+	BinaryPath      string  `json:"binaryPath,omitempty"`
+	Frozen          bool    `json:"frozen,omitempty"`
+	DurationSeconds float64 `json:"durationSeconds"`
+}
+
+// Summary collects results of bingo get. Nil summary does not collect anything.
+type Summary struct {
+	Results []Result `json:"results"`
+}
+
+func (s *Summary) add(r Result) {
+	if s == nil {
+		return
+	}
+	s.Results = append(s.Results, r)
+}
+
+// addRemoved adds results for all given mod files, which are about to be removed.
+func (s *Summary) addRemoved(name string, modFiles []string) error {
+	if s == nil {
+		return nil
+	}
+	for _, f := range modFiles {
+		r := Result{Name: name, ModFile: filepath.Base(f), Removed: true}
+		if mf, err := bingo.ReadModFile(f); err == nil {
+			if pkg := mf.DirectPackage(); pkg != nil {
+				r.PackagePath, r.PreviousVersion = pkg.Path(), pkg.Module.Version
+			}
+		}
+		s.add(r)
+	}
+	return nil
+}
+
+// PrintJSON prints summary as JSON. Nil summary does not print anything.
+func (s *Summary) PrintJSON(w io.Writer) error {
+	if s == nil {
+		return nil
+	}
+	if s.Results == nil {
+		s.Results = []Result{}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(s)
+}
+
+// Events is notified about progress of Get, e.g. to render it. Methods are called synchronously, from one goroutine.
+// Embed NoopEvents to implement only some of them; more methods might be added in future.
+type Events interface {
+	// OnStart is called before getting tools, with number of tool versions to get. It's not called if tool is only deleted.
+	OnStart(total int)
+	// OnToolStart is called before getting each tool version, with tool name and target (package path with optional version).
+	OnToolStart(tool, target string)
+	// OnResolveStart is called before resolving module and version of the tool, if not all of them are known or update is requested.
+	OnResolveStart(tool string)
+	// OnResolveFinish is called after resolving, with resolved version or error.
+	OnResolveFinish(tool, version string, err error)
+	// OnBuildStart is called before building given tool version.
+	OnBuildStart(tool, version string)
+	// OnBuildFinish is called after building, with path to the built binary or error.
+	OnBuildFinish(tool, binPath string, err error)
+	// OnToolFinish is called after getting each tool version, with error if it failed.
+	OnToolFinish(tool string, err error)
+	// OnFinish is always called once Get finished, with error if it failed.
+	OnFinish(err error)
+}
+
+// NoopEvents implements Events by doing nothing.
+type NoopEvents struct{}
+
+func (NoopEvents) OnStart(int)                           {}
+func (NoopEvents) OnToolStart(string, string)            {}
+func (NoopEvents) OnResolveStart(string)                 {}
+func (NoopEvents) OnResolveFinish(string, string, error) {}
+func (NoopEvents) OnBuildStart(string, string)           {}
+func (NoopEvents) OnBuildFinish(string, string, error)   {}
+func (NoopEvents) OnToolFinish(string, error)            {}
+func (NoopEvents) OnFinish(error)                        {}
+
+type installPackageConfig struct {
+	runner      *runner.Runner
+	modDir      string
+	relModDir   string
+	update      runner.GetUpdatePolicy
+	link        bool
+	linkMode    LinkMode
+	store       string
+	description string
+	labels      bingo.Labels
+	aliases     []string
+	// staleAliases are aliases removed from the tool by this get, so their links are removed on install.
+	staleAliases []string
+	goFlags      []string
+	binDir       *string
+	replaces     []*modfile.Replace
+	offline      bool
+	gobins       []string
+	gitignore    bool
+	policy       *bingo.ModulePolicy
+	exception    string
+	registry     *bingo.Registry
+	block        bool
+	summary      *Summary
+	events       Events
+}
+
+// DefaultTimeout is default limit of duration of the whole get.
+const DefaultTimeout = 5 * time.Minute
+
+// Config configures Get.
+type Config struct {
+	Runner *runner.Runner
+	// ModDir is an absolute path to mod directory, RelModDir the same directory as given by user.
+	ModDir    string
+	RelModDir string
+	Update    runner.GetUpdatePolicy
+	// Name is a name to get tool under, instead of default one (-n flag).
+	Name string
+	// Rename is a new name for tool referenced by name (-r flag).
+	Rename string
+	Link   bool
+	// LinkMode defines how tool is linked if Link is true (-link-mode flag). Empty means AutoLinkMode.
+	LinkMode LinkMode
+	// Store is a directory of user-level store of built binaries shared between projects (-store flag). If not empty,
+	// binaries are built there once and linked into GOBIN using LinkMode.
+	Store string
+	// Description sets description of the tool in its mod file (-description flag), if not empty.
+	Description string
+	// Labels are merged into labels of the tool (-label flag). Labels with empty value are removed.
+	Labels bingo.Labels
+	// Aliases replace extra names of the tool linked to the same binary (-alias flag), if not nil. Empty slice removes them.
+	Aliases []string
+	// GoFlags replace GOFLAGS persisted for the tool (-goflags flag), if not nil. Empty slice removes them.
+	GoFlags []string
+	// BinDir replaces directory the tool is installed into instead of GOBIN (-bindir flag), if not nil. Empty string
+	// removes it. Relative directory is relative to the current directory (project root) and is stored relative to the
+	// mod directory, so committed mod file works for every contributor.
+	BinDir *string
+	// Replaces replace modules with local directories, relative to the current directory or absolute (-replace flag).
+	// They are stored relative to the mod directory.
+	Replaces []*modfile.Replace
+	// OfflineBuild makes build phase, run once modules are resolved and downloaded, fail on any network access or change
+	// of mod file (-offline-build flag), instead of silently fetching something else.
+	OfflineBuild bool
+	// GOBINs are additional absolute directories tools are installed into, besides GOBIN (-gobin flag). Each gets its own
+	// copy of the built binary.
+	GOBINs []string
+	// ModulePolicy makes get refuse tools depending on modules it disallows (-module-policy flag), if not nil.
+	ModulePolicy *bingo.ModulePolicy
+	// PolicyException documents modules the tool may depend on despite ModulePolicy (-policy-exception flag), if not empty.
+	// It's in bingo.PolicyExceptionCommand format.
+	PolicyException string
+	// Registry of approved tools and their versions (-registry flag), if not nil. Tools pinned in versions it does not
+	// approve are reported with warning, or refused if RegistryBlock is true (-registry-mode=block flag).
+	Registry      *bingo.Registry
+	RegistryBlock bool
+	// Selector selects tools to get by labels (-selector flag), if target is empty or has only versions (e.g "@none").
+	Selector bingo.Labels
+	Helpers  bingo.HelpersConfig
+	// Timeout limits duration of the whole get (-timeout flag). Zero means DefaultTimeout.
+	Timeout time.Duration
+	// Summary collects results, if not nil.
+	Summary *Summary
+	// Events is notified about progress, if not nil.
+	Events Events
+}
+
+func (c Config) forPackage() installPackageConfig {
+	return installPackageConfig{
+		modDir:      c.ModDir,
+		relModDir:   c.RelModDir,
+		runner:      c.Runner,
+		update:      c.Update,
+		link:        c.Link,
+		linkMode:    c.LinkMode,
+		store:       c.Store,
+		description: c.Description,
+		labels:      c.Labels,
+		aliases:     c.Aliases,
+		goFlags:     c.GoFlags,
+		binDir:      c.BinDir,
+		replaces:    c.Replaces,
+		offline:     c.OfflineBuild,
+		gobins:      c.GOBINs,
+		gitignore:   c.Helpers.GenPolicies[bingo.GitignoreFile] != bingo.NeverGenPolicy,
+		policy:      c.ModulePolicy,
+		exception:   c.PolicyException,
+		registry:    c.Registry,
+		block:       c.RegistryBlock,
+		summary:     c.Summary,
+		events:      c.events(),
+	}
+}
+
+func (c Config) events() Events {
+	if c.Events == nil {
+		return NoopEvents{}
+	}
+	return c.Events
+}
+
+// getSelected gets all tools with labels matching selector, with given versions only target (e.g "@none"), if any.
+func getSelected(ctx context.Context, logger logging.Logger, c Config, rawTarget string) error {
+	if rawTarget != "" && !strings.HasPrefix(rawTarget, "@") {
+		return errors.Errorf("selector cannot be used with tool %v; use no target or only versions, e.g @none", rawTarget)
+	}
+	if c.Name != "" || c.Rename != "" {
+		return errors.New("name or rename cannot by specified with selector")
+	}
+	if c.Description != "" {
+		return errors.New("description cannot by specified with selector; it describes single tool")
+	}
+	pkgs, err := bingo.ListPinnedMainPackages(logger, c.RelModDir, false)
+	if err != nil {
+		return err
+	}
+	selected := pkgs.Filter(nil, "", c.Selector)
+	if len(selected) == 0 {
+		return errors.Errorf("no tool matches selector %v", c.Selector.String())
+	}
+
+	if rawTarget == "" {
+		total := 0
+		for _, p := range selected {
+			total += len(p.Versions)
+		}
+		c.events().OnStart(total)
+		return getPackages(ctx, logger, c, selected)
+	}
+
+	// Get each tool by name with given versions.
+	c.Selector = nil
+	for _, p := range selected {
+		if err := get(ctx, logger, c, p.Name+rawTarget); err != nil {
+			return errors.Wrapf(err, "get %v", p.Name+rawTarget)
+		}
+	}
+	return nil
+}
+
+func getAll(ctx context.Context, logger logging.Logger, c Config) (err error) {
+	if c.Name != "" {
+		return errors.New("name cannot by specified if no target was given")
+	}
+	if c.Rename != "" {
+		return errors.New("rename cannot by specified if no target was given")
+	}
+	if c.Description != "" {
+		return errors.New("description cannot by specified if no target was given")
+	}
+
+	pkgs, err := bingo.ListPinnedMainPackages(logger, c.RelModDir, false)
+	if err != nil {
+		return err
+	}
+	total := 0
+	for _, p := range pkgs {
+		total += len(p.Versions)
+	}
+	c.events().OnStart(total)
+	return getPackages(ctx, logger, c, pkgs)
+}
+
+func getPackages(ctx context.Context, logger logging.Logger, c Config, pkgs bingo.PackageRenderables) error {
+	for _, p := range pkgs {
+		for i, targetPkg := range p.ToPackages() {
+			if err := getPackage(ctx, logger, c.forPackage(), i, p.Name, targetPkg); err != nil {
+				return errors.Wrapf(err, "%d: getting %s", i, targetPkg.String())
+			}
+		}
+	}
+	return nil
+}
+
+// didYouMean returns "; did you mean <name>?" hint if given name looks like a typo of a pinned tool name, empty string otherwise.
+func didYouMean(logger logging.Logger, modDir, name string) string {
+	pkgs, err := bingo.ListPinnedMainPackages(logger, modDir, false)
+	if err != nil {
+		return ""
+	}
+
+	best, bestDist := "", -1
+	for _, p := range pkgs {
+		if d := editDistance(name, p.Name); bestDist < 0 || d < bestDist {
+			best, bestDist = p.Name, d
+		}
+	}
+	// Allow roughly one typo per three characters, but at least two.
+	if bestDist < 0 || bestDist > 2 && bestDist > len(name)/3 {
+		return ""
+	}
+	return fmt.Sprintf("; did you mean %v?", best)
+}
+
+// editDistance returns Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	ar, br := []rune(a), []rune(b)
+	prev := make([]int, len(br)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ar); i++ {
+		cur := make([]int, len(br)+1)
+		cur[0] = i
+		for j := 1; j <= len(br); j++ {
+			cost := 1
+			if ar[i-1] == br[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev = cur
+	}
+	return prev[len(br)]
+}
+
+func existingModFiles(modDir string, targetName string) (existingModFiles []string, _ error) {
+	existingModFiles, err := filepath.Glob(filepath.Join(modDir, targetName+".mod"))
+	if err != nil {
+		return nil, err
+	}
+	existingModArrFiles, err := arrayModFiles(modDir, targetName)
+	if err != nil {
+		return nil, err
+	}
+	return append(existingModFiles, existingModArrFiles...), nil
+}
+
+// arrayModFiles returns numbered array mod files of given tool (e.g tool.1.mod), but not of tools with names starting
+// with the tool name and a dot (e.g tool.v2.mod).
+func arrayModFiles(modDir string, targetName string) ([]string, error) {
+	matches, err := filepath.Glob(filepath.Join(modDir, targetName+".*.mod"))
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for _, f := range matches {
+		if _, ok := bingo.ModFileNumber(targetName, f); ok {
+			files = append(files, f)
+		}
+	}
+	return files, nil
+}
+
+// Get performs bingo get: it's like go get, but package aware, without go source files and on dedicated mod file.
+// rawTarget is name or target package path, optionally with module version or array versions. Empty target means all
+// pinned tools.
+func Get(ctx context.Context, logger logging.Logger, c Config, rawTarget string) (err error) {
+	timeout := c.Timeout
+	if timeout == 0 {
+		timeout = DefaultTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	defer func() { c.events().OnFinish(err) }()
+
+	// Cleanup all bingo modules' tmp files for fresh start.
+	if err := CleanTmpFiles(c.ModDir); err != nil {
+		return err
+	}
+	if err := ensureModDirExists(logger, c.RelModDir, c.Helpers); err != nil {
+		return errors.Wrap(err, "ensure mod dir")
+	}
+	return get(ctx, logger, c, rawTarget)
+}
+
+func get(ctx context.Context, logger logging.Logger, c Config, rawTarget string) (err error) {
+	if len(c.Selector) > 0 {
+		return getSelected(ctx, logger, c, rawTarget)
+	}
+	if rawTarget == "" {
+		// Empty target means to get all. It recursively invokes get for each existing binary.
+		return getAll(ctx, logger, c)
+	}
+
+	// NOTE: pkgPath can be empty. This means that tool was referenced by name.
+	name, pkgPath, versions, err := parseTarget(rawTarget)
+	if err != nil {
+		return errors.Wrapf(err, "parse %v", rawTarget)
+	}
+
+	if c.Update != runner.NoUpdatePolicy {
+		if versions[0] != "" || len(versions) > 1 {
+			return errors.Errorf("-u specified; upgrade cannot take version arguments (string after @), got %v", versions)
+		}
+	}
+
+	if c.Rename != "" {
+		// Treat rename specially.
+		if pkgPath != "" {
+			return errors.Errorf("-r rename has to reference installed tool by name not path, got: %v", pkgPath)
+		}
+		if versions[0] != "" || len(versions) > 1 {
+			return errors.Errorf("-r rename cannot take version arguments (string after @), got %v", versions)
+		}
+		if err := validateNewName(versions, name, c.Rename); err != nil {
+			return errors.Wrap(err, "-r")
+		}
+		newExisting, err := existingModFiles(c.ModDir, c.Rename)
+		if err != nil {
+			return errors.Wrapf(err, "existing mod files for %v", c.Rename)
+		}
+		if len(newExisting) > 0 {
+			return errors.Errorf("found existing installed binaries %v under name you want to rename on. Remove target name %s or use different one", newExisting, c.Rename)
+		}
+
+		existing, err := existingModFiles(c.ModDir, name)
+		if err != nil {
+			return errors.Wrapf(err, "existing mod files for %v", name)
+		}
+
+		if len(existing) == 0 {
+			return newSentinelError(bingo.ErrNotInstalled, "nothing to rename, tool %v not installed%s", name, didYouMean(logger, c.ModDir, name))
+		}
+
+		targets := make([]bingo.Package, 0, len(existing))
+		for _, e := range existing {
+			mf, err := bingo.ReadModFile(e)
+			if err != nil {
+				return errors.Wrapf(err, "found unparsable mod file %v. Uninstall it first via get %v@none or fix it manually.", e, name)
+			}
+
+			if mf.DirectPackage() == nil {
+				return errors.Wrapf(err, "failed to rename tool %v to %v name; found empty mod file %v; Use full path to install tool again", name, c.Rename, e)
+			}
+			targets = append(targets, *mf.DirectPackage())
+		}
+
+		c.events().OnStart(len(targets))
+		for i, t := range targets {
+			if err := getPackage(ctx, logger, c.forPackage(), i, c.Rename, t); err != nil {
+				return errors.Wrapf(err, "%s.mod: getting %s", c.Rename, t)
+			}
+		}
+
+		// Remove old mod files.
+		if err := c.Summary.addRemoved(name, existing); err != nil {
+			return err
+		}
+		return removeAllGlob(filepath.Join(c.ModDir, name+".*"))
+	}
+
+	targetName := name
+	if c.Name != "" {
+		if err := validateNewName(versions, name, c.Name); err != nil {
+			return errors.Wrap(err, "-n")
+		}
+		targetName = c.Name
+	}
+
+	existing, err := existingModFiles(c.ModDir, targetName)
+	if err != nil {
+		return errors.Wrapf(err, "existing mod files for %v", targetName)
+	}
+
+	switch versions[0] {
+	case "none":
+		if pkgPath != "" {
+			return errors.Errorf("cannot delete tool by full path. Use just %v@none name instead", targetName)
+		}
+		if len(existing) == 0 {
+			return newSentinelError(bingo.ErrNotInstalled, "nothing to delete, tool %v is not installed%s", targetName, didYouMean(logger, c.ModDir, targetName))
+		}
+		// None means we no longer want to version this package.
+		// NOTE: We don't remove binaries.
+		if err := c.Summary.addRemoved(targetName, existing); err != nil {
+			return err
+		}
+		return removeAllGlob(filepath.Join(c.ModDir, name+".*"))
+	case "":
+		if len(existing) > 1 && c.Update == runner.NoUpdatePolicy {
+			// Edge case. If no version is specified and no update is requested, allow to pull all array versions at once.
+			versions = make([]string, len(existing))
+		}
+	}
+
+	targets := make([]bingo.Package, 0, len(versions))
+	pathWasSpecified := pkgPath != ""
+	for i, v := range versions {
+		target := bingo.Package{Module: module.Version{Version: v}, RelPath: pkgPath} // "Unknown" module mode.
+		if len(existing) > i {
+			e := existing[i]
+
+			mf, err := bingo.ReadModFile(e)
+			if err != nil {
+				return errors.Wrapf(err, "found unparsable mod file %v. Uninstall it first via get %v@none or fix it manually.", e, name)
+			}
+
+			if mf.DirectPackage() != nil {
+				if target.Path() != "" && target.Path() != mf.DirectPackage().Path() {
+					if pathWasSpecified {
+						return errors.Errorf("found mod file %v that has different package path %q than given %q"+
+							"Uninstall existing tool using `%v@none` or use `-n` flag to choose different name", e, mf.DirectPackage().Path(), target.Path(), targetName)
+					}
+					return errors.Errorf("found array mod file %v that has different package path %q than previous in array %q. Manual edit?"+
+						"Uninstall existing tool using `%v@none` or use `-n` flag to choose different name", e, mf.DirectPackage().Path(), target.Path(), targetName)
+				}
+
+				target.Module.Path = mf.DirectPackage().Module.Path
+				if target.Module.Version == "" && c.Update == runner.NoUpdatePolicy {
+					// If no version and no update is requested, use the existing version.
+					target.Module.Version = mf.DirectPackage().Module.Version
+				}
+				target.RelPath = mf.DirectPackage().RelPath
+
+				// Save for future versions without potentially existing files.
+				pkgPath = target.Path()
+			} else if target.Path() == "" {
+				return errors.Wrapf(err, "failed to install tool %v found empty mod file %v; Use full path to install tool again", targetName, e)
+			}
+		}
+		if target.Path() == "" {
+			return newSentinelError(bingo.ErrNotInstalled, "tool referenced by name %v that was never installed before%s; Use full path to install a tool", name, didYouMean(logger, c.ModDir, name))
+		}
+		targets = append(targets, target)
+	}
+
+	c.events().OnStart(len(targets))
+	for i, t := range targets {
+		if err := getPackage(ctx, logger, c.forPackage(), i, targetName, t); err != nil {
+			return errors.Wrapf(err, "%s.mod: getting %s", targetName, t)
+		}
+	}
+
+	// Remove target unused arr mod files based on version file.
+	existingTargetModArrFiles, gerr := arrayModFiles(c.ModDir, targetName)
+	if gerr != nil {
+		err = gerr
+		return
+	}
+	for _, f := range existingTargetModArrFiles {
+		if i, _ := bingo.ModFileNumber(targetName, f); i >= len(versions) {
+			if serr := c.Summary.addRemoved(targetName, []string{f}); serr != nil {
+				err = serr
+				return
+			}
+			if rerr := os.RemoveAll(f); rerr != nil {
+				err = rerr
+				return
+			}
+		}
+	}
+	return nil
+}
+
+func validateNewName(versions []string, old, new string) error {
+	if new == old {
+		return errors.Errorf("cannot be the same as module name %v", new)
+	}
+	if versions[0] == "none" {
+		return errors.Errorf("cannot use with @none logic")
+	}
+	return nil
+}
+
+// CleanTmpFiles removes temporary files left in mod directory by Get.
+func CleanTmpFiles(modDir string) error {
+	// Remove all sum and tmp files
+	if err := removeAllGlob(filepath.Join(modDir, "*.sum")); err != nil {
+		return err
+	}
+	if err := removeAllGlob(filepath.Join(modDir, "*.*.tmp.*")); err != nil {
+		return err
+	}
+	return removeAllGlob(filepath.Join(modDir, "*.tmp.*"))
+}
+
+func validateTargetName(targetName string) error {
+	if targetName == "cmd" {
+		return errors.Errorf("package would be installed with ambiguous name %s. This is a common, but slightly annoying package layout"+
+			"It's advised to choose unique name with -n flag", targetName)
+	}
+	for _, reserved := range []string{bingo.FakeRootModFileName, bingo.ConstraintsModFileName} {
+		if targetName == strings.TrimSuffix(reserved, ".mod") {
+			return errors.Errorf("requested binary with name %q`. This is impossible, choose different name using -n flag", strings.TrimSuffix(reserved, ".mod"))
+		}
+	}
+	return nil
+}
+
+func resolvePackage(
+	ctx context.Context,
+	logger logging.Logger,
+	tmpModFile string,
+	runnable runner.Runnable,
+	update runner.GetUpdatePolicy,
+	target *bingo.Package,
+) (err error) {
+	// Do initial go get -d and remember output.
+	// NOTE: We have to use get -d to resolve version and tell us what is the module and what package.
+	// If go get will not succeed, or will not update go mod, we will try manual lookup.
+	// This is required to support modules depending on broken modules (and using exclude/replace statements).
+	out, gerr := runnable.GetD(update, target.String())
+	if gerr == nil {
+		mods, err := bingo.ModIndirectModules(tmpModFile)
+		if err != nil {
+			return err
+		}
+
+		switch len(mods) {
+		case 0:
+			return errors.Errorf("no indirect module found on %v", tmpModFile)
+		case 1:
+			target.RelPath = strings.TrimPrefix(strings.TrimPrefix(target.RelPath, mods[0].Path), "/")
+			target.Module = mods[0]
+			return nil
+		default:
+			if target.Module.Path != "" {
+				for _, m := range mods {
+					if m.Path == target.Module.Path {
+						target.RelPath = strings.TrimPrefix(strings.TrimPrefix(target.RelPath, m.Path), "/")
+						target.Module = m
+						return nil
+					}
+				}
+				return errors.Errorf("no indirect module found on %v for %v module", tmpModFile, target.Module.Path)
+			}
+
+			if m, ok := providingModule(runnable, mods, target.Path()); ok {
+				target.RelPath = strings.TrimPrefix(strings.TrimPrefix(target.RelPath, m.Path), "/")
+				target.Module = m
+				return nil
+			}
+
+			// In this case it is not successful from our perspective.
+			gerr = errors.New(out)
+		}
+	}
+
+	if ctx.Err() != nil {
+		// Don't fallback if go get was interrupted.
+		return gerr
+	}
+
+	// We fallback only if go-get failed which happens when it does not know what version to choose.
+	// In this case
+	if err := resolveInGoModCache(ctx, logger, update, target); err != nil {
+		return &bingo.ResolveError{
+			Module:     target.String(),
+			Err:        errors.Wrapf(err, "fallback to local go mod cache resolution failed after go get failure: %v", gerr),
+			ProxyChain: diagnoseProxyChain(ctx, runnable, target.Path()),
+		}
+	}
+	return nil
+}
+
+// diagnoseProxyChain returns description of which entries of GOPROXY chain go tried for given package and with what
+// result, so broken mirrors can be pinpointed. It's empty if GOPROXY is not a chain or the package is not fetched via proxy.
+func diagnoseProxyChain(ctx context.Context, runnable runner.Runnable, pkgPath string) string {
+	out, err := runnable.GoEnv("GOPROXY", "GONOPROXY")
+	if err != nil || ctx.Err() != nil {
+		return ""
+	}
+	goproxy, noproxy, _ := strings.Cut(out, "\n")
+	if len(runner.ParseGOPROXY(goproxy)) < 2 || runner.MatchModulePatterns(noproxy, pkgPath) {
+		return ""
+	}
+	return runner.DiagnoseProxyChain(ctx, http.DefaultClient, goproxy, pkgPath).String()
+}
+
+// providingModule returns module from given ones that provides package with given path. Package can be in nested module
+// (e.g github.com/org/repo/tools/cmd/x from github.com/org/repo/tools module with its own go.mod) while parent modules
+// are required too, so only modules with path being a prefix of the package path are considered and if there are more,
+// go list tells which one provides it. If go list fails, the longest (most nested) module path wins, same as in module cache
+// fallback (see resolveInGoModCache).
+func providingModule(runnable runner.Runnable, mods []module.Version, pkgPath string) (module.Version, bool) {
+	var candidates []module.Version
+	for _, m := range mods {
+		if m.Path == pkgPath || strings.HasPrefix(pkgPath, m.Path+"/") {
+			candidates = append(candidates, m)
+		}
+	}
+	switch len(candidates) {
+	case 0:
+		return module.Version{}, false
+	case 1:
+		return candidates[0], true
+	}
+
+	if out, err := runnable.List(runner.NoUpdatePolicy, "-mod=mod", "-f={{with .Module}}{{.Path}}{{end}}", pkgPath); err == nil {
+		for _, m := range candidates {
+			if m.Path == strings.TrimSpace(out) {
+				return m, true
+			}
+		}
+	}
+	longest := candidates[0]
+	for _, m := range candidates[1:] {
+		if len(m.Path) > len(longest.Path) {
+			longest = m
+		}
+	}
+	return longest, true
+}
+
+func gomodcache() string {
+	cachepath := os.Getenv("GOMODCACHE")
+	if gpath := os.Getenv("GOPATH"); gpath != "" && cachepath == "" {
+		cachepath = filepath.Join(gpath, "pkg/mod")
+	}
+	return cachepath
+}
+
+func latestModVersion(listFile string) (_ string, err error) {
+	f, err := os.Open(listFile)
+	if err != nil {
+		return "", err
+	}
+	defer errcapture.Do(&err, f.Close, "list file close")
+
+	scanner := bufio.NewScanner(f)
+	var lastVersion string
+	for scanner.Scan() {
+		lastVersion = scanner.Text()
+	}
+	if err := scanner.Err(); err != nil {
+		return "", err
+	}
+	if lastVersion == "" {
+		return "", errors.New("empty file")
+	}
+	return lastVersion, nil
+}
+
+// resolveInGoModCache will try to find a referenced module in the Go modules cache.
+func resolveInGoModCache(ctx context.Context, logger logging.Logger, update runner.GetUpdatePolicy, target *bingo.Package) error {
+	modMetaCache := filepath.Join(gomodcache(), "cache/download")
+	modulePath := target.Path()
+
+	// Since we don't know which part of full path is package, which part is module.
+	// Start from longest and go until we find one.
+	for ; len(strings.Split(modulePath, "/")) > 2; modulePath = filepath.Dir(modulePath) {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		modMetaDir := filepath.Join(modMetaCache, modulePath, "@v")
+		if _, err := os.Stat(modMetaDir); err != nil {
+			if os.IsNotExist(err) {
+				logger.Debug("resolveInGoModCache: directory does not exists", "dir", modMetaDir)
+				continue
+			}
+			return err
+		}
+		logger.Debug("resolveInGoModCache: found directory", "dir", modMetaDir)
+
+		// There are 2 major cases:
+		// 1. We have -u flag or version is not pinned: find latest module having this package.
+		if update != runner.NoUpdatePolicy || target.Module.Version == "" {
+			latest, err := latestModVersion(filepath.Join(modMetaDir, "list"))
+			if err != nil {
+				return errors.Wrapf(err, "get latest version from %v", filepath.Join(modMetaDir, "list"))
+			}
+
+			target.Module.Path = modulePath
+			target.Module.Version = latest
+			target.RelPath = strings.TrimPrefix(strings.TrimPrefix(target.RelPath, target.Module.Path), "/")
+			return nil
+		}
+
+		// 2. We don't have update flag and have version pinned: find exact version then.
+		// Look for .info files that have exact version or sha.
+		if strings.HasPrefix(target.Module.Version, "v") {
+			if _, err := os.Stat(filepath.Join(modMetaDir, target.Module.Version+".info")); err != nil {
+				if os.IsNotExist(err) {
+					logger.Debug("resolveInGoModCache: file not exists. Looking for different module",
+						"file", filepath.Join(modMetaDir, target.Module.Version+".info"))
+					continue
+				}
+				return err
+			}
+			target.Module.Path = modulePath
+			target.RelPath = strings.TrimPrefix(strings.TrimPrefix(target.RelPath, target.Module.Path), "/")
+			return nil
+		}
+
+		// We have commit sha.
+		files, err := ioutil.ReadDir(modMetaDir)
+		if err != nil {
+			return err
+		}
+
+		for _, f := range files {
+			if f.IsDir() {
+				continue
+			}
+			if len(target.Module.Version) > 12 && strings.HasSuffix(f.Name(), fmt.Sprintf("%v.info", target.Module.Version[:12])) {
+				target.Module.Path = modulePath
+				target.Module.Version = strings.TrimSuffix(f.Name(), ".info")
+				target.RelPath = strings.TrimPrefix(strings.TrimPrefix(target.RelPath, target.Module.Path), "/")
+				return nil
+			}
+		}
+
+		logger.Debug("resolveInGoModCache: .info file for sha does not exists. Looking for different module",
+			"sha", target.Module.Version[:12])
+	}
+	return errors.Errorf("no module was cached matching given package %v", target.Path())
+}
+
+// getPackage takes package array index, tool name and package path (also module path and version which are optional) and
+// generates new module with the given package's module as the only dependency (direct require statement).
+// For generation purposes we take the existing <name>.mod file (if exists, if paths matches). This allows:
+//   - Comments to be preserved.
+//   - First direct require module will be preserved (unless version changes)
+//   - Replace and exclude to be preserved if the // bingo:no_replace_fetch commend is found it such mod file.
+//
+// As resolution of module vs package for Go Module is convoluted and all code is under internal dir, we have to rely on `go` binary
+// capabilities and output.
+// TODO(bwplotka): Consider copying code for it? Of course it's would be easier if such tool would exist in Go project itself (:
+func getPackage(ctx context.Context, logger logging.Logger, c installPackageConfig, i int, name string, target bingo.Package) (err error) {
+	logger = logging.With(logger, "tool", name)
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	logger.Debug("getting target", "target", target.String(), "module", target.Module.Path)
+	start := time.Now()
+	defer func() { logToolPhase(logger, "get", start, err) }()
+	c.events.OnToolStart(name, target.String())
+	defer func() { c.events.OnToolFinish(name, err) }()
+
+	// The out module file we generate/maintain keep in modDir.
+	outModFile := filepath.Join(c.modDir, name+".mod")
+	tmpEmptyModFilePath := filepath.Join(c.modDir, name+"-e.tmp.mod")
+	tmpModFilePath := filepath.Join(c.modDir, name+".tmp.mod")
+	if i > 0 {
+		// Handle array go modules.
+		outModFile = filepath.Join(c.modDir, fmt.Sprintf("%s.%d.mod", name, i))
+		tmpEmptyModFilePath = filepath.Join(c.modDir, fmt.Sprintf("%s.%d-e.tmp.mod", name, i))
+		tmpModFilePath = filepath.Join(c.modDir, fmt.Sprintf("%s.%d.tmp.mod", name, i))
+	}
+
+	frozen := false
+	if c.update != runner.NoUpdatePolicy {
+		version, reason, ok, err := frozenVersion(outModFile)
+		if err != nil {
+			return err
+		}
+		if ok {
+			logger.Info("tool is frozen; skipping update", "reason", reason)
+			c.update = runner.NoUpdatePolicy
+			if target.Module.Version == "" {
+				target.Module.Version = version
+			}
+			frozen = true
+		}
+	}
+
+	localReplaceStmts, pinned, err := localReplaces(outModFile, c.modDir, c.replaces)
+	if err != nil {
+		return err
+	}
+
+	// If we don't have all information or update is set, resolve version.
+	var (
+		replaceStmts []*modfile.Replace
+		excludeStmts []*modfile.Exclude
+	)
+	if local := locallyReplaced(target, localReplaceStmts); local != nil {
+		// Module is replaced with local directory (e.g developed in sibling directory), there is nothing to resolve.
+		logger.Debug("module replaced with local directory", "module", local.Old.Path, "dir", local.New.Path)
+		if target.Module.Path == "" {
+			target.RelPath = strings.TrimPrefix(strings.TrimPrefix(target.Path(), local.Old.Path), "/")
+			target.Module.Path = local.Old.Path
+		}
+		if !strings.HasPrefix(target.Module.Version, "v") {
+			target.Module.Version = pinned
+			if target.Module.Version == "" {
+				target.Module.Version = localReplaceVersion
+			}
+		}
+		replaceStmts, excludeStmts, err = localReplaceStatements(c.modDir, local.New.Path)
+		if err != nil {
+			return err
+		}
+		for _, r := range replaceStmts {
+			if bingo.IsLocalReplace(r) {
+				// User given replaces take precedence.
+				localReplaceStmts = append([]*modfile.Replace{r}, localReplaceStmts...)
+			}
+		}
+	} else if target.Module.Version == "" || !strings.HasPrefix(target.Module.Version, "v") || target.Module.Path == "" || c.update != runner.NoUpdatePolicy {
+		// Set up totally empty mod file to get clear version to install.
+		tmpEmptyModFile, err := bingo.CreateFromExistingOrNew(ctx, c.runner, logger, "", tmpEmptyModFilePath)
+		if err != nil {
+			return errors.Wrap(err, "create empty tmp mod file")
+		}
+		defer errcapture.Do(&err, tmpEmptyModFile.Close, "close")
+
+		runnable := c.runner.With(ctx, tmpEmptyModFile.FileName(), c.modDir, nil).WithPrefix(name)
+		c.events.OnResolveStart(name)
+		resolveStart := time.Now()
+		if err := resolvePackage(ctx, logger, tmpEmptyModFile.FileName(), runnable, c.update, &target); err != nil {
+			c.events.OnResolveFinish(name, "", err)
+			return err
+		}
+		c.events.OnResolveFinish(name, target.Module.Version, nil)
+		logToolPhase(logger, "resolve", resolveStart, nil)
+
+		if !strings.HasSuffix(target.Module.Version, "+incompatible") {
+			replaceStmts, excludeStmts, err = autoFetchReplaceStatements(runnable, target)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	// Tools built from local directories are being developed, so they are not expected in registry.
+	if c.registry != nil && locallyReplaced(target, localReplaceStmts) == nil {
+		if err := c.registry.Check(target.Path(), target.Module.Path, target.Module.Version); err != nil {
+			if c.block {
+				return newSentinelError(bingo.ErrNotApproved, "%v", err)
+			}
+			logger.Warn("pinning tool version not approved by registry", "err", err)
+		}
+	}
+
+	// Now we should have target with all required info, prepare tmp file.
+	if err := CleanTmpFiles(c.modDir); err != nil {
+		return err
+	}
+	tmpModFile, err := bingo.CreateFromExistingOrNew(ctx, c.runner, logger, outModFile, tmpModFilePath)
+	if err != nil {
+		return errors.Wrap(err, "create tmp mod file")
+	}
+	defer errcapture.Do(&err, tmpModFile.Close, "close")
+
+	if !tmpModFile.AutoReplaceDisabled() && len(replaceStmts) > 0 {
+		if err := tmpModFile.SetReplace(replaceStmts...); err != nil {
+			return err
+		}
+	}
+	if !tmpModFile.AutoReplaceDisabled() && len(excludeStmts) > 0 {
+		if err := tmpModFile.SetExclude(excludeStmts...); err != nil {
+			return err
+		}
+	}
+	for _, r := range localReplaceStmts {
+		if err := tmpModFile.SetLocalReplace(r.Old.Path, r.Old.Version, r.New.Path); err != nil {
+			return err
+		}
+	}
+
+	var previousVersion string
+	// Currently user can't specify build flags and envvars from CLI, take if from optionally, manually updated mod file.
+	if old := tmpModFile.DirectPackage(); old != nil {
+		previousVersion = old.Module.Version
+		target.BuildEnvs = old.BuildEnvs
+		target.BuildFlags = old.BuildFlags
+	}
+	if err := tmpModFile.SetDirectRequire(target); err != nil {
+		return err
+	}
+	// Constraints are reapplied on every get, so changes in constraints file (including removals) are always reflected.
+	constraints, err := bingo.ReadConstraints(c.modDir)
+	if err != nil {
+		return errors.Wrap(err, "read constraints")
+	}
+	if err := tmpModFile.SetConstraints(constraints); err != nil {
+		return errors.Wrap(err, "apply constraints")
+	}
+	if c.description != "" {
+		tmpModFile.SetCommand(bingo.DescriptionCommand, c.description)
+	}
+	if c.exception != "" {
+		tmpModFile.SetCommand(bingo.PolicyExceptionCommand, c.exception)
+	}
+	if len(c.labels) > 0 {
+		existing, _ := tmpModFile.Command(bingo.LabelsCommand)
+		labels, err := bingo.ParseLabels(existing)
+		if err != nil {
+			logger.Warn("replacing malformed labels", "labels", existing, "err", err)
+		}
+		tmpModFile.SetCommand(bingo.LabelsCommand, labels.Merge(c.labels).String())
+	}
+	if c.aliases != nil {
+		prev, _ := tmpModFile.Command(bingo.AliasesCommand)
+		prevAliases, err := bingo.ParseAliases(prev)
+		if err != nil {
+			logger.Warn("replacing malformed aliases", "aliases", prev, "err", err)
+		}
+		c.staleAliases = staleAliases(prevAliases, c.aliases)
+		tmpModFile.SetCommand(bingo.AliasesCommand, strings.Join(c.aliases, ","))
+	}
+	if c.goFlags != nil {
+		tmpModFile.SetCommand(bingo.GoFlagsCommand, strings.Join(c.goFlags, " "))
+	}
+	if c.binDir != nil {
+		binDir, err := binDirArg(c.modDir, *c.binDir)
+		if err != nil {
+			return errors.Wrap(err, "bin dir")
+		}
+		tmpModFile.SetCommand(bingo.BinDirCommand, binDir)
+	}
+
+	if err := tmpModFile.Flush(); err != nil {
+		return err
+	}
+
+	c.events.OnBuildStart(name, target.Module.Version)
+	installStart := time.Now()
+	binDir, _ := tmpModFile.Command(bingo.BinDirCommand)
+	gobin := bingo.ToolBinDir(c.modDir, BinDir(c.modDir), binDir)
+	binPath := filepath.Join(gobin, bingo.ArtifactName(name, target.Module.Version, target.BuildEnvs))
+	if c.link && bingo.IsWasm(target.BuildEnvs) {
+		logger.Warn("WebAssembly module is not executable on its own, so it is not linked; use path from generated helpers instead",
+			"artifact", filepath.Base(binPath))
+	} else if c.link {
+		if linked, ok := linkedBinary(filepath.Join(gobin, name+bingo.GOEXE())); ok && filepath.Base(linked) != filepath.Base(binPath) {
+			// Relinking to other version of the tool is a normal upgrade; only binaries not pinned by this tool are surprising.
+			own, err := pinnedBinaries(c.modDir, name)
+			if err != nil {
+				return errors.Wrap(err, "pinned binaries")
+			}
+			if _, ok := own[filepath.Base(linked)]; !ok {
+				logger.Warn("replacing link to different binary, possibly pinned by other mod directory (e.g in monorepo)",
+					"link", name, "from", filepath.Base(linked), "to", filepath.Base(binPath))
+			}
+		}
+	}
+	rebuilt, err := install(ctx, c, name, tmpModFile)
+	if err != nil {
+		c.events.OnBuildFinish(name, "", err)
+		return errors.Wrap(err, "install")
+	}
+	c.events.OnBuildFinish(name, binPath, nil)
+	logToolPhase(logger, "install", installStart, nil)
+
+	// Record hash of resolved module content, so it can be verified without go.sum, which is not committed.
+	sum, err := bingo.ModuleSum(strings.TrimSuffix(tmpModFile.FileName(), ".mod")+".sum", target.Module.Path, target.Module.Version)
+	if err != nil && !os.IsNotExist(err) {
+		return errors.Wrap(err, "read module sum")
+	}
+	tmpModFile.SetCommand(bingo.SumCommand, sum)
+	if err := tmpModFile.Flush(); err != nil {
+		return err
+	}
+
+	// We were working on tmp file, do atomic rename.
+	if err := os.Rename(tmpModFile.FileName(), outModFile); err != nil {
+		return errors.Wrap(err, "rename")
+	}
+
+	if previousVersion != "" && previousVersion != target.Module.Version {
+		logger.Info("version changed", "version", VersionChange{From: previousVersion, To: target.Module.Version})
+	}
+
+	c.summary.add(Result{
+		Name:            name,
+		ModFile:         filepath.Base(outModFile),
+		PackagePath:     target.Path(),
+		PreviousVersion: previousVersion,
+		Version:         target.Module.Version,
+		Rebuilt:         rebuilt,
+		BinaryPath:      binPath,
+		Frozen:          frozen,
+		DurationSeconds: time.Since(start).Seconds(),
+	})
+	return nil
+}
+
+// pinnedBinaries returns names of binaries of all versions of given tool pinned in mod directory.
+func pinnedBinaries(modDir, name string) (map[string]struct{}, error) {
+	modFiles, err := existingModFiles(modDir, name)
+	if err != nil {
+		return nil, err
+	}
+	bins := map[string]struct{}{}
+	for _, f := range modFiles {
+		mf, err := bingo.ReadModFile(f)
+		if err != nil {
+			return nil, errors.Wrapf(err, "read %v", f)
+		}
+		pkg := mf.DirectPackage()
+		if pkg == nil {
+			continue
+		}
+		bins[bingo.ArtifactName(name, pkg.Module.Version, pkg.BuildEnvs)] = struct{}{}
+	}
+	return bins, nil
+}
+
+func localGoModFileAfterGet(gopath string, target bingo.Package) string {
+	modulePath := target.Module.String()
+
+	// Go get uses special notation for non-supported names. See https://github.com/bwplotka/bingo/issues/65.
+	var b strings.Builder
+	b.Grow(len(modulePath))
+	for i := 0; i < len(modulePath); i++ {
+		c := rune(modulePath[i])
+		if 'A' <= c && c <= 'Z' {
+			b.WriteByte('!')
+			c = unicode.To(unicode.LowerCase, c)
+		}
+		b.WriteRune(c)
+	}
+	return filepath.Join(gopath, "pkg", "mod", b.String(), "go.mod")
+}
+
+// autoFetchReplaceStatements is reproducing replace and exclude statements to be exactly the same as the target module we want to install.
+// It's a very common case where modules mitigate faulty modules or conflicts with replace (or exclude) directives.
+// Since we always download single tool dependency module per tool module, we can copy its replace and exclude if exists to fix this common case.
+func autoFetchReplaceStatements(runnable runner.Runnable, target bingo.Package) ([]*modfile.Replace, []*modfile.Exclude, error) {
+	gopath, err := runnable.GoEnv("GOPATH")
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "go env")
+	}
+
+	// We leverage fact that when go get runs if downloads the version we find as relevant locally
+	// in the GOPATH/pkg/mod/...
+	targetModFile := localGoModFileAfterGet(gopath, target)
+	if _, err := os.Stat(targetModFile); err != nil {
+		if os.IsNotExist(err) {
+			// Pre module package.
+			return nil, nil, nil
+		}
+		return nil, nil, errors.Wrapf(err, "stat target mod directory %v", targetModFile)
+	}
+
+	targetModParsed, err := bingo.ParseModFileOrReader(targetModFile, nil)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "parse target mod file %v", targetModFile)
+	}
+	return targetModParsed.Replace, targetModParsed.Exclude, nil
+}
+
+// GenHelpers (re)generates helpers for all pinned tools in given mod directory, or removes them if nothing is pinned.
+func GenHelpers(logger logging.Logger, relModDir string, cfg bingo.HelpersConfig, varPrefix, varSuffix string) error {
+	pkgs, err := bingo.ListPinnedMainPackages(logger, relModDir, true)
+	if err != nil {
+		return errors.Wrap(err, "list pinned")
+	}
+	if len(pkgs) == 0 {
+		return bingo.RemoveHelpers(relModDir, cfg)
+	}
+	WarnDuplicates(logger, pkgs)
+	pkgs.ApplyEnvVarNaming(varPrefix, varSuffix)
+	return bingo.GenHelpers(relModDir, version.Version, pkgs, cfg)
+}
+
+// RenderHelpers renders helpers GenHelpers would generate in given mod directory, without touching any file (e.g for
+// fmt -check). It returns their content by output path; nil content means the helper would be removed.
+func RenderHelpers(logger logging.Logger, relModDir string, cfg bingo.HelpersConfig, varPrefix, varSuffix string) (map[string][]byte, error) {
+	pkgs, err := bingo.ListPinnedMainPackages(logger, relModDir, false)
+	if err != nil {
+		return nil, errors.Wrap(err, "list pinned")
+	}
+	if len(pkgs) == 0 {
+		removed := map[string][]byte{}
+		for _, f := range cfg.RemovedHelpers(relModDir) {
+			removed[f] = nil
+		}
+		return removed, nil
+	}
+	pkgs.ApplyEnvVarNaming(varPrefix, varSuffix)
+	return bingo.RenderHelpers(relModDir, version.Version, pkgs, cfg)
+}
+
+// BinDir returns directory where tools pinned in given mod directory are installed: project-local bingo.LocalBinDir in
+// mod directory if it exists (see bingo.LocalBinPathMode), GOBIN otherwise.
+func BinDir(modDir string) string {
+	if bingo.HasLocalBinDir(modDir) {
+		return filepath.Join(modDir, bingo.LocalBinDir)
+	}
+	return GOBIN()
+}
+
+// binDirArg returns given directory (-bindir flag) as stored in bingo.BinDirCommand: slash separated and relative to
+// given absolute mod directory, unless it's absolute.
+func binDirArg(modDir, dir string) (string, error) {
+	if dir == "" || filepath.IsAbs(dir) {
+		return filepath.ToSlash(dir), nil
+	}
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	rel, err := filepath.Rel(modDir, abs)
+	if err != nil {
+		return "", errors.Wrapf(err, "%v relative to %v", abs, modDir)
+	}
+	return filepath.ToSlash(rel), nil
+}
+
+// GOBIN mimics the way go install finds where to install go tool: GOBIN if set, bin directory in the first GOPATH entry
+// otherwise. If GOPATH is not set either, default GOPATH (e.g $HOME/go) is used.
+func GOBIN() string {
+	if binPath := os.Getenv("GOBIN"); binPath != "" {
+		return binPath
+	}
+	gpath := os.Getenv("GOPATH")
+	if gpath == "" {
+		gpath = build.Default.GOPATH
+	}
+	if list := filepath.SplitList(gpath); len(list) > 0 {
+		return filepath.Join(list[0], "bin")
+	}
+	return ""
+}
+
+func install(ctx context.Context, c installPackageConfig, name string, modFile *bingo.ModFile) (rebuilt bool, err error) {
+	r, modDir := c.runner, c.modDir
+	pkg := modFile.DirectPackage()
+	if err := validateTargetName(name); err != nil {
+		return false, errors.Wrap(err, pkg.String())
+	}
+
+	// GOFLAGS persisted for the tool apply to all go commands building it, on top of build env vars.
+	var goFlagsEnv envars.EnvSlice
+	if goFlags, _ := modFile.Command(bingo.GoFlagsCommand); goFlags != "" {
+		goFlagsEnv = envars.EnvSlice{"GOFLAGS=" + goFlags}
+	}
+	buildEnvs := append(append(envars.EnvSlice{}, pkg.BuildEnvs...), goFlagsEnv...)
+
+	// Two purposes of doing list with mod=mod:
+	// * Check if path is pointing to non-buildable package.
+	// * Rebuild go.sum and go.mod (tidy) which is required to build with -mod=readonly (default) to work.
+	var listArgs []string
+	listArgs = append(listArgs, modFile.DirectPackage().BuildFlags...)
+	listArgs = append(listArgs, "-mod=mod", "-f={{.Name}}", pkg.Path())
+	listEnvs := goFlagsEnv
+	if bingo.IsWasm(pkg.BuildEnvs) {
+		// WebAssembly tools are often constrained to their platform (e.g //go:build wasip1), so list them for it.
+		listEnvs = buildEnvs
+	}
+	if listOutput, err := r.With(ctx, modFile.FileName(), modDir, listEnvs).List(runner.NoUpdatePolicy, listArgs...); err != nil {
+		return false, errors.Wrap(err, "list")
+	} else if !strings.HasSuffix(listOutput, "main") {
+		return false, newSentinelError(bingo.ErrNonMainPackage, "package %s is non-main (go list output %q), nothing to get and build", pkg.Path(), listOutput)
+	}
+	if c.policy != nil {
+		// Checked before build, so disallowed code is never compiled nor pinned.
+		if err := checkModulePolicy(ctx, r, modDir, *c.policy, modFile, buildEnvs); err != nil {
+			return false, err
+		}
+	}
+
+	// Module cache is read-only, so tools that need go generate are built from generated copy of their module.
+	buildModFile := modFile.FileName()
+	_, generate := modFile.Command(bingo.GenerateCommand)
+	if generate {
+		var cleanup func() error
+		buildModFile, cleanup, err = prepareGenerate(ctx, r, modDir, name, modFile, buildEnvs)
+		if err != nil {
+			return false, errors.Wrap(err, "generate")
+		}
+		defer errcapture.Do(&err, cleanup, "remove generated module copy")
+	}
+
+	buildFlags := pkg.BuildFlags
+	if c.offline {
+		// List above downloaded all modules needed to build the package and completed go.sum, so build must not need
+		// network nor resolve anything again.
+		buildEnvs = append(buildEnvs, "GOPROXY=off")
+		buildFlags = append([]string{"-mod=readonly"}, buildFlags...)
+	}
+
+	binDir, _ := modFile.Command(bingo.BinDirCommand)
+	gobin := bingo.ToolBinDir(modDir, BinDir(modDir), binDir)
+	if binDir != "" {
+		if err := os.MkdirAll(gobin, os.ModePerm); err != nil {
+			return false, errors.Wrap(err, "create bin dir")
+		}
+	}
+
+	// go install does not define -modfile flag so so we mimic go install with go build -o instead.
+	binPath := filepath.Join(gobin, bingo.ArtifactName(name, pkg.Module.Version, pkg.BuildEnvs))
+	// Binaries built from local directories change with their sources (and generated ones with generators), so they are never stored.
+	// WebAssembly modules are not stored either, as store links executables only.
+	wasm := bingo.IsWasm(pkg.BuildEnvs)
+	rebuilt = true
+	if c.store == "" || generate || len(modFile.LocalReplaces()) > 0 || wasm {
+		buildStart := time.Now()
+		if err := r.With(ctx, buildModFile, modDir, buildEnvs).WithPrefix(name).Build(pkg.Path(), binPath, buildFlags...); err != nil {
+			return false, errors.Wrap(err, "build versioned")
+		}
+		if err := recordBuildStats(modDir, name, modFile, time.Since(buildStart)); err != nil {
+			return false, err
+		}
+	} else {
+		storePath, err := storeBinPath(c.store, name, modFile, r.GoVersion().String())
+		if err != nil {
+			return false, err
+		}
+		if _, err := os.Stat(storePath); err == nil {
+			rebuilt = false
+		} else if !os.IsNotExist(err) {
+			return false, errors.Wrap(err, "stat stored binary")
+		} else if err := buildToStore(ctx, c, name, modFile, buildEnvs, buildFlags, storePath); err != nil {
+			return false, err
+		}
+		// Link adds executable suffix itself.
+		if err := LinkBinary(c.linkMode, storePath, filepath.Join(gobin, name+"-"+pkg.Module.Version)); err != nil {
+			return false, errors.Wrap(err, "link versioned from store")
+		}
+	}
+
+	if err := linkNames(c, name, modFile, binPath, gobin); err != nil {
+		return false, err
+	}
+	if binDir != "" && c.gitignore {
+		if err := ignoreInstalled(modDir, gobin, name, modFile); err != nil {
+			return false, errors.Wrap(err, "update .gitignore in bin dir")
+		}
+	}
+
+	// Binary is built once. Additional GOBINs get a copy, so they are self-contained (e.g when copied into container image).
+	for _, dir := range c.gobins {
+		if filepath.Clean(dir) == filepath.Clean(gobin) {
+			continue
+		}
+		if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+			return false, errors.Wrapf(err, "create gobin %v", dir)
+		}
+		// Link adds executable suffix itself, so WebAssembly modules (with their own suffix) are copied directly.
+		copyVersioned := func() error {
+			return LinkBinary(CopyLinkMode, binPath, filepath.Join(dir, name+"-"+pkg.Module.Version))
+		}
+		if wasm {
+			copyVersioned = func() error { return copyFile(binPath, filepath.Join(dir, filepath.Base(binPath))) }
+		}
+		if err := copyVersioned(); err != nil {
+			return false, errors.Wrapf(err, "copy versioned to gobin %v", dir)
+		}
+		if err := linkNames(c, name, modFile, filepath.Join(dir, filepath.Base(binPath)), dir); err != nil {
+			return false, errors.Wrapf(err, "gobin %v", dir)
+		}
+		if c.gitignore {
+			if err := ignoreInstalled(modDir, dir, name, modFile); err != nil {
+				return false, errors.Wrapf(err, "update .gitignore in gobin %v", dir)
+			}
+		}
+	}
+	return rebuilt, nil
+}
+
+// buildToStore builds tool from given mod file into given path in the store. Binary is built to unique temporary file
+// in the store directory and renamed, so concurrent builds (e.g in other projects) never see nor overwrite partial binary.
+func buildToStore(ctx context.Context, c installPackageConfig, name string, modFile *bingo.ModFile, buildEnvs envars.EnvSlice, buildFlags []string, storePath string) (err error) {
+	if err := os.MkdirAll(filepath.Dir(storePath), os.ModePerm); err != nil {
+		return errors.Wrap(err, "create store dir")
+	}
+	tmp, err := ioutil.TempFile(filepath.Dir(storePath), filepath.Base(storePath)+".*.tmp")
+	if err != nil {
+		return errors.Wrap(err, "create tmp file in store")
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = os.Remove(tmp.Name())
+		}
+	}()
+
+	pkg := modFile.DirectPackage()
+	buildStart := time.Now()
+	if err := c.runner.With(ctx, modFile.FileName(), c.modDir, buildEnvs).WithPrefix(name).Build(pkg.Path(), tmp.Name(), buildFlags...); err != nil {
+		return errors.Wrap(err, "build versioned")
+	}
+	if err := recordBuildStats(c.modDir, name, modFile, time.Since(buildStart)); err != nil {
+		return err
+	}
+	return errors.Wrap(os.Rename(tmp.Name(), storePath), "rename stored binary")
+}
+
+// linkNames links tool name and its aliases in given directory to given binary, if linking was requested. Links of stale
+// aliases are removed regardless. WebAssembly modules are never linked, as they are not executable on their own.
+func linkNames(c installPackageConfig, name string, modFile *bingo.ModFile, binPath, dir string) error {
+	if bingo.IsWasm(modFile.DirectPackage().BuildEnvs) {
+		return nil
+	}
+	if err := removeStaleAliases(c, name, binPath, dir); err != nil {
+		return err
+	}
+	if !c.link {
+		return nil
+	}
+
+	if err := LinkBinary(c.linkMode, binPath, filepath.Join(dir, name)); err != nil {
+		return errors.Wrap(err, "link")
+	}
+	arg, _ := modFile.Command(bingo.AliasesCommand)
+	aliases, err := bingo.ParseAliases(arg)
+	if err != nil {
+		return errors.Wrap(err, "aliases")
+	}
+	for _, a := range aliases {
+		if err := LinkBinary(c.linkMode, binPath, filepath.Join(dir, a)); err != nil {
+			return errors.Wrapf(err, "link alias %v", a)
+		}
+	}
+	return nil
+}
+
+// staleAliases returns given previous aliases of the tool which are not in given new ones.
+func staleAliases(prev, aliases []string) []string {
+	var stale []string
+	for _, p := range prev {
+		found := false
+		for _, a := range aliases {
+			if a == p {
+				found = true
+				break
+			}
+		}
+		if !found {
+			stale = append(stale, p)
+		}
+	}
+	return stale
+}
+
+// removeStaleAliases removes links of stale aliases of the tool from given directory. Symbolic links pointing to binaries
+// not pinned by the tool (e.g by other mod directory in monorepo) are left untouched.
+func removeStaleAliases(c installPackageConfig, name, binPath, dir string) error {
+	if len(c.staleAliases) == 0 {
+		return nil
+	}
+	own, err := pinnedBinaries(c.modDir, name)
+	if err != nil {
+		return errors.Wrap(err, "pinned binaries")
+	}
+	own[filepath.Base(binPath)] = struct{}{}
+	for _, a := range c.staleAliases {
+		path := filepath.Join(dir, a)
+		if !linkExists(path) {
+			continue
+		}
+		if linked, ok := linkedBinary(path + bingo.GOEXE()); ok {
+			if _, ok := own[filepath.Base(linked)]; !ok {
+				continue
+			}
+		}
+		if err := removeLink(path); err != nil {
+			return errors.Wrapf(err, "remove stale alias %v", a)
+		}
+	}
+	return nil
+}
+
+// recordBuildStats records duration and number of dependencies of the tool build from given mod file, for bingo stats.
+// Dependencies are counted from go.sum file, which is still there after install's go list.
+func recordBuildStats(modDir, name string, modFile *bingo.ModFile, d time.Duration) error {
+	pkg := modFile.DirectPackage()
+	deps, err := bingo.CountSumModules(strings.TrimSuffix(modFile.FileName(), ".mod")+".sum", pkg.Module.Path)
+	if err != nil && !os.IsNotExist(err) {
+		return errors.Wrap(err, "count dependencies")
+	}
+	return errors.Wrap(
+		bingo.WriteBuildStats(modDir, name, pkg.Module.Version, bingo.BuildStats{Duration: d, Dependencies: deps, BuiltAt: time.Now()}),
+		"record build stats",
+	)
+}
+
+const (
+	gitignoreBegin = "# BEGIN bingo managed block. Content of this block is regenerated by bingo; put your own entries outside of it."
+	gitignoreEnd   = "# END bingo managed block."
+)
+
+const gitignore = `
+# Ignore everything
+*
+
+# But not these files:
+!.gitignore
+!*.mod
+!README.md
+!Variables.mk
+!variables.env
+!variables.go
+!bootstrap.sh
+!bootstrap.ps1
+!config
+!templates/
+!templates/*.tmpl
+
+*tmp.mod
+`
+
+func ensureModDirExists(logger logging.Logger, relModDir string, helpers bingo.HelpersConfig) error {
+	_, err := os.Stat(relModDir)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			return errors.Wrapf(err, "stat bingo module dir %s", relModDir)
+		}
+
+		logger.Info("Bingo not used before here, creating directory for pinned modules for you", "dir", relModDir)
+		if err := os.MkdirAll(relModDir, os.ModePerm); err != nil {
+			return errors.Wrapf(err, "create moddir %s", relModDir)
+		}
+	}
+
+	// Hack against:
+	// "A file named go.mod must still be present in order to determine the module root directory, but it is not accessed."
+	// Ref: https://golang.org/doc/go1.14#go-flags
+	// TODO(bwplotka): Remove it: https://github.com/bwplotka/bingo/issues/20
+	if err := bingo.WriteFileIfChanged(
+		filepath.Join(relModDir, bingo.FakeRootModFileName),
+		[]byte("module _ // Fake go.mod auto-created by 'bingo' for go -moddir compatibility with non-Go projects. Commit this file, together with other .mod files."),
+	); err != nil {
+		return err
+	}
+
+	// gitignore.
+	gitignoreFile := filepath.Join(relModDir, bingo.GitignoreFile)
+	if ok, err := helpers.ShouldGenerate(bingo.GitignoreFile, gitignoreFile); err != nil || !ok {
+		return err
+	}
+	existing, err := ioutil.ReadFile(gitignoreFile)
+	if err != nil && !os.IsNotExist(err) {
+		return errors.Wrap(err, "read .gitignore")
+	}
+	return bingo.WriteFileIfChanged(gitignoreFile, []byte(mergeGitignore(string(existing), gitignore)))
+}
+
+// mergeGitignore returns .gitignore content with managed entries put into marked block, preserving all user entries outside of it.
+// For .gitignore generated before managed block was introduced, lines that are not part of managed content are treated as user entries.
+func mergeGitignore(existing, managed string) string {
+	block := gitignoreBegin + "\n" + strings.Trim(managed, "\n") + "\n" + gitignoreEnd + "\n"
+
+	if b := strings.Index(existing, gitignoreBegin); b >= 0 {
+		if e := strings.Index(existing[b:], gitignoreEnd); e >= 0 {
+			rest := strings.TrimPrefix(existing[b+e+len(gitignoreEnd):], "\n")
+			return existing[:b] + block + rest
+		}
+	}
+
+	if strings.TrimSpace(existing) == "" {
+		return block
+	}
+	managedLines := map[string]struct{}{}
+	for _, l := range strings.Split(managed, "\n") {
+		managedLines[strings.TrimSpace(l)] = struct{}{}
+	}
+	var user []string
+	for _, l := range strings.Split(strings.TrimRight(existing, "\n"), "\n") {
+		if _, ok := managedLines[strings.TrimSpace(l)]; ok {
+			continue
+		}
+		user = append(user, l)
+	}
+	if len(user) == 0 {
+		return block
+	}
+	return block + "\n" + strings.Join(user, "\n") + "\n"
+}
+
+// ignoreInstalled adds entries ignoring binary and links of given tool to managed block of .gitignore in given directory,
+// if it's inside the project (the current directory), so installed binaries are never committed. Entries of other tools
+// installed there are kept. Mod directory has its own .gitignore ignoring everything but mod files.
+func ignoreInstalled(modDir, dir, name string, modFile *bingo.ModFile) error {
+	wd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	if rel, err := filepath.Rel(wd, dir); err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return nil
+	}
+	if rel, err := filepath.Rel(modDir, dir); err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return nil
+	}
+
+	arg, _ := modFile.Command(bingo.AliasesCommand)
+	aliases, err := bingo.ParseAliases(arg)
+	if err != nil {
+		return errors.Wrap(err, "aliases")
+	}
+	entries := []string{"/" + name + "-v*"}
+	for _, n := range append([]string{name}, aliases...) {
+		entries = append(entries, "/"+n+bingo.GOEXE())
+		if runtime.GOOS == "windows" {
+			entries = append(entries, "/"+n+".cmd")
+		}
+	}
+
+	gitignoreFile := filepath.Join(dir, bingo.GitignoreFile)
+	existing, err := ioutil.ReadFile(gitignoreFile)
+	if err != nil && !os.IsNotExist(err) {
+		return errors.Wrap(err, "read .gitignore")
+	}
+	entries = append(entries, managedGitignoreEntries(string(existing))...)
+	sort.Strings(entries)
+	managed := make([]string, 0, len(entries))
+	for i, e := range entries {
+		if i == 0 || e != entries[i-1] {
+			managed = append(managed, e)
+		}
+	}
+	return bingo.WriteFileIfChanged(gitignoreFile, []byte(mergeGitignore(string(existing), strings.Join(managed, "\n"))))
+}
+
+// managedGitignoreEntries returns entries from managed block of given .gitignore content, if any.
+func managedGitignoreEntries(existing string) []string {
+	b := strings.Index(existing, gitignoreBegin)
+	if b < 0 {
+		return nil
+	}
+	e := strings.Index(existing[b:], gitignoreEnd)
+	if e < 0 {
+		return nil
+	}
+	var entries []string
+	for _, l := range strings.Split(existing[b+len(gitignoreBegin):b+e], "\n") {
+		if l = strings.TrimSpace(l); l != "" {
+			entries = append(entries, l)
+		}
+	}
+	return entries
+}
+
+func removeAllGlob(glob string) error {
+	files, err := filepath.Glob(glob)
+	if err != nil {
+		return err
+	}
+	for _, f := range files {
+		if err := os.RemoveAll(f); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// newSentinelError returns error with given message that matches given sentinel error with errors.Is, so messages stay
+// descriptive while errors are inspectable.
+func newSentinelError(sentinel error, format string, args ...interface{}) error {
+	return &sentinelError{sentinel: sentinel, msg: fmt.Sprintf(format, args...)}
+}
+
+type sentinelError struct {
+	sentinel error
+	msg      string
+}
+
+func (e *sentinelError) Error() string { return e.msg }
+
+func (e *sentinelError) Is(target error) bool { return target == e.sentinel }
+
+// VersionChange is log attribute value for tool version change. JSON logs print it as object with from and to fields.
+type VersionChange struct {
+	From, To string
+}
+
+func (v VersionChange) String() string { return v.From + " → " + v.To }
+
+func (v VersionChange) LogValue() slog.Value {
+	return slog.GroupValue(slog.String("from", v.From), slog.String("to", v.To))
+}
+
+// logToolPhase logs on debug level that given phase finished (with error if not nil), with its duration. Logger is expected
+// to have tool attribute.
+func logToolPhase(logger logging.Logger, phase string, start time.Time, err error) {
+	attrs := []any{"phase", phase, "durationSeconds", time.Since(start).Seconds()}
+	if err != nil {
+		logger.Debug("phase failed", append(attrs, "err", err)...)
+		return
+	}
+	logger.Debug("phase finished", attrs...)
+}
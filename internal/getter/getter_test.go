@@ -0,0 +1,1319 @@
+// Copyright (c) Bartłomiej Płotka @bwplotka
+// Licensed under the Apache License 2.0.
+
+package getter
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"go/build"
+	"io"
+	"io/ioutil"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/bwplotka/bingo/pkg/bingo"
+	"github.com/bwplotka/bingo/pkg/envars"
+	"github.com/bwplotka/bingo/pkg/runner"
+	"github.com/efficientgo/tools/core/pkg/testutil"
+	"github.com/pkg/errors"
+	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/module"
+)
+
+func TestParseTarget(t *testing.T) {
+	for _, tcase := range []struct {
+		target string
+
+		expectedName     string
+		expectedPkgPath  string
+		expectedVersions []string
+		expectedErr      error
+	}{
+		{
+			target:      "",
+			expectedErr: errors.New("target is empty, this should be filtered earlier"),
+		},
+		{
+			target:           "tool",
+			expectedName:     "tool",
+			expectedVersions: []string{""},
+		},
+		{
+			target:       "github.com/bwplotka/bingo",
+			expectedName: "bingo", expectedPkgPath: "github.com/bwplotka/bingo",
+			expectedVersions: []string{""},
+		},
+		{
+			target:       "sigs.k8s.io/kustomize/kustomize",
+			expectedName: "kustomize", expectedPkgPath: "sigs.k8s.io/kustomize/kustomize",
+			expectedVersions: []string{""},
+		},
+		{
+			target:       "sigs.k8s.io/kustomize/kustomize/v3",
+			expectedName: "kustomize", expectedPkgPath: "sigs.k8s.io/kustomize/kustomize/v3",
+			expectedVersions: []string{""},
+		},
+		{
+			target:       "github.com/bwplotka/bingo/v21314213532",
+			expectedName: "bingo", expectedPkgPath: "github.com/bwplotka/bingo/v21314213532",
+			expectedVersions: []string{""},
+		},
+		{
+			target:       "tool@version1",
+			expectedName: "tool", expectedVersions: []string{"version1"},
+		},
+		{
+			target:       "tool@version1123,version3,version1241",
+			expectedName: "tool", expectedVersions: []string{"version1123", "version3", "version1241"},
+		},
+		{
+			target:       "tool@none",
+			expectedName: "tool", expectedVersions: []string{"none"},
+		},
+		{
+			target:      "tool@version1123,version13,version1123",
+			expectedErr: errors.New("version duplicates are not allowed, got: [version1123 version13 version1123]"),
+		},
+		{
+			target:      "tool@version1123,version13,none",
+			expectedErr: errors.New("none is not allowed when there are more than one specified Version, got: [version1123 version13 none]"),
+		},
+		{
+			target:       "github.com/bwplotka/bingo/v2@v0.2.5-rc.1214,bb92924b84d060515f8eb35f428a8fd816c1d938,version1241",
+			expectedName: "bingo", expectedPkgPath: "github.com/bwplotka/bingo/v2", expectedVersions: []string{"v0.2.5-rc.1214", "bb92924b84d060515f8eb35f428a8fd816c1d938", "version1241"},
+		},
+	} {
+		t.Run("", func(t *testing.T) {
+			n, p, v, err := parseTarget(tcase.target)
+			if tcase.expectedErr != nil {
+				testutil.NotOk(t, err)
+				testutil.Equals(t, tcase.expectedErr.Error(), err.Error())
+				return
+			}
+
+			testutil.Ok(t, err)
+			testutil.Equals(t, tcase.expectedName, n)
+			testutil.Equals(t, tcase.expectedPkgPath, p)
+			testutil.Equals(t, tcase.expectedVersions, v)
+		})
+	}
+
+}
+
+func TestMergeGitignore(t *testing.T) {
+	managed := "\n# Ignore everything\n*\n\n!*.mod\n"
+	block := gitignoreBegin + "\n# Ignore everything\n*\n\n!*.mod\n" + gitignoreEnd + "\n"
+
+	for _, tcase := range []struct {
+		name     string
+		existing string
+		expected string
+	}{
+		{name: "no file", existing: "", expected: block},
+		{name: "legacy file without user entries", existing: managed, expected: block},
+		{name: "legacy file with user entries", existing: managed + "!my-tool.sh\n", expected: block + "\n!my-tool.sh\n"},
+		{
+			name:     "managed block with user entries around",
+			existing: "# Mine.\n!a\n" + gitignoreBegin + "\nold\n" + gitignoreEnd + "\n!b\n",
+			expected: "# Mine.\n!a\n" + block + "!b\n",
+		},
+	} {
+		t.Run(tcase.name, func(t *testing.T) {
+			testutil.Equals(t, tcase.expected, mergeGitignore(tcase.existing, managed))
+		})
+	}
+}
+
+func TestIgnoreInstalled(t *testing.T) {
+	t.Setenv("GOEXE", "")
+	project := t.TempDir()
+	wd, err := os.Getwd()
+	testutil.Ok(t, err)
+	testutil.Ok(t, os.Chdir(project))
+	t.Cleanup(func() { _ = os.Chdir(wd) })
+
+	modDir := filepath.Join(project, ".bingo")
+	testutil.Ok(t, os.MkdirAll(modDir, os.ModePerm))
+	newModFile := func(name, content string) *bingo.ModFile {
+		testutil.Ok(t, ioutil.WriteFile(filepath.Join(modDir, name+".mod"), []byte(content), os.ModePerm))
+		mf, err := bingo.OpenModFile(filepath.Join(modDir, name+".mod"))
+		testutil.Ok(t, err)
+		t.Cleanup(func() { testutil.Ok(t, mf.Close()) })
+		return mf
+	}
+	protocGenGo := newModFile("protoc-gen-go", "module _\n\nrequire google.golang.org/protobuf v1.31.0 // cmd/protoc-gen-go\n")
+	buf := newModFile("buf", "module _\n\n// bingo:aliases b\n\nrequire github.com/bufbuild/buf v0.1.0 // cmd/buf\n")
+
+	binDir := filepath.Join(project, "bin")
+	testutil.Ok(t, os.MkdirAll(binDir, os.ModePerm))
+	testutil.Ok(t, ioutil.WriteFile(filepath.Join(binDir, ".gitignore"), []byte("/protoc\n"), os.ModePerm))
+	testutil.Ok(t, ignoreInstalled(modDir, binDir, "protoc-gen-go", protocGenGo))
+	testutil.Ok(t, ignoreInstalled(modDir, binDir, "buf", buf))
+	testutil.Ok(t, ignoreInstalled(modDir, binDir, "buf", buf))
+
+	b, err := ioutil.ReadFile(filepath.Join(binDir, ".gitignore"))
+	testutil.Ok(t, err)
+	testutil.Equals(t, gitignoreBegin+"\n/b\n/buf\n/buf-v*\n/protoc-gen-go\n/protoc-gen-go-v*\n"+gitignoreEnd+"\n\n/protoc\n", string(b))
+
+	otherDir := filepath.Join(project, "tools", "bin")
+	testutil.Ok(t, os.MkdirAll(otherDir, os.ModePerm))
+	testutil.Ok(t, ignoreInstalled(modDir, otherDir, "buf", buf))
+	b, err = ioutil.ReadFile(filepath.Join(otherDir, ".gitignore"))
+	testutil.Ok(t, err)
+	testutil.Equals(t, gitignoreBegin+"\n/b\n/buf\n/buf-v*\n"+gitignoreEnd+"\n", string(b))
+
+	// Directories outside of the project and in mod directory are not touched.
+	for _, dir := range []string{t.TempDir(), filepath.Join(modDir, bingo.LocalBinDir)} {
+		testutil.Ok(t, ignoreInstalled(modDir, dir, "buf", buf))
+		_, err := os.Stat(filepath.Join(dir, ".gitignore"))
+		testutil.Assert(t, os.IsNotExist(err), "expected no .gitignore in %v", dir)
+	}
+}
+
+func TestGetSummary(t *testing.T) {
+	var nilSummary *Summary
+	nilSummary.add(Result{Name: "a"})
+	testutil.Ok(t, nilSummary.addRemoved("a", []string{"a.mod"}))
+	testutil.Ok(t, nilSummary.PrintJSON(nil))
+
+	tmpDir, err := ioutil.TempDir(os.TempDir(), "bingo-summary")
+	testutil.Ok(t, err)
+	t.Cleanup(func() { testutil.Ok(t, os.RemoveAll(tmpDir)) })
+	testutil.Ok(t, ioutil.WriteFile(filepath.Join(tmpDir, "faillint.mod"), []byte("module _\n\nrequire github.com/fatih/faillint v1.5.0\n"), os.ModePerm))
+
+	s := &Summary{}
+	b := &bytes.Buffer{}
+	testutil.Ok(t, s.PrintJSON(b))
+	testutil.Equals(t, "{\n  \"results\": []\n}\n", b.String())
+
+	s.add(Result{Name: "goimports", ModFile: "goimports.mod", PackagePath: "golang.org/x/tools/cmd/goimports", Version: "v0.1.0", Rebuilt: true, BinaryPath: "/bin/goimports-v0.1.0", DurationSeconds: 1.5})
+	testutil.Ok(t, s.addRemoved("faillint", []string{filepath.Join(tmpDir, "faillint.mod")}))
+	testutil.Equals(t, []Result{
+		{Name: "goimports", ModFile: "goimports.mod", PackagePath: "golang.org/x/tools/cmd/goimports", Version: "v0.1.0", Rebuilt: true, BinaryPath: "/bin/goimports-v0.1.0", DurationSeconds: 1.5},
+		{Name: "faillint", ModFile: "faillint.mod", PackagePath: "github.com/fatih/faillint", PreviousVersion: "v1.5.0", Removed: true},
+	}, s.Results)
+}
+
+func TestDidYouMean(t *testing.T) {
+	testutil.Equals(t, 0, editDistance("faillint", "faillint"))
+	testutil.Equals(t, 1, editDistance("golanci-lint", "golangci-lint"))
+	testutil.Equals(t, 3, editDistance("kitten", "sitting"))
+	testutil.Equals(t, 4, editDistance("", "buf4"))
+
+	tmpDir, err := ioutil.TempDir(os.TempDir(), "bingo-did-you-mean")
+	testutil.Ok(t, err)
+	t.Cleanup(func() { testutil.Ok(t, os.RemoveAll(tmpDir)) })
+	testutil.Ok(t, ioutil.WriteFile(filepath.Join(tmpDir, "golangci-lint.mod"), []byte("module _\n\nrequire github.com/golangci/golangci-lint v1.35.0 // cmd/golangci-lint\n"), os.ModePerm))
+	testutil.Ok(t, ioutil.WriteFile(filepath.Join(tmpDir, "faillint.mod"), []byte("module _\n\nrequire github.com/fatih/faillint v1.5.0\n"), os.ModePerm))
+
+	logger := slog.New(slog.NewTextHandler(ioutil.Discard, nil))
+	testutil.Equals(t, "; did you mean golangci-lint?", didYouMean(logger, tmpDir, "golanci-lint"))
+	testutil.Equals(t, "; did you mean faillint?", didYouMean(logger, tmpDir, "falint"))
+	testutil.Equals(t, "", didYouMean(logger, tmpDir, "buf"))
+	testutil.Equals(t, "", didYouMean(logger, filepath.Join(tmpDir, "not-existing"), "buf"))
+}
+
+func TestFreeze(t *testing.T) {
+	modDir := t.TempDir()
+	testutil.Ok(t, ioutil.WriteFile(filepath.Join(modDir, "faillint.mod"), []byte("module _\n\nrequire github.com/fatih/faillint v1.5.0\n"), os.ModePerm))
+	testutil.Ok(t, ioutil.WriteFile(filepath.Join(modDir, "buf.mod"), []byte("module _\n\nrequire github.com/bufbuild/buf v0.1.0 // cmd/buf\n"), os.ModePerm))
+	testutil.Ok(t, ioutil.WriteFile(filepath.Join(modDir, "buf.1.mod"), []byte("module _\n\nrequire github.com/bufbuild/buf v0.2.0 // cmd/buf\n"), os.ModePerm))
+	logger := slog.New(slog.NewTextHandler(ioutil.Discard, nil))
+
+	_, _, ok, err := frozenVersion(filepath.Join(modDir, "faillint.mod"))
+	testutil.Ok(t, err)
+	testutil.Equals(t, false, ok)
+
+	testutil.Ok(t, Freeze(logger, modDir, "faillint", "", true))
+	version, reason, ok, err := frozenVersion(filepath.Join(modDir, "faillint.mod"))
+	testutil.Ok(t, err)
+	testutil.Equals(t, true, ok)
+	testutil.Equals(t, "v1.5.0", version)
+	testutil.Equals(t, DefaultFreezeReason, reason)
+
+	testutil.Ok(t, Freeze(logger, modDir, "buf", "generated code is committed", true))
+	pkgs, err := bingo.ListPinnedMainPackages(logger, modDir, false)
+	testutil.Ok(t, err)
+	for _, p := range pkgs {
+		testutil.Equals(t, true, p.Frozen, p.Name)
+	}
+	_, reason, ok, err = frozenVersion(filepath.Join(modDir, "buf.1.mod"))
+	testutil.Ok(t, err)
+	testutil.Equals(t, true, ok)
+	testutil.Equals(t, "generated code is committed", reason)
+
+	testutil.Ok(t, Freeze(logger, modDir, "buf", "", false))
+	_, _, ok, err = frozenVersion(filepath.Join(modDir, "buf.1.mod"))
+	testutil.Ok(t, err)
+	testutil.Equals(t, false, ok)
+
+	_, _, ok, err = frozenVersion(filepath.Join(modDir, "not-existing.mod"))
+	testutil.Ok(t, err)
+	testutil.Equals(t, false, ok)
+	testutil.NotOk(t, Freeze(logger, modDir, "not-existing", "", true))
+}
+
+func TestFreeze_UpdateAll(t *testing.T) {
+	modDir := t.TempDir()
+	t.Setenv("GOBIN", t.TempDir())
+	modFilePath := filepath.Join(modDir, "faillint.mod")
+	testutil.Ok(t, ioutil.WriteFile(modFilePath, []byte("module _\n\nrequire github.com/fatih/faillint v1.5.0\n"), os.ModePerm))
+	logger := slog.New(slog.NewTextHandler(ioutil.Discard, nil))
+	testutil.Ok(t, Freeze(logger, modDir, "faillint", "", true))
+
+	fakeGo := runner.ExecutorFunc(func(_ context.Context, output io.Writer, _ []string, _, _ string, args ...string) error {
+		switch args[0] {
+		case "version":
+			_, err := io.WriteString(output, "go version go1.21.0 linux/amd64\n")
+			return err
+		case "list":
+			_, err := io.WriteString(output, "main\n")
+			return err
+		case "build":
+			return nil
+		}
+		return errors.Errorf("frozen tool should not be resolved, got go %v", strings.Join(args, " "))
+	})
+	ctx := context.Background()
+	r, err := runner.NewRunnerWithExecutor(ctx, nil, false, "go", fakeGo)
+	testutil.Ok(t, err)
+
+	summary := &Summary{}
+	c := Config{Runner: r, ModDir: modDir, RelModDir: modDir, Update: runner.UpdatePolicy, Summary: summary}
+	testutil.Ok(t, Get(ctx, logger, c, ""))
+
+	mf, err := bingo.ReadModFile(modFilePath)
+	testutil.Ok(t, err)
+	testutil.Equals(t, "v1.5.0", mf.DirectPackage().Module.Version)
+	_, ok := mf.Command(bingo.FrozenCommand)
+	testutil.Assert(t, ok, "tool should stay frozen")
+	testutil.Equals(t, 1, len(summary.Results))
+	testutil.Equals(t, true, summary.Results[0].Frozen)
+	testutil.Equals(t, "v1.5.0", summary.Results[0].Version)
+}
+
+func TestMerge(t *testing.T) {
+	modDir := t.TempDir()
+	testutil.Ok(t, ioutil.WriteFile(filepath.Join(modDir, "golangci-lint.mod"), []byte("module _\n\nrequire github.com/golangci/golangci-lint v1.55.2 // cmd/golangci-lint\n"), os.ModePerm))
+	testutil.Ok(t, ioutil.WriteFile(filepath.Join(modDir, "lint.mod"), []byte("module _ // bingo:aliases l\n\nrequire github.com/golangci/golangci-lint v1.50.0 // cmd/golangci-lint\n"), os.ModePerm))
+	testutil.Ok(t, ioutil.WriteFile(filepath.Join(modDir, "faillint.mod"), []byte("module _\n\nrequire github.com/fatih/faillint v1.5.0\n"), os.ModePerm))
+	logger := slog.New(slog.NewTextHandler(ioutil.Discard, nil))
+
+	pkgs, err := bingo.ListPinnedMainPackages(logger, modDir, false)
+	testutil.Ok(t, err)
+	testutil.Equals(t, []bingo.DuplicatePin{{
+		PackagePath: "github.com/golangci/golangci-lint/cmd/golangci-lint",
+		Names:       []string{"golangci-lint", "lint"},
+	}}, pkgs.Duplicates())
+
+	testutil.NotOk(t, Merge(logger, modDir, "golangci-lint", []string{"faillint"}))
+	testutil.NotOk(t, Merge(logger, modDir, "golangci-lint", []string{"golangci-lint"}))
+	testutil.NotOk(t, Merge(logger, modDir, "golangci-lint", []string{"not-existing"}))
+
+	testutil.Ok(t, Merge(logger, modDir, "golangci-lint", []string{"lint"}))
+	_, err = os.Stat(filepath.Join(modDir, "lint.mod"))
+	testutil.Assert(t, os.IsNotExist(err), "lint.mod should be removed")
+	pkgs, err = bingo.ListPinnedMainPackages(logger, modDir, false)
+	testutil.Ok(t, err)
+	testutil.Equals(t, 0, len(pkgs.Duplicates()))
+	testutil.Equals(t, 2, len(pkgs))
+	testutil.Equals(t, "golangci-lint", pkgs[1].Name)
+	testutil.Equals(t, "v1.55.2", pkgs[1].Versions[0].Version)
+	testutil.Equals(t, []string{"l", "lint"}, pkgs[1].AliasNames())
+}
+
+func TestRename(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("links get extensions on Windows")
+	}
+	t.Setenv("GOEXE", "")
+	modDir := t.TempDir()
+	gobin := t.TempDir()
+	testutil.Ok(t, ioutil.WriteFile(filepath.Join(modDir, "faillint.mod"), []byte("module _ // bingo:aliases fl\n\nrequire github.com/fatih/faillint v1.5.0\n"), os.ModePerm))
+	testutil.Ok(t, ioutil.WriteFile(filepath.Join(modDir, "misspell.mod"), []byte("module _\n\nrequire github.com/client9/misspell v0.3.4 // cmd/misspell\n"), os.ModePerm))
+	testutil.Ok(t, bingo.WriteBuildStats(modDir, "faillint", "v1.5.0", bingo.BuildStats{Dependencies: 3}))
+	testutil.Ok(t, ioutil.WriteFile(filepath.Join(gobin, "faillint-v1.5.0"), []byte("binary"), 0755))
+	testutil.Ok(t, LinkBinary(SymlinkLinkMode, filepath.Join(gobin, "faillint-v1.5.0"), filepath.Join(gobin, "faillint")))
+	testutil.Ok(t, LinkBinary(SymlinkLinkMode, filepath.Join(gobin, "faillint-v1.5.0"), filepath.Join(gobin, "fl")))
+	logger := slog.New(slog.NewTextHandler(ioutil.Discard, nil))
+
+	testutil.NotOk(t, Rename(logger, modDir, gobin, SymlinkLinkMode, true, "faillint", "faillint"))
+	testutil.NotOk(t, Rename(logger, modDir, gobin, SymlinkLinkMode, true, "faillint", "misspell"))
+	err := Rename(logger, modDir, gobin, SymlinkLinkMode, true, "not-existing", "x")
+	testutil.Assert(t, errors.Is(err, bingo.ErrNotInstalled), "unexpected error %v", err)
+
+	testutil.Ok(t, Rename(logger, modDir, gobin, SymlinkLinkMode, true, "faillint", "lint"))
+	for _, removed := range []string{filepath.Join(modDir, "faillint.mod"), filepath.Join(gobin, "faillint-v1.5.0"), filepath.Join(gobin, "faillint")} {
+		_, err := os.Lstat(removed)
+		testutil.Assert(t, os.IsNotExist(err), "%v should be removed", removed)
+	}
+	pkgs, err := bingo.ListPinnedMainPackages(logger, modDir, false)
+	testutil.Ok(t, err)
+	testutil.Equals(t, 2, len(pkgs))
+	testutil.Equals(t, "lint", pkgs[0].Name)
+	testutil.Equals(t, []string{"fl"}, pkgs[0].AliasNames())
+
+	for _, l := range []string{"lint", "fl"} {
+		linked, ok := linkedBinary(filepath.Join(gobin, l))
+		testutil.Equals(t, true, ok)
+		testutil.Equals(t, filepath.Join(gobin, "lint-v1.5.0"), linked)
+	}
+	s, err := bingo.ReadBuildStats(modDir, "lint", "v1.5.0")
+	testutil.Ok(t, err)
+	testutil.Equals(t, 3, s.Dependencies)
+}
+
+func TestMigrateModDir(t *testing.T) {
+	project := t.TempDir()
+	from, to := filepath.Join(project, ".bingo"), filepath.Join(project, "tools", "pins")
+	testutil.Ok(t, os.MkdirAll(filepath.Join(from, bingo.LocalBinDir), os.ModePerm))
+	testutil.Ok(t, ioutil.WriteFile(filepath.Join(from, "faillint.mod"), []byte("module _\n\nrequire github.com/fatih/faillint v1.5.0\n"), os.ModePerm))
+	testutil.Ok(t, ioutil.WriteFile(filepath.Join(from, "tool.mod"), []byte("module _\n\n// bingo:bindir ../bin\n\nrequire example.com/tool v1.0.0\n\nreplace example.com/tool => ../tool\n"), os.ModePerm))
+	testutil.Ok(t, ioutil.WriteFile(filepath.Join(from, "config"), []byte("timeout = 10m\n"), os.ModePerm))
+	testutil.Ok(t, ioutil.WriteFile(filepath.Join(from, bingo.LocalBinDir, "faillint-v1.5.0"), []byte("binary"), 0755))
+	testutil.Ok(t, os.Symlink(filepath.Join(from, bingo.LocalBinDir, "faillint-v1.5.0"), filepath.Join(from, bingo.LocalBinDir, "faillint")))
+
+	testutil.NotOk(t, MigrateModDir(from, filepath.Join(from, "pins")))
+	testutil.NotOk(t, MigrateModDir(filepath.Join(project, "not-existing"), to))
+	testutil.Ok(t, MigrateModDir(from, to, "config"))
+
+	_, err := os.Stat(filepath.Join(from, "faillint.mod"))
+	testutil.Assert(t, os.IsNotExist(err), "faillint.mod should be moved")
+	_, err = os.Stat(filepath.Join(from, "config"))
+	testutil.Ok(t, err)
+
+	mf, err := bingo.ReadModFile(filepath.Join(to, "tool.mod"))
+	testutil.Ok(t, err)
+	binDir, _ := mf.Command(bingo.BinDirCommand)
+	testutil.Equals(t, "../../bin", binDir)
+	testutil.Equals(t, "../../tool", mf.LocalReplaces()[0].New.Path)
+
+	linked, ok := linkedBinary(filepath.Join(to, bingo.LocalBinDir, "faillint"))
+	testutil.Equals(t, true, ok)
+	testutil.Equals(t, filepath.Join(to, bingo.LocalBinDir, "faillint-v1.5.0"), linked)
+
+	testutil.NotOk(t, MigrateModDir(to, from))
+}
+
+func TestSplitAndMergeArray(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("links get extensions on Windows")
+	}
+	t.Setenv("GOEXE", "")
+	modDir := t.TempDir()
+	gobin := t.TempDir()
+	for i, v := range []string{"v1.4.0", "v1.5.0", "v1.3.0"} {
+		f := "faillint.mod"
+		if i > 0 {
+			f = fmt.Sprintf("faillint.%d.mod", i)
+		}
+		testutil.Ok(t, ioutil.WriteFile(filepath.Join(modDir, f), []byte("module _\n\n// bingo:aliases fl\n\nrequire github.com/fatih/faillint "+v+"\n"), os.ModePerm))
+		testutil.Ok(t, ioutil.WriteFile(filepath.Join(gobin, "faillint-"+v), []byte(v), 0755))
+	}
+	testutil.Ok(t, ioutil.WriteFile(filepath.Join(modDir, "misspell.mod"), []byte("module _\n\nrequire github.com/client9/misspell v0.3.4 // cmd/misspell\n"), os.ModePerm))
+	testutil.Ok(t, LinkBinary(SymlinkLinkMode, filepath.Join(gobin, "faillint-v1.3.0"), filepath.Join(gobin, "faillint")))
+	logger := slog.New(slog.NewTextHandler(ioutil.Discard, nil))
+
+	versions := func(name string) []string {
+		pkgs, err := bingo.ListPinnedMainPackages(logger, modDir, false)
+		testutil.Ok(t, err)
+		var vs []string
+		for _, p := range pkgs {
+			if p.Name != name {
+				continue
+			}
+			for _, v := range p.Versions {
+				vs = append(vs, v.Version)
+			}
+		}
+		return vs
+	}
+	linked := func(name string) string {
+		l, ok := linkedBinary(filepath.Join(gobin, name))
+		testutil.Equals(t, true, ok)
+		return filepath.Base(l)
+	}
+
+	testutil.NotOk(t, Split(logger, modDir, gobin, SymlinkLinkMode, "misspell", "misspell-legacy", nil))
+	testutil.NotOk(t, Split(logger, modDir, gobin, SymlinkLinkMode, "faillint", "misspell", nil))
+	testutil.NotOk(t, Split(logger, modDir, gobin, SymlinkLinkMode, "faillint", "faillint-legacy", []string{"v0.1.0"}))
+	testutil.NotOk(t, Split(logger, modDir, gobin, SymlinkLinkMode, "faillint", "faillint-legacy", []string{"v1.3.0", "v1.4.0", "v1.5.0"}))
+
+	testutil.Ok(t, Split(logger, modDir, gobin, SymlinkLinkMode, "faillint", "faillint-legacy", nil))
+	testutil.Equals(t, []string{"v1.5.0"}, versions("faillint"))
+	testutil.Equals(t, []string{"v1.4.0", "v1.3.0"}, versions("faillint-legacy"))
+	_, err := os.Stat(filepath.Join(modDir, "faillint.1.mod"))
+	testutil.Assert(t, os.IsNotExist(err), "faillint.1.mod should be renumbered")
+	b, err := ioutil.ReadFile(filepath.Join(gobin, "faillint-legacy-v1.3.0"))
+	testutil.Ok(t, err)
+	testutil.Equals(t, "v1.3.0", string(b))
+	testutil.Equals(t, "faillint-v1.5.0", linked("faillint"))
+	testutil.Equals(t, "faillint-legacy-v1.3.0", linked("faillint-legacy"))
+	mf, err := bingo.ReadModFile(filepath.Join(modDir, "faillint-legacy.mod"))
+	testutil.Ok(t, err)
+	_, ok := mf.Command(bingo.AliasesCommand)
+	testutil.Equals(t, false, ok)
+	// Split pins are not reported as duplicates to merge.
+	pkgs, err := bingo.ListPinnedMainPackages(logger, modDir, false)
+	testutil.Ok(t, err)
+	testutil.Equals(t, 0, len(pkgs.Duplicates()))
+
+	testutil.NotOk(t, MergeArray(logger, modDir, gobin, "faillint", []string{"misspell"}))
+	testutil.Ok(t, MergeArray(logger, modDir, gobin, "faillint", []string{"faillint-legacy"}))
+	testutil.Equals(t, []string{"v1.5.0", "v1.4.0", "v1.3.0"}, versions("faillint"))
+	for _, f := range []string{"faillint.mod", "faillint.1.mod", "faillint.2.mod"} {
+		mf, err := bingo.ReadModFile(filepath.Join(modDir, f))
+		testutil.Ok(t, err)
+		_, ok := mf.Command(bingo.SplitFromCommand)
+		testutil.Assert(t, !ok, "merged pins should not be marked as split in %v", f)
+	}
+	testutil.Equals(t, 0, len(versions("faillint-legacy")))
+	_, err = os.Stat(filepath.Join(gobin, "faillint-v1.3.0"))
+	testutil.Ok(t, err)
+	_, err = os.Lstat(filepath.Join(gobin, "faillint-legacy"))
+	testutil.Assert(t, os.IsNotExist(err), "link of merged tool should be removed")
+}
+
+func TestDedupe(t *testing.T) {
+	modDir := t.TempDir()
+	testutil.Ok(t, ioutil.WriteFile(filepath.Join(modDir, "goimports.mod"), []byte("module _\n\nrequire golang.org/x/tools v0.1.5 // cmd/goimports\n"), os.ModePerm))
+	testutil.Ok(t, ioutil.WriteFile(filepath.Join(modDir, "faillint.mod"), []byte("module _\n\nrequire github.com/fatih/faillint v1.5.0\n"), os.ModePerm))
+	testutil.Ok(t, ioutil.WriteFile(filepath.Join(modDir, "buf.mod"), []byte("module _ // bingo:frozen\n\nrequire github.com/bufbuild/buf v0.1.0 // cmd/buf\n"), os.ModePerm))
+	testutil.Ok(t, ioutil.WriteFile(filepath.Join(modDir, "lint.mod"), []byte("module _\n\nrequire github.com/golangci/golangci-lint v1.55.2 // cmd/golangci-lint\n\nreplace golang.org/x/sys => golang.org/x/sys v0.0.1\n"), os.ModePerm))
+
+	// Fake go listing modules of each tool.
+	buildLists := map[string]string{
+		"goimports": "_\ngolang.org/x/tools v0.1.5\ngolang.org/x/sys v0.1.0\ngolang.org/x/mod v0.4.0\n",
+		"faillint":  "_\ngithub.com/fatih/faillint v1.5.0\ngolang.org/x/tools v0.1.0\ngolang.org/x/sys v0.2.0\n",
+		"buf":       "_\ngithub.com/bufbuild/buf v0.1.0\ngolang.org/x/sys v0.3.0\n",
+		"lint":      "_\ngithub.com/golangci/golangci-lint v1.55.2\ngolang.org/x/sys v0.1.0 golang.org/x/sys v0.0.1\ngolang.org/x/mod v0.3.0\n",
+	}
+	fakeGo := runner.ExecutorFunc(func(_ context.Context, output io.Writer, _ []string, _, _ string, args ...string) error {
+		if args[0] == "version" {
+			_, err := io.WriteString(output, "go version go1.21.0 linux/amd64\n")
+			return err
+		}
+		modFile := strings.TrimPrefix(args[1], "-modfile=")
+		b, err := ioutil.ReadFile(modFile)
+		testutil.Ok(t, err)
+		testutil.Assert(t, !strings.Contains(string(b), bingo.DedupeCommand), "dedupe replaces should be dropped before listing")
+		_, err = io.WriteString(output, buildLists[strings.TrimSuffix(filepath.Base(modFile), ".dedupe.tmp.mod")])
+		return err
+	})
+	ctx := context.Background()
+	r, err := runner.NewRunnerWithExecutor(ctx, nil, false, "go", fakeGo)
+	testutil.Ok(t, err)
+	logger := slog.New(slog.NewTextHandler(ioutil.Discard, nil))
+
+	expected := []DedupeChange{
+		{ModFile: "faillint.mod", Module: "golang.org/x/tools", From: "v0.1.0", To: "v0.1.5"},
+		{ModFile: "goimports.mod", Module: "golang.org/x/sys", From: "v0.1.0", To: "v0.2.0"},
+		{ModFile: "lint.mod", Module: "golang.org/x/mod", From: "v0.3.0", To: "v0.4.0"},
+	}
+	changes, err := Dedupe(ctx, logger, r, modDir, nil, true)
+	testutil.Ok(t, err)
+	testutil.Equals(t, expected, changes)
+	b, err := ioutil.ReadFile(filepath.Join(modDir, "goimports.mod"))
+	testutil.Ok(t, err)
+	testutil.Equals(t, "module _\n\nrequire golang.org/x/tools v0.1.5 // cmd/goimports\n", string(b))
+
+	changes, err = Dedupe(ctx, logger, r, modDir, []string{"golang.org/x/sys"}, false)
+	testutil.Ok(t, err)
+	testutil.Equals(t, expected[1:2], changes)
+	changes, err = Dedupe(ctx, logger, r, modDir, nil, false)
+	testutil.Ok(t, err)
+	testutil.Equals(t, expected, changes)
+	b, err = ioutil.ReadFile(filepath.Join(modDir, "goimports.mod"))
+	testutil.Ok(t, err)
+	testutil.Equals(t, "module _ // Auto generated by https://github.com/bwplotka/bingo. DO NOT EDIT\n\n"+
+		"replace golang.org/x/sys v0.1.0 => golang.org/x/sys v0.2.0 // bingo:dedupe\n\n"+
+		"require golang.org/x/tools v0.1.5 // cmd/goimports\n", string(b))
+
+	// Replaces added by dedupe are kept by get, unless tool replaces the same module itself.
+	mf, err := bingo.OpenModFile(filepath.Join(modDir, "goimports.mod"))
+	testutil.Ok(t, err)
+	testutil.Ok(t, mf.SetReplace(&modfile.Replace{Old: module.Version{Path: "golang.org/x/mod"}, New: module.Version{Path: "golang.org/x/mod", Version: "v0.4.1"}}))
+	testutil.Equals(t, 1, len(mf.DedupeReplaces()))
+	testutil.Ok(t, mf.SetReplace(&modfile.Replace{Old: module.Version{Path: "golang.org/x/sys"}, New: module.Version{Path: "golang.org/x/sys", Version: "v0.1.1"}}))
+	testutil.Equals(t, 0, len(mf.DedupeReplaces()))
+	testutil.Ok(t, mf.Close())
+
+	testutil.Ok(t, RemoveDedupe(modDir))
+	b, err = ioutil.ReadFile(filepath.Join(modDir, "faillint.mod"))
+	testutil.Ok(t, err)
+	testutil.Assert(t, !strings.Contains(string(b), bingo.DedupeCommand), "dedupe replaces should be removed, got %s", b)
+	tmpFiles, err := filepath.Glob(filepath.Join(modDir, "*tmp*"))
+	testutil.Ok(t, err)
+	testutil.Equals(t, 0, len(tmpFiles))
+}
+
+func TestLocalReplaces(t *testing.T) {
+	root := t.TempDir()
+	modDir := filepath.Join(root, ".bingo")
+	toolDir := filepath.Join(root, "..", filepath.Base(root)+"-tool")
+	testutil.Ok(t, os.MkdirAll(modDir, os.ModePerm))
+	testutil.Ok(t, os.MkdirAll(filepath.Join(root, "api"), os.ModePerm))
+	testutil.Ok(t, os.MkdirAll(toolDir, os.ModePerm))
+	t.Cleanup(func() { _ = os.RemoveAll(toolDir) })
+	testutil.Ok(t, ioutil.WriteFile(filepath.Join(toolDir, "go.mod"), []byte("module github.com/org/tool\n\nreplace github.com/org/api => ../"+filepath.Base(root)+"/api\n\nreplace github.com/old/dep => github.com/old/dep v0.1.0\n\nexclude github.com/old/dep v0.0.1\n"), os.ModePerm))
+	testutil.Ok(t, ioutil.WriteFile(filepath.Join(modDir, "tool.mod"), []byte("module _\n\nreplace github.com/org/lib => ../lib\n\nrequire github.com/org/tool v1.0.0 // cmd/tool\n"), os.ModePerm))
+
+	replaces, pinned, err := localReplaces(filepath.Join(modDir, "tool.mod"), modDir, []*modfile.Replace{{Old: module.Version{Path: "github.com/org/tool"}, New: module.Version{Path: toolDir}}})
+	testutil.Ok(t, err)
+	testutil.Equals(t, "v1.0.0", pinned)
+	testutil.Equals(t, 2, len(replaces))
+	testutil.Equals(t, "../lib", replaces[0].New.Path)
+	testutil.Equals(t, "../../"+filepath.Base(toolDir), replaces[1].New.Path)
+
+	_, _, err = localReplaces(filepath.Join(modDir, "tool.mod"), modDir, []*modfile.Replace{{Old: module.Version{Path: "github.com/org/tool"}, New: module.Version{Path: filepath.Join(root, "not-existing")}}})
+	testutil.NotOk(t, err)
+
+	testutil.Equals(t, replaces[1], locallyReplaced(bingo.Package{RelPath: "github.com/org/tool/cmd/tool"}, replaces))
+	testutil.Equals(t, replaces[1], locallyReplaced(bingo.Package{Module: module.Version{Path: "github.com/org/tool"}, RelPath: "cmd/tool"}, replaces))
+	testutil.Assert(t, locallyReplaced(bingo.Package{RelPath: "github.com/org/toolbox"}, replaces) == nil)
+	testutil.Assert(t, locallyReplaced(bingo.Package{Module: module.Version{Path: "github.com/org"}, RelPath: "tool"}, replaces) == nil)
+
+	fetched, excludes, err := localReplaceStatements(modDir, replaces[1].New.Path)
+	testutil.Ok(t, err)
+	testutil.Equals(t, []*modfile.Replace{
+		{Old: module.Version{Path: "github.com/org/api"}, New: module.Version{Path: "../api"}},
+		{Old: module.Version{Path: "github.com/old/dep"}, New: module.Version{Path: "github.com/old/dep", Version: "v0.1.0"}},
+	}, stripSyntax(fetched))
+	testutil.Equals(t, 1, len(excludes))
+}
+
+func stripSyntax(replaces []*modfile.Replace) []*modfile.Replace {
+	r := make([]*modfile.Replace, 0, len(replaces))
+	for _, rep := range replaces {
+		r = append(r, &modfile.Replace{Old: rep.Old, New: rep.New})
+	}
+	return r
+}
+
+func TestGet_NotInstalledErrors(t *testing.T) {
+	modDir := t.TempDir()
+	testutil.Ok(t, ioutil.WriteFile(filepath.Join(modDir, "faillint.mod"), []byte("module _\n\nrequire github.com/fatih/faillint v1.5.0\n"), os.ModePerm))
+
+	logger := slog.New(slog.NewTextHandler(ioutil.Discard, nil))
+	c := Config{ModDir: modDir, RelModDir: modDir}
+	for _, target := range []string{"falint", "falint@none"} {
+		err := Get(context.Background(), logger, c, target)
+		testutil.NotOk(t, err)
+		testutil.Assert(t, errors.Is(err, bingo.ErrNotInstalled), "unexpected error %v", err)
+		testutil.Assert(t, strings.Contains(err.Error(), "did you mean faillint?"), err.Error())
+	}
+
+	c.Rename = "other"
+	err := Get(context.Background(), logger, c, "falint")
+	testutil.Assert(t, errors.Is(err, bingo.ErrNotInstalled), "unexpected error %v", err)
+	testutil.Assert(t, !errors.Is(err, bingo.ErrNonMainPackage), "unexpected error %v", err)
+}
+
+func TestGet_DescriptionRequiresSingleTool(t *testing.T) {
+	modDir := t.TempDir()
+	mod := []byte("module _\n\n// bingo:labels team=platform\n\nrequire github.com/fatih/faillint v1.5.0\n")
+	testutil.Ok(t, ioutil.WriteFile(filepath.Join(modDir, "faillint.mod"), mod, os.ModePerm))
+
+	logger := slog.New(slog.NewTextHandler(ioutil.Discard, nil))
+	c := Config{ModDir: modDir, RelModDir: modDir, Description: "Lints imports."}
+	err := Get(context.Background(), logger, c, "")
+	testutil.NotOk(t, err)
+	testutil.Assert(t, strings.Contains(err.Error(), "description cannot by specified"), err.Error())
+
+	c.Selector = bingo.Labels{"team": "platform"}
+	err = Get(context.Background(), logger, c, "@none")
+	testutil.NotOk(t, err)
+	testutil.Assert(t, strings.Contains(err.Error(), "description cannot by specified"), err.Error())
+
+	// Tools stay untouched.
+	b, err := ioutil.ReadFile(filepath.Join(modDir, "faillint.mod"))
+	testutil.Ok(t, err)
+	testutil.Equals(t, string(mod), string(b))
+}
+
+type recordEvents struct {
+	NoopEvents
+
+	events []string
+}
+
+func (r *recordEvents) OnStart(total int) {
+	r.events = append(r.events, fmt.Sprintf("start %d", total))
+}
+func (r *recordEvents) OnFinish(err error) {
+	r.events = append(r.events, fmt.Sprintf("finish %v", errors.Is(err, bingo.ErrNotInstalled)))
+}
+
+func TestGet_Events(t *testing.T) {
+	modDir := t.TempDir()
+	testutil.Ok(t, ioutil.WriteFile(filepath.Join(modDir, "faillint.mod"), []byte("module _\n\nrequire github.com/fatih/faillint v1.5.0\n"), os.ModePerm))
+
+	logger := slog.New(slog.NewTextHandler(ioutil.Discard, nil))
+	e := &recordEvents{}
+	c := Config{ModDir: modDir, RelModDir: modDir, Events: e}
+	testutil.NotOk(t, Get(context.Background(), logger, c, "falint"))
+	testutil.Ok(t, Get(context.Background(), logger, c, "faillint@none"))
+	testutil.Equals(t, []string{"finish true", "finish false"}, e.events)
+}
+
+func TestInstall_OfflineBuild(t *testing.T) {
+	modDir := t.TempDir()
+	t.Setenv("GOBIN", t.TempDir())
+	modFilePath := filepath.Join(modDir, "faillint.mod")
+	testutil.Ok(t, ioutil.WriteFile(modFilePath, []byte("module _\n\nrequire github.com/fatih/faillint v1.5.0 // -ldflags=-s\n"), os.ModePerm))
+
+	var listEnv, buildEnv, buildArgs []string
+	fakeGo := runner.ExecutorFunc(func(_ context.Context, output io.Writer, env []string, _, _ string, args ...string) error {
+		switch args[0] {
+		case "version":
+			_, err := io.WriteString(output, "go version go1.21.0 linux/amd64\n")
+			return err
+		case "list":
+			listEnv = env
+			_, err := io.WriteString(output, "main\n")
+			return err
+		case "build":
+			buildEnv, buildArgs = env, args
+		}
+		return nil
+	})
+	ctx := context.Background()
+	r, err := runner.NewRunnerWithExecutor(ctx, nil, false, "go", fakeGo)
+	testutil.Ok(t, err)
+
+	mf, err := bingo.OpenModFile(modFilePath)
+	testutil.Ok(t, err)
+	defer func() { testutil.Ok(t, mf.Close()) }()
+
+	_, err = install(ctx, installPackageConfig{runner: r, modDir: modDir, offline: true}, "faillint", mf)
+	testutil.Ok(t, err)
+	proxy, _ := envars.EnvSlice(listEnv).Lookup("GOPROXY")
+	testutil.Assert(t, proxy != "off", "list resolves modules, so it should have network access")
+	proxy, _ = envars.EnvSlice(buildEnv).Lookup("GOPROXY")
+	testutil.Equals(t, "off", proxy)
+	testutil.Equals(t, []string{"-mod=readonly", "-ldflags=-s"}, buildArgs[3:5])
+}
+
+func TestInstall_GOBINs(t *testing.T) {
+	modDir := t.TempDir()
+	gobin := t.TempDir()
+	t.Setenv("GOBIN", gobin)
+	modFilePath := filepath.Join(modDir, "faillint.mod")
+	testutil.Ok(t, ioutil.WriteFile(modFilePath, []byte("module _\n\n// bingo:aliases fl\n\nrequire github.com/fatih/faillint v1.5.0\n"), os.ModePerm))
+
+	builds := 0
+	fakeGo := runner.ExecutorFunc(func(_ context.Context, output io.Writer, _ []string, _, _ string, args ...string) error {
+		switch args[0] {
+		case "version":
+			_, err := io.WriteString(output, "go version go1.21.0 linux/amd64\n")
+			return err
+		case "list":
+			_, err := io.WriteString(output, "main\n")
+			return err
+		case "build":
+			builds++
+			for _, a := range args {
+				if strings.HasPrefix(a, "-o=") {
+					return ioutil.WriteFile(strings.TrimPrefix(a, "-o="), []byte("faillint binary"), 0755)
+				}
+			}
+		}
+		return nil
+	})
+	ctx := context.Background()
+	r, err := runner.NewRunnerWithExecutor(ctx, nil, false, "go", fakeGo)
+	testutil.Ok(t, err)
+
+	mf, err := bingo.OpenModFile(modFilePath)
+	testutil.Ok(t, err)
+	defer func() { testutil.Ok(t, mf.Close()) }()
+
+	extra := filepath.Join(t.TempDir(), "bin")
+	c := installPackageConfig{runner: r, modDir: modDir, link: true, linkMode: SymlinkLinkMode, gobins: []string{gobin, extra}}
+	_, err = install(ctx, c, "faillint", mf)
+	testutil.Ok(t, err)
+	testutil.Equals(t, 1, builds)
+
+	for _, dir := range []string{gobin, extra} {
+		for _, n := range []string{bingo.BinaryName("faillint", "v1.5.0"), "faillint" + bingo.GOEXE(), "fl" + bingo.GOEXE()} {
+			b, err := ioutil.ReadFile(filepath.Join(dir, n))
+			testutil.Ok(t, err)
+			testutil.Equals(t, "faillint binary", string(b))
+		}
+	}
+	// Extra GOBIN is self-contained.
+	linked, ok := linkedBinary(filepath.Join(extra, "faillint"+bingo.GOEXE()))
+	testutil.Assert(t, ok, "expected symlink")
+	testutil.Equals(t, filepath.Join(extra, bingo.BinaryName("faillint", "v1.5.0")), linked)
+	_, ok = linkedBinary(filepath.Join(extra, bingo.BinaryName("faillint", "v1.5.0")))
+	testutil.Assert(t, !ok, "expected copy of versioned binary")
+}
+
+func TestInstall_StaleAliases(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("symlinks require privileges on Windows")
+	}
+	t.Setenv("GOEXE", "")
+	modDir := t.TempDir()
+	gobin := t.TempDir()
+	t.Setenv("GOBIN", gobin)
+	modFilePath := filepath.Join(modDir, "faillint.mod")
+	testutil.Ok(t, ioutil.WriteFile(modFilePath, []byte("module _\n\n// bingo:aliases fl,flint,other\n\nrequire github.com/fatih/faillint v1.5.0\n"), os.ModePerm))
+
+	fakeGo := runner.ExecutorFunc(func(_ context.Context, output io.Writer, _ []string, _, _ string, args ...string) error {
+		switch args[0] {
+		case "version":
+			_, err := io.WriteString(output, "go version go1.21.0 linux/amd64\n")
+			return err
+		case "list":
+			_, err := io.WriteString(output, "main\n")
+			return err
+		case "build":
+			for _, a := range args {
+				if strings.HasPrefix(a, "-o=") {
+					return ioutil.WriteFile(strings.TrimPrefix(a, "-o="), []byte("faillint binary"), 0755)
+				}
+			}
+		}
+		return nil
+	})
+	ctx := context.Background()
+	r, err := runner.NewRunnerWithExecutor(ctx, nil, false, "go", fakeGo)
+	testutil.Ok(t, err)
+
+	mf, err := bingo.OpenModFile(modFilePath)
+	testutil.Ok(t, err)
+	defer func() { testutil.Ok(t, mf.Close()) }()
+
+	c := installPackageConfig{runner: r, modDir: modDir, link: true, linkMode: SymlinkLinkMode}
+	_, err = install(ctx, c, "faillint", mf)
+	testutil.Ok(t, err)
+	// Alias link replaced by other project should be kept.
+	testutil.Ok(t, os.Remove(filepath.Join(gobin, "other")))
+	testutil.Ok(t, os.Symlink(filepath.Join(gobin, "other-v0.1.0"), filepath.Join(gobin, "other")))
+
+	testutil.Equals(t, []string{"flint", "other"}, staleAliases([]string{"fl", "flint", "other"}, []string{"f", "fl"}))
+	mf.SetCommand(bingo.AliasesCommand, "f,fl")
+	testutil.Ok(t, mf.Flush())
+	c.staleAliases = []string{"flint", "other"}
+	_, err = install(ctx, c, "faillint", mf)
+	testutil.Ok(t, err)
+
+	for _, n := range []string{"faillint", "f", "fl"} {
+		_, ok := linkedBinary(filepath.Join(gobin, n))
+		testutil.Assert(t, ok, "expected link %v", n)
+	}
+	_, err = os.Lstat(filepath.Join(gobin, "flint"))
+	testutil.Assert(t, os.IsNotExist(err), "stale alias link should be removed")
+	linked, ok := linkedBinary(filepath.Join(gobin, "other"))
+	testutil.Assert(t, ok, "link to binary not pinned by the tool should be kept")
+	testutil.Equals(t, filepath.Join(gobin, "other-v0.1.0"), linked)
+}
+
+func TestInstall_Store(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("symlinks require privileges on Windows")
+	}
+	t.Setenv("GOEXE", "")
+	modDir := t.TempDir()
+	gobin := t.TempDir()
+	t.Setenv("GOBIN", gobin)
+	modFilePath := filepath.Join(modDir, "faillint.mod")
+	testutil.Ok(t, ioutil.WriteFile(modFilePath, []byte("module _\n\nrequire github.com/fatih/faillint v1.5.0\n"), os.ModePerm))
+
+	builds := 0
+	fakeGo := runner.ExecutorFunc(func(_ context.Context, output io.Writer, _ []string, _, _ string, args ...string) error {
+		switch args[0] {
+		case "version":
+			_, err := io.WriteString(output, "go version go1.21.0 linux/amd64\n")
+			return err
+		case "list":
+			_, err := io.WriteString(output, "main\n")
+			return err
+		case "build":
+			builds++
+			for _, a := range args {
+				if strings.HasPrefix(a, "-o=") {
+					return ioutil.WriteFile(strings.TrimPrefix(a, "-o="), []byte("faillint binary"), 0755)
+				}
+			}
+		}
+		return nil
+	})
+	ctx := context.Background()
+	r, err := runner.NewRunnerWithExecutor(ctx, nil, false, "go", fakeGo)
+	testutil.Ok(t, err)
+
+	mf, err := bingo.OpenModFile(modFilePath)
+	testutil.Ok(t, err)
+	defer func() { testutil.Ok(t, mf.Close()) }()
+
+	store := t.TempDir()
+	c := installPackageConfig{runner: r, modDir: modDir, store: store}
+	rebuilt, err := install(ctx, c, "faillint", mf)
+	testutil.Ok(t, err)
+	testutil.Assert(t, rebuilt, "expected build on store miss")
+
+	testutil.Ok(t, os.Remove(filepath.Join(gobin, "faillint-v1.5.0")))
+	rebuilt, err = install(ctx, c, "faillint", mf)
+	testutil.Ok(t, err)
+	testutil.Assert(t, !rebuilt, "expected no build on store hit")
+	testutil.Equals(t, 1, builds)
+
+	b, err := ioutil.ReadFile(filepath.Join(gobin, "faillint-v1.5.0"))
+	testutil.Ok(t, err)
+	testutil.Equals(t, "faillint binary", string(b))
+	tmps, err := filepath.Glob(filepath.Join(store, "*", "*.tmp"))
+	testutil.Ok(t, err)
+	testutil.Equals(t, 0, len(tmps))
+}
+
+func TestInstall_ModulePolicy(t *testing.T) {
+	modDir := t.TempDir()
+	t.Setenv("GOBIN", t.TempDir())
+	modFilePath := filepath.Join(modDir, "faillint.mod")
+	testutil.Ok(t, ioutil.WriteFile(modFilePath, []byte("module _\n\nrequire github.com/fatih/faillint v1.5.0\n"), os.ModePerm))
+
+	builds := 0
+	fakeGo := runner.ExecutorFunc(func(_ context.Context, output io.Writer, _ []string, _, _ string, args ...string) error {
+		switch args[0] {
+		case "version":
+			_, err := io.WriteString(output, "go version go1.21.0 linux/amd64\n")
+			return err
+		case "list":
+			out := "main\n"
+			if args[3] == "-deps" {
+				out = "\ngithub.com/fatih/faillint v1.5.0\ngithub.com/evil/lib v0.1.0\ngithub.com/fatih/faillint v1.5.0\n"
+			}
+			_, err := io.WriteString(output, out)
+			return err
+		case "build":
+			builds++
+		}
+		return nil
+	})
+	ctx := context.Background()
+	r, err := runner.NewRunnerWithExecutor(ctx, nil, false, "go", fakeGo)
+	testutil.Ok(t, err)
+
+	mf, err := bingo.OpenModFile(modFilePath)
+	testutil.Ok(t, err)
+	defer func() { testutil.Ok(t, mf.Close()) }()
+
+	c := installPackageConfig{runner: r, modDir: modDir, policy: &bingo.ModulePolicy{Deny: []string{"github.com/evil/..."}}}
+	_, err = install(ctx, c, "faillint", mf)
+	testutil.NotOk(t, err)
+	testutil.Assert(t, errors.Is(err, bingo.ErrPolicyViolation), "expected policy violation, got %v", err)
+	testutil.Equals(t, 0, builds)
+
+	mf.SetCommand(bingo.PolicyExceptionCommand, "github.com/evil/lib@v0.1.0 reviewed in SEC-123")
+	testutil.Ok(t, mf.Flush())
+	_, err = install(ctx, c, "faillint", mf)
+	testutil.Ok(t, err)
+	testutil.Equals(t, 1, builds)
+
+	violations, err := ModulePolicyViolations(ctx, r, modDir, bingo.ModulePolicy{Allow: []string{"github.com/fatih/..."}}, modFilePath)
+	testutil.Ok(t, err)
+	testutil.Equals(t, 0, len(violations))
+	violations, err = ModulePolicyViolations(ctx, r, modDir, bingo.ModulePolicy{Deny: []string{"github.com/fatih/faillint@v1.5.0"}}, modFilePath)
+	testutil.Ok(t, err)
+	testutil.Equals(t, []bingo.PolicyViolation{{Module: module.Version{Path: "github.com/fatih/faillint", Version: "v1.5.0"}, Rule: "github.com/fatih/faillint@v1.5.0"}}, violations)
+	_, err = os.Stat(filepath.Join(modDir, "faillint.policy.tmp.mod"))
+	testutil.Assert(t, os.IsNotExist(err), "tmp mod file should be removed")
+}
+
+func TestInstall_BinDir(t *testing.T) {
+	modDir := t.TempDir()
+	t.Setenv("GOBIN", t.TempDir())
+	modFilePath := filepath.Join(modDir, "protoc-gen-go.mod")
+	testutil.Ok(t, ioutil.WriteFile(modFilePath, []byte("module _\n\n// bingo:bindir ../protoc/bin\n\nrequire google.golang.org/protobuf v1.31.0 // cmd/protoc-gen-go\n"), os.ModePerm))
+
+	var out string
+	fakeGo := runner.ExecutorFunc(func(_ context.Context, output io.Writer, _ []string, _, _ string, args ...string) error {
+		switch args[0] {
+		case "version":
+			_, err := io.WriteString(output, "go version go1.21.0 linux/amd64\n")
+			return err
+		case "list":
+			_, err := io.WriteString(output, "main\n")
+			return err
+		case "build":
+			out = args[2]
+		}
+		return nil
+	})
+	ctx := context.Background()
+	r, err := runner.NewRunnerWithExecutor(ctx, nil, false, "go", fakeGo)
+	testutil.Ok(t, err)
+
+	mf, err := bingo.OpenModFile(modFilePath)
+	testutil.Ok(t, err)
+	defer func() { testutil.Ok(t, mf.Close()) }()
+
+	_, err = install(ctx, installPackageConfig{runner: r, modDir: modDir}, "protoc-gen-go", mf)
+	testutil.Ok(t, err)
+	binDir := filepath.Join(filepath.Dir(modDir), "protoc", "bin")
+	testutil.Equals(t, "-o="+filepath.Join(binDir, bingo.BinaryName("protoc-gen-go", "v1.31.0")), out)
+	_, err = os.Stat(binDir)
+	testutil.Ok(t, err)
+}
+
+func TestInstall_Wasm(t *testing.T) {
+	modDir := t.TempDir()
+	gobin := t.TempDir()
+	t.Setenv("GOBIN", gobin)
+	modFilePath := filepath.Join(modDir, "plugin.mod")
+	testutil.Ok(t, ioutil.WriteFile(modFilePath, []byte("module _\n\nrequire github.com/org/plugin v0.1.0 // GOOS=wasip1 GOARCH=wasm\n"), os.ModePerm))
+
+	var listEnv []string
+	fakeGo := runner.ExecutorFunc(func(_ context.Context, output io.Writer, env []string, _, _ string, args ...string) error {
+		switch args[0] {
+		case "version":
+			_, err := io.WriteString(output, "go version go1.21.0 linux/amd64\n")
+			return err
+		case "list":
+			listEnv = env
+			_, err := io.WriteString(output, "main\n")
+			return err
+		case "build":
+			return ioutil.WriteFile(strings.TrimPrefix(args[2], "-o="), []byte("\x00asm"), os.ModePerm)
+		}
+		return nil
+	})
+	ctx := context.Background()
+	r, err := runner.NewRunnerWithExecutor(ctx, nil, false, "go", fakeGo)
+	testutil.Ok(t, err)
+
+	mf, err := bingo.OpenModFile(modFilePath)
+	testutil.Ok(t, err)
+	defer func() { testutil.Ok(t, mf.Close()) }()
+
+	extraGOBIN := t.TempDir()
+	c := installPackageConfig{runner: r, modDir: modDir, link: true, store: t.TempDir(), gobins: []string{extraGOBIN}}
+	_, err = install(ctx, c, "plugin", mf)
+	testutil.Ok(t, err)
+	testutil.Assert(t, strings.Contains(strings.Join(listEnv, " "), "GOARCH=wasm"), "expected wasm list env, got %v", listEnv)
+	for _, dir := range []string{gobin, extraGOBIN} {
+		_, err = os.Stat(filepath.Join(dir, "plugin-v0.1.0.wasm"))
+		testutil.Ok(t, err)
+		// WebAssembly modules are not linked.
+		_, err = os.Lstat(filepath.Join(dir, "plugin"+bingo.GOEXE()))
+		testutil.Assert(t, os.IsNotExist(err), "expected no link, got %v", err)
+	}
+}
+
+func TestGOBIN(t *testing.T) {
+	t.Setenv("GOBIN", "/gobin")
+	t.Setenv("GOPATH", "/gopath")
+	testutil.Equals(t, "/gobin", GOBIN())
+
+	t.Setenv("GOBIN", "")
+	testutil.Equals(t, filepath.Join("/gopath", "bin"), GOBIN())
+	t.Setenv("GOPATH", "/gopath"+string(filepath.ListSeparator)+"/other")
+	testutil.Equals(t, filepath.Join("/gopath", "bin"), GOBIN())
+
+	t.Setenv("GOPATH", "")
+	testutil.Equals(t, filepath.Join(build.Default.GOPATH, "bin"), GOBIN())
+}
+
+func TestBinDirArg(t *testing.T) {
+	wd, err := os.Getwd()
+	testutil.Ok(t, err)
+
+	for _, tcase := range []struct{ dir, expected string }{
+		{dir: "", expected: ""},
+		{dir: "third_party/protoc/bin", expected: "../third_party/protoc/bin"},
+		{dir: filepath.Join(wd, ".bingo", "bin"), expected: filepath.ToSlash(filepath.Join(wd, ".bingo", "bin"))},
+	} {
+		t.Run(tcase.dir, func(t *testing.T) {
+			got, err := binDirArg(filepath.Join(wd, ".bingo"), tcase.dir)
+			testutil.Ok(t, err)
+			testutil.Equals(t, tcase.expected, got)
+		})
+	}
+}
+
+func TestInstall_Generate(t *testing.T) {
+	modDir := t.TempDir()
+	t.Setenv("GOBIN", t.TempDir())
+	modFilePath := filepath.Join(modDir, "hugo.mod")
+	testutil.Ok(t, ioutil.WriteFile(modFilePath, []byte("module _\n\n// bingo:generate ./assets ./cmd/hugo\n\nrequire github.com/gohugoio/hugo v0.83.1 // cmd/hugo\n"), os.ModePerm))
+
+	// Module cache is read-only.
+	cacheDir := filepath.Join(t.TempDir(), "hugo@v0.83.1")
+	testutil.Ok(t, os.MkdirAll(filepath.Join(cacheDir, "assets"), os.ModePerm))
+	testutil.Ok(t, ioutil.WriteFile(filepath.Join(cacheDir, "go.mod"), []byte("module github.com/gohugoio/hugo\n"), 0444))
+	testutil.Ok(t, ioutil.WriteFile(filepath.Join(cacheDir, "assets", "gen.go"), []byte("package assets\n"), 0444))
+
+	var generateDir string
+	var generateArgs []string
+	fakeGo := runner.ExecutorFunc(func(_ context.Context, output io.Writer, _ []string, dir, _ string, args ...string) error {
+		switch args[0] {
+		case "version":
+			_, err := io.WriteString(output, "go version go1.21.0 linux/amd64\n")
+			return err
+		case "list":
+			if args[3] == "-m" {
+				_, err := io.WriteString(output, cacheDir+"\n")
+				return err
+			}
+			_, err := io.WriteString(output, "main\n")
+			return err
+		case "generate":
+			generateDir, generateArgs = dir, args[1:]
+			// Generators write into module source.
+			return ioutil.WriteFile(filepath.Join(dir, "assets", "gen.go"), []byte("package assets\n\nvar Generated = true\n"), os.ModePerm)
+		case "build":
+			modFile := strings.TrimPrefix(args[1], "-modfile=")
+			testutil.Equals(t, filepath.Join(modDir, "hugo.generate.tmp.mod"), modFile)
+			b, err := ioutil.ReadFile(modFile)
+			testutil.Ok(t, err)
+			testutil.Assert(t, strings.Contains(string(b), "replace github.com/gohugoio/hugo => "+generateDir+"\n"), string(b))
+		}
+		return nil
+	})
+	ctx := context.Background()
+	r, err := runner.NewRunnerWithExecutor(ctx, nil, false, "go", fakeGo)
+	testutil.Ok(t, err)
+
+	mf, err := bingo.OpenModFile(modFilePath)
+	testutil.Ok(t, err)
+	defer func() { testutil.Ok(t, mf.Close()) }()
+
+	_, err = install(ctx, installPackageConfig{runner: r, modDir: modDir}, "hugo", mf)
+	testutil.Ok(t, err)
+	testutil.Equals(t, []string{"./assets", "./cmd/hugo"}, generateArgs)
+	testutil.Equals(t, "hugo@v0.83.1", filepath.Base(generateDir))
+	// Temporary files are removed.
+	_, err = os.Stat(generateDir)
+	testutil.Assert(t, os.IsNotExist(err), "generated copy should be removed, got %v", err)
+	_, err = os.Stat(filepath.Join(modDir, "hugo.generate.tmp.mod"))
+	testutil.Assert(t, os.IsNotExist(err), "tmp mod file should be removed, got %v", err)
+}
+
+func TestResolvePackage_GoModCacheFallback(t *testing.T) {
+	cacheDir := t.TempDir()
+	t.Setenv("GOMODCACHE", cacheDir)
+	metaDir := filepath.Join(cacheDir, "cache/download/github.com/bufbuild/buf/@v")
+	testutil.Ok(t, os.MkdirAll(metaDir, os.ModePerm))
+	testutil.Ok(t, ioutil.WriteFile(filepath.Join(metaDir, "list"), []byte("v0.1.0\nv0.2.0\n"), os.ModePerm))
+	testutil.Ok(t, ioutil.WriteFile(filepath.Join(metaDir, "v0.1.0.info"), []byte("{}"), os.ModePerm))
+
+	// Fake go that only knows its version, so every go get fails.
+	var cmds []string
+	fakeGo := runner.ExecutorFunc(func(_ context.Context, output io.Writer, _ []string, _, _ string, args ...string) error {
+		cmds = append(cmds, strings.Join(args, " "))
+		if args[0] == "version" {
+			_, err := io.WriteString(output, "go version go1.21.0 linux/amd64\n")
+			return err
+		}
+		return errors.New("go get failed")
+	})
+	ctx := context.Background()
+	r, err := runner.NewRunnerWithExecutor(ctx, nil, false, "go", fakeGo)
+	testutil.Ok(t, err)
+	logger := slog.New(slog.NewTextHandler(ioutil.Discard, nil))
+
+	t.Run("latest", func(t *testing.T) {
+		target := bingo.Package{RelPath: "github.com/bufbuild/buf/cmd/buf"}
+		testutil.Ok(t, resolvePackage(ctx, logger, "tmp.mod", r.With(ctx, "tmp.mod", "", nil), runner.NoUpdatePolicy, &target))
+		testutil.Equals(t, "github.com/bufbuild/buf/cmd/buf@v0.2.0", target.String())
+		testutil.Equals(t, "cmd/buf", target.RelPath)
+		testutil.Equals(t, "get -modfile=tmp.mod -d github.com/bufbuild/buf/cmd/buf", cmds[len(cmds)-1])
+	})
+	t.Run("pinned", func(t *testing.T) {
+		target := bingo.Package{RelPath: "github.com/bufbuild/buf/cmd/buf", Module: module.Version{Version: "v0.1.0"}}
+		testutil.Ok(t, resolvePackage(ctx, logger, "tmp.mod", r.With(ctx, "tmp.mod", "", nil), runner.NoUpdatePolicy, &target))
+		testutil.Equals(t, "github.com/bufbuild/buf", target.Module.Path)
+	})
+	t.Run("not cached", func(t *testing.T) {
+		target := bingo.Package{RelPath: "github.com/bufbuild/buf/cmd/buf", Module: module.Version{Version: "v0.3.0"}}
+		err := resolvePackage(ctx, logger, "tmp.mod", r.With(ctx, "tmp.mod", "", nil), runner.NoUpdatePolicy, &target)
+		var rerr *bingo.ResolveError
+		testutil.Assert(t, errors.As(err, &rerr), "unexpected error %v", err)
+	})
+	t.Run("cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(ctx)
+		cancel()
+		target := bingo.Package{RelPath: "github.com/bufbuild/buf/cmd/buf"}
+		err := resolvePackage(ctx, logger, "tmp.mod", r.With(ctx, "tmp.mod", "", nil), runner.NoUpdatePolicy, &target)
+		testutil.Assert(t, errors.Is(err, context.Canceled), "unexpected error %v", err)
+		testutil.Equals(t, "", target.Module.Version)
+	})
+}
+
+func TestResolvePackage_NestedModule(t *testing.T) {
+	tmpModFile := filepath.Join(t.TempDir(), "tmp.mod")
+	testutil.Ok(t, ioutil.WriteFile(tmpModFile, []byte(`module _
+
+go 1.21
+
+require (
+	github.com/org/repo v1.2.0 // indirect
+	github.com/org/repo/tools v0.3.0 // indirect
+	github.com/other/lib v0.1.0 // indirect
+)
+`), os.ModePerm))
+
+	for _, tcase := range []struct {
+		name    string
+		listOut string
+		listErr error
+	}{
+		{name: "go list", listOut: "github.com/org/repo/tools\n"},
+		{name: "go list failed", listErr: errors.New("go list failed")},
+	} {
+		t.Run(tcase.name, func(t *testing.T) {
+			fakeGo := runner.ExecutorFunc(func(_ context.Context, output io.Writer, _ []string, _, _ string, args ...string) error {
+				switch args[0] {
+				case "version":
+					_, err := io.WriteString(output, "go version go1.21.0 linux/amd64\n")
+					return err
+				case "list":
+					if tcase.listErr != nil {
+						return tcase.listErr
+					}
+					_, err := io.WriteString(output, tcase.listOut)
+					return err
+				}
+				return nil
+			})
+			ctx := context.Background()
+			r, err := runner.NewRunnerWithExecutor(ctx, nil, false, "go", fakeGo)
+			testutil.Ok(t, err)
+			logger := slog.New(slog.NewTextHandler(ioutil.Discard, nil))
+
+			target := bingo.Package{RelPath: "github.com/org/repo/tools/cmd/x"}
+			testutil.Ok(t, resolvePackage(ctx, logger, tmpModFile, r.With(ctx, tmpModFile, "", nil), runner.NoUpdatePolicy, &target))
+			testutil.Equals(t, "github.com/org/repo/tools", target.Module.Path)
+			testutil.Equals(t, "v0.3.0", target.Module.Version)
+			testutil.Equals(t, "cmd/x", target.RelPath)
+		})
+	}
+}
+
+func TestGet_Selector(t *testing.T) {
+	modDir := t.TempDir()
+	testutil.Ok(t, ioutil.WriteFile(filepath.Join(modDir, "faillint.mod"), []byte("module _\n\n// bingo:labels team=platform\n\nrequire github.com/fatih/faillint v1.5.0\n"), os.ModePerm))
+	testutil.Ok(t, ioutil.WriteFile(filepath.Join(modDir, "buf.mod"), []byte("module _\n\n// bingo:labels team=platform\n\nrequire github.com/bufbuild/buf v0.1.0 // cmd/buf\n"), os.ModePerm))
+	testutil.Ok(t, ioutil.WriteFile(filepath.Join(modDir, "misspell.mod"), []byte("module _\n\nrequire github.com/client9/misspell v0.3.4 // cmd/misspell\n"), os.ModePerm))
+
+	logger := slog.New(slog.NewTextHandler(ioutil.Discard, nil))
+	c := Config{ModDir: modDir, RelModDir: modDir, Selector: bingo.Labels{"team": "platform"}}
+	testutil.NotOk(t, Get(context.Background(), logger, c, "faillint"))
+	testutil.NotOk(t, Get(context.Background(), logger, Config{ModDir: modDir, RelModDir: modDir, Selector: bingo.Labels{"team": "docs"}}, "@none"))
+
+	testutil.Ok(t, Get(context.Background(), logger, c, "@none"))
+	pkgs, err := bingo.ListPinnedMainPackages(logger, modDir, false)
+	testutil.Ok(t, err)
+	testutil.Equals(t, 1, len(pkgs))
+	testutil.Equals(t, "misspell", pkgs[0].Name)
+}
+
+func TestPinnedBinaries(t *testing.T) {
+	t.Setenv("GOEXE", "")
+	modDir := t.TempDir()
+	testutil.Ok(t, ioutil.WriteFile(filepath.Join(modDir, "faillint.mod"), []byte("module _\n\nrequire github.com/fatih/faillint v1.5.0\n"), os.ModePerm))
+	testutil.Ok(t, ioutil.WriteFile(filepath.Join(modDir, "faillint.1.mod"), []byte("module _\n\nrequire github.com/fatih/faillint v1.4.0\n"), os.ModePerm))
+	testutil.Ok(t, ioutil.WriteFile(filepath.Join(modDir, "misspell.mod"), []byte("module _\n\nrequire github.com/client9/misspell v0.3.4 // cmd/misspell\n"), os.ModePerm))
+
+	bins, err := pinnedBinaries(modDir, "faillint")
+	testutil.Ok(t, err)
+	testutil.Equals(t, map[string]struct{}{"faillint-v1.5.0": {}, "faillint-v1.4.0": {}}, bins)
+}
+
+func TestLinkBinary(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("links get extensions on Windows")
+	}
+	t.Setenv("GOEXE", "")
+	dir := t.TempDir()
+	binPath := filepath.Join(dir, "faillint-v1.5.0")
+	testutil.Ok(t, ioutil.WriteFile(binPath, []byte("binary"), 0755))
+	linkPath := filepath.Join(dir, "faillint")
+
+	for _, mode := range []LinkMode{SymlinkLinkMode, HardlinkLinkMode, CopyLinkMode, AutoLinkMode, ""} {
+		t.Run(string(mode), func(t *testing.T) {
+			testutil.Ok(t, LinkBinary(mode, binPath, linkPath))
+			b, err := ioutil.ReadFile(linkPath)
+			testutil.Ok(t, err)
+			testutil.Equals(t, "binary", string(b))
+		})
+	}
+
+	testutil.Ok(t, LinkBinary(SymlinkLinkMode, binPath, linkPath))
+	linked, ok := linkedBinary(linkPath)
+	testutil.Equals(t, true, ok)
+	testutil.Equals(t, binPath, linked)
+	_, ok = linkedBinary(binPath)
+	testutil.Equals(t, false, ok)
+
+	testutil.Ok(t, LinkBinary(ShimLinkMode, binPath, linkPath))
+	b, err := ioutil.ReadFile(linkPath)
+	testutil.Ok(t, err)
+	testutil.Equals(t, "#!/bin/sh\nexec '"+binPath+"' \"$@\"\n", string(b))
+
+	testutil.NotOk(t, LinkBinary("junction", binPath, linkPath))
+}
+
+func TestLinkPath(t *testing.T) {
+	testutil.Equals(t, "/bin/faillint", linkPath("linux", "", ShimLinkMode, "/bin/faillint"))
+	testutil.Equals(t, "/bin/faillint", linkPath("linux", "", CopyLinkMode, "/bin/faillint"))
+	testutil.Equals(t, `C:\bin\faillint.exe`, linkPath("windows", ".exe", CopyLinkMode, `C:\bin\faillint`))
+	testutil.Equals(t, `C:\bin\faillint.cmd`, linkPath("windows", ".exe", ShimLinkMode, `C:\bin\faillint`))
+	testutil.Equals(t, "@echo off\r\n\"C:\\bin\\faillint-v1.5.0\" %*\r\n", shimScript("windows", `C:\bin\faillint-v1.5.0`))
+}
+
+func TestStoreBinPath(t *testing.T) {
+	t.Setenv("GOEXE", "")
+	t.Setenv("GOOS", "linux")
+	t.Setenv("GOARCH", "amd64")
+	dir := t.TempDir()
+
+	open := func(name, content string) *bingo.ModFile {
+		t.Helper()
+		f := filepath.Join(dir, name)
+		testutil.Ok(t, ioutil.WriteFile(f, []byte(content), os.ModePerm))
+		mf, err := bingo.OpenModFile(f)
+		testutil.Ok(t, err)
+		t.Cleanup(func() { _ = mf.Close() })
+		return mf
+	}
+	a := open("a.mod", "module _\n\nrequire github.com/fatih/faillint v1.5.0\n")
+	b := open("b.mod", "module _\n\nrequire github.com/fatih/faillint v1.5.0\n")
+	c := open("c.mod", "module _\n\nrequire github.com/fatih/faillint v1.5.0 // CGO_ENABLED=1 GOARCH=arm64\n")
+
+	pa, err := storeBinPath("/store", "faillint", a, "1.21.0")
+	testutil.Ok(t, err)
+	testutil.Assert(t, strings.HasPrefix(pa, filepath.Join("/store", "linux_amd64", "faillint-v1.5.0-")), pa)
+
+	pb, err := storeBinPath("/store", "faillint", b, "1.21.0")
+	testutil.Ok(t, err)
+	testutil.Equals(t, pa, pb)
+
+	pb, err = storeBinPath("/store", "faillint", b, "1.22.0")
+	testutil.Ok(t, err)
+	testutil.Assert(t, pa != pb)
+
+	pc, err := storeBinPath("/store", "faillint", c, "1.21.0")
+	testutil.Ok(t, err)
+	testutil.Assert(t, strings.HasPrefix(pc, filepath.Join("/store", "linux_arm64", "faillint-v1.5.0-")), pc)
+
+	// Comments and bingo commands do not change the build.
+	d := open("d.mod", "module _ // Auto generated by https://github.com/bwplotka/bingo. DO NOT EDIT\n\n// bingo:description Lints imports.\n\n// Pinned for CI.\nrequire github.com/fatih/faillint v1.5.0\n")
+	pd, err := storeBinPath("/store", "faillint", d, "1.21.0")
+	testutil.Ok(t, err)
+	testutil.Equals(t, pa, pd)
+
+	// Ambient GOFLAGS used by build do.
+	t.Setenv("GOFLAGS", "-trimpath")
+	pa2, err := storeBinPath("/store", "faillint", a, "1.21.0")
+	testutil.Ok(t, err)
+	testutil.Assert(t, pa != pa2)
+}
@@ -0,0 +1,140 @@
+// Copyright (c) Bartłomiej Płotka @bwplotka
+// Licensed under the Apache License 2.0.
+
+package getter
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"strings"
+
+	"github.com/bwplotka/bingo/pkg/bingo"
+	"github.com/efficientgo/tools/core/pkg/errcapture"
+	"github.com/pkg/errors"
+)
+
+// LinkMode defines how unversioned tool name is linked to pinned binary (e.g with -l flag).
+type LinkMode string
+
+const (
+	// AutoLinkMode tries SymlinkLinkMode, HardlinkLinkMode, CopyLinkMode and ShimLinkMode in this order and uses the
+	// first one that works. Useful on Windows, where symlinks require developer mode or admin rights.
+	AutoLinkMode LinkMode = "auto"
+	// SymlinkLinkMode creates symbolic link.
+	SymlinkLinkMode LinkMode = "symlink"
+	// HardlinkLinkMode creates hard link. It works only within the same file system.
+	HardlinkLinkMode LinkMode = "hardlink"
+	// CopyLinkMode copies the binary.
+	CopyLinkMode LinkMode = "copy"
+	// ShimLinkMode generates script that executes the binary: <name>.cmd on Windows, POSIX shell script elsewhere.
+	ShimLinkMode LinkMode = "shim"
+)
+
+// linkPath returns path of the link for given mode, OS and executable suffix. On Windows, shims get .cmd extension,
+// other links given executable suffix (GOEXE), as binaries do.
+func linkPath(goos, goexe string, mode LinkMode, path string) string {
+	if mode != ShimLinkMode {
+		return path + goexe
+	}
+	if goos == "windows" {
+		return path + ".cmd"
+	}
+	return path
+}
+
+// LinkBinary links given binary under given path using given mode. Empty mode means AutoLinkMode. Any existing link
+// under this path (created in any mode) is replaced.
+func LinkBinary(mode LinkMode, binPath, path string) error {
+	if err := removeLink(path); err != nil {
+		return err
+	}
+
+	switch mode {
+	case AutoLinkMode, "":
+		var errs []string
+		for _, m := range []LinkMode{SymlinkLinkMode, HardlinkLinkMode, CopyLinkMode, ShimLinkMode} {
+			err := link(m, binPath, linkPath(runtime.GOOS, bingo.GOEXE(), m, path))
+			if err == nil {
+				return nil
+			}
+			errs = append(errs, err.Error())
+		}
+		return errors.Errorf("all link modes failed: %s", strings.Join(errs, "; "))
+	case SymlinkLinkMode, HardlinkLinkMode, CopyLinkMode, ShimLinkMode:
+		return link(mode, binPath, linkPath(runtime.GOOS, bingo.GOEXE(), mode, path))
+	default:
+		return errors.Errorf("unknown link mode %q", mode)
+	}
+}
+
+// removeLink removes link (created in any mode) under given path, if any.
+func removeLink(path string) error {
+	for _, m := range []LinkMode{SymlinkLinkMode, ShimLinkMode} {
+		if err := os.RemoveAll(linkPath(runtime.GOOS, bingo.GOEXE(), m, path)); err != nil {
+			return errors.Wrap(err, "rm")
+		}
+	}
+	return nil
+}
+
+// linkExists returns true if there is link (created in any mode) under given path.
+func linkExists(path string) bool {
+	for _, m := range []LinkMode{SymlinkLinkMode, ShimLinkMode} {
+		if _, err := os.Lstat(linkPath(runtime.GOOS, bingo.GOEXE(), m, path)); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// linkedBinary returns binary given symbolic link points to. It returns false if there is no symbolic link under given
+// path, including links created in hardlink, copy and shim modes, which do not record binary they were created from, so
+// callers comparing linked binaries (e.g relinking on rename) only handle symlink mode.
+func linkedBinary(path string) (string, bool) {
+	target, err := os.Readlink(path)
+	if err != nil {
+		return "", false
+	}
+	return target, true
+}
+
+func link(mode LinkMode, binPath, path string) error {
+	switch mode {
+	case SymlinkLinkMode:
+		return errors.Wrap(os.Symlink(binPath, path), "symlink")
+	case HardlinkLinkMode:
+		return errors.Wrap(os.Link(binPath, path), "hardlink")
+	case CopyLinkMode:
+		return errors.Wrap(copyFile(binPath, path), "copy")
+	case ShimLinkMode:
+		return errors.Wrap(os.WriteFile(path, []byte(shimScript(runtime.GOOS, binPath)), 0755), "shim")
+	}
+	return errors.Errorf("unknown link mode %q", mode)
+}
+
+// shimScript returns script for given OS that executes given binary with all arguments.
+func shimScript(goos, binPath string) string {
+	if goos == "windows" {
+		return fmt.Sprintf("@echo off\r\n\"%s\" %%*\r\n", binPath)
+	}
+	return fmt.Sprintf("#!/bin/sh\nexec '%s' \"$@\"\n", strings.ReplaceAll(binPath, "'", `'\''`))
+}
+
+func copyFile(src, dst string) (err error) {
+	s, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer errcapture.Do(&err, s.Close, "close source")
+
+	d, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0755)
+	if err != nil {
+		return err
+	}
+	defer errcapture.Do(&err, d.Close, "close destination")
+
+	_, err = io.Copy(d, s)
+	return err
+}
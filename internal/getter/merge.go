@@ -0,0 +1,121 @@
+// Copyright (c) Bartłomiej Płotka @bwplotka
+// Licensed under the Apache License 2.0.
+
+package getter
+
+import (
+	"os"
+	"strings"
+
+	"github.com/bwplotka/bingo/pkg/bingo"
+	"github.com/bwplotka/bingo/pkg/logging"
+	"github.com/pkg/errors"
+)
+
+// WarnDuplicates warns about packages pinned under more than one tool name among given tools, suggesting how to merge them.
+func WarnDuplicates(logger logging.Logger, pkgs bingo.PackageRenderables) {
+	for _, d := range pkgs.Duplicates() {
+		logger.Warn("package is pinned under many names, so their versions can diverge; merge them into aliases of a single pin",
+			"package", d.PackagePath, "names", strings.Join(d.Names, ","), "merge", "bingo merge "+strings.Join(d.Names, " "))
+	}
+}
+
+// Merge merges pins of given duplicate tools (the same package pinned under different names) into pin of given tool:
+// mod files of duplicates are removed and their names and aliases are added to aliases of the tool, so they are still
+// linked and have variables in generated helpers, but are always upgraded together. Versions pinned only by duplicates
+// are dropped.
+func Merge(logger logging.Logger, modDir, name string, duplicates []string) error {
+	modFiles, pkgPath, versions, err := pinnedModFiles(logger, modDir, name)
+	if err != nil {
+		return err
+	}
+	mf, err := bingo.ReadModFile(modFiles[0])
+	if err != nil {
+		return errors.Wrapf(err, "read %v", modFiles[0])
+	}
+	arg, _ := mf.Command(bingo.AliasesCommand)
+
+	var toRemove []string
+	for _, d := range duplicates {
+		if d == name {
+			return errors.Errorf("cannot merge tool %v into itself", name)
+		}
+		dupModFiles, dupPkgPath, dupVersions, err := pinnedModFiles(logger, modDir, d)
+		if err != nil {
+			return err
+		}
+		if dupPkgPath != pkgPath {
+			return errors.Errorf("tool %v pins package %v, not %v pinned by %v; only pins of the same package can be merged", d, dupPkgPath, pkgPath, name)
+		}
+		if dropped := missing(versions, dupVersions); len(dropped) > 0 {
+			logger.Warn("dropping versions pinned only by merged tool", "tool", d, "versions", strings.Join(dropped, ","), "kept", strings.Join(versions, ","))
+		}
+
+		dmf, err := bingo.ReadModFile(dupModFiles[0])
+		if err != nil {
+			return errors.Wrapf(err, "read %v", dupModFiles[0])
+		}
+		dupAliases, _ := dmf.Command(bingo.AliasesCommand)
+		arg = strings.Join([]string{arg, d, dupAliases}, ",")
+		toRemove = append(toRemove, dupModFiles...)
+	}
+
+	aliases, err := bingo.ParseAliases(arg)
+	if err != nil {
+		return errors.Wrap(err, "aliases")
+	}
+	for _, f := range modFiles {
+		if err := setCommand(f, bingo.AliasesCommand, strings.Join(aliases, ",")); err != nil {
+			return errors.Wrap(err, f)
+		}
+	}
+	for _, f := range toRemove {
+		if err := os.Remove(f); err != nil {
+			return errors.Wrap(err, "remove merged mod file")
+		}
+	}
+	logger.Info("merged pins; run 'bingo get -l' to link aliases to the pinned binary", "tool", name, "aliases", strings.Join(aliases, ","))
+	return nil
+}
+
+// pinnedModFiles returns mod files, package path and pinned versions of given tool.
+func pinnedModFiles(logger logging.Logger, modDir, name string) (modFiles []string, pkgPath string, versions []string, _ error) {
+	modFiles, err := existingModFiles(modDir, name)
+	if err != nil {
+		return nil, "", nil, err
+	}
+	if len(modFiles) == 0 {
+		return nil, "", nil, newSentinelError(bingo.ErrNotInstalled, "tool %v is not installed%s", name, didYouMean(logger, modDir, name))
+	}
+	for _, f := range modFiles {
+		mf, err := bingo.ReadModFile(f)
+		if err != nil {
+			return nil, "", nil, errors.Wrapf(err, "read %v", f)
+		}
+		pkg := mf.DirectPackage()
+		if pkg == nil {
+			return nil, "", nil, errors.Errorf("no direct package found in %v", f)
+		}
+		pkgPath = pkg.Path()
+		versions = append(versions, pkg.Module.Version)
+	}
+	return modFiles, pkgPath, versions, nil
+}
+
+// missing returns elements of b that are not in a.
+func missing(a, b []string) []string {
+	var m []string
+	for _, e := range b {
+		found := false
+		for _, e2 := range a {
+			if e == e2 {
+				found = true
+				break
+			}
+		}
+		if !found {
+			m = append(m, e)
+		}
+	}
+	return m
+}
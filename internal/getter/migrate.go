@@ -0,0 +1,129 @@
+// Copyright (c) Bartłomiej Płotka @bwplotka
+// Licensed under the Apache License 2.0.
+
+package getter
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/bwplotka/bingo/pkg/bingo"
+	"github.com/efficientgo/tools/core/pkg/errcapture"
+	"github.com/pkg/errors"
+)
+
+// MigrateModDir moves mod directory from given absolute path to given new absolute path, e.g from .bingo to tools/pins.
+// Paths stored in mod files relative to the mod directory (local replaces and bin directories outside of it) are rewritten
+// relative to the new location, and symbolic links pointing into the old directory (e.g in local bin directory or shims)
+// are pointed into the new one. Given files (relative to mod directory, e.g project configuration file) stay in place.
+// Generated helpers are not regenerated.
+func MigrateModDir(from, to string, keep ...string) error {
+	if fi, err := os.Stat(from); err != nil {
+		return errors.Wrapf(err, "mod directory %v", from)
+	} else if !fi.IsDir() {
+		return errors.Errorf("mod directory %v is not a directory", from)
+	}
+	if _, err := os.Stat(to); err == nil {
+		return errors.Errorf("%v already exists; remove it first or choose other directory", to)
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+	if within(from, to) {
+		return errors.Errorf("cannot move mod directory %v into itself (%v)", from, to)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(to), os.ModePerm); err != nil {
+		return errors.Wrap(err, "create parent directory")
+	}
+	if err := os.Rename(from, to); err != nil {
+		return errors.Wrap(err, "move mod directory")
+	}
+	for _, k := range keep {
+		if _, err := os.Stat(filepath.Join(to, k)); os.IsNotExist(err) {
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(filepath.Join(from, k)), os.ModePerm); err != nil {
+			return err
+		}
+		if err := os.Rename(filepath.Join(to, k), filepath.Join(from, k)); err != nil {
+			return errors.Wrapf(err, "keep %v", k)
+		}
+	}
+
+	if err := CleanTmpFiles(to); err != nil {
+		return err
+	}
+	modFiles, err := filepath.Glob(filepath.Join(to, "*.mod"))
+	if err != nil {
+		return err
+	}
+	for _, f := range modFiles {
+		if filepath.Base(f) == "go.mod" {
+			continue
+		}
+		if err := rebaseModFile(f, from, to); err != nil {
+			return errors.Wrap(err, f)
+		}
+	}
+	if err := filepath.Walk(to, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.Mode()&os.ModeSymlink == 0 {
+			return err
+		}
+		target, err := os.Readlink(path)
+		if err != nil || !filepath.IsAbs(target) || !within(from, target) {
+			return err
+		}
+		rel, err := filepath.Rel(from, target)
+		if err != nil {
+			return err
+		}
+		if err := os.Remove(path); err != nil {
+			return err
+		}
+		return os.Symlink(filepath.Join(to, rel), path)
+	}); err != nil {
+		return errors.Wrap(err, "relink")
+	}
+	return nil
+}
+
+// rebaseModFile rewrites relative local replaces and bin directory of given mod file moved from given mod directory to
+// the new one, unless they point within the mod directory itself, which moved as well.
+func rebaseModFile(modFile, from, to string) (err error) {
+	mf, err := bingo.OpenModFile(modFile)
+	if err != nil {
+		return err
+	}
+	defer errcapture.Do(&err, mf.Close, "close")
+
+	for _, r := range mf.LocalReplaces() {
+		dir := filepath.FromSlash(r.New.Path)
+		if filepath.IsAbs(dir) || within(from, filepath.Join(from, dir)) {
+			continue
+		}
+		rel, err := relReplaceDir(to, filepath.Join(from, dir))
+		if err != nil {
+			return err
+		}
+		if err := mf.SetLocalReplace(r.Old.Path, r.Old.Version, rel); err != nil {
+			return err
+		}
+	}
+	if binDir, _ := mf.Command(bingo.BinDirCommand); binDir != "" && !filepath.IsAbs(filepath.FromSlash(binDir)) {
+		if dir := filepath.Join(from, filepath.FromSlash(binDir)); !within(from, dir) {
+			rel, err := filepath.Rel(to, dir)
+			if err != nil {
+				return err
+			}
+			mf.SetCommand(bingo.BinDirCommand, filepath.ToSlash(rel))
+		}
+	}
+	return nil
+}
+
+// within returns true if given path is given directory or is inside it.
+func within(dir, path string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
@@ -0,0 +1,108 @@
+// Copyright (c) Bartłomiej Płotka @bwplotka
+// Licensed under the Apache License 2.0.
+
+package getter
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/bwplotka/bingo/pkg/bingo"
+	"github.com/bwplotka/bingo/pkg/envars"
+	"github.com/bwplotka/bingo/pkg/runner"
+	"github.com/efficientgo/tools/core/pkg/errcapture"
+	"github.com/pkg/errors"
+	"golang.org/x/mod/module"
+)
+
+// buildModules returns all modules (including the tool's own one) providing packages built into the tool pinned in given
+// mod file. Listing can change mod file, so it has to be a temporary one.
+func buildModules(ctx context.Context, r *runner.Runner, modDir, modFile string, pkg *bingo.Package, env envars.EnvSlice) ([]module.Version, error) {
+	args := append(append([]string{}, pkg.BuildFlags...), "-mod=mod", "-deps",
+		"-f={{with .Module}}{{.Path}} {{.Version}}{{end}}", pkg.Path())
+	out, err := r.With(ctx, modFile, modDir, env).List(runner.NoUpdatePolicy, args...)
+	if err != nil {
+		return nil, errors.Wrap(err, "list dependencies")
+	}
+	seen := map[string]struct{}{}
+	var mods []module.Version
+	for _, l := range strings.Split(out, "\n") {
+		f := strings.Fields(l)
+		if len(f) != 2 {
+			continue
+		}
+		if _, ok := seen[f[0]]; ok {
+			continue
+		}
+		seen[f[0]] = struct{}{}
+		mods = append(mods, module.Version{Path: f[0], Version: f[1]})
+	}
+	return mods, nil
+}
+
+// checkModulePolicy returns error if any module built into the tool pinned in given (temporary) mod file violates given
+// policy, except modules documented with bingo.PolicyExceptionCommand in the mod file.
+func checkModulePolicy(ctx context.Context, r *runner.Runner, modDir string, p bingo.ModulePolicy, modFile *bingo.ModFile, env envars.EnvSlice) error {
+	violations, err := modulePolicyViolations(ctx, r, modDir, p, modFile.FileName(), modFile, env)
+	if err != nil {
+		return err
+	}
+	if len(violations) == 0 {
+		return nil
+	}
+	names := make([]string, 0, len(violations))
+	for _, v := range violations {
+		names = append(names, v.String())
+	}
+	return newSentinelError(bingo.ErrPolicyViolation, "%s depends on modules disallowed by module policy: %s",
+		modFile.DirectPackage().String(), strings.Join(names, ", "))
+}
+
+func modulePolicyViolations(ctx context.Context, r *runner.Runner, modDir string, p bingo.ModulePolicy, listModFile string, modFile *bingo.ModFile, env envars.EnvSlice) ([]bingo.PolicyViolation, error) {
+	var exceptions []string
+	if arg, ok := modFile.Command(bingo.PolicyExceptionCommand); ok {
+		var err error
+		if exceptions, _, err = bingo.ParsePolicyExceptions(arg); err != nil {
+			return nil, err
+		}
+	}
+	mods, err := buildModules(ctx, r, modDir, listModFile, modFile.DirectPackage(), env)
+	if err != nil {
+		return nil, err
+	}
+	return p.Check(mods, exceptions), nil
+}
+
+// ModulePolicyViolations returns modules built into the tool pinned in given mod file that violate given policy, except
+// modules documented with bingo.PolicyExceptionCommand in the mod file. Mod file is not changed.
+func ModulePolicyViolations(ctx context.Context, r *runner.Runner, modDir string, p bingo.ModulePolicy, modFile string) (_ []bingo.PolicyViolation, err error) {
+	mf, err := bingo.ReadModFile(modFile)
+	if err != nil {
+		return nil, err
+	}
+	if mf.DirectPackage() == nil {
+		return nil, errors.Errorf("no tool pinned in %v", modFile)
+	}
+
+	b, err := ioutil.ReadFile(modFile)
+	if err != nil {
+		return nil, err
+	}
+	tmpModFile := strings.TrimSuffix(modFile, ".mod") + ".policy.tmp.mod"
+	if err := ioutil.WriteFile(tmpModFile, b, os.ModePerm); err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = os.Remove(strings.TrimSuffix(tmpModFile, ".mod") + ".sum")
+		errcapture.Do(&err, func() error { return os.Remove(tmpModFile) }, "remove tmp mod file")
+	}()
+
+	var env envars.EnvSlice
+	if goFlags, _ := mf.Command(bingo.GoFlagsCommand); goFlags != "" {
+		env = envars.EnvSlice{"GOFLAGS=" + goFlags}
+	}
+	env = append(env, mf.DirectPackage().BuildEnvs...)
+	return modulePolicyViolations(ctx, r, modDir, p, tmpModFile, mf, env)
+}
@@ -0,0 +1,123 @@
+// Copyright (c) Bartłomiej Płotka @bwplotka
+// Licensed under the Apache License 2.0.
+
+package getter
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/bwplotka/bingo/pkg/bingo"
+	"github.com/bwplotka/bingo/pkg/logging"
+	"github.com/pkg/errors"
+)
+
+// Rename renames tool pinned under given name to given new name without rebuilding it: its mod files and recorded build
+// stats are moved and installed binaries in gobin (or the tool's bin directory) are renamed. Link of the old name (get -l)
+// is removed and the new name is linked in given mode instead; symbolic links of aliases are pointed at renamed binaries.
+// If gitignore is true, renamed binaries in bin directory within the project are ignored, as get does.
+func Rename(logger logging.Logger, modDir, gobin string, linkMode LinkMode, gitignore bool, name, newName string) error {
+	if newName == name {
+		return errors.Errorf("new name cannot be the same as the current one %v", name)
+	}
+	if err := validateTargetName(newName); err != nil {
+		return err
+	}
+	newExisting, err := existingModFiles(modDir, newName)
+	if err != nil {
+		return errors.Wrapf(err, "existing mod files for %v", newName)
+	}
+	if len(newExisting) > 0 {
+		return errors.Errorf("tool %v is already pinned (%v); remove it with 'bingo get %v@none' first or use different name", newName, strings.Join(newExisting, ","), newName)
+	}
+	modFiles, err := existingModFiles(modDir, name)
+	if err != nil {
+		return errors.Wrapf(err, "existing mod files for %v", name)
+	}
+	if len(modFiles) == 0 {
+		return newSentinelError(bingo.ErrNotInstalled, "nothing to rename, tool %v is not installed%s", name, didYouMean(logger, modDir, name))
+	}
+
+	var (
+		mf  *bingo.ModFile
+		dir string
+		// renamed maps names of previous binaries to paths of renamed ones.
+		renamed = map[string]string{}
+		latest  string
+	)
+	for _, f := range modFiles {
+		mf, err = bingo.ReadModFile(f)
+		if err != nil {
+			return errors.Wrapf(err, "read %v", f)
+		}
+		pkg := mf.DirectPackage()
+		if pkg == nil {
+			return errors.Errorf("no direct package found in %v", f)
+		}
+		binDir, _ := mf.Command(bingo.BinDirCommand)
+		dir = bingo.ToolBinDir(modDir, gobin, binDir)
+
+		from := filepath.Join(dir, bingo.ArtifactName(name, pkg.Module.Version, pkg.BuildEnvs))
+		latest = filepath.Join(dir, bingo.ArtifactName(newName, pkg.Module.Version, pkg.BuildEnvs))
+		if err := os.Rename(from, latest); err != nil && !os.IsNotExist(err) {
+			return errors.Wrap(err, "rename binary")
+		}
+		renamed[filepath.Base(from)] = latest
+		if err := bingo.RenameBuildStats(modDir, name, newName, pkg.Module.Version); err != nil {
+			return errors.Wrap(err, "rename build stats")
+		}
+		// Keep array index, if any, e.g tool.1.mod.
+		if err := os.Rename(f, filepath.Join(modDir, newName+strings.TrimPrefix(filepath.Base(f), name))); err != nil {
+			return errors.Wrap(err, "rename mod file")
+		}
+	}
+
+	if !bingo.IsWasm(mf.DirectPackage().BuildEnvs) {
+		if err := relink(logger, linkMode, dir, name, newName, renamed, latest); err != nil {
+			return err
+		}
+		arg, _ := mf.Command(bingo.AliasesCommand)
+		aliases, err := bingo.ParseAliases(arg)
+		if err != nil {
+			return errors.Wrap(err, "aliases")
+		}
+		for _, a := range aliases {
+			if linked, ok := linkedBinary(filepath.Join(dir, a+bingo.GOEXE())); ok {
+				if to, ok := renamed[filepath.Base(linked)]; ok {
+					if err := LinkBinary(SymlinkLinkMode, to, filepath.Join(dir, a)); err != nil {
+						return errors.Wrapf(err, "link alias %v", a)
+					}
+				}
+			}
+		}
+	}
+	if binDir, _ := mf.Command(bingo.BinDirCommand); binDir != "" && gitignore {
+		if err := ignoreInstalled(modDir, dir, newName, mf); err != nil {
+			return errors.Wrap(err, "update .gitignore in bin dir")
+		}
+	}
+	logger.Info("renamed tool", "from", name, "to", newName)
+	return nil
+}
+
+// relink replaces link of given name in given directory, if any, by link of given new name in given mode. New link points
+// to renamed binary the previous link pointed to, if known, or to given latest binary otherwise. Links pointing to binaries
+// not pinned by the tool (e.g by other mod directory in monorepo) are left untouched.
+func relink(logger logging.Logger, mode LinkMode, dir, name, newName string, renamed map[string]string, latest string) error {
+	path := filepath.Join(dir, name)
+	if !linkExists(path) {
+		return nil
+	}
+	to := latest
+	if linked, ok := linkedBinary(path + bingo.GOEXE()); ok {
+		if to, ok = renamed[filepath.Base(linked)]; !ok {
+			logger.Warn("link points to binary not pinned by renamed tool, leaving it", "link", name, "binary", filepath.Base(linked))
+			return nil
+		}
+	}
+	if err := removeLink(path); err != nil {
+		return err
+	}
+	return errors.Wrap(LinkBinary(mode, to, filepath.Join(dir, newName)), "link")
+}
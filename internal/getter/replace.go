@@ -0,0 +1,109 @@
+// Copyright (c) Bartłomiej Płotka @bwplotka
+// Licensed under the Apache License 2.0.
+
+package getter
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/bwplotka/bingo/pkg/bingo"
+	"github.com/pkg/errors"
+	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/module"
+)
+
+// localReplaceVersion is a version required for module replaced with local directory, if no version is pinned. It's
+// the same version go uses for such modules.
+const localReplaceVersion = "v0.0.0-00010101000000-000000000000"
+
+// localReplaces returns replaces with local directories from given existing mod file (if any), overridden by given
+// ones (-replace flag). Directories of given replaces are relative to the current directory (project root) and are
+// returned relative to the mod directory, so committed mod file works for every contributor. It also returns version
+// pinned in the existing mod file, if any.
+func localReplaces(modFile, modDir string, given []*modfile.Replace) (_ []*modfile.Replace, pinned string, _ error) {
+	var replaces []*modfile.Replace
+	if mf, err := bingo.ReadModFile(modFile); err == nil {
+		replaces = mf.LocalReplaces()
+		if p := mf.DirectPackage(); p != nil {
+			pinned = p.Module.Version
+		}
+	} else if !os.IsNotExist(err) {
+		return nil, "", errors.Wrapf(err, "read %v", modFile)
+	}
+
+	for _, r := range given {
+		dir, err := filepath.Abs(r.New.Path)
+		if err != nil {
+			return nil, "", err
+		}
+		if _, err := os.Stat(filepath.Join(dir, "go.mod")); err != nil {
+			return nil, "", errors.Wrapf(err, "replace %v with %v: directory with go.mod expected", r.Old.Path, r.New.Path)
+		}
+		rel, err := relReplaceDir(modDir, dir)
+		if err != nil {
+			return nil, "", err
+		}
+		replaces = append(replaces, &modfile.Replace{Old: r.Old, New: module.Version{Path: rel}})
+	}
+	return replaces, pinned, nil
+}
+
+// relReplaceDir returns given absolute directory relative to the mod directory, in the form expected by replace
+// statement (e.g "../foo").
+func relReplaceDir(modDir, dir string) (string, error) {
+	rel, err := filepath.Rel(modDir, dir)
+	if err != nil {
+		return "", errors.Wrapf(err, "%v relative to %v", dir, modDir)
+	}
+	rel = filepath.ToSlash(rel)
+	if !strings.HasPrefix(rel, "../") && rel != ".." {
+		rel = "./" + rel
+	}
+	return rel, nil
+}
+
+// locallyReplaced returns the replace of the module containing package of given target (the one with the longest
+// module path, if many, the last one if the same), if any.
+func locallyReplaced(target bingo.Package, replaces []*modfile.Replace) *modfile.Replace {
+	var found *modfile.Replace
+	for _, r := range replaces {
+		if target.Module.Path != "" && target.Module.Path != r.Old.Path {
+			continue
+		}
+		if p := target.Path(); p != r.Old.Path && !strings.HasPrefix(p, r.Old.Path+"/") {
+			continue
+		}
+		if found == nil || len(r.Old.Path) >= len(found.Old.Path) {
+			found = r
+		}
+	}
+	return found
+}
+
+// localReplaceStatements is like autoFetchReplaceStatements, but for module replaced with local directory (relative
+// to the mod directory). Replaces with local directories in its go.mod are rebased to the mod directory.
+func localReplaceStatements(modDir, dir string) ([]*modfile.Replace, []*modfile.Exclude, error) {
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(modDir, dir)
+	}
+	goMod := filepath.Join(dir, "go.mod")
+	parsed, err := bingo.ParseModFileOrReader(goMod, nil)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "parse local mod file %v", goMod)
+	}
+
+	replaces := make([]*modfile.Replace, 0, len(parsed.Replace))
+	for _, r := range parsed.Replace {
+		if bingo.IsLocalReplace(r) && !filepath.IsAbs(r.New.Path) {
+			rel, err := relReplaceDir(modDir, filepath.Join(dir, r.New.Path))
+			if err != nil {
+				return nil, nil, err
+			}
+			r = &modfile.Replace{Old: r.Old, New: module.Version{Path: rel}}
+		}
+		replaces = append(replaces, r)
+	}
+	return replaces, parsed.Exclude, nil
+}
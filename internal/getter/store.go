@@ -0,0 +1,85 @@
+// Copyright (c) Bartłomiej Płotka @bwplotka
+// Licensed under the Apache License 2.0.
+
+package getter
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/bwplotka/bingo/pkg/bingo"
+	"github.com/bwplotka/bingo/pkg/runner"
+	"github.com/pkg/errors"
+	"golang.org/x/mod/modfile"
+)
+
+// DefaultStoreDir returns default directory of user-level store of built binaries: $XDG_DATA_HOME/bingo/store or
+// ~/.local/share/bingo/store if XDG_DATA_HOME is not set.
+func DefaultStoreDir() (string, error) {
+	if d := os.Getenv("XDG_DATA_HOME"); d != "" {
+		return filepath.Join(d, "bingo", "store"), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".local", "share", "bingo", "store"), nil
+}
+
+// storeBinPath returns path of binary built from given (tidied) mod file in given store directory. Binaries are stored
+// per platform and keyed by hash of build inputs only: required modules with versions, replaces and excludes, build
+// flags, env vars and GOFLAGS (persisted and ambient ones) and Go version, so the same tool version built the same way
+// is stored once for all projects, no matter what comments or bingo commands its mod files have.
+func storeBinPath(store, name string, modFile *bingo.ModFile, goVersion string) (string, error) {
+	b, err := ioutil.ReadFile(modFile.FileName())
+	if err != nil {
+		return "", errors.Wrap(err, "read mod file")
+	}
+	m, err := modfile.Parse(modFile.FileName(), b, nil)
+	if err != nil {
+		return "", errors.Wrap(err, "parse mod file")
+	}
+	pkg := modFile.DirectPackage()
+	goos, goarch := runtime.GOOS, runtime.GOARCH
+	if v := os.Getenv("GOOS"); v != "" {
+		goos = v
+	}
+	if v := os.Getenv("GOARCH"); v != "" {
+		goarch = v
+	}
+	if v, ok := pkg.BuildEnvs.Lookup("GOOS"); ok {
+		goos = v
+	}
+	if v, ok := pkg.BuildEnvs.Lookup("GOARCH"); ok {
+		goarch = v
+	}
+
+	h := sha256.New()
+	write := func(s ...string) { _, _ = fmt.Fprintln(h, strings.Join(s, " ")) }
+	write("package", pkg.Path())
+	for _, r := range m.Require {
+		write("require", r.Mod.Path, r.Mod.Version)
+	}
+	for _, r := range m.Replace {
+		write("replace", r.Old.Path, r.Old.Version, r.New.Path, r.New.Version)
+	}
+	for _, e := range m.Exclude {
+		write("exclude", e.Mod.Path, e.Mod.Version)
+	}
+	write("flags", strings.Join(pkg.BuildFlags, " "))
+	write("envs", strings.Join(pkg.BuildEnvs, " "))
+	goFlags, _ := modFile.Command(bingo.GoFlagsCommand)
+	write("goflags", goFlags)
+	// Runner strips the same ambient GOFLAGS, so only flags that are actually used matter.
+	ambientGoFlags, _ := runner.SanitizeGoFlags(os.Getenv("GOFLAGS"))
+	write("ambient goflags", ambientGoFlags)
+	write("go", goVersion)
+	key := hex.EncodeToString(h.Sum(nil))[:16]
+	return filepath.Join(store, goos+"_"+goarch, name+"-"+pkg.Module.Version+"-"+key+bingo.GOEXE()), nil
+}
@@ -0,0 +1,235 @@
+// Copyright (c) Bartłomiej Płotka @bwplotka
+// Licensed under the Apache License 2.0.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/bwplotka/bingo/pkg/bingo"
+	"github.com/bwplotka/bingo/pkg/envars"
+	"github.com/bwplotka/bingo/pkg/runner"
+	"github.com/pkg/errors"
+)
+
+// unknownLicense is reported for modules without license file or with license bingo does not recognize.
+const unknownLicense = "unknown"
+
+// knownLicenses are SPDX identifiers of licenses recognized by bingo licenses, with phrases identifying them in license
+// file text (whitespace normalized, case insensitive). More specific ones go first.
+var knownLicenses = []struct {
+	id string
+	re *regexp.Regexp
+}{
+	{id: "AGPL-3.0", re: regexp.MustCompile(`(?i)gnu affero general public license`)},
+	{id: "LGPL-3.0", re: regexp.MustCompile(`(?i)gnu lesser general public license version 3`)},
+	{id: "LGPL-2.1", re: regexp.MustCompile(`(?i)gnu lesser general public license version 2\.1`)},
+	{id: "GPL-3.0", re: regexp.MustCompile(`(?i)gnu general public license version 3`)},
+	{id: "GPL-2.0", re: regexp.MustCompile(`(?i)gnu general public license version 2`)},
+	{id: "MPL-2.0", re: regexp.MustCompile(`(?i)mozilla public license,? (?:v\. |version )?2\.0`)},
+	{id: "Apache-2.0", re: regexp.MustCompile(`(?i)apache license,? version 2\.0`)},
+	{id: "BSD-3-Clause", re: regexp.MustCompile(`(?i)redistributions in binary form must reproduce.* neither the name of`)},
+	{id: "BSD-2-Clause", re: regexp.MustCompile(`(?i)redistributions in binary form must reproduce`)},
+	{id: "MIT", re: regexp.MustCompile(`(?i)permission is hereby granted, free of charge`)},
+	{id: "ISC", re: regexp.MustCompile(`(?i)permission to use, copy, modify, and(?:/or)? distribute this software for any purpose`)},
+	{id: "Unlicense", re: regexp.MustCompile(`(?i)this is free and unencumbered software released into the public domain`)},
+	{id: "CC0-1.0", re: regexp.MustCompile(`(?i)cc0 1\.0 universal`)},
+}
+
+// detectLicenses returns SPDX identifiers of licenses found in license files (LICENSE*, LICENCE*, COPYING*) in given
+// module directory, sorted, or unknownLicense if there are none or none is recognized.
+func detectLicenses(dir string) ([]string, error) {
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	found := map[string]struct{}{}
+	for _, f := range files {
+		n := strings.ToUpper(f.Name())
+		if f.IsDir() || !strings.HasPrefix(n, "LICENSE") && !strings.HasPrefix(n, "LICENCE") && !strings.HasPrefix(n, "COPYING") {
+			continue
+		}
+		b, err := ioutil.ReadFile(filepath.Join(dir, f.Name()))
+		if err != nil {
+			return nil, err
+		}
+		text := strings.Join(strings.Fields(string(b)), " ")
+		for _, l := range knownLicenses {
+			if l.re.MatchString(text) {
+				found[l.id] = struct{}{}
+				break
+			}
+		}
+	}
+	if len(found) == 0 {
+		return []string{unknownLicense}, nil
+	}
+	ids := make([]string, 0, len(found))
+	for id := range found {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids, nil
+}
+
+// licensePolicy defines which licenses dependencies of pinned tools may use.
+type licensePolicy struct {
+	// Allow lists allowed licenses. If empty, all licenses except denied ones are allowed.
+	Allow []string
+	// Deny lists disallowed licenses.
+	Deny []string
+	// Exceptions are modules (<module> or <module>@<version>) exempt from the policy.
+	Exceptions []string
+}
+
+// parseLicensePolicy parses license policy file with 'allow', 'deny' and 'exceptions' lists (see bingo.ParseYAMLLists), e.g:
+//
+//	allow:
+//	  - MIT
+//	  - Apache-2.0
+//	exceptions:
+//	  - github.com/hashicorp/hcl # MPL-2.0, approved by legal.
+func parseLicensePolicy(r io.Reader) (licensePolicy, error) {
+	lists, err := bingo.ParseYAMLLists(r, "allow", "deny", "exceptions")
+	if err != nil {
+		return licensePolicy{}, err
+	}
+	return licensePolicy{Allow: lists["allow"], Deny: lists["deny"], Exceptions: lists["exceptions"]}, nil
+}
+
+// License policy check results.
+const (
+	licenseAllowed   = "allowed"
+	licenseDenied    = "denied"
+	licenseException = "exception"
+)
+
+// check returns result of checking given module version with given licenses against the policy. Modules with many
+// licenses (e.g dual licensed) are allowed if any of them is allowed.
+func (p licensePolicy) check(module, version string, licenses []string) string {
+	for _, e := range p.Exceptions {
+		if e == module || e == module+"@"+version {
+			return licenseException
+		}
+	}
+	for _, l := range licenses {
+		if containsFold(p.Deny, l) {
+			continue
+		}
+		if len(p.Allow) == 0 || containsFold(p.Allow, l) {
+			return licenseAllowed
+		}
+	}
+	return licenseDenied
+}
+
+func containsFold(list []string, s string) bool {
+	for _, l := range list {
+		if strings.EqualFold(l, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// moduleLicense is a module built into pinned tool version, with its licenses, printed by bingo licenses.
+type moduleLicense struct {
+	Tool        string   `json:"tool"`
+	ToolVersion string   `json:"toolVersion"`
+	Module      string   `json:"module"`
+	Version     string   `json:"version"`
+	Licenses    []string `json:"licenses"`
+	// Status is a result of license policy check (allowed, denied or exception), empty if no policy was given.
+	Status string `json:"status,omitempty"`
+}
+
+// collectLicenses returns licenses of all modules (including the tool's own one) built into each version of given tools, as
+// listed by go list for the tool's package with its build flags and env variables.
+func collectLicenses(ctx context.Context, r *runner.Runner, modDir string, pkgs bingo.PackageRenderables) ([]moduleLicense, error) {
+	var ret []moduleLicense
+	for _, p := range pkgs {
+		env := envars.EnvSlice(p.BuildEnvVars)
+		if p.GoFlags != "" {
+			env = append(env, "GOFLAGS="+p.GoFlags)
+		}
+		for _, v := range p.Versions {
+			args := append(append([]string{}, p.BuildFlags...), "-mod=mod", "-deps",
+				"-f={{with .Module}}{{if not .Main}}{{.Path}} {{.Version}} {{.Dir}}{{end}}{{end}}", p.PackagePath)
+			out, err := r.With(ctx, filepath.Join(modDir, v.ModFile), modDir, env).List(runner.NoUpdatePolicy, args...)
+			if err != nil {
+				return nil, errors.Wrapf(err, "list modules of %s@%s", p.Name, v.Version)
+			}
+
+			seen := map[string]struct{}{}
+			var mods []moduleLicense
+			for _, l := range strings.Split(out, "\n") {
+				f := strings.SplitN(strings.TrimSpace(l), " ", 3)
+				if len(f) != 3 {
+					continue
+				}
+				if _, ok := seen[f[0]]; ok {
+					continue
+				}
+				seen[f[0]] = struct{}{}
+				licenses, err := detectLicenses(f[2])
+				if err != nil {
+					return nil, errors.Wrapf(err, "detect licenses of %s", f[0])
+				}
+				mods = append(mods, moduleLicense{Tool: p.Name, ToolVersion: v.Version, Module: f[0], Version: f[1], Licenses: licenses})
+			}
+			sort.Slice(mods, func(i, j int) bool { return mods[i].Module < mods[j].Module })
+			ret = append(ret, mods...)
+		}
+	}
+	return ret, nil
+}
+
+// applyLicensePolicy sets Status of given modules and returns those violating the policy.
+func applyLicensePolicy(p licensePolicy, mods []moduleLicense) (violations []moduleLicense) {
+	for i, m := range mods {
+		mods[i].Status = p.check(m.Module, m.Version, m.Licenses)
+		if mods[i].Status == licenseDenied {
+			violations = append(violations, mods[i])
+		}
+	}
+	return violations
+}
+
+// printLicenses prints modules with their licenses as aligned table or, if asJSON is true, as JSON.
+func printLicenses(w io.Writer, mods []moduleLicense, asJSON bool) error {
+	if asJSON {
+		if mods == nil {
+			mods = []moduleLicense{}
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(mods)
+	}
+
+	withStatus := len(mods) > 0 && mods[0].Status != ""
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	if withStatus {
+		_, _ = fmt.Fprintln(tw, "Tool\tModule\tVersion\tLicense\tStatus")
+		_, _ = fmt.Fprintln(tw, "----\t------\t-------\t-------\t------")
+	} else {
+		_, _ = fmt.Fprintln(tw, "Tool\tModule\tVersion\tLicense")
+		_, _ = fmt.Fprintln(tw, "----\t------\t-------\t-------")
+	}
+	for _, m := range mods {
+		fields := []string{m.Tool + "@" + m.ToolVersion, m.Module, m.Version, strings.Join(m.Licenses, " OR ")}
+		if withStatus {
+			fields = append(fields, m.Status)
+		}
+		_, _ = fmt.Fprintln(tw, strings.Join(fields, "\t"))
+	}
+	return errors.Wrap(tw.Flush(), "flush")
+}
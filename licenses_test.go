@@ -0,0 +1,151 @@
+// Copyright (c) Bartłomiej Płotka @bwplotka
+// Licensed under the Apache License 2.0.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/bwplotka/bingo/pkg/bingo"
+	"github.com/bwplotka/bingo/pkg/runner"
+	"github.com/efficientgo/tools/core/pkg/testutil"
+)
+
+func TestDetectLicenses(t *testing.T) {
+	for _, tcase := range []struct {
+		name     string
+		files    map[string]string
+		expected []string
+	}{
+		{name: "no license", expected: []string{unknownLicense}},
+		{name: "unrecognized", files: map[string]string{"LICENSE": "All rights reserved."}, expected: []string{unknownLicense}},
+		{
+			name:     "MIT",
+			files:    map[string]string{"LICENSE": "MIT License\n\nPermission is hereby granted, free of charge, to any person\nobtaining a copy"},
+			expected: []string{"MIT"},
+		},
+		{
+			name: "BSD-3-Clause",
+			files: map[string]string{"LICENSE.md": "* Redistributions in binary form must reproduce the above copyright\n" +
+				"  notice.\n* Neither the name of Google Inc. nor the names of its contributors"},
+			expected: []string{"BSD-3-Clause"},
+		},
+		{
+			name: "dual licensed",
+			files: map[string]string{
+				"LICENSE-APACHE": "Apache License\n                           Version 2.0, January 2004",
+				"LICENSE-MIT":    "Permission is hereby granted, free of charge",
+				"README.md":      "GNU AFFERO GENERAL PUBLIC LICENSE",
+			},
+			expected: []string{"Apache-2.0", "MIT"},
+		},
+		{
+			name:     "GPL-3.0 in COPYING",
+			files:    map[string]string{"COPYING": "GNU GENERAL PUBLIC LICENSE\n   Version 3, 29 June 2007"},
+			expected: []string{"GPL-3.0"},
+		},
+	} {
+		t.Run(tcase.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for f, c := range tcase.files {
+				testutil.Ok(t, ioutil.WriteFile(filepath.Join(dir, f), []byte(c), os.ModePerm))
+			}
+			got, err := detectLicenses(dir)
+			testutil.Ok(t, err)
+			testutil.Equals(t, tcase.expected, got)
+		})
+	}
+}
+
+func TestParseLicensePolicy(t *testing.T) {
+	p, err := parseLicensePolicy(strings.NewReader(`# Org-wide policy.
+allow:
+  - MIT
+  - "Apache-2.0" # Most of Go modules.
+deny: [GPL-3.0, 'AGPL-3.0']
+exceptions:
+  - github.com/hashicorp/hcl@v1.0.0 # MPL-2.0, approved by legal.
+`))
+	testutil.Ok(t, err)
+	testutil.Equals(t, licensePolicy{
+		Allow:      []string{"MIT", "Apache-2.0"},
+		Deny:       []string{"GPL-3.0", "AGPL-3.0"},
+		Exceptions: []string{"github.com/hashicorp/hcl@v1.0.0"},
+	}, p)
+
+	for _, malformed := range []string{"- MIT\n", "licenses:\n  - MIT\n", "allow: MIT\n", "allow\n"} {
+		_, err := parseLicensePolicy(strings.NewReader(malformed))
+		testutil.NotOk(t, err, malformed)
+	}
+}
+
+func TestLicensePolicy_Check(t *testing.T) {
+	p := licensePolicy{Allow: []string{"MIT", "Apache-2.0"}, Deny: []string{"MIT"}, Exceptions: []string{"github.com/a/gpl", "github.com/a/lgpl@v1.0.0"}}
+	testutil.Equals(t, licenseAllowed, p.check("github.com/a/b", "v1.0.0", []string{"apache-2.0"}))
+	testutil.Equals(t, licenseDenied, p.check("github.com/a/b", "v1.0.0", []string{"MIT"}))
+	testutil.Equals(t, licenseAllowed, p.check("github.com/a/b", "v1.0.0", []string{"Apache-2.0", "MIT"}))
+	testutil.Equals(t, licenseDenied, p.check("github.com/a/b", "v1.0.0", []string{unknownLicense}))
+	testutil.Equals(t, licenseException, p.check("github.com/a/gpl", "v2.0.0", []string{"GPL-3.0"}))
+	testutil.Equals(t, licenseException, p.check("github.com/a/lgpl", "v1.0.0", []string{"LGPL-3.0"}))
+	testutil.Equals(t, licenseDenied, p.check("github.com/a/lgpl", "v1.1.0", []string{"LGPL-3.0"}))
+
+	// Without allow list, everything except denied is allowed.
+	testutil.Equals(t, licenseAllowed, licensePolicy{Deny: []string{"GPL-3.0"}}.check("github.com/a/b", "v1.0.0", []string{unknownLicense}))
+}
+
+func TestCollectLicenses(t *testing.T) {
+	modDir := t.TempDir()
+	mitDir, gplDir := t.TempDir(), t.TempDir()
+	testutil.Ok(t, ioutil.WriteFile(filepath.Join(mitDir, "LICENSE"), []byte("Permission is hereby granted, free of charge"), os.ModePerm))
+	testutil.Ok(t, ioutil.WriteFile(filepath.Join(gplDir, "COPYING"), []byte("GNU GENERAL PUBLIC LICENSE Version 3"), os.ModePerm))
+
+	var listArgs []string
+	fakeGo := runner.ExecutorFunc(func(_ context.Context, output io.Writer, _ []string, _, _ string, args ...string) error {
+		switch args[0] {
+		case "version":
+			_, err := io.WriteString(output, "go version go1.21.0 linux/amd64\n")
+			return err
+		case "list":
+			listArgs = args
+			_, err := io.WriteString(output, "\n\ngithub.com/fatih/faillint v1.5.0 "+mitDir+"\ngithub.com/a/gpl v0.1.0 "+gplDir+"\ngithub.com/fatih/faillint v1.5.0 "+mitDir+"\n")
+			return err
+		}
+		return nil
+	})
+	ctx := context.Background()
+	r, err := runner.NewRunnerWithExecutor(ctx, nil, false, "go", fakeGo)
+	testutil.Ok(t, err)
+
+	mods, err := collectLicenses(ctx, r, modDir, bingo.PackageRenderables{{
+		Name:        "faillint",
+		PackagePath: "github.com/fatih/faillint",
+		BuildFlags:  []string{"-tags=netgo"},
+		Versions:    []bingo.PackageVersionRenderable{{Version: "v1.5.0", ModFile: "faillint.mod"}},
+	}})
+	testutil.Ok(t, err)
+	testutil.Equals(t, []string{"-tags=netgo", "-mod=mod", "-deps"}, listArgs[2:5])
+	testutil.Equals(t, "github.com/fatih/faillint", listArgs[len(listArgs)-1])
+	testutil.Equals(t, []moduleLicense{
+		{Tool: "faillint", ToolVersion: "v1.5.0", Module: "github.com/a/gpl", Version: "v0.1.0", Licenses: []string{"GPL-3.0"}},
+		{Tool: "faillint", ToolVersion: "v1.5.0", Module: "github.com/fatih/faillint", Version: "v1.5.0", Licenses: []string{"MIT"}},
+	}, mods)
+
+	violations := applyLicensePolicy(licensePolicy{Allow: []string{"MIT"}}, mods)
+	testutil.Equals(t, 1, len(violations))
+	testutil.Equals(t, "github.com/a/gpl", violations[0].Module)
+
+	b := &bytes.Buffer{}
+	testutil.Ok(t, printLicenses(b, mods, false))
+	testutil.Equals(t, `Tool             Module                     Version  License  Status
+----             ------                     -------  -------  ------
+faillint@v1.5.0  github.com/a/gpl           v0.1.0   GPL-3.0  denied
+faillint@v1.5.0  github.com/fatih/faillint  v1.5.0   MIT      allowed
+`, b.String())
+}
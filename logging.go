@@ -0,0 +1,128 @@
+// Copyright (c) Bartłomiej Płotka @bwplotka
+// Licensed under the Apache License 2.0.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/bwplotka/bingo/internal/getter"
+	"golang.org/x/term"
+)
+
+const (
+	textLogFormat = "text"
+	jsonLogFormat = "json"
+)
+
+// ANSI escape sequences used for colored text logs.
+const (
+	colorReset  = "\033[0m"
+	colorRed    = "\033[31m"
+	colorGreen  = "\033[32m"
+	colorYellow = "\033[33m"
+)
+
+// newLogger returns logger printing records with at least given level to given writer in given format.
+// Color applies only to text format.
+func newLogger(w io.Writer, format string, level slog.Level, color bool) *slog.Logger {
+	if format == jsonLogFormat {
+		return slog.New(slog.NewJSONHandler(w, &slog.HandlerOptions{Level: level}))
+	}
+	return slog.New(&textHandler{w: w, level: level, color: color, mu: &sync.Mutex{}})
+}
+
+// useColor returns true if output written to given file can be colored: it's a terminal and neither NO_COLOR
+// (see https://no-color.org) nor TERM=dumb is set.
+func useColor(f *os.File) bool {
+	if _, ok := os.LookupEnv("NO_COLOR"); ok || os.Getenv("TERM") == "dumb" {
+		return false
+	}
+	return term.IsTerminal(int(f.Fd()))
+}
+
+// textHandler is slog.Handler printing human readable records: message, followed by attributes as key=value pairs.
+// Warnings and errors are prefixed with "Warning:" and "Error:" respectively. Time and level are not printed.
+type textHandler struct {
+	w     io.Writer
+	level slog.Level
+	color bool
+	attrs []slog.Attr
+	group string
+
+	mu *sync.Mutex
+}
+
+func (h *textHandler) Enabled(_ context.Context, l slog.Level) bool { return l >= h.level }
+
+func (h *textHandler) Handle(_ context.Context, r slog.Record) error {
+	b := &strings.Builder{}
+	switch {
+	case r.Level >= slog.LevelError:
+		b.WriteString(h.colored(colorRed, "Error: "))
+	case r.Level >= slog.LevelWarn:
+		b.WriteString(h.colored(colorYellow, "Warning: "))
+	}
+	b.WriteString(r.Message)
+
+	writeAttr := func(a slog.Attr) {
+		if a.Equal(slog.Attr{}) {
+			return
+		}
+		if v, ok := a.Value.Any().(getter.VersionChange); ok {
+			_, _ = fmt.Fprintf(b, " %s=%s → %s", a.Key, h.colored(colorRed, v.From), h.colored(colorGreen, v.To))
+			return
+		}
+		_, _ = fmt.Fprintf(b, " %s=%v", a.Key, a.Value.Resolve())
+	}
+	for _, a := range h.attrs {
+		writeAttr(a)
+	}
+	r.Attrs(func(a slog.Attr) bool {
+		if h.group != "" {
+			a.Key = h.group + "." + a.Key
+		}
+		writeAttr(a)
+		return true
+	})
+	b.WriteString("\n")
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	_, err := io.WriteString(h.w, b.String())
+	return err
+}
+
+func (h *textHandler) colored(color, s string) string {
+	if !h.color {
+		return s
+	}
+	return color + s + colorReset
+}
+
+func (h *textHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	n := *h
+	n.attrs = append([]slog.Attr{}, h.attrs...)
+	for _, a := range attrs {
+		if h.group != "" {
+			a.Key = h.group + "." + a.Key
+		}
+		n.attrs = append(n.attrs, a)
+	}
+	return &n
+}
+
+func (h *textHandler) WithGroup(name string) slog.Handler {
+	n := *h
+	if n.group != "" {
+		name = n.group + "." + name
+	}
+	n.group = name
+	return &n
+}
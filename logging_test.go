@@ -0,0 +1,89 @@
+// Copyright (c) Bartłomiej Płotka @bwplotka
+// Licensed under the Apache License 2.0.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/bwplotka/bingo/internal/getter"
+	"github.com/efficientgo/tools/core/pkg/testutil"
+	"github.com/pkg/errors"
+)
+
+func TestTextLogger(t *testing.T) {
+	b := &bytes.Buffer{}
+	logger := newLogger(b, textLogFormat, slog.LevelInfo, false)
+
+	logger.Debug("not printed")
+	logger.Info("hello")
+	logger.With("tool", "faillint").Warn("not installed", "gobin", "/gobin")
+	logger.Error("boom")
+	testutil.Equals(t, "hello\nWarning: not installed tool=faillint gobin=/gobin\nError: boom\n", b.String())
+
+	b.Reset()
+	logger = newLogger(b, textLogFormat, slog.LevelDebug, false)
+	logger.With("tool", "faillint").Debug("phase finished", "phase", "install", "durationSeconds", 1.5)
+	testutil.Equals(t, "phase finished tool=faillint phase=install durationSeconds=1.5\n", b.String())
+}
+
+func TestTextLogger_Color(t *testing.T) {
+	b := &bytes.Buffer{}
+	logger := newLogger(b, textLogFormat, slog.LevelInfo, true)
+
+	logger.Info("version changed", "version", getter.VersionChange{From: "v1.4.0", To: "v1.5.0"})
+	logger.Warn("retracted")
+	logger.Error("boom")
+	testutil.Equals(t, "version changed version=\033[31mv1.4.0\033[0m → \033[32mv1.5.0\033[0m\n"+
+		"\033[33mWarning: \033[0mretracted\n"+
+		"\033[31mError: \033[0mboom\n", b.String())
+
+	b.Reset()
+	logger = newLogger(b, textLogFormat, slog.LevelInfo, false)
+	logger.Info("version changed", "version", getter.VersionChange{From: "v1.4.0", To: "v1.5.0"})
+	testutil.Equals(t, "version changed version=v1.4.0 → v1.5.0\n", b.String())
+
+	b.Reset()
+	logger = newLogger(b, jsonLogFormat, slog.LevelInfo, true)
+	logger.Info("version changed", "version", getter.VersionChange{From: "v1.4.0", To: "v1.5.0"})
+	testutil.Assert(t, strings.Contains(b.String(), `"version":{"from":"v1.4.0","to":"v1.5.0"}`), b.String())
+}
+
+func TestJSONLogger(t *testing.T) {
+	b := &bytes.Buffer{}
+	logger := newLogger(b, jsonLogFormat, slog.LevelDebug, false)
+
+	logger.Info("hello")
+	logger.Error("boom")
+	logger.With("tool", "faillint").Debug("phase finished", "phase", "install", "durationSeconds", 2.5)
+	logger.With("tool", "faillint").Debug("phase failed", "phase", "get", "durationSeconds", 0.5, "err", errors.New("install: exit 1"))
+
+	type record struct {
+		Level           string  `json:"level"`
+		Msg             string  `json:"msg"`
+		Tool            string  `json:"tool"`
+		Phase           string  `json:"phase"`
+		Err             string  `json:"err"`
+		DurationSeconds float64 `json:"durationSeconds"`
+	}
+	var records []record
+	for _, l := range strings.Split(strings.TrimSpace(b.String()), "\n") {
+		r := record{}
+		testutil.Ok(t, json.Unmarshal([]byte(l), &r))
+		if r.Phase == "install" {
+			testutil.Assert(t, r.DurationSeconds >= 2, "unexpected duration %v", r.DurationSeconds)
+		}
+		r.DurationSeconds = 0
+		records = append(records, r)
+	}
+	testutil.Equals(t, []record{
+		{Level: "INFO", Msg: "hello"},
+		{Level: "ERROR", Msg: "boom"},
+		{Level: "DEBUG", Msg: "phase finished", Tool: "faillint", Phase: "install"},
+		{Level: "DEBUG", Msg: "phase failed", Tool: "faillint", Phase: "get", Err: "install: exit 1"},
+	}, records)
+}
@@ -4,16 +4,22 @@
 package main
 
 import (
+	"bufio"
+	"bytes"
 	"context"
 	"flag"
 	"fmt"
-	"log"
+	"io"
+	"log/slog"
+	"net/http"
 	"os"
 	"path/filepath"
 	"regexp"
 	"strings"
 	"syscall"
+	"text/template"
 
+	"github.com/bwplotka/bingo/internal/getter"
 	"github.com/bwplotka/bingo/pkg/bingo"
 	"github.com/bwplotka/bingo/pkg/runner"
 	"github.com/bwplotka/bingo/pkg/version"
@@ -28,12 +34,68 @@ func exitOnUsageError(usage func(), v ...interface{}) {
 	os.Exit(1)
 }
 
+// exitCodeError is returned by command that finished successfully, but wants bingo to exit with given non-zero code.
+type exitCodeError struct {
+	code int
+	msg  string
+}
+
+func (e exitCodeError) Error() string { return e.msg }
+
+// remediation returns hint how to fix given error, if its cause is known.
+func remediation(err error) string {
+	var (
+		resolveErr *bingo.ResolveError
+		buildErr   *runner.BuildError
+	)
+	switch {
+	case errors.Is(err, bingo.ErrNotInstalled):
+		return "Run 'bingo list' to see pinned tools, or reference the tool by full package path to pin it."
+	case errors.Is(err, bingo.ErrNonMainPackage):
+		return "Reference a main package, usually a cmd/<tool> directory of the module."
+	case errors.Is(err, bingo.ErrNotApproved):
+		return "Pin version approved by registry, or ask registry maintainers to approve it; use -registry-mode=warn to only warn."
+	case errors.Is(err, bingo.ErrPolicyViolation):
+		return "Pin other version of the tool, or document approved exception with -policy-exception (e.g '<module>@<version> <reason>')."
+	case errors.As(err, &resolveErr) && resolveErr.ProxyChain != "":
+		return fmt.Sprintf("Check that %s exists; proxies of GOPROXY chain failing with other status than 404 or 410 stop the chain, so"+
+			" fix or remove them, or separate them with '|' to fall back on any error.", resolveErr.Module)
+	case errors.As(err, &resolveErr):
+		return fmt.Sprintf("Check that %s exists; for private modules set GOPRIVATE and make sure git can access them.", resolveErr.Module)
+	case errors.As(err, &buildErr):
+		return "Build of the tool failed; rerun with -v to see full build output. Build flags and env variables can be set in the tool's .mod file."
+	}
+	return ""
+}
+
 func main() {
-	logger := log.New(os.Stderr, "", 0)
+	// Logger is created once all flags are parsed, before any command runs.
+	var logger *slog.Logger
+	// status is set if progress can be rendered on terminal status line.
+	var status *statusLine
 
 	// Main flags.
 	flags := flag.NewFlagSet("bingo", flag.ContinueOnError)
 	verbose := flags.Bool("v", false, "Print more'")
+	quiet := flags.Bool("quiet", false, "Print nothing but errors (e.g for Makefile usage).")
+	debug := flags.Bool("debug", false, "Print even more than -v: exact go commands with extra env variables, working directory and timings.")
+	printCommands := flags.Bool("print-commands", false, "Print every go command before it runs, as shell command line with working directory,"+
+		" -modfile and environment variables differing from yours, without the rest of verbose logging, so failing step can be reproduced manually.")
+	logFormat := flags.String("log-format", textLogFormat, "Format of logs printed to stderr. One of: 'text', 'json' (one structured"+
+		" record per line with time, level, message and for tool phases also tool, phase and duration).")
+	noColor := flags.Bool("no-color", false, "Disable colored output. Colors are used only on terminals and never if NO_COLOR env variable is set.")
+	modDirOverride := flags.String("m", "", "Mod directory used by the command, overriding its -moddir flag, e.g 'bingo -m services/a/.bingo get'."+
+		" Useful in monorepos with per component mod directories.")
+	goVCS := flags.String("govcs", "", "GOVCS enforced for all go commands run by bingo, overriding environment, e.g 'github.com:git,*:off'"+
+		" to allow fetching only approved hosts directly. Set it in project configuration file to apply organisation rules to every contributor.")
+	goProxy := flags.String("goproxy", "", "GOPROXY enforced for all go commands run by bingo, overriding environment, e.g internal module proxy.")
+	goPrivate := flags.String("goprivate", "", "GOPRIVATE enforced for all go commands run by bingo, overriding environment.")
+	goInsecure := flags.String("goinsecure", "", "GOINSECURE enforced for all go commands run by bingo, overriding environment: comma separated"+
+		" module path patterns fetched without TLS verification (or over HTTP). Prefer -ca-file.")
+	caFile := flags.String("ca-file", "", "Path to PEM bundle of CA certificates trusted by go (SSL_CERT_FILE) and git (GIT_SSL_CAINFO) run by"+
+		" bingo, e.g for private module hosts and proxies using internal CA. Certificates are verified before any command runs. The bundle"+
+		" replaces system roots for go and git, so it has to contain public CAs too if public hosts (e.g proxy.golang.org) are used."+
+		" HTTP clients of bingo itself (e.g search, -registry, GOPROXY diagnostics) do not use it.")
 
 	// Get flags.
 	getFlags := flag.NewFlagSet("bingo get", flag.ContinueOnError)
@@ -45,24 +107,314 @@ func main() {
 		" bingo get will return error. If -n is used with existing binary name, copy of this binary will be done. Cannot be used with -r")
 	getRename := getFlags.String("r", "", "The -r flag instructs to get existing binary and rename it with given name."+
 		" Allowed characters [A-z0-9._-]. If -r is used and no package/binary is specified or non existing binary name is used, bingo"+
-		" will return error. Cannot be used with -n.")
+		" will return error. Cannot be used with -n. Tool is rebuilt; use bingo rename to rename it without rebuilding.")
 	goCmd := getFlags.String("go", "go", "Path to the go command.")
+	getTimeout := getFlags.Duration("timeout", getter.DefaultTimeout, "Time limit of the whole get, including fetching and building all tools.")
 	getUpdate := getFlags.Bool("u", false, "The -u flag instructs get to update modules providing dependencies of packages named on the command line to use newer minor or patch releases when available.")
 	getUpdatePatch := getFlags.Bool("upatch", false, "The -upatch flag (not -u patch) also instructs get to update dependencies, but changes the default to select patch releases.")
 
 	getInsecure := getFlags.Bool("insecure", false, "Use -insecure flag when using 'go get'")
+	getOfflineBuild := getFlags.Bool("offline-build", false, "Build tools with GOPROXY=off and -mod=readonly once their modules are resolved"+
+		" and downloaded, so any unexpected network access or resolution drift during build fails loudly. Useful for hermetic CI builds.")
+	getIsolateEnv := getFlags.Bool("isolate-env", false, "Run go commands with minimal environment: only HOME, PATH, GOPATH, GOMODCACHE, GOCACHE,"+
+		" module proxy (GOPROXY, GOPRIVATE, GONOSUMDB etc.) and HTTP proxy variables (plus those go needs on Windows) are passed, so builds"+
+		" are not affected by e.g CGO_CFLAGS or GOOS exported in your shell. Build environment variables and GOFLAGS set per tool still apply.")
 	getLink := getFlags.Bool("l", false, "If enabled, bingo will also create soft link called <tool> that links to the current"+
 		"<tool>-<version> binary. Use Variables.mk and variables.env if you want to be sure that what you are invoking is what is pinned.")
+	var getGOBINs []string
+	getFlags.Func("gobin", "Additional directory to install tools into, besides GOBIN (or <moddir>/bin). Can be specified multiple times,"+
+		" e.g '-gobin=./bin -gobin=/usr/local/bin'. Tools are built once and copied into each directory; -l links are created there as well.",
+		func(dir string) error {
+			if dir == "" {
+				return errors.New("empty directory")
+			}
+			getGOBINs = append(getGOBINs, dir)
+			return nil
+		})
+	getStore := getFlags.String("store", "", "Directory of user-level store of built binaries shared between projects, "+
+		"or 'default' for $XDG_DATA_HOME/bingo/store (~/.local/share/bingo/store). If set, each tool is built there once per module version, "+
+		"dependencies, build flags, Go version and platform, and linked (see -link-mode) into GOBIN as <tool>-<version>, so projects pinning the "+
+		"same version do not build and store it again. Defaults to BINGO_STORE env variable.")
+	getLinkMode := getFlags.String("link-mode", string(getter.AutoLinkMode), "Defines how -l links <tool> (and -store links <tool>-<version>) to the binary. One of: 'symlink', "+
+		"'hardlink', 'copy', 'shim' (<tool>.cmd script on Windows, shell script elsewhere) or 'auto' (first of those that works, e.g on Windows "+
+		"without developer mode, where symlinks are not allowed). On Windows, links get .exe extension.")
+
+	getDescription := getFlags.String("description", "", "Short description of what the tool is for, shown by bingo list. Stored as"+
+		" '// bingo:description <text>' comment in the tool's mod file. Requires single tool target; cannot be used with -selector.")
+
+	getAliases := getFlags.String("alias", "", "Comma separated extra names of the tool, e.g 'k' for kubectl, linked to the same binary"+
+		" by -l and activate, with their own variables in generated helpers. Replaces existing aliases; 'none' removes them. Stored as"+
+		" '// bingo:aliases <aliases>' comment in the tool's mod file.")
+	getGoFlags := getFlags.String("goflags", "", "Space separated GOFLAGS for go commands building the tool, e.g '-tags=netgo -trimpath'. Ambient GOFLAGS"+
+		" environment variable flags that break or change builds (e.g -mod, -tags) are ignored, so set them this way. 'none' removes them."+
+		" Stored as '// bingo:goflags <flags>' comment in the tool's mod file.")
+	getBinDir := getFlags.String("bindir", "", "Directory to install the tool into instead of GOBIN, relative to the current directory or absolute,"+
+		" for tools that have to live in a specific place (e.g protoc plugins next to protoc). 'none' removes it. Stored relative to"+
+		" mod directory as '// bingo:bindir <dir>' comment in the tool's mod file and used by generated helpers.")
+
+	getReplaces := getFlags.String("replace", "", "Comma separated <module>=<directory> pairs replacing modules with local directories,"+
+		" e.g 'github.com/org/tool=../tool' for tools developed in sibling directories. Directories are relative to the project root (or absolute)"+
+		" and stored relative to moddir, so committed mod files work for every contributor. Such modules are not resolved, and are built from"+
+		" the local sources.")
+	getSelf := getFlags.Bool("self", false, "If enabled, the tool lives in the current repository: target is a package of the module containing the"+
+		" current directory (full path or relative to module root, e.g './cmd/tool') and the module is replaced with its root directory"+
+		" (as with -replace), so the tool is always built from the local checkout, while still having stable variable in generated helpers.")
+
+	getModulePolicy := getFlags.String("module-policy", "", "Path to module policy file with 'allow' and 'deny' lists of module rules"+
+		" ('<module>', '<module>/...' for all modules below it, optionally with '@<version>'), e.g banning typosquats or known-bad releases."+
+		" If set, get refuses tools depending (directly or not) on disallowed modules, except those documented with -policy-exception.")
+	getPolicyException := getFlags.String("policy-exception", "", "Comma separated module rules the tool may depend on despite -module-policy,"+
+		" followed by mandatory reason, e.g 'github.com/org/lib@v1.2.3 reviewed in SEC-123'. Stored as '// bingo:policy_exception <rules> <reason>'"+
+		" comment in the tool's mod file.")
+
+	getRegistry := getFlags.String("registry", "", "URL (http or https) or path of organization registry of approved tools and their version"+
+		" ranges: JSON object or YAML map of package (or module) paths to semver constraints, e.g"+
+		" 'github.com/golangci/golangci-lint/cmd/golangci-lint: \">= 1.55, < 2\"'. If set, pinning tools or versions it does not approve is"+
+		" reported according to -registry-mode.")
+	getRegistryMode := getFlags.String("registry-mode", registryWarnMode, "Defines what happens when tool version not approved by -registry is"+
+		" pinned. One of: 'warn' (log warning), 'block' (fail get).")
+
+	getLabels := getFlags.String("label", "", "Comma separated labels to set on the tool, e.g 'team=platform,stage=release'. Labels are"+
+		" merged with existing ones; empty value (e.g 'team=') removes the label. Stored as '// bingo:labels <labels>' comment in the tool's mod file.")
+	getSelector := getFlags.String("selector", "", "Comma separated labels of tools to get, e.g 'team=platform'. Can be used only without"+
+		" target (get all matching tools) or with versions only target, e.g 'bingo get -selector team=platform @none' deletes all matching tools.")
+
+	getVarPrefix := getFlags.String("var-prefix", "", "Prefix added to all generated tool variable names (e.g in Variables.mk and variables.env). "+
+		"Variable name can be also set explicitly per tool, by adding '// bingo:var_name <NAME>' comment to the tool's mod file.")
+	getVarSuffix := getFlags.String("var-suffix", "", "Suffix added to all generated tool variable names (before _ARRAY suffix for array tools).")
+	getBinPathMode := getFlags.String("bin-path-mode", "", "Defines how paths to binaries are rendered in generated "+
+		"Variables.mk and variables.env. One of: 'gobin' (paths relative to $GOBIN resolved where helpers are used), 'absolute' "+
+		"(absolute paths resolved during generation), 'relocatable' (paths relative to $BINGO_BIN which defaults to $GOBIN, but can be overridden), "+
+		"'local' (binaries are installed in project-local, gitignored <moddir>/bin directory instead of $GOBIN, paths relative to $BINGO_BIN which "+
+		"defaults to it). Once <moddir>/bin exists, all bingo commands use it and this flag defaults to 'local'; otherwise to 'gobin'.")
+	getGenPolicy := getFlags.String("gen-policy", "", "Comma separated list of <file>=<policy> pairs controlling when files generated in moddir "+
+		"are written. Files: README.md, .gitignore, Variables.mk, variables.env, variables.go. Policies: 'always' (default), 'if-missing' "+
+		"(generate only if file does not exist, so it can be customized) and 'never'. For example: -gen-policy=README.md=if-missing,.gitignore=never")
+	getGoPackage := getFlags.String("go-package", "bingo", "Package name used in generated variables.go file.")
+	getGoBuildConstraint := getFlags.String("go-build-constraint", "", "Optional build constraint expression (e.g 'tools') added as //go:build line to generated variables.go file.")
+	getGoOut := getFlags.String("go-out", "", "Path to the generated variables.go file. By default it's generated in moddir directory.")
+
+	getReport := getFlags.String("report", "", "File to regenerate inventory report of all pinned tools in (see 'bingo report') after"+
+		" successful get, e.g 'docs/tools.md'. Format is html for .html and .htm files, markdown otherwise.")
+
+	getOutput := getFlags.String("output", "", "If set to 'json', summary of what happened to each tool version (previous and new version, "+
+		"whether it was rebuilt or removed, binary path and duration) is printed to stdout as JSON once get finishes.")
+
+	getChangedExitCode := getFlags.Int("changed-exit-code", 0, "If set to non-zero value, bingo get exits with this code (instead of 0) "+
+		"if any tool's mod file was added, changed or removed. Errors always exit with code 1.")
+	getCommit := getFlags.Bool("commit", false, "If true, successful get creates git commit containing only files in moddir (and -go-out file) written by this get, "+
+		"if any tool version was added, changed or removed. Other changes, staged or not, are not committed.")
+	getCommitMessage := getFlags.String("commit-message", "", "Go template of commit message created with -commit. "+
+		"Available fields: .Summary (e.g 'bump golangci-lint v1.55.2 → v1.59.0' or 'update 3 tools') and .Changes (list of changes "+
+		"with .Name, .From and .To versions; .From is empty for added tools and .To for removed ones). By default "+
+		"'bingo: {{ .Summary }}' with list of changes in the message body, if more than one tool changed.")
+	getSplit := getFlags.Bool("split", false, "If true, -commit creates separate commit for each changed tool (e.g with 'bingo get -u -commit -split'), "+
+		"so each bump can be reviewed, reverted or cherry-picked independently. Regenerated helper files are committed last, in a separate commit.")
 
 	// Go flags is so broken, need to add shadow -v flag to make those work in both before and after `get` command.
 	getVerbose := getFlags.Bool("v", false, "Print more'")
+	getQuiet := getFlags.Bool("quiet", false, "Print nothing but errors (e.g for Makefile usage).")
+	getDebug := getFlags.Bool("debug", false, "Print even more than -v: exact go commands with extra env variables, working directory and timings.")
+	getLogFormat := getFlags.String("log-format", "", "Format of logs printed to stderr. One of: 'text', 'json'. Same as bingo -log-format flag.")
+	getNoColor := getFlags.Bool("no-color", false, "Disable colored output. Same as bingo -no-color flag.")
 
 	// List flags.
 	listFlags := flag.NewFlagSet("bingo list", flag.ContinueOnError)
 	listModDir := listFlags.String("moddir", ".bingo", "Directory where separate modules for each binary is"+
 		" maintained. If does not exists, bingo list will fail.")
+	listOutput := listFlags.String("o", "table", "Output format. One of: 'table' (aligned text for humans), 'json' or 'yaml' (structured output"+
+		" including build flags, env vars, mod file names and binary paths), 'csv' (one row per pinned version, with the same fields).")
+	listFormat := listFlags.String("format", "", "Go template executed for each pinned tool version instead of -o output, as 'go list -f' does, e.g"+
+		" '{{.Name}} {{.Version}} {{.BinaryPath}}'. Fields are the same as in json output of tool and its version; 'join' function joins lists,"+
+		" e.g '{{join .BuildFlags \" \"}}'.")
+	listFilter := listFlags.String("filter", "", "Regular expression tool names have to fully match to be listed, e.g 'golangci.*'.")
+	listSelector := listFlags.String("selector", "", "Comma separated labels tools have to have to be listed, e.g 'team=platform,stage=release'."+
+		" Empty value (e.g 'team=') selects tools without such label.")
+	listBuildInfo := listFlags.Bool("buildinfo", false, "Read build info of installed binaries and show Go version and VCS revision they were built with,"+
+		" and whether they were built with different Go version, build env vars or flags than current ones (table, json and yaml outputs).")
+	listSort := listFlags.String("sort", bingo.SortByName, "Order of listed tools. One of: 'name', 'version' (latest pinned version, lowest first),"+
+		" 'module' (module path) or 'updated' (most recently modified mod file first).")
+	listAllWorkspaces := listFlags.Bool("all-workspaces", false, "If enabled, tools pinned in all mod directories with the same name as"+
+		" -moddir (e.g .bingo) found in the current directory and its subdirectories (e.g services/a/.bingo in monorepo) are listed, grouped by"+
+		" mod directory (table, json and yaml outputs). Tools pinned in different versions under the same name, whose links (get -l) would"+
+		" overwrite each other in shared GOBIN, are reported as warnings.")
+	listModule := listFlags.String("module", "", "Module path pattern tools have to match to be listed, where '...' matches any string, e.g 'github.com/golangci/...'.")
+	listSummary := listFlags.Bool("summary", false, "If enabled, counts of tools (all, pinned versions, array and frozen ones, by module host"+
+		" and by label) are printed instead of tools, e.g for mod directories with hundreds of tools (table and json outputs).")
+	listUpdates := listFlags.Bool("updates", false, "If enabled with -summary, each tool is checked for newer module version (requires network"+
+		" access) and outdated tools are counted.")
+	listRegistry := listFlags.String("registry", "", "URL (http or https) or path of organization registry of approved tools and their version"+
+		" ranges (see 'get -registry'). If set, -updates counts tools listed in registry as outdated only if newer version approved by it exists.")
+	listLimit := listFlags.Int("limit", 0, "Maximum number of tools listed per page (after filtering and sorting); 0 lists all tools.")
+	listPage := listFlags.Int("page", 1, "Page of tools listed with -limit, starting from 1.")
 	// Go flags is so broken, need to add shadow -v flag to make those work in both before and after `list` command.
 	listVerbose := listFlags.Bool("v", false, "Print more'")
+	listQuiet := listFlags.Bool("quiet", false, "Print nothing but errors.")
+	listDebug := listFlags.Bool("debug", false, "Print even more than -v: exact go commands with extra env variables, working directory and timings.")
+
+	// Diff flags.
+	diffFlags := flag.NewFlagSet("bingo diff", flag.ContinueOnError)
+	diffModDir := diffFlags.String("moddir", ".bingo", "Directory where separate modules for each binary is"+
+		" maintained, relative to the current directory within git repository.")
+
+	// Freeze flags.
+	freezeFlags := flag.NewFlagSet("bingo freeze", flag.ContinueOnError)
+	freezeModDir := freezeFlags.String("moddir", ".bingo", "Directory where separate modules for each binary is"+
+		" maintained. If does not exists, bingo freeze will fail.")
+	freezeReason := freezeFlags.String("reason", "", "Why the tool has to stay at its pinned version, e.g 'generated code is committed'."+
+		" Stored as '// bingo:frozen <reason>' comment in the tool's mod file.")
+	freezeRemove := freezeFlags.Bool("remove", false, "If enabled, the tool is unfrozen, so it's updated by bingo get -u again.")
+
+	// Merge flags.
+	mergeFlags := flag.NewFlagSet("bingo merge", flag.ContinueOnError)
+	mergeModDir := mergeFlags.String("moddir", ".bingo", "Directory where separate modules for each binary is"+
+		" maintained. If does not exists, bingo merge will fail.")
+	mergeArray := mergeFlags.Bool("array", false, "If enabled, versions pinned by duplicates are appended to versions of <binary> (array of"+
+		" versions, e.g for tool@v1 and tool-legacy@v0 pins) instead of being dropped, and their binaries are renamed accordingly."+
+		" Duplicate names are not added as aliases.")
+
+	// Split flags.
+	splitFlags := flag.NewFlagSet("bingo split", flag.ContinueOnError)
+	splitModDir := splitFlags.String("moddir", ".bingo", "Directory where separate modules for each binary is"+
+		" maintained. If does not exists, bingo split will fail.")
+	splitName := splitFlags.String("n", "", "Name of the new tool with split versions. By default <binary>-legacy.")
+	splitVersions := splitFlags.String("versions", "", "Comma separated versions moved to the new tool. By default all but the highest pinned version.")
+
+	// Rename flags.
+	renameFlags := flag.NewFlagSet("bingo rename", flag.ContinueOnError)
+	renameModDir := renameFlags.String("moddir", ".bingo", "Directory where separate modules for each binary is"+
+		" maintained. If does not exists, bingo rename will fail.")
+
+	// Dedupe flags.
+	dedupeFlags := flag.NewFlagSet("bingo dedupe", flag.ContinueOnError)
+	dedupeModDir := dedupeFlags.String("moddir", ".bingo", "Directory where separate modules for each binary is"+
+		" maintained. If does not exists, bingo dedupe will fail.")
+	dedupeDryRun := dedupeFlags.Bool("dry-run", false, "If enabled, versions that would be aligned are printed, but mod files are not changed.")
+	dedupeRemove := dedupeFlags.Bool("remove", false, "If enabled, all replace statements added by dedupe are removed, so tools use their own dependency versions again.")
+
+	// Check flags.
+	checkFlags := flag.NewFlagSet("bingo check", flag.ContinueOnError)
+	checkModDir := checkFlags.String("moddir", ".bingo", "Directory where separate modules for each binary is"+
+		" maintained. If does not exists, bingo check will fail.")
+	checkFix := checkFlags.Bool("fix", false, "If enabled, mod files that are not in canonical form are rewritten in it, instead of failing.")
+	checkBins := checkFlags.Bool("binaries", false, "If enabled, check also reads build info of installed binaries (versioned ones, links and aliases)"+
+		" and fails if any was not built from pinned package and version, e.g because it was overwritten by 'go install <package>@latest' outside of bingo.")
+	checkModulePolicy := checkFlags.String("module-policy", "", "Path to module policy file (see 'get -module-policy'). If set, check also fails"+
+		" if any pinned tool depends on modules disallowed by it, except those documented with '// bingo:policy_exception' comment in its mod file.")
+
+	// Fmt flags.
+	fmtFlags := flag.NewFlagSet("bingo fmt", flag.ContinueOnError)
+	fmtModDir := fmtFlags.String("moddir", ".bingo", "Directory where separate modules for each binary is"+
+		" maintained. If does not exists, bingo fmt will fail.")
+	fmtCheck := fmtFlags.Bool("check", false, "If enabled, files are not changed; bingo fmt prints files that are not formatted and fails if "+
+		"there are any, e.g to enforce formatting in CI.")
+
+	// Moddir flags.
+	moddirFlags := flag.NewFlagSet("bingo moddir", flag.ContinueOnError)
+	moddirMakefile := moddirFlags.String("makefile", "Makefile", "Path to Makefile with 'include <mod directory>/Variables.mk' line"+
+		" rewritten to include Variables.mk from the new mod directory. Ignored if the file does not exist.")
+
+	// Activate flags.
+	activateFlags := flag.NewFlagSet("bingo activate", flag.ContinueOnError)
+	activateModDir := activateFlags.String("moddir", ".bingo", "Directory where separate modules for each binary is"+
+		" maintained. If does not exists, bingo activate will fail.")
+	activateLazy := activateFlags.Bool("lazy", false, "Create shim scripts instead of links, which run 'bingo get <tool>' (with this bingo binary,"+
+		" from the current directory) first if the pinned binary is missing, so tools work on the first use in fresh clones.")
+
+	// GitHub Actions env flags.
+	ghaEnvFlags := flag.NewFlagSet("bingo gha-env", flag.ContinueOnError)
+	ghaEnvModDir := ghaEnvFlags.String("moddir", ".bingo", "Directory where separate modules for each binary is"+
+		" maintained. If does not exists, bingo gha-env will fail.")
+	ghaEnvVarPrefix := ghaEnvFlags.String("var-prefix", "", "Prefix added to all exported tool variable names. Use the same value as for bingo get.")
+	ghaEnvVarSuffix := ghaEnvFlags.String("var-suffix", "", "Suffix added to all exported tool variable names. Use the same value as for bingo get.")
+
+	// Cache key flags.
+	cacheKeyFlags := flag.NewFlagSet("bingo cachekey", flag.ContinueOnError)
+	cacheKeyModDir := cacheKeyFlags.String("moddir", ".bingo", "Directory where separate modules for each binary is"+
+		" maintained. If does not exists, bingo cachekey will fail.")
+	cacheKeyPlatform := cacheKeyFlags.Bool("platform", false, "If enabled, GOOS and GOARCH (as reported by 'go env') are included in the key.")
+	cacheKeyGoVersion := cacheKeyFlags.Bool("go-version", false, "If enabled, Go version is included in the key.")
+	cacheKeyPrefix := cacheKeyFlags.String("prefix", "bingo-", "Prefix of printed key.")
+
+	// Stats flags.
+	statsFlags := flag.NewFlagSet("bingo stats", flag.ContinueOnError)
+	statsModDir := statsFlags.String("moddir", ".bingo", "Directory where separate modules for each binary is"+
+		" maintained. If does not exists, bingo stats will fail.")
+	statsOutput := statsFlags.String("o", "table", "Output format. One of: table, json.")
+
+	// Licenses flags.
+	licensesFlags := flag.NewFlagSet("bingo licenses", flag.ContinueOnError)
+	licensesModDir := licensesFlags.String("moddir", ".bingo", "Directory where separate modules for each binary is"+
+		" maintained. If does not exists, bingo licenses will fail.")
+	licensesOutput := licensesFlags.String("o", "table", "Output format. One of: table, json.")
+	licensesPolicy := licensesFlags.String("policy", "", "Path to license policy file with 'allow' and 'deny' lists of SPDX license"+
+		" identifiers and 'exceptions' list of modules (<module> or <module>@<version>) exempt from the policy. If set, bingo licenses"+
+		" fails when any module built into pinned tools uses disallowed license.")
+
+	// Report flags.
+	reportFlags := flag.NewFlagSet("bingo report", flag.ContinueOnError)
+	reportModDir := reportFlags.String("moddir", ".bingo", "Directory where separate modules for each binary is"+
+		" maintained. If does not exists, bingo report will fail.")
+	reportOutput := reportFlags.String("o", "", "Output format. One of: markdown, html. Defaults to html if -out file has .html or .htm"+
+		" extension, markdown otherwise.")
+	reportOut := reportFlags.String("out", "", "File to write report to, instead of stdout. It's rewritten only if report changes.")
+
+	// Detect flags.
+	detectFlags := flag.NewFlagSet("bingo detect", flag.ContinueOnError)
+	detectModDir := detectFlags.String("moddir", ".bingo", "Directory where separate modules for each binary is"+
+		" maintained. If does not exists, all found tools are reported.")
+	detectOutput := detectFlags.String("o", "table", "Output format. One of: table, json.")
+
+	// Search flags.
+	searchFlags := flag.NewFlagSet("bingo search", flag.ContinueOnError)
+	searchOutput := searchFlags.String("o", "table", "Output format. One of: table, json.")
+	searchLimit := searchFlags.Int("limit", 10, "Maximum number of printed packages.")
+	searchURL := searchFlags.String("url", defaultSearchURL, "Base URL of pkg.go.dev API, e.g of self-hosted pkgsite instance.")
+
+	// Add flags.
+	addFlags := flag.NewFlagSet("bingo add", flag.ContinueOnError)
+	addModDir := addFlags.String("moddir", ".bingo", "Directory where separate modules for each binary will be"+
+		" maintained. If the directory does not exist, it is created, as with bingo get.")
+	addURL := addFlags.String("url", defaultSearchURL, "Base URL of pkg.go.dev API used to search for tools.")
+
+	// Bootstrap flags.
+	bootstrapFlags := flag.NewFlagSet("bingo bootstrap", flag.ContinueOnError)
+	bootstrapModDir := bootstrapFlags.String("moddir", ".bingo", "Directory where separate modules for each binary is"+
+		" maintained. Has to be a sub directory of the current directory. If does not exists, bingo bootstrap will fail.")
+
+	// Mise flags.
+	miseFlags := flag.NewFlagSet("bingo mise", flag.ContinueOnError)
+	miseModDir := miseFlags.String("moddir", ".bingo", "Directory where separate modules for each binary is"+
+		" maintained. Has to be within the current directory. If does not exists, bingo mise will fail.")
+
+	// VS Code flags.
+	vscodeFlags := flag.NewFlagSet("bingo vscode", flag.ContinueOnError)
+	vscodeModDir := vscodeFlags.String("moddir", ".bingo", "Directory where separate modules for each binary is"+
+		" maintained. Has to be within the current directory. If does not exists, bingo vscode will fail.")
+	vscodeWrite := vscodeFlags.Bool("w", false, "If enabled, settings are merged into .vscode/settings.json file instead of being printed.")
+
+	// UI flags.
+	uiFlags := flag.NewFlagSet("bingo ui", flag.ContinueOnError)
+	uiModDir := uiFlags.String("moddir", ".bingo", "Directory where separate modules for each binary will be"+
+		" maintained. If the directory does not exist, no tools are shown.")
+
+	// Self-update flags.
+	selfUpdateFlags := flag.NewFlagSet("bingo self-update", flag.ContinueOnError)
+
+	// Path flags.
+	pathFlags := flag.NewFlagSet("bingo path", flag.ContinueOnError)
+
+	// Version flags.
+	versionFlags := flag.NewFlagSet("bingo version", flag.ContinueOnError)
+	versionJSON := versionFlags.Bool("json", false, "If enabled, prints JSON with bingo version, commit and Go version it was built from "+
+		"(if known), platform and list of supported features, so scripts can verify bingo capabilities.")
+
+	// Completion flags.
+	completionFlags := flag.NewFlagSet("bingo completion", flag.ContinueOnError)
+	completionModDir := completionFlags.String("moddir", ".bingo", "Directory where separate modules for each binary is"+
+		" maintained. Used with -tools.")
+	completionPrintTools := completionFlags.Bool("tools", false, "If enabled, names of all pinned tools and <tool>@<version> for all "+
+		"their pinned versions are printed instead of completion script. Used by completion scripts.")
 
 	flags.Usage = func() {
 		getFlagsHelp := &strings.Builder{}
@@ -72,7 +424,126 @@ func main() {
 		listFlagsHelp := &strings.Builder{}
 		listFlags.SetOutput(listFlagsHelp)
 		listFlags.PrintDefaults()
-		fmt.Printf(bingoHelpFmt, getFlagsHelp.String(), listFlagsHelp.String())
+
+		diffFlagsHelp := &strings.Builder{}
+		diffFlags.SetOutput(diffFlagsHelp)
+		diffFlags.PrintDefaults()
+
+		freezeFlagsHelp := &strings.Builder{}
+		freezeFlags.SetOutput(freezeFlagsHelp)
+		freezeFlags.PrintDefaults()
+
+		mergeFlagsHelp := &strings.Builder{}
+		mergeFlags.SetOutput(mergeFlagsHelp)
+		mergeFlags.PrintDefaults()
+
+		splitFlagsHelp := &strings.Builder{}
+		splitFlags.SetOutput(splitFlagsHelp)
+		splitFlags.PrintDefaults()
+
+		renameFlagsHelp := &strings.Builder{}
+		renameFlags.SetOutput(renameFlagsHelp)
+		renameFlags.PrintDefaults()
+
+		dedupeFlagsHelp := &strings.Builder{}
+		dedupeFlags.SetOutput(dedupeFlagsHelp)
+		dedupeFlags.PrintDefaults()
+
+		checkFlagsHelp := &strings.Builder{}
+		checkFlags.SetOutput(checkFlagsHelp)
+		checkFlags.PrintDefaults()
+
+		fmtFlagsHelp := &strings.Builder{}
+		fmtFlags.SetOutput(fmtFlagsHelp)
+		fmtFlags.PrintDefaults()
+
+		moddirFlagsHelp := &strings.Builder{}
+		moddirFlags.SetOutput(moddirFlagsHelp)
+		moddirFlags.PrintDefaults()
+
+		activateFlagsHelp := &strings.Builder{}
+		activateFlags.SetOutput(activateFlagsHelp)
+		activateFlags.PrintDefaults()
+
+		ghaEnvFlagsHelp := &strings.Builder{}
+		ghaEnvFlags.SetOutput(ghaEnvFlagsHelp)
+		ghaEnvFlags.PrintDefaults()
+
+		cacheKeyFlagsHelp := &strings.Builder{}
+		cacheKeyFlags.SetOutput(cacheKeyFlagsHelp)
+		cacheKeyFlags.PrintDefaults()
+
+		statsFlagsHelp := &strings.Builder{}
+		statsFlags.SetOutput(statsFlagsHelp)
+		statsFlags.PrintDefaults()
+
+		licensesFlagsHelp := &strings.Builder{}
+		licensesFlags.SetOutput(licensesFlagsHelp)
+		licensesFlags.PrintDefaults()
+
+		reportFlagsHelp := &strings.Builder{}
+		reportFlags.SetOutput(reportFlagsHelp)
+		reportFlags.PrintDefaults()
+
+		detectFlagsHelp := &strings.Builder{}
+		detectFlags.SetOutput(detectFlagsHelp)
+		detectFlags.PrintDefaults()
+
+		searchFlagsHelp := &strings.Builder{}
+		searchFlags.SetOutput(searchFlagsHelp)
+		searchFlags.PrintDefaults()
+
+		addFlagsHelp := &strings.Builder{}
+		addFlags.SetOutput(addFlagsHelp)
+		addFlags.PrintDefaults()
+
+		bootstrapFlagsHelp := &strings.Builder{}
+		bootstrapFlags.SetOutput(bootstrapFlagsHelp)
+		bootstrapFlags.PrintDefaults()
+
+		miseFlagsHelp := &strings.Builder{}
+		miseFlags.SetOutput(miseFlagsHelp)
+		miseFlags.PrintDefaults()
+
+		vscodeFlagsHelp := &strings.Builder{}
+		vscodeFlags.SetOutput(vscodeFlagsHelp)
+		vscodeFlags.PrintDefaults()
+
+		uiFlagsHelp := &strings.Builder{}
+		uiFlags.SetOutput(uiFlagsHelp)
+		uiFlags.PrintDefaults()
+
+		selfUpdateFlagsHelp := &strings.Builder{}
+		selfUpdateFlags.SetOutput(selfUpdateFlagsHelp)
+		selfUpdateFlags.PrintDefaults()
+
+		pathFlagsHelp := &strings.Builder{}
+		pathFlags.SetOutput(pathFlagsHelp)
+		pathFlags.PrintDefaults()
+
+		versionFlagsHelp := &strings.Builder{}
+		versionFlags.SetOutput(versionFlagsHelp)
+		versionFlags.PrintDefaults()
+
+		completionFlagsHelp := &strings.Builder{}
+		completionFlags.SetOutput(completionFlagsHelp)
+		completionFlags.PrintDefaults()
+		fmt.Printf(bingoHelpFmt, getFlagsHelp.String(), listFlagsHelp.String(), diffFlagsHelp.String(), freezeFlagsHelp.String(), mergeFlagsHelp.String(), splitFlagsHelp.String(), renameFlagsHelp.String(), dedupeFlagsHelp.String(), checkFlagsHelp.String(),
+			fmtFlagsHelp.String(), moddirFlagsHelp.String(), activateFlagsHelp.String(), ghaEnvFlagsHelp.String(),
+			cacheKeyFlagsHelp.String(), statsFlagsHelp.String(), licensesFlagsHelp.String(), reportFlagsHelp.String(), detectFlagsHelp.String(), searchFlagsHelp.String(), addFlagsHelp.String(), bootstrapFlagsHelp.String(), miseFlagsHelp.String(), vscodeFlagsHelp.String(), uiFlagsHelp.String(),
+			completionFlagsHelp.String(), selfUpdateFlagsHelp.String(), pathFlagsHelp.String(), versionFlagsHelp.String())
+		if plugins := listPlugins(); len(plugins) > 0 {
+			fmt.Printf("\nPlugins (bingo-<name> executables found on PATH):\n\n  %s\n", strings.Join(plugins, "\n  "))
+		}
+	}
+	configPath, err := loadConfig(flags, getFlags, listFlags, diffFlags, freezeFlags, mergeFlags, splitFlags, renameFlags, dedupeFlags, checkFlags, fmtFlags, moddirFlags, activateFlags, ghaEnvFlags, cacheKeyFlags, statsFlags, licensesFlags, reportFlags, detectFlags, searchFlags, addFlags, bootstrapFlags,
+		miseFlags, vscodeFlags, uiFlags, selfUpdateFlags, pathFlags, versionFlags, completionFlags)
+	if err != nil {
+		exitOnUsageError(flags.Usage, "Failed to load project configuration:", err)
+	}
+	if err := loadEnvConfig(flags, getFlags, listFlags, diffFlags, freezeFlags, mergeFlags, splitFlags, renameFlags, dedupeFlags, checkFlags, fmtFlags, moddirFlags, activateFlags, ghaEnvFlags, cacheKeyFlags, statsFlags, licensesFlags, reportFlags, detectFlags, searchFlags, addFlags, bootstrapFlags,
+		miseFlags, vscodeFlags, uiFlags, selfUpdateFlags, pathFlags, versionFlags, completionFlags); err != nil {
+		exitOnUsageError(flags.Usage, "Failed to load configuration from environment variables:", err)
 	}
 	if err := flags.Parse(os.Args[1:]); err != nil {
 		if errors.Cause(err) == flag.ErrHelp {
@@ -85,6 +556,31 @@ func main() {
 		exitOnUsageError(flags.Usage, "No command specified")
 	}
 	var cmdFunc func(ctx context.Context, r *runner.Runner) error
+	// helpersConfig returns config of helpers regenerated by commands other than get (e.g fmt) in given mod directory.
+	// Helpers are generated with 'get' options, which can be set in project configuration file.
+	helpersConfig := func(modDir string) (bingo.HelpersConfig, error) {
+		genPolicies, err := bingo.ParseGenPolicies(*getGenPolicy)
+		if err != nil {
+			return bingo.HelpersConfig{}, errors.Wrap(err, "parse get -gen-policy")
+		}
+		binPathMode := bingo.BinPathMode(*getBinPathMode)
+		if binPathMode == "" && bingo.HasLocalBinDir(modDir) {
+			binPathMode = bingo.LocalBinPathMode
+		}
+		gobinPath, err := filepath.Abs(getter.BinDir(modDir))
+		if err != nil {
+			return bingo.HelpersConfig{}, errors.Wrap(err, "abs gobin")
+		}
+		return bingo.HelpersConfig{
+			BinPathMode:       binPathMode,
+			GOBIN:             gobinPath,
+			GoPackage:         *getGoPackage,
+			GoBuildConstraint: *getGoBuildConstraint,
+			GoOutFile:         *getGoOut,
+			GenPolicies:       genPolicies,
+		}, nil
+	}
+
 	switch flags.Arg(0) {
 	case "get":
 		getFlags.SetOutput(os.Stdout)
@@ -95,6 +591,12 @@ func main() {
 		if !*verbose && *getVerbose {
 			*verbose = true
 		}
+		*quiet = *quiet || *getQuiet
+		*debug = *debug || *getDebug
+		*noColor = *noColor || *getNoColor
+		if *getLogFormat != "" {
+			*logFormat = *getLogFormat
+		}
 
 		if *getModDir == "" {
 			exitOnUsageError(flags.Usage, "'moddir' flag cannot be empty")
@@ -104,6 +606,103 @@ func main() {
 			exitOnUsageError(flags.Usage, "'go' flag cannot be empty")
 		}
 
+		switch bingo.BinPathMode(*getBinPathMode) {
+		case "", bingo.GOBINBinPathMode, bingo.AbsoluteBinPathMode, bingo.RelocatableBinPathMode, bingo.LocalBinPathMode:
+		default:
+			exitOnUsageError(flags.Usage, "Unknown -bin-path-mode", *getBinPathMode)
+		}
+
+		if *getChangedExitCode < 0 || *getChangedExitCode == 1 {
+			exitOnUsageError(flags.Usage, "-changed-exit-code has to be 0 (disabled) or different than 1, which is reserved for errors")
+		}
+		switch getter.LinkMode(*getLinkMode) {
+		case getter.AutoLinkMode, getter.SymlinkLinkMode, getter.HardlinkLinkMode, getter.CopyLinkMode, getter.ShimLinkMode:
+		default:
+			exitOnUsageError(flags.Usage, "Unknown -link-mode", *getLinkMode)
+		}
+		if *getSplit && !*getCommit {
+			exitOnUsageError(flags.Usage, "-split can be used only with -commit")
+		}
+
+		switch *getOutput {
+		case "", "json":
+		default:
+			exitOnUsageError(flags.Usage, "Unknown -output format", *getOutput)
+		}
+
+		genPolicies, err := bingo.ParseGenPolicies(*getGenPolicy)
+		if err != nil {
+			exitOnUsageError(flags.Usage, "Failed to parse -gen-policy:", err)
+		}
+		labels, err := bingo.ParseLabels(*getLabels)
+		if err != nil {
+			exitOnUsageError(flags.Usage, "Invalid -label:", err)
+		}
+		selector, err := bingo.ParseLabels(*getSelector)
+		if err != nil {
+			exitOnUsageError(flags.Usage, "Invalid -selector:", err)
+		}
+		var goFlags []string
+		if *getGoFlags != "" {
+			if getFlags.NArg() == 0 {
+				exitOnUsageError(flags.Usage, "-goflags can be used only with tool target")
+			}
+			goFlags = []string{}
+			if *getGoFlags != "none" {
+				if err := runner.ValidateToolGoFlags(*getGoFlags); err != nil {
+					exitOnUsageError(flags.Usage, "Invalid -goflags:", err)
+				}
+				goFlags = strings.Fields(*getGoFlags)
+			}
+		}
+		var binDir *string
+		if *getBinDir != "" {
+			if getFlags.NArg() == 0 {
+				exitOnUsageError(flags.Usage, "-bindir can be used only with tool target")
+			}
+			dir := ""
+			if *getBinDir != "none" {
+				dir = *getBinDir
+			}
+			binDir = &dir
+		}
+		var aliases []string
+		if *getAliases != "" {
+			if getFlags.NArg() == 0 {
+				exitOnUsageError(flags.Usage, "-alias can be used only with tool target")
+			}
+			aliases = []string{}
+			if *getAliases != "none" {
+				if aliases, err = bingo.ParseAliases(*getAliases); err != nil {
+					exitOnUsageError(flags.Usage, "Invalid -alias:", err)
+				}
+			}
+		}
+		if *getPolicyException != "" {
+			if getFlags.NArg() == 0 {
+				exitOnUsageError(flags.Usage, "-policy-exception can be used only with tool target")
+			}
+			if _, _, err := bingo.ParsePolicyExceptions(*getPolicyException); err != nil {
+				exitOnUsageError(flags.Usage, "Invalid -policy-exception:", err)
+			}
+		}
+		modulePolicy, err := readModulePolicy(*getModulePolicy)
+		if err != nil {
+			exitOnUsageError(flags.Usage, "Invalid -module-policy file:", err)
+		}
+		switch *getRegistryMode {
+		case registryWarnMode, registryBlockMode:
+		default:
+			exitOnUsageError(flags.Usage, "Unknown -registry-mode", *getRegistryMode)
+		}
+		replaces, err := bingo.ParseLocalReplaces(*getReplaces)
+		if err != nil {
+			exitOnUsageError(flags.Usage, "Invalid -replace:", err)
+		}
+		if len(replaces) > 0 && getFlags.NArg() == 0 {
+			exitOnUsageError(flags.Usage, "-replace can be used only with tool target")
+		}
+
 		upPolicy := runner.NoUpdatePolicy
 		if *getUpdate {
 			upPolicy = runner.UpdatePolicy
@@ -117,6 +716,17 @@ func main() {
 		}
 
 		target := getFlags.Arg(0)
+		if *getSelf {
+			if target == "" {
+				exitOnUsageError(flags.Usage, "-self can be used only with tool target")
+			}
+			selfPkg, selfReplace, err := selfTarget(".", target)
+			if err != nil {
+				exitOnUsageError(flags.Usage, "Invalid -self target:", err)
+			}
+			target = selfPkg
+			replaces = append(replaces, selfReplace)
+		}
 		if *getRename != "" && *getName != "" {
 			exitOnUsageError(flags.Usage, "Both -n and -r were specified. You can either rename or create new one.")
 		}
@@ -136,35 +746,158 @@ func main() {
 			defer func() {
 				if err == nil {
 					// Leave tmp files on error for debug purposes.
-					if cerr := cleanGoGetTmpFiles(modDir); cerr != nil {
-						logger.Println("cannot clean tmp files", err)
+					if cerr := getter.CleanTmpFiles(modDir); cerr != nil {
+						logger.Warn("cannot clean tmp files", "err", cerr)
 					}
 				}
 			}()
 
-			cfg := getConfig{
-				runner:    r,
-				modDir:    modDir,
-				relModDir: relModDir,
-				update:    upPolicy,
-				name:      *getName,
-				rename:    *getRename,
-				verbose:   *verbose,
-				link:      *getLink,
+			binPathMode := bingo.BinPathMode(*getBinPathMode)
+			switch {
+			case binPathMode == bingo.LocalBinPathMode:
+				if err := os.MkdirAll(filepath.Join(modDir, bingo.LocalBinDir), os.ModePerm); err != nil {
+					return errors.Wrap(err, "create local bin dir")
+				}
+			case bingo.HasLocalBinDir(modDir) && binPathMode == "":
+				binPathMode = bingo.LocalBinPathMode
+			case bingo.HasLocalBinDir(modDir) && binPathMode == bingo.GOBINBinPathMode:
+				return errors.Errorf("tools are installed in %s; remove this directory to install them in GOBIN again",
+					filepath.Join(relModDir, bingo.LocalBinDir))
+			}
+			gobinPath, err := filepath.Abs(getter.BinDir(modDir))
+			if err != nil {
+				return errors.Wrap(err, "abs gobin")
+			}
+			store := *getStore
+			if store == "default" {
+				if store, err = getter.DefaultStoreDir(); err != nil {
+					return errors.Wrap(err, "default store dir")
+				}
+			}
+			if store != "" {
+				if store, err = filepath.Abs(store); err != nil {
+					return errors.Wrap(err, "abs store")
+				}
+			}
+			gobins := make([]string, 0, len(getGOBINs))
+			for _, dir := range getGOBINs {
+				abs, err := filepath.Abs(dir)
+				if err != nil {
+					return errors.Wrap(err, "abs gobin")
+				}
+				gobins = append(gobins, abs)
+			}
+			helpersCfg := bingo.HelpersConfig{
+				BinPathMode:       binPathMode,
+				GOBIN:             gobinPath,
+				GoPackage:         *getGoPackage,
+				GoBuildConstraint: *getGoBuildConstraint,
+				GoOutFile:         *getGoOut,
+				GenPolicies:       genPolicies,
+			}
+			var registry *bingo.Registry
+			if *getRegistry != "" {
+				if registry, err = loadRegistry(ctx, http.DefaultClient, *getRegistry); err != nil {
+					return errors.Wrap(err, "load registry")
+				}
+			}
+			cfg := getter.Config{
+				Runner:          r,
+				ModDir:          modDir,
+				RelModDir:       relModDir,
+				Update:          upPolicy,
+				Name:            *getName,
+				Rename:          *getRename,
+				Link:            *getLink,
+				LinkMode:        getter.LinkMode(*getLinkMode),
+				Store:           store,
+				Description:     *getDescription,
+				Labels:          labels,
+				Aliases:         aliases,
+				GoFlags:         goFlags,
+				BinDir:          binDir,
+				Replaces:        replaces,
+				ModulePolicy:    modulePolicy,
+				PolicyException: *getPolicyException,
+				Registry:        registry,
+				RegistryBlock:   *getRegistryMode == registryBlockMode,
+				Selector:        selector,
+				OfflineBuild:    *getOfflineBuild,
+				GOBINs:          gobins,
+				Helpers:         helpersCfg,
+				Timeout:         *getTimeout,
+				Events:          newProgress(logger, status),
+			}
+			if *getOutput == "json" || *getCommit {
+				cfg.Summary = &getter.Summary{}
 			}
 
-			if err := get(ctx, logger, cfg, target); err != nil {
+			hashBefore, err := bingo.ModDirHash(modDir)
+			if err != nil {
+				return errors.Wrap(err, "hash mod dir")
+			}
+			// Only files written by this get are committed, so unrelated edits (even in mod directory) are left alone.
+			var commitPaths []string
+			if *getGoOut != "" {
+				commitPaths = append(commitPaths, *getGoOut)
+			}
+			var beforeGet map[string][]byte
+			if *getCommit {
+				if beforeGet, err = snapshotFiles(relModDir, commitPaths...); err != nil {
+					return errors.Wrap(err, "snapshot mod dir")
+				}
+			}
+			if err := getter.Get(ctx, logger, cfg, target); err != nil {
 				return errors.Wrap(err, "get")
 			}
 
-			pkgs, err := bingo.ListPinnedMainPackages(logger, modDir, true)
+			if err := getter.GenHelpers(logger, relModDir, helpersCfg, *getVarPrefix, *getVarSuffix); err != nil {
+				return err
+			}
+			if *getReport != "" {
+				pkgs, err := bingo.ListPinnedMainPackages(logger, modDir, false)
+				if err != nil {
+					return errors.Wrap(err, "list pinned")
+				}
+				rows, err := collectReport(ctx, r, modDir, pkgs)
+				if err != nil {
+					return errors.Wrap(err, "report")
+				}
+				if err := writeReportFile(*getReport, rows, reportFormat(*getReport)); err != nil {
+					return errors.Wrap(err, "write report")
+				}
+			}
+			if *getOutput == "json" {
+				if err := cfg.Summary.PrintJSON(os.Stdout); err != nil {
+					return err
+				}
+			}
+			if *getCommit {
+				afterGet, err := snapshotFiles(relModDir, commitPaths...)
+				if err != nil {
+					return errors.Wrap(err, "snapshot mod dir")
+				}
+				if err := commit(ctx, logger, cfg.Summary, *getCommitMessage, *getSplit, relModDir, changedFiles(beforeGet, afterGet)...); err != nil {
+					return errors.Wrap(err, "commit")
+				}
+			}
+			if *getLink && binPathMode != bingo.LocalBinPathMode {
+				if msg := pathWarning(gobinPath, os.Getenv("PATH")); msg != "" {
+					logger.Warn(msg)
+				}
+			}
+
+			if *getChangedExitCode == 0 {
+				return nil
+			}
+			hashAfter, err := bingo.ModDirHash(modDir)
 			if err != nil {
-				return errors.Wrap(err, "list pinned")
+				return errors.Wrap(err, "hash mod dir")
 			}
-			if len(pkgs) == 0 {
-				return bingo.RemoveHelpers(modDir)
+			if hashBefore != hashAfter {
+				return exitCodeError{code: *getChangedExitCode, msg: "pinned tools changed"}
 			}
-			return bingo.GenHelpers(relModDir, version.Version, pkgs)
+			return nil
 		}
 	case "list":
 		listFlags.SetOutput(os.Stdout)
@@ -175,6 +908,8 @@ func main() {
 		if !*verbose && *listVerbose {
 			*verbose = true
 		}
+		*quiet = *quiet || *listQuiet
+		*debug = *debug || *listDebug
 
 		if *listModDir == "" {
 			exitOnUsageError(flags.Usage, "'moddir' flag cannot be empty")
@@ -184,78 +919,1328 @@ func main() {
 			exitOnUsageError(flags.Usage, "Too many arguments; only one binary/package or no argument is expected ")
 		}
 
-		target := listFlags.Arg(0)
-		cmdFunc = func(ctx context.Context, r *runner.Runner) error {
-			modDir, err := filepath.Abs(*listModDir)
-			if err != nil {
-				return errors.Wrap(err, "abs")
+		switch *listOutput {
+		case "table", "json", "yaml", "csv":
+		default:
+			exitOnUsageError(flags.Usage, "Unknown -o output format", *listOutput)
+		}
+		switch *listSort {
+		case bingo.SortByName, bingo.SortByVersion, bingo.SortByModule, bingo.SortByUpdated:
+		default:
+			exitOnUsageError(flags.Usage, "Unknown -sort order", *listSort)
+		}
+
+		var listTemplate *template.Template
+		if *listFormat != "" {
+			if *listOutput != "table" || *listSummary {
+				exitOnUsageError(flags.Usage, "-format cannot be used together with -o or -summary")
 			}
-			pkgs, err := bingo.ListPinnedMainPackages(logger, modDir, false)
-			if err != nil {
-				return err
+			var err error
+			if listTemplate, err = bingo.ParseListTemplate(*listFormat); err != nil {
+				exitOnUsageError(flags.Usage, "Invalid -format template:", err)
 			}
+		}
 
-			bingo.SortRenderables(pkgs)
-			return pkgs.PrintTab(target, os.Stdout)
+		var nameFilter *regexp.Regexp
+		if *listFilter != "" {
+			var err error
+			if nameFilter, err = regexp.Compile("^(?:" + *listFilter + ")$"); err != nil {
+				exitOnUsageError(flags.Usage, "Invalid -filter regular expression:", err)
+			}
 		}
-	case "version":
-		cmdFunc = func(ctx context.Context, r *runner.Runner) error {
-			_, err := fmt.Fprintln(os.Stdout, version.Version)
-			return err
+
+		selector, err := bingo.ParseLabels(*listSelector)
+		if err != nil {
+			exitOnUsageError(flags.Usage, "Invalid -selector:", err)
 		}
-	default:
-		exitOnUsageError(flags.Usage, "No such command", flags.Arg(0))
-	}
 
-	g := &run.Group{}
-	g.Add(run.SignalHandler(context.Background(), syscall.SIGINT, syscall.SIGTERM))
+		if *listAllWorkspaces && *listOutput == "csv" {
+			exitOnUsageError(flags.Usage, "-all-workspaces does not support csv output")
+		}
+		if *listSummary && *listOutput != "table" && *listOutput != "json" {
+			exitOnUsageError(flags.Usage, "-summary supports only table and json outputs")
+		}
+		if *listUpdates && !*listSummary {
+			exitOnUsageError(flags.Usage, "-updates can be used only with -summary")
+		}
+		if *listLimit < 0 || *listPage < 1 {
+			exitOnUsageError(flags.Usage, "-limit has to be non negative and -page positive")
+		}
 
-	// Command run actor.
-	{
-		ctx, cancel := context.WithCancel(context.Background())
-		g.Add(func() error {
-			r, err := runner.NewRunner(ctx, logger, *getInsecure, *goCmd)
-			if err != nil {
-				return err
+		target := listFlags.Arg(0)
+		cmdFunc = func(ctx context.Context, r *runner.Runner) error {
+			relModDirs := []string{*listModDir}
+			if *listAllWorkspaces {
+				var err error
+				if relModDirs, err = findModDirs(".", filepath.Base(*listModDir)); err != nil {
+					return errors.Wrap(err, "find mod directories")
+				}
 			}
 
-			if *verbose {
-				r.Verbose()
+			pkgsByModDir := map[string]bingo.PackageRenderables{}
+			outs := map[string]*bytes.Buffer{}
+			for _, relModDir := range relModDirs {
+				modDir, err := filepath.Abs(relModDir)
+				if err != nil {
+					return errors.Wrap(err, "abs")
+				}
+				pkgs, err := bingo.ListPinnedMainPackages(logger, modDir, false)
+				if err != nil {
+					return err
+				}
+				pkgsByModDir[relModDir] = pkgs
+				getter.WarnDuplicates(logger, pkgs)
+
+				pkgs = pkgs.Filter(nameFilter, *listModule, selector)
+				if err := bingo.SortRenderablesBy(pkgs, *listSort); err != nil {
+					return err
+				}
+				gobinPath, err := filepath.Abs(getter.BinDir(modDir))
+				if err != nil {
+					return errors.Wrap(err, "abs gobin")
+				}
+				if *listBuildInfo {
+					pkgs.LoadBuildInfo(gobinPath, r.GoVersion())
+				}
+
+				out := &bytes.Buffer{}
+				outs[relModDir] = out
+				if *listSummary {
+					summary := summarize(pkgs)
+					if *listUpdates {
+						var registry *bingo.Registry
+						if *listRegistry != "" {
+							if registry, err = loadRegistry(ctx, http.DefaultClient, *listRegistry); err != nil {
+								return errors.Wrap(err, "load registry")
+							}
+						}
+						outdated, err := countOutdated(ctx, r, modDir, pkgs, registry)
+						if err != nil {
+							return err
+						}
+						summary.OutdatedTools = &outdated
+					}
+					if err := summary.print(out, *listOutput == "json"); err != nil {
+						return err
+					}
+					continue
+				}
+
+				all := len(pkgs)
+				pkgs = pkgs.Page(*listLimit, *listPage)
+				switch {
+				case listTemplate != nil:
+					err = pkgs.PrintTemplate(target, gobinPath, listTemplate, out)
+				case *listOutput == "table":
+					err = pkgs.PrintTab(target, out)
+					if err == nil && len(pkgs) < all {
+						err = printPageFooter(out, *listLimit, *listPage, len(pkgs), all)
+					}
+				case *listOutput == "yaml":
+					err = pkgs.PrintYAML(target, gobinPath, out)
+				case *listOutput == "csv":
+					err = pkgs.PrintCSV(target, gobinPath, out)
+				default:
+					err = pkgs.PrintJSON(target, gobinPath, out)
+				}
+				if err != nil {
+					return err
+				}
 			}
-			return cmdFunc(ctx, r)
-		}, func(error) {
-			cancel()
-		})
-	}
-	if err := g.Run(); err != nil {
-		if *verbose {
-			// Use %+v for github.com/pkg/errors error to print with stack.
-			logger.Fatalf("Error: %+v", errors.Wrapf(err, "%s command failed", flags.Arg(0)))
+
+			if !*listAllWorkspaces {
+				_, err := io.Copy(os.Stdout, outs[*listModDir])
+				return err
+			}
+			for _, c := range linkConflicts(relModDirs, pkgsByModDir) {
+				logger.Warn("tool pinned in different versions in different mod directories; its links (get -l) in shared GOBIN conflict", "pins", c)
+			}
+			return printWorkspaces(os.Stdout, *listOutput, relModDirs, outs)
+		}
+	case "diff":
+		diffFlags.SetOutput(os.Stdout)
+		if err := diffFlags.Parse(flags.Args()[1:]); err != nil {
+			exitOnUsageError(flags.Usage, "Failed to parse flags for diff command:", err)
 		}
-		logger.Fatalf("Error: %v", errors.Wrapf(err, "%s command failed", flags.Arg(0)))
-	}
-}
 
-const bingoHelpFmt = `bingo: 'go get' like, simple CLI that allows automated versioning of Go package level binaries (e.g required as dev tools by your project!)
-built on top of Go Modules, allowing reproducible dev environments. 'bingo' allows to easily maintain a separate, nested Go Module for each binary.
+		if *diffModDir == "" {
+			exitOnUsageError(flags.Usage, "'moddir' flag cannot be empty")
+		}
 
-For detailed examples and documentation see: https://github.com/bwplotka/bingo
+		if diffFlags.NArg() < 1 || diffFlags.NArg() > 2 {
+			exitOnUsageError(flags.Usage, "Expected one git ref (compared with working tree) or two git refs")
+		}
 
-'bingo' supports following commands:
+		fromRef, toRef := diffFlags.Arg(0), diffFlags.Arg(1)
+		cmdFunc = func(ctx context.Context, r *runner.Runner) error {
+			return diff(ctx, logger, *diffModDir, fromRef, toRef, os.Stdout)
+		}
+	case "freeze":
+		freezeFlags.SetOutput(os.Stdout)
+		if err := freezeFlags.Parse(flags.Args()[1:]); err != nil {
+			exitOnUsageError(flags.Usage, "Failed to parse flags for freeze command:", err)
+		}
 
-Commands:
+		if *freezeModDir == "" {
+			exitOnUsageError(flags.Usage, "'moddir' flag cannot be empty")
+		}
 
-  get <flags> [<package or binary>[@version1 or none,version2,version3...]]
+		if freezeFlags.NArg() != 1 {
+			exitOnUsageError(flags.Usage, "Expected exactly one argument: name of pinned tool")
+		}
 
-%s
+		name := freezeFlags.Arg(0)
+		cmdFunc = func(ctx context.Context, r *runner.Runner) error {
+			modDir, err := filepath.Abs(*freezeModDir)
+			if err != nil {
+				return errors.Wrap(err, "abs")
+			}
+			return getter.Freeze(logger, modDir, name, *freezeReason, !*freezeRemove)
+		}
+	case "merge":
+		mergeFlags.SetOutput(os.Stdout)
+		if err := mergeFlags.Parse(flags.Args()[1:]); err != nil {
+			exitOnUsageError(flags.Usage, "Failed to parse flags for merge command:", err)
+		}
 
-  list <flags> [<package or binary>]
+		if *mergeModDir == "" {
+			exitOnUsageError(flags.Usage, "'moddir' flag cannot be empty")
+		}
 
-List enumerates all or one binary that are/is currently pinned in this project. It will print exact path, Version and immutable output.
+		if mergeFlags.NArg() < 2 {
+			exitOnUsageError(flags.Usage, "Expected at least two arguments: name of pinned tool and names of its duplicates to merge")
+		}
 
-%s
+		cmdFunc = func(ctx context.Context, r *runner.Runner) error {
+			modDir, err := filepath.Abs(*mergeModDir)
+			if err != nil {
+				return errors.Wrap(err, "abs")
+			}
+			helpersCfg, err := helpersConfig(modDir)
+			if err != nil {
+				return err
+			}
+			if *mergeArray {
+				err = getter.MergeArray(logger, modDir, helpersCfg.GOBIN, mergeFlags.Arg(0), mergeFlags.Args()[1:])
+			} else {
+				err = getter.Merge(logger, modDir, mergeFlags.Arg(0), mergeFlags.Args()[1:])
+			}
+			if err != nil {
+				return err
+			}
+			return getter.GenHelpers(logger, *mergeModDir, helpersCfg, *getVarPrefix, *getVarSuffix)
+		}
+	case "split":
+		splitFlags.SetOutput(os.Stdout)
+		if err := splitFlags.Parse(flags.Args()[1:]); err != nil {
+			exitOnUsageError(flags.Usage, "Failed to parse flags for split command:", err)
+		}
 
-  version
+		if *splitModDir == "" {
+			exitOnUsageError(flags.Usage, "'moddir' flag cannot be empty")
+		}
+
+		if splitFlags.NArg() != 1 {
+			exitOnUsageError(flags.Usage, "Expected exactly one argument: name of pinned array tool")
+		}
+		name := splitFlags.Arg(0)
+		newName := *splitName
+		if newName == "" {
+			newName = name + "-legacy"
+		}
+		if !regexp.MustCompile(`^[a-zA-Z0-9._-]+$`).MatchString(newName) {
+			exitOnUsageError(flags.Usage, "New name contains not allowed characters", newName)
+		}
+		var versions []string
+		if *splitVersions != "" {
+			versions = strings.Split(*splitVersions, ",")
+		}
+
+		cmdFunc = func(ctx context.Context, r *runner.Runner) error {
+			modDir, err := filepath.Abs(*splitModDir)
+			if err != nil {
+				return errors.Wrap(err, "abs")
+			}
+			helpersCfg, err := helpersConfig(modDir)
+			if err != nil {
+				return err
+			}
+			if err := getter.Split(logger, modDir, helpersCfg.GOBIN, getter.LinkMode(*getLinkMode), name, newName, versions); err != nil {
+				return err
+			}
+			return getter.GenHelpers(logger, *splitModDir, helpersCfg, *getVarPrefix, *getVarSuffix)
+		}
+	case "rename":
+		renameFlags.SetOutput(os.Stdout)
+		if err := renameFlags.Parse(flags.Args()[1:]); err != nil {
+			exitOnUsageError(flags.Usage, "Failed to parse flags for rename command:", err)
+		}
+
+		if *renameModDir == "" {
+			exitOnUsageError(flags.Usage, "'moddir' flag cannot be empty")
+		}
+
+		if renameFlags.NArg() != 2 {
+			exitOnUsageError(flags.Usage, "Expected exactly two arguments: name of pinned tool and its new name")
+		}
+		if !regexp.MustCompile(`^[a-zA-Z0-9._-]+$`).MatchString(renameFlags.Arg(1)) {
+			exitOnUsageError(flags.Usage, "New name contains not allowed characters", renameFlags.Arg(1))
+		}
+
+		cmdFunc = func(ctx context.Context, r *runner.Runner) error {
+			modDir, err := filepath.Abs(*renameModDir)
+			if err != nil {
+				return errors.Wrap(err, "abs")
+			}
+			helpersCfg, err := helpersConfig(modDir)
+			if err != nil {
+				return err
+			}
+			if err := getter.Rename(logger, modDir, helpersCfg.GOBIN, getter.LinkMode(*getLinkMode), helpersCfg.GenPolicies[bingo.GitignoreFile] != bingo.NeverGenPolicy,
+				renameFlags.Arg(0), renameFlags.Arg(1)); err != nil {
+				return err
+			}
+			return getter.GenHelpers(logger, *renameModDir, helpersCfg, *getVarPrefix, *getVarSuffix)
+		}
+	case "dedupe":
+		dedupeFlags.SetOutput(os.Stdout)
+		if err := dedupeFlags.Parse(flags.Args()[1:]); err != nil {
+			exitOnUsageError(flags.Usage, "Failed to parse flags for dedupe command:", err)
+		}
+
+		if *dedupeModDir == "" {
+			exitOnUsageError(flags.Usage, "'moddir' flag cannot be empty")
+		}
+
+		if *dedupeRemove && (*dedupeDryRun || dedupeFlags.NArg() > 0) {
+			exitOnUsageError(flags.Usage, "-remove cannot be used with -dry-run or modules")
+		}
+
+		cmdFunc = func(ctx context.Context, r *runner.Runner) error {
+			modDir, err := filepath.Abs(*dedupeModDir)
+			if err != nil {
+				return errors.Wrap(err, "abs")
+			}
+			if _, err := os.Stat(modDir); err != nil {
+				return errors.Wrapf(err, "stat bingo module dir %s", modDir)
+			}
+			if *dedupeRemove {
+				return getter.RemoveDedupe(modDir)
+			}
+			changes, err := getter.Dedupe(ctx, logger, r, modDir, dedupeFlags.Args(), *dedupeDryRun)
+			if err != nil {
+				return err
+			}
+			for _, c := range changes {
+				if _, err := fmt.Fprintf(os.Stdout, "%s: %s %s -> %s\n", c.ModFile, c.Module, c.From, c.To); err != nil {
+					return err
+				}
+			}
+			if len(changes) > 0 && !*dedupeDryRun {
+				logger.Info("aligned dependency versions; run 'bingo get' to rebuild tools with them", "changes", len(changes))
+			}
+			return nil
+		}
+	case "check":
+		checkFlags.SetOutput(os.Stdout)
+		if err := checkFlags.Parse(flags.Args()[1:]); err != nil {
+			exitOnUsageError(flags.Usage, "Failed to parse flags for check command:", err)
+		}
+
+		if *checkModDir == "" {
+			exitOnUsageError(flags.Usage, "'moddir' flag cannot be empty")
+		}
+
+		modulePolicy, err := readModulePolicy(*checkModulePolicy)
+		if err != nil {
+			exitOnUsageError(flags.Usage, "Invalid -module-policy file:", err)
+		}
+
+		cmdFunc = func(ctx context.Context, r *runner.Runner) error {
+			if _, err := os.Stat(*checkModDir); err != nil {
+				return errors.Wrap(err, "stat moddir")
+			}
+			if err := checkModFiles(os.Stdout, *checkModDir, *checkFix); err != nil {
+				return err
+			}
+			if *checkBins {
+				pkgs, err := bingo.ListPinnedMainPackages(logger, *checkModDir, false)
+				if err != nil {
+					return errors.Wrap(err, "list pinned")
+				}
+				gobinPath, err := filepath.Abs(getter.BinDir(*checkModDir))
+				if err != nil {
+					return errors.Wrap(err, "abs gobin")
+				}
+				if err := checkBinaries(os.Stdout, gobinPath, pkgs); err != nil {
+					return err
+				}
+			}
+			if modulePolicy == nil {
+				return nil
+			}
+			return checkModDirPolicy(ctx, os.Stdout, r, *checkModDir, *modulePolicy)
+		}
+	case "fmt":
+		fmtFlags.SetOutput(os.Stdout)
+		if err := fmtFlags.Parse(flags.Args()[1:]); err != nil {
+			exitOnUsageError(flags.Usage, "Failed to parse flags for fmt command:", err)
+		}
+
+		if *fmtModDir == "" {
+			exitOnUsageError(flags.Usage, "'moddir' flag cannot be empty")
+		}
+
+		if fmtFlags.NArg() > 0 {
+			exitOnUsageError(flags.Usage, "No arguments are expected")
+		}
+
+		cmdFunc = func(ctx context.Context, r *runner.Runner) error {
+			modDir, err := filepath.Abs(*fmtModDir)
+			if err != nil {
+				return errors.Wrap(err, "abs")
+			}
+			if _, err := os.Stat(modDir); err != nil {
+				return errors.Wrap(err, "stat moddir")
+			}
+			helpersCfg, err := helpersConfig(modDir)
+			if err != nil {
+				return err
+			}
+			return formatModDir(os.Stdout, logger, *fmtModDir, helpersCfg, *getVarPrefix, *getVarSuffix, *fmtCheck)
+		}
+	case "moddir":
+		moddirFlags.SetOutput(os.Stdout)
+		if err := moddirFlags.Parse(flags.Args()[1:]); err != nil {
+			exitOnUsageError(flags.Usage, "Failed to parse flags for moddir command:", err)
+		}
+
+		if moddirFlags.NArg() != 3 || moddirFlags.Arg(0) != "migrate" {
+			exitOnUsageError(flags.Usage, "Expected 'migrate' subcommand with exactly two arguments: mod directory and its new location")
+		}
+		from, to := filepath.Clean(moddirFlags.Arg(1)), filepath.Clean(moddirFlags.Arg(2))
+		if filepath.IsAbs(to) {
+			exitOnUsageError(flags.Usage, "New mod directory has to be relative to the current directory, got", to)
+		}
+
+		cmdFunc = func(ctx context.Context, r *runner.Runner) error {
+			fromAbs, err := filepath.Abs(from)
+			if err != nil {
+				return errors.Wrap(err, "abs")
+			}
+			toAbs, err := filepath.Abs(to)
+			if err != nil {
+				return errors.Wrap(err, "abs")
+			}
+			// Project configuration file is looked up at fixed path, so it has to stay there.
+			configDir := filepath.Dir(configFile)
+			keepConfig := from == configDir && to != configDir
+			var keep []string
+			if keepConfig {
+				keep = append(keep, filepath.Base(configFile))
+			}
+			if err := getter.MigrateModDir(fromAbs, toAbs, keep...); err != nil {
+				return err
+			}
+			if err := setConfigModDir(configFile, filepath.ToSlash(to), keepConfig); err != nil {
+				return errors.Wrap(err, "update project configuration")
+			}
+			if ok, err := rewriteMakefileInclude(*moddirMakefile, from, to); err != nil && !os.IsNotExist(err) {
+				return errors.Wrap(err, "rewrite Makefile include")
+			} else if err == nil && !ok {
+				logger.Warn("no include of Variables.mk from migrated mod directory found", "makefile", *moddirMakefile)
+			}
+			helpersCfg, err := helpersConfig(toAbs)
+			if err != nil {
+				return err
+			}
+			if err := getter.GenHelpers(logger, to, helpersCfg, *getVarPrefix, *getVarSuffix); err != nil {
+				return err
+			}
+			logger.Info("migrated mod directory; update other references to it (e.g sourced variables.env or CI cache paths)", "from", from, "to", to)
+			return nil
+		}
+	case "activate":
+		activateFlags.SetOutput(os.Stdout)
+		if err := activateFlags.Parse(flags.Args()[1:]); err != nil {
+			exitOnUsageError(flags.Usage, "Failed to parse flags for activate command:", err)
+		}
+
+		if *activateModDir == "" {
+			exitOnUsageError(flags.Usage, "'moddir' flag cannot be empty")
+		}
+
+		if activateFlags.NArg() != 1 {
+			exitOnUsageError(flags.Usage, "Expected exactly one argument: shell name (bash, zsh or powershell)")
+		}
+
+		shell := activateFlags.Arg(0)
+		cmdFunc = func(ctx context.Context, r *runner.Runner) error {
+			modDir, err := filepath.Abs(*activateModDir)
+			if err != nil {
+				return errors.Wrap(err, "abs")
+			}
+			var lazy *lazyInstall
+			if *activateLazy {
+				bingoPath, err := os.Executable()
+				if err != nil {
+					return errors.Wrap(err, "find bingo executable")
+				}
+				wd, err := os.Getwd()
+				if err != nil {
+					return errors.Wrap(err, "getwd")
+				}
+				lazy = &lazyInstall{bingo: bingoPath, wd: wd, relModDir: *activateModDir}
+			}
+			return activate(logger, modDir, shell, lazy, os.Stdout)
+		}
+	case "gha-env":
+		ghaEnvFlags.SetOutput(os.Stdout)
+		if err := ghaEnvFlags.Parse(flags.Args()[1:]); err != nil {
+			exitOnUsageError(flags.Usage, "Failed to parse flags for gha-env command:", err)
+		}
+
+		if *ghaEnvModDir == "" {
+			exitOnUsageError(flags.Usage, "'moddir' flag cannot be empty")
+		}
+
+		if ghaEnvFlags.NArg() > 0 {
+			exitOnUsageError(flags.Usage, "No arguments are expected")
+		}
+
+		cmdFunc = func(ctx context.Context, r *runner.Runner) error {
+			modDir, err := filepath.Abs(*ghaEnvModDir)
+			if err != nil {
+				return errors.Wrap(err, "abs")
+			}
+			pkgs, err := bingo.ListPinnedMainPackages(logger, modDir, false)
+			if err != nil {
+				return errors.Wrap(err, "list pinned")
+			}
+			pkgs.ApplyEnvVarNaming(*ghaEnvVarPrefix, *ghaEnvVarSuffix)
+			return ghaEnv(logger, modDir, pkgs, os.Getenv("GITHUB_ENV"), os.Getenv("GITHUB_PATH"))
+		}
+	case "cachekey":
+		cacheKeyFlags.SetOutput(os.Stdout)
+		if err := cacheKeyFlags.Parse(flags.Args()[1:]); err != nil {
+			exitOnUsageError(flags.Usage, "Failed to parse flags for cachekey command:", err)
+		}
+
+		if *cacheKeyModDir == "" {
+			exitOnUsageError(flags.Usage, "'moddir' flag cannot be empty")
+		}
+
+		if cacheKeyFlags.NArg() > 0 {
+			exitOnUsageError(flags.Usage, "No arguments are expected")
+		}
+
+		cmdFunc = func(ctx context.Context, r *runner.Runner) error {
+			modDir, err := filepath.Abs(*cacheKeyModDir)
+			if err != nil {
+				return errors.Wrap(err, "abs")
+			}
+			if _, err := os.Stat(modDir); err != nil {
+				return errors.Wrapf(err, "stat bingo module dir %s", modDir)
+			}
+
+			var extra []string
+			if *cacheKeyPlatform {
+				platform, err := r.With(ctx, "", "", nil).GoEnv("GOOS", "GOARCH")
+				if err != nil {
+					return errors.Wrap(err, "go env")
+				}
+				extra = append(extra, strings.Fields(platform)...)
+			}
+			if *cacheKeyGoVersion {
+				extra = append(extra, r.GoVersion().String())
+			}
+			h, err := bingo.ModDirHash(modDir, extra...)
+			if err != nil {
+				return err
+			}
+			_, err = fmt.Fprintln(os.Stdout, *cacheKeyPrefix+h)
+			return err
+		}
+	case "stats":
+		statsFlags.SetOutput(os.Stdout)
+		if err := statsFlags.Parse(flags.Args()[1:]); err != nil {
+			exitOnUsageError(flags.Usage, "Failed to parse flags for stats command:", err)
+		}
+
+		if *statsModDir == "" {
+			exitOnUsageError(flags.Usage, "'moddir' flag cannot be empty")
+		}
+
+		if statsFlags.NArg() > 0 {
+			exitOnUsageError(flags.Usage, "No arguments are expected")
+		}
+
+		switch *statsOutput {
+		case "table", "json":
+		default:
+			exitOnUsageError(flags.Usage, "Unknown -o output format", *statsOutput)
+		}
+
+		cmdFunc = func(ctx context.Context, r *runner.Runner) error {
+			modDir, err := filepath.Abs(*statsModDir)
+			if err != nil {
+				return errors.Wrap(err, "abs")
+			}
+			if _, err := os.Stat(modDir); err != nil {
+				return errors.Wrapf(err, "stat bingo module dir %s", modDir)
+			}
+			pkgs, err := bingo.ListPinnedMainPackages(logger, modDir, false)
+			if err != nil {
+				return errors.Wrap(err, "list pinned")
+			}
+			gobinPath, err := filepath.Abs(getter.BinDir(modDir))
+			if err != nil {
+				return errors.Wrap(err, "abs gobin")
+			}
+			stats, err := collectStats(modDir, gobinPath, pkgs)
+			if err != nil {
+				return err
+			}
+			return printStats(os.Stdout, stats, *statsOutput == "json")
+		}
+	case "licenses":
+		licensesFlags.SetOutput(os.Stdout)
+		if err := licensesFlags.Parse(flags.Args()[1:]); err != nil {
+			exitOnUsageError(flags.Usage, "Failed to parse flags for licenses command:", err)
+		}
+
+		if *licensesModDir == "" {
+			exitOnUsageError(flags.Usage, "'moddir' flag cannot be empty")
+		}
+
+		if licensesFlags.NArg() > 0 {
+			exitOnUsageError(flags.Usage, "No arguments are expected")
+		}
+
+		switch *licensesOutput {
+		case "table", "json":
+		default:
+			exitOnUsageError(flags.Usage, "Unknown -o output format", *licensesOutput)
+		}
+
+		var policy *licensePolicy
+		if *licensesPolicy != "" {
+			f, err := os.Open(*licensesPolicy)
+			if err != nil {
+				exitOnUsageError(flags.Usage, "Failed to open -policy file:", err)
+			}
+			p, err := parseLicensePolicy(f)
+			_ = f.Close()
+			if err != nil {
+				exitOnUsageError(flags.Usage, "Invalid -policy file:", err)
+			}
+			policy = &p
+		}
+
+		cmdFunc = func(ctx context.Context, r *runner.Runner) error {
+			modDir, err := filepath.Abs(*licensesModDir)
+			if err != nil {
+				return errors.Wrap(err, "abs")
+			}
+			if _, err := os.Stat(modDir); err != nil {
+				return errors.Wrapf(err, "stat bingo module dir %s", modDir)
+			}
+			pkgs, err := bingo.ListPinnedMainPackages(logger, modDir, false)
+			if err != nil {
+				return errors.Wrap(err, "list pinned")
+			}
+			mods, err := collectLicenses(ctx, r, modDir, pkgs)
+			if err != nil {
+				return err
+			}
+			var violations []moduleLicense
+			if policy != nil {
+				violations = applyLicensePolicy(*policy, mods)
+			}
+			if err := printLicenses(os.Stdout, mods, *licensesOutput == "json"); err != nil {
+				return err
+			}
+			if len(violations) > 0 {
+				names := make([]string, 0, len(violations))
+				for _, v := range violations {
+					names = append(names, fmt.Sprintf("%s@%s (%s, used by %s)", v.Module, v.Version, strings.Join(v.Licenses, " OR "), v.Tool))
+				}
+				return errors.Errorf("%d modules use licenses disallowed by %s: %s", len(violations), *licensesPolicy, strings.Join(names, ", "))
+			}
+			return nil
+		}
+	case "report":
+		reportFlags.SetOutput(os.Stdout)
+		if err := reportFlags.Parse(flags.Args()[1:]); err != nil {
+			exitOnUsageError(flags.Usage, "Failed to parse flags for report command:", err)
+		}
+
+		if *reportModDir == "" {
+			exitOnUsageError(flags.Usage, "'moddir' flag cannot be empty")
+		}
+
+		if reportFlags.NArg() > 0 {
+			exitOnUsageError(flags.Usage, "No arguments are expected")
+		}
+
+		format := *reportOutput
+		if format == "" {
+			format = reportFormat(*reportOut)
+		}
+		switch format {
+		case markdownReportFormat, htmlReportFormat:
+		default:
+			exitOnUsageError(flags.Usage, "Unknown -o output format", format)
+		}
+
+		cmdFunc = func(ctx context.Context, r *runner.Runner) error {
+			modDir, err := filepath.Abs(*reportModDir)
+			if err != nil {
+				return errors.Wrap(err, "abs")
+			}
+			if _, err := os.Stat(modDir); err != nil {
+				return errors.Wrapf(err, "stat bingo module dir %s", modDir)
+			}
+			pkgs, err := bingo.ListPinnedMainPackages(logger, modDir, false)
+			if err != nil {
+				return errors.Wrap(err, "list pinned")
+			}
+			rows, err := collectReport(ctx, r, modDir, pkgs)
+			if err != nil {
+				return err
+			}
+			if *reportOut == "" {
+				return writeReport(os.Stdout, rows, format)
+			}
+			return writeReportFile(*reportOut, rows, format)
+		}
+	case "detect":
+		detectFlags.SetOutput(os.Stdout)
+		if err := detectFlags.Parse(flags.Args()[1:]); err != nil {
+			exitOnUsageError(flags.Usage, "Failed to parse flags for detect command:", err)
+		}
+
+		if *detectModDir == "" {
+			exitOnUsageError(flags.Usage, "'moddir' flag cannot be empty")
+		}
+
+		if detectFlags.NArg() > 0 {
+			exitOnUsageError(flags.Usage, "No arguments are expected")
+		}
+
+		switch *detectOutput {
+		case "table", "json":
+		default:
+			exitOnUsageError(flags.Usage, "Unknown -o output format", *detectOutput)
+		}
+
+		cmdFunc = func(ctx context.Context, r *runner.Runner) error {
+			modDir, err := filepath.Abs(*detectModDir)
+			if err != nil {
+				return errors.Wrap(err, "abs")
+			}
+			pkgs, err := bingo.ListPinnedMainPackages(logger, modDir, false)
+			if err != nil {
+				return errors.Wrap(err, "list pinned")
+			}
+			wd, err := os.Getwd()
+			if err != nil {
+				return errors.Wrap(err, "getwd")
+			}
+			found, err := detectUnpinned(wd, modDir, pkgs)
+			if err != nil {
+				return errors.Wrap(err, "detect")
+			}
+			return printUnpinned(os.Stdout, found, *detectOutput == "json")
+		}
+	case "search":
+		searchFlags.SetOutput(os.Stdout)
+		if err := searchFlags.Parse(flags.Args()[1:]); err != nil {
+			exitOnUsageError(flags.Usage, "Failed to parse flags for search command:", err)
+		}
+
+		if searchFlags.NArg() == 0 {
+			exitOnUsageError(flags.Usage, "Expected search term")
+		}
+		if *searchLimit <= 0 {
+			exitOnUsageError(flags.Usage, "'limit' flag has to be positive")
+		}
+
+		switch *searchOutput {
+		case "table", "json":
+		default:
+			exitOnUsageError(flags.Usage, "Unknown -o output format", *searchOutput)
+		}
+
+		cmdFunc = func(ctx context.Context, r *runner.Runner) error {
+			results, err := searchMainPackages(ctx, http.DefaultClient, *searchURL, strings.Join(searchFlags.Args(), " "), *searchLimit)
+			if err != nil {
+				return err
+			}
+			return printSearchResults(os.Stdout, results, *searchOutput == "json")
+		}
+	case "add":
+		addFlags.SetOutput(os.Stdout)
+		if err := addFlags.Parse(flags.Args()[1:]); err != nil {
+			exitOnUsageError(flags.Usage, "Failed to parse flags for add command:", err)
+		}
+
+		if *addModDir == "" {
+			exitOnUsageError(flags.Usage, "'moddir' flag cannot be empty")
+		}
+
+		cmdFunc = func(ctx context.Context, r *runner.Runner) error {
+			modDir, err := filepath.Abs(*addModDir)
+			if err != nil {
+				return errors.Wrap(err, "abs")
+			}
+			w := &addWizard{
+				in:  bufio.NewReader(os.Stdin),
+				out: os.Stdout,
+				search: func(term string) ([]searchResult, error) {
+					return searchMainPackages(ctx, http.DefaultClient, *addURL, term, 10)
+				},
+				versions: func(modPath string) ([]string, error) {
+					return moduleVersions(ctx, r, modPath)
+				},
+			}
+			c, err := w.run(strings.Join(addFlags.Args(), " "))
+			if err != nil {
+				return err
+			}
+
+			helpersCfg, err := helpersConfig(modDir)
+			if err != nil {
+				return err
+			}
+			cfg := getter.Config{Runner: r, ModDir: modDir, RelModDir: *addModDir, Name: c.Name, GoFlags: c.GoFlags, Helpers: helpersCfg}
+			if err := getter.Get(ctx, logger, cfg, c.Target); err != nil {
+				return err
+			}
+			return getter.GenHelpers(logger, *addModDir, helpersCfg, *getVarPrefix, *getVarSuffix)
+		}
+	case "bootstrap":
+		bootstrapFlags.SetOutput(os.Stdout)
+		if err := bootstrapFlags.Parse(flags.Args()[1:]); err != nil {
+			exitOnUsageError(flags.Usage, "Failed to parse flags for bootstrap command:", err)
+		}
+
+		if *bootstrapModDir == "" {
+			exitOnUsageError(flags.Usage, "'moddir' flag cannot be empty")
+		}
+
+		if bootstrapFlags.NArg() > 0 {
+			exitOnUsageError(flags.Usage, "No arguments are expected")
+		}
+
+		cmdFunc = func(ctx context.Context, r *runner.Runner) error {
+			modDir, err := filepath.Abs(*bootstrapModDir)
+			if err != nil {
+				return errors.Wrap(err, "abs")
+			}
+			if _, err := os.Stat(modDir); err != nil {
+				return errors.Wrapf(err, "stat bingo module dir %s", modDir)
+			}
+			pkgs, err := bingo.ListPinnedMainPackages(logger, modDir, false)
+			if err != nil {
+				return errors.Wrap(err, "list pinned")
+			}
+			return bingo.GenBootstrap(*bootstrapModDir, version.Version, pkgs)
+		}
+	case "mise":
+		miseFlags.SetOutput(os.Stdout)
+		if err := miseFlags.Parse(flags.Args()[1:]); err != nil {
+			exitOnUsageError(flags.Usage, "Failed to parse flags for mise command:", err)
+		}
+
+		if *miseModDir == "" {
+			exitOnUsageError(flags.Usage, "'moddir' flag cannot be empty")
+		}
+
+		if miseFlags.NArg() > 0 {
+			exitOnUsageError(flags.Usage, "No arguments are expected")
+		}
+
+		cmdFunc = func(ctx context.Context, r *runner.Runner) error {
+			return mise(logger, *miseModDir, version.Version)
+		}
+	case "vscode":
+		vscodeFlags.SetOutput(os.Stdout)
+		if err := vscodeFlags.Parse(flags.Args()[1:]); err != nil {
+			exitOnUsageError(flags.Usage, "Failed to parse flags for vscode command:", err)
+		}
+
+		if *vscodeModDir == "" {
+			exitOnUsageError(flags.Usage, "'moddir' flag cannot be empty")
+		}
+
+		if vscodeFlags.NArg() > 0 {
+			exitOnUsageError(flags.Usage, "No arguments are expected")
+		}
+
+		cmdFunc = func(ctx context.Context, r *runner.Runner) error {
+			return vscode(logger, *vscodeModDir, *vscodeWrite, os.Stdout)
+		}
+	case "ui":
+		uiFlags.SetOutput(os.Stdout)
+		if err := uiFlags.Parse(flags.Args()[1:]); err != nil {
+			exitOnUsageError(flags.Usage, "Failed to parse flags for ui command:", err)
+		}
+
+		if *uiModDir == "" {
+			exitOnUsageError(flags.Usage, "'moddir' flag cannot be empty")
+		}
+
+		if uiFlags.NArg() > 0 {
+			exitOnUsageError(flags.Usage, "No arguments are expected")
+		}
+
+		cmdFunc = func(ctx context.Context, r *runner.Runner) error {
+			modDir, err := filepath.Abs(*uiModDir)
+			if err != nil {
+				return errors.Wrap(err, "abs")
+			}
+			helpersCfg, err := helpersConfig(modDir)
+			if err != nil {
+				return err
+			}
+			return ui(ctx, r, modDir, *uiModDir, helpersCfg, *getVarPrefix, *getVarSuffix)
+		}
+	case "completion":
+		completionFlags.SetOutput(os.Stdout)
+		if err := completionFlags.Parse(flags.Args()[1:]); err != nil {
+			exitOnUsageError(flags.Usage, "Failed to parse flags for completion command:", err)
+		}
+
+		if *completionModDir == "" {
+			exitOnUsageError(flags.Usage, "'moddir' flag cannot be empty")
+		}
+
+		if !*completionPrintTools && completionFlags.NArg() != 1 {
+			exitOnUsageError(flags.Usage, "Expected exactly one argument: shell name (bash, zsh or fish)")
+		}
+
+		shell := completionFlags.Arg(0)
+		cmds := []completionCmd{
+			newCompletionCmd("get", getFlags, true),
+			newCompletionCmd("list", listFlags, true),
+			newCompletionCmd("diff", diffFlags, false),
+			newCompletionCmd("freeze", freezeFlags, true),
+			newCompletionCmd("merge", mergeFlags, true),
+			newCompletionCmd("split", splitFlags, true),
+			newCompletionCmd("rename", renameFlags, true),
+			newCompletionCmd("dedupe", dedupeFlags, false),
+			newCompletionCmd("check", checkFlags, false),
+			newCompletionCmd("fmt", fmtFlags, false),
+			newCompletionCmd("moddir", moddirFlags, false),
+			newCompletionCmd("activate", activateFlags, false),
+			newCompletionCmd("gha-env", ghaEnvFlags, false),
+			newCompletionCmd("cachekey", cacheKeyFlags, false),
+			newCompletionCmd("stats", statsFlags, false),
+			newCompletionCmd("licenses", licensesFlags, false),
+			newCompletionCmd("report", reportFlags, false),
+			newCompletionCmd("detect", detectFlags, false),
+			newCompletionCmd("search", searchFlags, false),
+			newCompletionCmd("add", addFlags, false),
+			newCompletionCmd("bootstrap", bootstrapFlags, false),
+			newCompletionCmd("mise", miseFlags, false),
+			newCompletionCmd("vscode", vscodeFlags, false),
+			newCompletionCmd("ui", uiFlags, false),
+			newCompletionCmd("completion", completionFlags, false),
+			newCompletionCmd("self-update", selfUpdateFlags, false),
+			newCompletionCmd("path", pathFlags, false),
+			newCompletionCmd("version", versionFlags, false),
+		}
+		cmdFunc = func(ctx context.Context, r *runner.Runner) error {
+			if *completionPrintTools {
+				modDir, err := filepath.Abs(*completionModDir)
+				if err != nil {
+					return errors.Wrap(err, "abs")
+				}
+				return completionTools(logger, modDir, os.Stdout)
+			}
+			script, err := completionScript(shell, cmds)
+			if err != nil {
+				return err
+			}
+			_, err = fmt.Fprint(os.Stdout, script)
+			return err
+		}
+	case "self-update":
+		selfUpdateFlags.SetOutput(os.Stdout)
+		if err := selfUpdateFlags.Parse(flags.Args()[1:]); err != nil {
+			exitOnUsageError(flags.Usage, "Failed to parse flags for self-update command:", err)
+		}
+
+		if selfUpdateFlags.NArg() > 1 {
+			exitOnUsageError(flags.Usage, "Too many arguments except none or version")
+		}
+
+		cmdFunc = func(ctx context.Context, r *runner.Runner) error {
+			return selfUpdate(ctx, logger, r, selfUpdateFlags.Arg(0))
+		}
+	case "path":
+		pathFlags.SetOutput(os.Stdout)
+		if err := pathFlags.Parse(flags.Args()[1:]); err != nil {
+			exitOnUsageError(flags.Usage, "Failed to parse flags for path command:", err)
+		}
+
+		if pathFlags.NArg() > 1 {
+			exitOnUsageError(flags.Usage, "Expected at most one argument: shell name (bash, zsh, sh, fish or powershell)")
+		}
+
+		cmdFunc = func(ctx context.Context, r *runner.Runner) error {
+			gobinPath, err := filepath.Abs(getter.GOBIN())
+			if err != nil {
+				return errors.Wrap(err, "abs gobin")
+			}
+			return printPath(os.Stdout, pathFlags.Arg(0), gobinPath)
+		}
+	case "version":
+		versionFlags.SetOutput(os.Stdout)
+		if err := versionFlags.Parse(flags.Args()[1:]); err != nil {
+			exitOnUsageError(flags.Usage, "Failed to parse flags for version command:", err)
+		}
+
+		if versionFlags.NArg() > 0 {
+			exitOnUsageError(flags.Usage, "No arguments are expected")
+		}
+
+		cmdFunc = func(ctx context.Context, r *runner.Runner) error {
+			return printVersion(os.Stdout, *versionJSON)
+		}
+	default:
+		// Unknown commands are dispatched to bingo-<command> plugins on PATH, git style.
+		plugin, err := lookPlugin(flags.Arg(0))
+		if err != nil {
+			exitOnUsageError(flags.Usage, "No such command", flags.Arg(0))
+		}
+		cmdFunc = func(ctx context.Context, r *runner.Runner) error {
+			return runPlugin(ctx, logger, plugin, flags.Args()[1:])
+		}
+	}
+
+	if *debug {
+		*verbose = true
+	}
+	if *quiet && *verbose {
+		exitOnUsageError(flags.Usage, "-quiet cannot be used together with -v or -debug")
+	}
+	// Validated once commands merged their own -log-format into the global one.
+	switch *logFormat {
+	case textLogFormat, jsonLogFormat:
+	default:
+		exitOnUsageError(flags.Usage, "Unknown -log-format", *logFormat)
+	}
+	logLevel := slog.LevelInfo
+	switch {
+	case *quiet:
+		logLevel = slog.LevelError
+	case *verbose:
+		logLevel = slog.LevelDebug
+	}
+	var stderr io.Writer = os.Stderr
+	if *logFormat == textLogFormat && !*quiet && !*verbose && isInteractive(os.Stderr) {
+		status = &statusLine{w: os.Stderr}
+		stderr = status
+	}
+	logger = newLogger(stderr, *logFormat, logLevel, !*noColor && useColor(os.Stderr))
+
+	g := &run.Group{}
+	g.Add(run.SignalHandler(context.Background(), syscall.SIGINT, syscall.SIGTERM))
+
+	// Command run actor.
+	{
+		ctx, cancel := context.WithCancel(context.Background())
+		g.Add(func() error {
+			cmdFlags := []*flag.FlagSet{getFlags, listFlags, diffFlags, freezeFlags, mergeFlags, splitFlags, renameFlags, dedupeFlags, checkFlags, fmtFlags, moddirFlags, activateFlags, ghaEnvFlags, cacheKeyFlags, statsFlags, licensesFlags, reportFlags, detectFlags, searchFlags, addFlags, bootstrapFlags,
+				miseFlags, vscodeFlags, uiFlags, completionFlags}
+			if *modDirOverride != "" {
+				if err := overrideModDir(*modDirOverride, cmdFlags...); err != nil {
+					return err
+				}
+			}
+			root, err := chdirToProjectRoot(cmdFlags...)
+			if err != nil {
+				return errors.Wrap(err, "find project root")
+			}
+			if root != "" {
+				logger.Debug("mod directory found in parent directory; running from project root", "root", root)
+			}
+			if configPath != "" {
+				logger.Debug("using project configuration", "file", configPath)
+			}
+
+			r, err := runner.NewRunner(ctx, logger, *getInsecure, *goCmd)
+			if err != nil {
+				return err
+			}
+
+			if *verbose {
+				r.Verbose()
+				if *logFormat == textLogFormat {
+					// Show progress of long fetches and builds as it happens.
+					r.Stream(os.Stderr)
+				}
+			}
+			if *debug {
+				r.Debug()
+			}
+			if *printCommands {
+				r.PrintCommands(stderr)
+			}
+			if *getIsolateEnv {
+				r.IsolateEnv()
+			}
+			for k, v := range map[string]string{"GOVCS": *goVCS, "GOPROXY": *goProxy, "GOPRIVATE": *goPrivate, "GOINSECURE": *goInsecure} {
+				if v != "" {
+					r.EnforceEnv(k + "=" + v)
+				}
+			}
+			if *caFile != "" {
+				env, err := caBundleEnv(*caFile)
+				if err != nil {
+					return errors.Wrap(err, "ca-file")
+				}
+				logger.Debug("trusting CA bundle", "env", strings.Join(env, " "))
+				r.EnforceEnv(env...)
+			}
+			return cmdFunc(ctx, r)
+		}, func(error) {
+			cancel()
+		})
+	}
+	if err := g.Run(); err != nil {
+		if ecErr, ok := errors.Cause(err).(exitCodeError); ok {
+			logger.Debug(ecErr.Error())
+			os.Exit(ecErr.code)
+		}
+		if *verbose {
+			// Use %+v for github.com/pkg/errors error to print with stack.
+			logger.Error(fmt.Sprintf("%+v", errors.Wrapf(err, "%s command failed", flags.Arg(0))))
+		} else {
+			logger.Error(errors.Wrapf(err, "%s command failed", flags.Arg(0)).Error())
+		}
+		if hint := remediation(err); hint != "" {
+			logger.Info("Hint: " + hint)
+		}
+		os.Exit(1)
+	}
+}
+
+const bingoHelpFmt = `bingo: 'go get' like, simple CLI that allows automated versioning of Go package level binaries (e.g required as dev tools by your project!)
+built on top of Go Modules, allowing reproducible dev environments. 'bingo' allows to easily maintain a separate, nested Go Module for each binary.
+
+For detailed examples and documentation see: https://github.com/bwplotka/bingo
+
+'bingo' supports following commands:
+
+Commands:
+
+  get <flags> [<package or binary>[@version1 or none,version2,version3...]]
+
+%s
+
+  list <flags> [<package or binary>]
+
+List enumerates all or one binary that are/is currently pinned in this project. It will print exact path, Version and immutable output.
+
+%s
+
+  diff <flags> <git ref> [<git ref>]
+
+Diff prints tools added (+), removed (-) and changed (~; versions, package, build env vars or flags) in <moddir> between
+two git revisions, or between given revision and the working tree, e.g: bingo diff origin/main
+
+%s
+
+  freeze <flags> <binary>
+
+Freeze marks the tool as held at its exact pinned version (e.g code generator whose output is committed), so 'get -u' and
+'get -upatch' skip it and report it as frozen. Explicitly requested versions are still installed.
+
+%s
+
+  merge <flags> <binary> <duplicate binary>...
+
+Merge merges pins of the same package under different names (e.g golangci-lint and lint, reported as warning by 'get' and 'list')
+into pin of <binary>: mod files of duplicates are removed and their names are added as aliases of <binary>, so they are upgraded
+together. Versions pinned only by duplicates are dropped, unless -array is used.
+
+%s
+
+  split <flags> <binary>
+
+Split moves some versions of array tool (e.g tool@v1,v2) to separate tool (e.g tool-legacy), so they can be upgraded, aliased
+or removed independently. Mod files are renumbered, binaries of moved versions renamed, links (get -l) updated and generated
+helpers regenerated. Use 'merge -array' to merge them back.
+
+%s
+
+  rename <flags> <binary> <new name>
+
+Rename renames pinned tool without rebuilding it: its mod files are moved, installed binaries renamed and link of the old name
+(get -l) replaced by link of the new name, created with 'get -link-mode'. Generated helpers are regenerated, so update references
+to the tool's variable (e.g $(FAILLINT)) accordingly.
+
+%s
+
+  dedupe <flags> [<module>...]
+
+Dedupe aligns versions of dependencies shared by pinned tools (all or given modules, e.g golang.org/x/tools) to the highest version
+any of them uses, so less modules are downloaded. Tools are isolated by default, so it's strictly opt-in: versions are only
+upgraded, with replace statements marked with '// bingo:dedupe' comment, which 'get' keeps. Run 'get' to rebuild tools after.
+
+%s
+
+  check <flags>
+
+Check verifies that all mod files in <moddir> are in canonical form, the same as 'get' writes them, so manual edits (e.g of build
+flags or env vars in require comment, extra require statements or missing header) do not cause confusing failures later. With -fix,
+such files are rewritten in canonical form. With -binaries, it also verifies that installed binaries were built from pinned versions,
+and with -module-policy, that no pinned tool depends on disallowed modules.
+
+%s
+
+  fmt <flags>
+
+Fmt rewrites all mod files in <moddir> in canonical form (as 'check -fix' does) and regenerates helper files (e.g Variables.mk)
+with 'get' options from project configuration file, so <moddir> content is the same on every contributor's machine. It prints
+changed files.
+
+%s
+
+  moddir <flags> migrate <mod directory> <new mod directory>
+
+Moddir migrate moves mod directory (e.g .bingo) to new location (e.g tools/pins), rewrites paths stored in mod files relative to it,
+'include <mod directory>/Variables.mk' line in Makefile and regenerates helper files. If .bingo is moved, project configuration
+file .bingo/config stays in place with 'moddir' key set to the new location, so bingo commands keep finding it.
+
+%s
+
+  activate <flags> <bash, zsh or powershell>
+
+Activate links unversioned names of all pinned tools to their pinned binaries in <moddir>/shims directory and prints shell
+script that prepends this directory to PATH. Use it as: eval "$(bingo activate bash)" or, in PowerShell:
+bingo activate powershell | Out-String | Invoke-Expression
+With -lazy, shims are scripts running 'bingo get <tool>' first if the pinned binary is missing.
+
+%s
+
+  gha-env <flags>
+
+Gha-env is meant to be run inside GitHub Actions workflow step. It links unversioned names of all pinned tools in <moddir>/shims
+directory (as activate does) and appends it to $GITHUB_PATH file, and exports variable for each tool (as in variables.env) to $GITHUB_ENV
+file, so next steps can invoke pinned tools by name or variable.
+
+%s
+
+  cachekey <flags>
+
+Cachekey prints stable hash of all pinned tools' mod files, suitable for CI cache key (e.g for GOBIN or GOMODCACHE), so cache is invalidated
+exactly when pins change.
+
+%s
+
+  stats <flags>
+
+Stats prints size of installed binary, duration of the last build and number of dependency modules of each pinned tool version,
+helping to decide which tools to prebuild, cache remotely or drop. Build duration and dependencies are recorded by 'get' when
+the binary is built on this machine, so they are unknown for binaries linked from the store or built by older bingo versions.
+
+%s
+
+  licenses <flags>
+
+Licenses prints licenses of all modules built into each pinned tool version (detected from their LICENSE, LICENCE or COPYING files).
+With -policy, it checks them against given policy and fails if any module uses disallowed license, so pinned tools pass the same
+compliance gate as product code.
+
+%s
+
+  report <flags>
+
+Report generates markdown (or HTML) inventory of all pinned tools with their description, versions, module, license and date of
+the last update, suitable for docs site or compliance wiki. Use 'get -report <file>' to regenerate it on every get.
+
+%s
+
+  detect <flags>
+
+Detect scans Makefiles, shell scripts, Dockerfiles and GitHub workflows in the current directory for invocations of well-known Go
+tools and 'go run' or 'go install' of remote packages that are not pinned in <moddir>, and prints them with suggested 'bingo get' command.
+
+%s
+
+  search <flags> <term>
+
+Search queries pkg.go.dev for main packages (so tools) matching given term and prints their module, latest version and number of
+packages importing them, so you can find the package path to pin, e.g: bingo search protobuf lint
+
+%s
+
+  add <flags> [<term>]
+
+Add interactively searches pkg.go.dev for a tool, lets you pick one of found main packages, its version (from the module proxy),
+name and GOFLAGS, and pins it as 'bingo get' would.
+
+%s
+
+  bootstrap <flags>
+
+Bootstrap generates bootstrap.sh (POSIX shell) and bootstrap.ps1 (PowerShell) scripts in <moddir> that install pinned (or current, or the
+latest released) bingo version and run 'bingo get' with given arguments. Commit them, so contributors and CI images with only Go installed can install all tools.
+
+%s
+
+  mise <flags>
+
+Mise links unversioned names of all pinned tools in <moddir>/shims directory (as activate does) and generates
+.config/mise/conf.d/bingo.toml mise (https://mise.jdx.dev) config that puts this directory on PATH within the project.
+
+%s
+
+  vscode <flags>
+
+Vscode links unversioned names of all pinned tools in <moddir>/shims directory (as activate does) and prints VS Code Go extension
+settings (go.alternateTools, go.lintTool, go.formatTool) pointing at them, so editor uses the same tool versions as CI.
+
+%s
+
+  ui <flags>
+
+Ui opens interactive terminal dashboard listing all pinned tools with their versions and whether they are installed. It allows to
+upgrade, rebuild or delete a tool and check for newer versions with single keys. Generated helpers are regenerated with default 'get' options.
+
+%s
+
+  completion <flags> <bash, zsh or fish>
+
+Completion prints shell completion script that completes commands, flags, pinned tool names and their pinned versions.
+For example: source <(bingo completion bash)
+
+%s
+
+  self-update <flags> [<version>]
+
+Self-update installs given (or latest) bingo version into GOBIN as bingo-<version> binary and links bingo to it, the same way
+'get' installs tools. Module checksum is verified by Go against checksum database (unless disabled, e.g with GOSUMDB=off).
+
+%s
+
+  path <flags> [<bash, zsh, sh, fish or powershell>]
+
+Path prints line to add to given shell's profile (by default the shell from $SHELL), that puts GOBIN on PATH, so tools linked
+with 'get -l' can be found by name. For example: bingo path >> ~/.bashrc
+
+%s
+
+  version <flags>
 
 Prints bingo Version.
+
+%s
+
+Any other command is dispatched to bingo-<command> executable found on PATH (plugin), with all remaining arguments. Plugins get
+BINGO_BIN, BINGO_VERSION, BINGO_MODDIR, BINGO_GOBIN and BINGO_LIST_JSON (pinned tools as printed by 'list -o json') env variables.
 `
@@ -0,0 +1,26 @@
+// Copyright (c) Bartłomiej Płotka @bwplotka
+// Licensed under the Apache License 2.0.
+
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/bwplotka/bingo/pkg/bingo"
+	"github.com/bwplotka/bingo/pkg/runner"
+	"github.com/efficientgo/tools/core/pkg/testutil"
+	"github.com/pkg/errors"
+)
+
+func TestRemediation(t *testing.T) {
+	testutil.Equals(t, "", remediation(errors.New("other")))
+	testutil.Assert(t, strings.HasPrefix(remediation(errors.Wrap(bingo.ErrNotInstalled, "get")), "Run 'bingo list'"), "")
+	testutil.Assert(t, strings.HasPrefix(remediation(errors.Wrap(bingo.ErrNonMainPackage, "install")), "Reference a main package"), "")
+	testutil.Equals(t, "Check that github.com/fatih/faillint@v9.0.0 exists; for private modules set GOPRIVATE and make sure git can access them.",
+		remediation(errors.Wrap(&bingo.ResolveError{Module: "github.com/fatih/faillint@v9.0.0", Err: errors.New("not found")}, "get")))
+	testutil.Assert(t, strings.Contains(remediation(errors.Wrap(&bingo.ResolveError{
+		Module: "github.com/fatih/faillint@v9.0.0", Err: errors.New("not found"), ProxyChain: "https://mirror (502 Bad Gateway) -> direct (not tried)",
+	}, "get")), "GOPROXY chain"), "")
+	testutil.Assert(t, strings.HasPrefix(remediation(errors.Wrap(&runner.BuildError{Output: "out", Err: errors.New("exit 1")}, "install")), "Build of the tool failed"), "")
+}
@@ -0,0 +1,58 @@
+// Copyright (c) Bartłomiej Płotka @bwplotka
+// Licensed under the Apache License 2.0.
+
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/bwplotka/bingo/internal/getter"
+	"github.com/bwplotka/bingo/pkg/bingo"
+	"github.com/pkg/errors"
+)
+
+// miseConfigFile is a mise (https://mise.jdx.dev) project config file, relative to project root, that bingo maintains.
+// mise loads all *.toml files from this directory, so it does not collide with user's own mise config.
+var miseConfigFile = filepath.Join(".config", "mise", "conf.d", "bingo.toml")
+
+// mise links pinned tools to shims directory (as activate does) and generates mise config in the current directory
+// (assumed to be project root) that adds shims directory to PATH whenever mise is activated within the project.
+func mise(logger *slog.Logger, relModDir, version string) error {
+	relModDir = filepath.Clean(relModDir)
+	if filepath.IsAbs(relModDir) || strings.HasPrefix(relModDir, "..") {
+		return errors.Errorf("mise config requires mod directory to be within the current directory, got %v", relModDir)
+	}
+	modDir, err := filepath.Abs(relModDir)
+	if err != nil {
+		return errors.Wrap(err, "abs")
+	}
+
+	pkgs, err := bingo.ListPinnedMainPackages(logger, modDir, false)
+	if err != nil {
+		return errors.Wrap(err, "list pinned")
+	}
+	gobinPath, err := filepath.Abs(getter.BinDir(modDir))
+	if err != nil {
+		return errors.Wrap(err, "abs gobin")
+	}
+	if err := linkShims(logger, filepath.Join(modDir, shimsDir), gobinPath, pkgs, nil); err != nil {
+		return errors.Wrap(err, "link shims")
+	}
+
+	if err := os.MkdirAll(filepath.Dir(miseConfigFile), os.ModePerm); err != nil {
+		return errors.Wrap(err, "create mise config directory")
+	}
+	return bingo.WriteFileIfChanged(miseConfigFile, []byte(miseConfig(filepath.ToSlash(relModDir), version)))
+}
+
+func miseConfig(relModDir, version string) string {
+	return fmt.Sprintf(`# Auto generated mise config managed by https://github.com/bwplotka/bingo %s. DO NOT EDIT.
+# It puts tools pinned by bingo on PATH. Run 'bingo mise' after 'bingo get' to relink tools if pins changed.
+[env]
+_.path = ["{{config_root}}/%s/%s"]
+`, version, relModDir, shimsDir)
+}
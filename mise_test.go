@@ -0,0 +1,18 @@
+// Copyright (c) Bartłomiej Płotka @bwplotka
+// Licensed under the Apache License 2.0.
+
+package main
+
+import (
+	"testing"
+
+	"github.com/efficientgo/tools/core/pkg/testutil"
+)
+
+func TestMiseConfig(t *testing.T) {
+	testutil.Equals(t, `# Auto generated mise config managed by https://github.com/bwplotka/bingo v0.0.0-test. DO NOT EDIT.
+# It puts tools pinned by bingo on PATH. Run 'bingo mise' after 'bingo get' to relink tools if pins changed.
+[env]
+_.path = ["{{config_root}}/tools/.bingo/shims"]
+`, miseConfig("tools/.bingo", "v0.0.0-test"))
+}
@@ -0,0 +1,125 @@
+// Copyright (c) Bartłomiej Płotka @bwplotka
+// Licensed under the Apache License 2.0.
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// findProjectRoot returns given directory or its closest parent that contains given relative mod directory, the same
+// way git looks for .git directory. It returns false if mod directory was not found up to the file system root.
+func findProjectRoot(dir, relModDir string) (string, bool) {
+	for {
+		if fi, err := os.Stat(filepath.Join(dir, relModDir)); err == nil && fi.IsDir() {
+			return dir, true
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", false
+		}
+		dir = parent
+	}
+}
+
+// chdirToProjectRoot changes working directory to the project root, if mod directory given by -moddir flag of parsed
+// command flags is relative and exists only in one of the parent directories, so bingo works from any project
+// subdirectory and all generated paths stay relative to the project root. It returns the new working directory, or
+// empty string if it was not changed.
+func chdirToProjectRoot(cmdFlags ...*flag.FlagSet) (string, error) {
+	for _, fs := range cmdFlags {
+		if !fs.Parsed() {
+			continue
+		}
+		f := fs.Lookup("moddir")
+		if f == nil {
+			return "", nil
+		}
+		relModDir := f.Value.String()
+		if relModDir == "" || filepath.IsAbs(relModDir) {
+			return "", nil
+		}
+		wd, err := os.Getwd()
+		if err != nil {
+			return "", errors.Wrap(err, "getwd")
+		}
+		root, ok := findProjectRoot(wd, relModDir)
+		if !ok || root == wd {
+			return "", nil
+		}
+		return root, errors.Wrap(os.Chdir(root), "chdir")
+	}
+	return "", nil
+}
+
+// overrideModDir sets -moddir flag of parsed command flags to given mod directory (bingo -m flag). It fails if command
+// does not use mod directory.
+func overrideModDir(modDir string, cmdFlags ...*flag.FlagSet) error {
+	for _, fs := range cmdFlags {
+		if !fs.Parsed() {
+			continue
+		}
+		if fs.Lookup("moddir") == nil {
+			break
+		}
+		return fs.Set("moddir", modDir)
+	}
+	return errors.New("-m flag is not supported by this command")
+}
+
+// rewriteMakefileInclude rewrites include of Variables.mk from given mod directory in given Makefile to include it from
+// given new mod directory. It returns false if there is no such include.
+func rewriteMakefileInclude(makefile, modDir, newModDir string) (bool, error) {
+	b, err := ioutil.ReadFile(makefile)
+	if err != nil {
+		return false, err
+	}
+	re := regexp.MustCompile(`(?m)^(\s*-?include\s+(?:\./)?)` + regexp.QuoteMeta(filepath.ToSlash(modDir)) + `(/Variables\.mk\b)`)
+	if !re.Match(b) {
+		return false, nil
+	}
+	return true, ioutil.WriteFile(makefile, re.ReplaceAll(b, []byte("${1}"+filepath.ToSlash(newModDir)+"${2}")), 0666)
+}
+
+// setConfigModDir sets 'moddir' key of given project configuration file to given mod directory, if the key is there. If
+// create is true, the key (and the file, if needed) is added otherwise.
+func setConfigModDir(path, modDir string, create bool) error {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			return err
+		}
+		if !create {
+			return nil
+		}
+	}
+
+	entry := fmt.Sprintf("moddir = %q", modDir)
+	lines := strings.Split(string(b), "\n")
+	for i, l := range lines {
+		l = strings.TrimSpace(l)
+		if strings.HasPrefix(l, "[") {
+			// Keys below are scoped to commands.
+			break
+		}
+		if j := strings.IndexAny(l, "=:"); j > 0 && strings.TrimSpace(l[:j]) == "moddir" {
+			lines[i] = entry
+			return ioutil.WriteFile(path, []byte(strings.Join(lines, "\n")), 0666)
+		}
+	}
+	if !create {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, []byte(entry+"\n"+string(b)), 0666)
+}
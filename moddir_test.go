@@ -0,0 +1,113 @@
+// Copyright (c) Bartłomiej Płotka @bwplotka
+// Licensed under the Apache License 2.0.
+
+package main
+
+import (
+	"flag"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/efficientgo/tools/core/pkg/testutil"
+)
+
+func TestChdirToProjectRoot(t *testing.T) {
+	wd, err := os.Getwd()
+	testutil.Ok(t, err)
+	t.Cleanup(func() { _ = os.Chdir(wd) })
+
+	root, err := filepath.EvalSymlinks(t.TempDir())
+	testutil.Ok(t, err)
+	sub := filepath.Join(root, "a", "b")
+	testutil.Ok(t, os.MkdirAll(sub, os.ModePerm))
+	testutil.Ok(t, os.MkdirAll(filepath.Join(root, ".bingo"), os.ModePerm))
+
+	got, ok := findProjectRoot(sub, ".bingo")
+	testutil.Equals(t, true, ok)
+	testutil.Equals(t, root, got)
+	got, ok = findProjectRoot(root, ".bingo")
+	testutil.Equals(t, true, ok)
+	testutil.Equals(t, root, got)
+	_, ok = findProjectRoot(sub, ".not-existing")
+	testutil.Equals(t, false, ok)
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.String("moddir", ".bingo", "")
+	notParsed := flag.NewFlagSet("other", flag.ContinueOnError)
+
+	// Not parsed flags are ignored.
+	testutil.Ok(t, os.Chdir(sub))
+	got, err = chdirToProjectRoot(notParsed)
+	testutil.Ok(t, err)
+	testutil.Equals(t, "", got)
+
+	testutil.Ok(t, fs.Parse(nil))
+	got, err = chdirToProjectRoot(notParsed, fs)
+	testutil.Ok(t, err)
+	testutil.Equals(t, root, got)
+	cwd, err := os.Getwd()
+	testutil.Ok(t, err)
+	testutil.Equals(t, root, cwd)
+
+	// Already in the project root.
+	got, err = chdirToProjectRoot(fs)
+	testutil.Ok(t, err)
+	testutil.Equals(t, "", got)
+
+	// Absolute mod directory is used as is.
+	testutil.Ok(t, os.Chdir(sub))
+	testutil.Ok(t, fs.Parse([]string{"-moddir", filepath.Join(root, ".bingo")}))
+	got, err = chdirToProjectRoot(fs)
+	testutil.Ok(t, err)
+	testutil.Equals(t, "", got)
+}
+
+func TestOverrideModDir(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	modDir := fs.String("moddir", ".bingo", "")
+	noModDir := flag.NewFlagSet("version", flag.ContinueOnError)
+	notParsed := flag.NewFlagSet("other", flag.ContinueOnError)
+
+	testutil.Ok(t, fs.Parse(nil))
+	testutil.Ok(t, overrideModDir("services/a/.bingo", notParsed, fs))
+	testutil.Equals(t, "services/a/.bingo", *modDir)
+
+	testutil.Ok(t, noModDir.Parse(nil))
+	testutil.NotOk(t, overrideModDir("services/a/.bingo", noModDir))
+}
+
+func TestRewriteMakefileInclude(t *testing.T) {
+	makefile := filepath.Join(t.TempDir(), "Makefile")
+	testutil.Ok(t, ioutil.WriteFile(makefile, []byte("include .bingo/Variables.mk\n-include ./.bingo/Variables.mk\n\nlint: $(GOLANGCI_LINT)\n"), os.ModePerm))
+
+	ok, err := rewriteMakefileInclude(makefile, ".bingo", "tools/pins")
+	testutil.Ok(t, err)
+	testutil.Equals(t, true, ok)
+	b, err := ioutil.ReadFile(makefile)
+	testutil.Ok(t, err)
+	testutil.Equals(t, "include tools/pins/Variables.mk\n-include ./tools/pins/Variables.mk\n\nlint: $(GOLANGCI_LINT)\n", string(b))
+
+	ok, err = rewriteMakefileInclude(makefile, ".bingo", "tools/pins")
+	testutil.Ok(t, err)
+	testutil.Equals(t, false, ok)
+}
+
+func TestSetConfigModDir(t *testing.T) {
+	config := filepath.Join(t.TempDir(), ".bingo", "config")
+	testutil.Ok(t, setConfigModDir(config, "tools/pins", false))
+	_, err := os.Stat(config)
+	testutil.Assert(t, os.IsNotExist(err), "config should not be created")
+
+	testutil.Ok(t, setConfigModDir(config, "tools/pins", true))
+	b, err := ioutil.ReadFile(config)
+	testutil.Ok(t, err)
+	testutil.Equals(t, "moddir = \"tools/pins\"\n", string(b))
+
+	testutil.Ok(t, ioutil.WriteFile(config, []byte("timeout = 10m\nmoddir: tools/pins\n\n[list]\nmoddir = other\n"), os.ModePerm))
+	testutil.Ok(t, setConfigModDir(config, ".bingo", false))
+	b, err = ioutil.ReadFile(config)
+	testutil.Ok(t, err)
+	testutil.Equals(t, "timeout = 10m\nmoddir = \".bingo\"\n\n[list]\nmoddir = other\n", string(b))
+}
@@ -0,0 +1,85 @@
+// Copyright (c) Bartłomiej Płotka @bwplotka
+// Licensed under the Apache License 2.0.
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+
+	"github.com/pkg/errors"
+)
+
+// onPath returns true if given directory is one of the directories in given PATH env variable value.
+func onPath(dir, path string) bool {
+	dir = filepath.Clean(dir)
+	for _, p := range filepath.SplitList(path) {
+		if p == "" {
+			continue
+		}
+		if abs, err := filepath.Abs(p); err == nil && abs == dir {
+			return true
+		}
+	}
+	return false
+}
+
+// defaultShell returns name of the user's shell, guessed from SHELL env variable.
+func defaultShell() string {
+	if runtime.GOOS == "windows" {
+		return "powershell"
+	}
+	if s := filepath.Base(os.Getenv("SHELL")); s != "." && s != string(filepath.Separator) {
+		return s
+	}
+	return "sh"
+}
+
+// pathLine returns line to add to profile of given shell, that appends given directory to PATH, and the usual profile file.
+func pathLine(shell, dir string) (line, profile string, err error) {
+	switch shell {
+	case "bash":
+		return fmt.Sprintf(`export PATH="${PATH}:%s"`, dir), "~/.bashrc", nil
+	case "zsh":
+		return fmt.Sprintf(`export PATH="${PATH}:%s"`, dir), "~/.zshrc", nil
+	case "sh":
+		return fmt.Sprintf(`export PATH="${PATH}:%s"`, dir), "~/.profile", nil
+	case "fish":
+		return fmt.Sprintf(`fish_add_path --append '%s'`, dir), "~/.config/fish/config.fish", nil
+	case "powershell", "pwsh":
+		return fmt.Sprintf(`$env:Path += ";%s"`, dir), "$PROFILE", nil
+	default:
+		return "", "", errors.Errorf("unsupported shell %q; expected one of bash, zsh, sh, fish, powershell", shell)
+	}
+}
+
+// pathWarning returns warning with instructions for user's shell if given gobin directory is not on given PATH,
+// empty string otherwise.
+func pathWarning(gobin, path string) string {
+	if onPath(gobin, path) {
+		return ""
+	}
+	msg := fmt.Sprintf("GOBIN %s is not on PATH, so linked tools won't be found by name.", gobin)
+	if _, profile, err := pathLine(defaultShell(), gobin); err == nil {
+		msg += fmt.Sprintf(" Add it by running: bingo path >> %s", profile)
+	} else {
+		msg += " Print line to add to your shell profile with: bingo path <shell>"
+	}
+	return msg
+}
+
+// printPath writes line to add to profile of given shell (or user's shell if empty), so that given gobin directory is on PATH.
+func printPath(w io.Writer, shell, gobin string) error {
+	if shell == "" {
+		shell = defaultShell()
+	}
+	line, _, err := pathLine(shell, gobin)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(w, line)
+	return err
+}
@@ -0,0 +1,51 @@
+// Copyright (c) Bartłomiej Płotka @bwplotka
+// Licensed under the Apache License 2.0.
+
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/efficientgo/tools/core/pkg/testutil"
+)
+
+func TestOnPath(t *testing.T) {
+	gobin := filepath.Join(t.TempDir(), "bin")
+	path := strings.Join([]string{"/usr/bin", "", gobin + string(filepath.Separator)}, string(os.PathListSeparator))
+	testutil.Equals(t, true, onPath(gobin, path))
+	testutil.Equals(t, false, onPath(gobin, "/usr/bin"))
+	testutil.Equals(t, false, onPath(gobin, ""))
+}
+
+func TestPathWarning(t *testing.T) {
+	t.Setenv("SHELL", "/bin/zsh")
+
+	testutil.Equals(t, "", pathWarning("/go/bin", "/usr/bin:/go/bin"))
+	testutil.Equals(t, "GOBIN /go/bin is not on PATH, so linked tools won't be found by name. Add it by running: bingo path >> ~/.zshrc",
+		pathWarning("/go/bin", "/usr/bin"))
+
+	t.Setenv("SHELL", "/bin/tcsh")
+	testutil.Equals(t, "GOBIN /go/bin is not on PATH, so linked tools won't be found by name. Print line to add to your shell profile with: bingo path <shell>",
+		pathWarning("/go/bin", "/usr/bin"))
+}
+
+func TestPrintPath(t *testing.T) {
+	b := &bytes.Buffer{}
+	testutil.Ok(t, printPath(b, "bash", "/go/bin"))
+	testutil.Equals(t, "export PATH=\"${PATH}:/go/bin\"\n", b.String())
+
+	b.Reset()
+	testutil.Ok(t, printPath(b, "fish", "/go/bin"))
+	testutil.Equals(t, "fish_add_path --append '/go/bin'\n", b.String())
+
+	b.Reset()
+	t.Setenv("SHELL", "/usr/bin/zsh")
+	testutil.Ok(t, printPath(b, "", "/go/bin"))
+	testutil.Equals(t, "export PATH=\"${PATH}:/go/bin\"\n", b.String())
+
+	testutil.NotOk(t, printPath(b, "tcsh", "/go/bin"))
+}
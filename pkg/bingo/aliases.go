@@ -0,0 +1,65 @@
+// Copyright (c) Bartłomiej Płotka @bwplotka
+// Licensed under the Apache License 2.0.
+
+package bingo
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// AliasesCommand sets extra names of the tool, linked to the same pinned binary, e.g `// bingo:aliases k,kctl`.
+const AliasesCommand = "bingo:aliases"
+
+var aliasRegexp = regexp.MustCompile(`^[A-Za-z0-9._-]+$`)
+
+// AliasRenderable is an extra name of the tool with its own variable name in generated helpers.
+type AliasRenderable struct {
+	Name       string
+	EnvVarName string
+}
+
+// ParseAliases parses comma separated tool aliases (e.g "k,kctl"). Returned aliases are sorted and unique.
+func ParseAliases(s string) ([]string, error) {
+	var aliases []string
+	seen := map[string]struct{}{}
+	for _, a := range strings.Split(s, ",") {
+		a = strings.TrimSpace(a)
+		if a == "" {
+			continue
+		}
+		if !aliasRegexp.MatchString(a) {
+			return nil, errors.Errorf("invalid alias %q; allowed characters [A-Za-z0-9._-]", a)
+		}
+		if _, ok := seen[a]; ok {
+			continue
+		}
+		seen[a] = struct{}{}
+		aliases = append(aliases, a)
+	}
+	sort.Strings(aliases)
+	return aliases, nil
+}
+
+func aliasRenderables(aliases []string) []AliasRenderable {
+	if len(aliases) == 0 {
+		return nil
+	}
+	r := make([]AliasRenderable, 0, len(aliases))
+	for _, a := range aliases {
+		r = append(r, AliasRenderable{Name: a, EnvVarName: envVarName(a)})
+	}
+	return r
+}
+
+// AliasNames returns names of all aliases of the tool.
+func (p PackageRenderable) AliasNames() []string {
+	var names []string
+	for _, a := range p.Aliases {
+		names = append(names, a.Name)
+	}
+	return names
+}
@@ -0,0 +1,38 @@
+// Copyright (c) Bartłomiej Płotka @bwplotka
+// Licensed under the Apache License 2.0.
+
+package bingo
+
+import (
+	"testing"
+
+	"github.com/efficientgo/tools/core/pkg/testutil"
+)
+
+func TestParseAliases(t *testing.T) {
+	a, err := ParseAliases(" kctl, k,,k ")
+	testutil.Ok(t, err)
+	testutil.Equals(t, []string{"k", "kctl"}, a)
+
+	a, err = ParseAliases("")
+	testutil.Ok(t, err)
+	testutil.Equals(t, 0, len(a))
+
+	_, err = ParseAliases("k/ctl")
+	testutil.NotOk(t, err)
+}
+
+func TestAliases_EnvVarNames(t *testing.T) {
+	pkgs := PackageRenderables{
+		{Name: "kubectl", EnvVarName: "KUBECTL", Aliases: aliasRenderables([]string{"k", "kube-ctl"})},
+		{Name: "faillint", EnvVarName: "FAILLINT"},
+	}
+	pkgs.ApplyEnvVarNaming("TOOL_", "")
+	testutil.Equals(t, []AliasRenderable{{Name: "k", EnvVarName: "TOOL_K"}, {Name: "kube-ctl", EnvVarName: "TOOL_KUBE_CTL"}}, pkgs[0].Aliases)
+	testutil.Equals(t, []string{"k", "kube-ctl"}, pkgs[0].AliasNames())
+	testutil.Ok(t, pkgs.ValidateEnvVarNames())
+
+	pkgs[1].Aliases = aliasRenderables([]string{"k"})
+	pkgs[1].Aliases[0].EnvVarName = "TOOL_K"
+	testutil.NotOk(t, pkgs.ValidateEnvVarNames())
+}
@@ -0,0 +1,228 @@
+// Copyright (c) Bartłomiej Płotka @bwplotka
+// Licensed under the Apache License 2.0.
+
+// Package api is a supported Go API for managing tools pinned by bingo, for build tools (e.g magefiles, release tooling)
+// that want to manage pins programmatically instead of invoking bingo CLI. Changes to this package follow semantic
+// versioning of bingo; everything else in this module can change at any time.
+package api
+
+import (
+	"context"
+	"path/filepath"
+
+	"github.com/bwplotka/bingo/internal/getter"
+	"github.com/bwplotka/bingo/pkg/bingo"
+	"github.com/bwplotka/bingo/pkg/logging"
+	"github.com/bwplotka/bingo/pkg/runner"
+	"github.com/pkg/errors"
+)
+
+// Options configures ModDir. Zero value is valid.
+type Options struct {
+	// Logger is used for logs, e.g. *slog.Logger or adapter to your logging stack. Nil discards logs.
+	Logger logging.Logger
+	// GoCmd is a go command to use. Defaults to "go".
+	GoCmd string
+	// Insecure enables fetching modules over insecure protocols (-insecure flag of go get).
+	Insecure bool
+	// Executor runs go commands. Nil runs go as OS processes. Inject a fake one (e.g. runner.ExecutorFunc) in tests.
+	Executor runner.Executor
+	// Helpers configures generated helper files (Variables.mk, variables.env, variables.go, ...), regenerated after
+	// every change. GOBIN defaults to absolute path of GOBIN or project-local bin directory in mod directory, if it exists
+	// (BinPathMode defaults to bingo.LocalBinPathMode then).
+	Helpers bingo.HelpersConfig
+	// Events is notified about progress of Get, Install and Delete, e.g. to render it. Nil means no events.
+	Events Events
+}
+
+// Events is notified about progress of getting tools. Embed NoopEvents to implement only some of the methods.
+type Events = getter.Events
+
+// NoopEvents implements Events by doing nothing.
+type NoopEvents = getter.NoopEvents
+
+// ModDir is a bingo mod directory with tools pinned in separate modules.
+type ModDir struct {
+	dir    string
+	absDir string
+	logger logging.Logger
+	runner *runner.Runner
+	opts   Options
+}
+
+// Open returns ModDir for given mod directory (e.g ".bingo"). Directory is created on first Get, if not existing.
+// It fails if go command is not found or not supported.
+func Open(ctx context.Context, dir string, opts Options) (*ModDir, error) {
+	opts.Logger = logging.OrDiscard(opts.Logger)
+	if opts.GoCmd == "" {
+		opts.GoCmd = "go"
+	}
+	if opts.Helpers.GoPackage == "" {
+		opts.Helpers.GoPackage = "bingo"
+	}
+
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, errors.Wrap(err, "abs")
+	}
+	if opts.Helpers.GOBIN == "" {
+		gobin, err := filepath.Abs(getter.BinDir(absDir))
+		if err != nil {
+			return nil, errors.Wrap(err, "abs gobin")
+		}
+		opts.Helpers.GOBIN = gobin
+	}
+	if opts.Helpers.BinPathMode == "" && bingo.HasLocalBinDir(absDir) {
+		opts.Helpers.BinPathMode = bingo.LocalBinPathMode
+	}
+	r, err := runner.NewRunnerWithExecutor(ctx, opts.Logger, opts.Insecure, opts.GoCmd, opts.Executor)
+	if err != nil {
+		return nil, err
+	}
+	return &ModDir{dir: dir, absDir: absDir, logger: opts.Logger, runner: r, opts: opts}, nil
+}
+
+// Result describes outcome of a change for a single tool version.
+type Result struct {
+	Name        string
+	ModFile     string
+	PackagePath string
+	// PreviousVersion is a version pinned before the change, if any.
+	PreviousVersion string
+	Version         string
+	// Removed is true if tool version is no longer pinned.
+	Removed    bool
+	BinaryPath string
+}
+
+// GetOptions configures ModDir.Get.
+type GetOptions struct {
+	// Update updates the tool to the latest version (-u flag), UpdatePatch to the latest patch version (-u=patch flag).
+	Update, UpdatePatch bool
+	// Name is a name to pin tool under, instead of default one (-n flag).
+	Name string
+	// Link creates unversioned link to the pinned binary in GOBIN (-l flag).
+	Link bool
+	// LinkMode defines how Link links the binary: "auto" (default), "symlink", "hardlink", "copy" or "shim" (-link-mode flag).
+	LinkMode string
+	// Description sets description of what the tool is for (-description flag).
+	Description string
+	// Labels are merged into labels of the tool (-label flag). Labels with empty value are removed.
+	Labels map[string]string
+}
+
+// Get pins and installs tool, the same as bingo get <target>. Target is package path or name of pinned tool, optionally
+// with versions, e.g "github.com/fatih/faillint@v1.5.0" or "buf@v0.1.0,v0.2.0". Empty target means all pinned tools.
+func (d *ModDir) Get(ctx context.Context, target string, opts GetOptions) ([]Result, error) {
+	c := d.config()
+	c.Name = opts.Name
+	c.Link = opts.Link
+	c.LinkMode = getter.LinkMode(opts.LinkMode)
+	c.Description = opts.Description
+	c.Labels = opts.Labels
+	switch {
+	case opts.Update:
+		c.Update = runner.UpdatePolicy
+	case opts.UpdatePatch:
+		c.Update = runner.UpdatePatchPolicy
+	}
+	return d.get(ctx, c, target)
+}
+
+// Install installs pinned versions of given tool (or all pinned tools if name is empty) without changing pins.
+func (d *ModDir) Install(ctx context.Context, name string) error {
+	_, err := d.get(ctx, d.config(), name)
+	return err
+}
+
+// Delete unpins all versions of given tool. Installed binaries are not removed.
+func (d *ModDir) Delete(ctx context.Context, name string) error {
+	if name == "" {
+		return errors.New("tool name is required")
+	}
+	_, err := d.get(ctx, d.config(), name+"@none")
+	return err
+}
+
+// Tool is a tool pinned in ModDir.
+type Tool struct {
+	Name        string
+	ModulePath  string
+	PackagePath string
+	// EnvVarName is a name of variable with binary path in generated helpers.
+	EnvVarName string
+	// Description describes what the tool is for, if set.
+	Description string
+	Labels      map[string]string
+	Versions    []ToolVersion
+}
+
+// ToolVersion is a single pinned version of a tool.
+type ToolVersion struct {
+	Version    string
+	ModFile    string
+	BinaryPath string
+}
+
+// List returns all pinned tools, sorted by name.
+func (d *ModDir) List() ([]Tool, error) {
+	pkgs, err := bingo.ListPinnedMainPackages(d.logger, d.absDir, false)
+	if err != nil {
+		return nil, err
+	}
+	bingo.SortRenderables(pkgs)
+
+	tools := make([]Tool, 0, len(pkgs))
+	for _, p := range pkgs {
+		t := Tool{Name: p.Name, ModulePath: p.ModPath, PackagePath: p.PackagePath, EnvVarName: p.EnvVarName, Description: p.Description, Labels: p.Labels}
+		for _, v := range p.Versions {
+			t.Versions = append(t.Versions, ToolVersion{
+				Version:    v.Version,
+				ModFile:    v.ModFile,
+				BinaryPath: filepath.Join(p.InstallDir(d.opts.Helpers.GOBIN), p.ArtifactName(v.Version)),
+			})
+		}
+		tools = append(tools, t)
+	}
+	return tools, nil
+}
+
+func (d *ModDir) config() getter.Config {
+	return getter.Config{
+		Runner:    d.runner,
+		ModDir:    d.absDir,
+		RelModDir: d.dir,
+		Helpers:   d.opts.Helpers,
+		Summary:   &getter.Summary{},
+		Events:    d.opts.Events,
+	}
+}
+
+func (d *ModDir) get(ctx context.Context, c getter.Config, target string) (_ []Result, err error) {
+	defer func() {
+		if err == nil {
+			err = getter.CleanTmpFiles(d.absDir)
+		}
+	}()
+
+	if err := getter.Get(ctx, d.logger, c, target); err != nil {
+		return nil, errors.Wrap(err, "get")
+	}
+	if err := getter.GenHelpers(d.logger, d.dir, d.opts.Helpers, "", ""); err != nil {
+		return nil, errors.Wrap(err, "generate helpers")
+	}
+
+	results := make([]Result, 0, len(c.Summary.Results))
+	for _, r := range c.Summary.Results {
+		results = append(results, Result{
+			Name:            r.Name,
+			ModFile:         r.ModFile,
+			PackagePath:     r.PackagePath,
+			PreviousVersion: r.PreviousVersion,
+			Version:         r.Version,
+			Removed:         r.Removed,
+			BinaryPath:      r.BinaryPath,
+		})
+	}
+	return results, nil
+}
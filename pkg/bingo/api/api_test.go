@@ -0,0 +1,76 @@
+// Copyright (c) Bartłomiej Płotka @bwplotka
+// Licensed under the Apache License 2.0.
+
+package api
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bwplotka/bingo/pkg/bingo"
+	"github.com/efficientgo/tools/core/pkg/testutil"
+)
+
+func TestModDir(t *testing.T) {
+	ctx := context.Background()
+	dir := filepath.Join(t.TempDir(), ".bingo")
+	testutil.Ok(t, os.MkdirAll(dir, os.ModePerm))
+	testutil.Ok(t, ioutil.WriteFile(filepath.Join(dir, "faillint.mod"), []byte("module _\n\nrequire github.com/fatih/faillint v1.5.0\n"), os.ModePerm))
+	testutil.Ok(t, ioutil.WriteFile(filepath.Join(dir, "buf.mod"), []byte("module _\n\nrequire github.com/bufbuild/buf v0.1.0 // cmd/buf\n"), os.ModePerm))
+	testutil.Ok(t, ioutil.WriteFile(filepath.Join(dir, "buf.1.mod"), []byte("module _\n\nrequire github.com/bufbuild/buf v0.2.0 // cmd/buf\n"), os.ModePerm))
+
+	d, err := Open(ctx, dir, Options{Helpers: bingo.HelpersConfig{GOBIN: "/gobin"}})
+	testutil.Ok(t, err)
+
+	tools, err := d.List()
+	testutil.Ok(t, err)
+	testutil.Equals(t, []Tool{
+		{
+			Name: "buf", ModulePath: "github.com/bufbuild/buf", PackagePath: "github.com/bufbuild/buf/cmd/buf", EnvVarName: "BUF_ARRAY",
+			Versions: []ToolVersion{
+				{Version: "v0.1.0", ModFile: "buf.mod", BinaryPath: "/gobin/buf-v0.1.0"},
+				{Version: "v0.2.0", ModFile: "buf.1.mod", BinaryPath: "/gobin/buf-v0.2.0"},
+			},
+		},
+		{
+			Name: "faillint", ModulePath: "github.com/fatih/faillint", PackagePath: "github.com/fatih/faillint", EnvVarName: "FAILLINT",
+			Versions: []ToolVersion{{Version: "v1.5.0", ModFile: "faillint.mod", BinaryPath: "/gobin/faillint-v1.5.0"}},
+		},
+	}, tools)
+
+	testutil.NotOk(t, d.Delete(ctx, ""))
+	testutil.NotOk(t, d.Delete(ctx, "not-pinned"))
+	testutil.Ok(t, d.Delete(ctx, "buf"))
+
+	tools, err = d.List()
+	testutil.Ok(t, err)
+	testutil.Equals(t, 1, len(tools))
+	testutil.Equals(t, "faillint", tools[0].Name)
+
+	// Helpers are regenerated.
+	b, err := ioutil.ReadFile(filepath.Join(dir, "Variables.mk"))
+	testutil.Ok(t, err)
+	testutil.Assert(t, !bytes.Contains(b, []byte("BUF")), string(b))
+	testutil.Assert(t, bytes.Contains(b, []byte("FAILLINT")), string(b))
+}
+
+func TestModDir_ListBinDir(t *testing.T) {
+	root := t.TempDir()
+	dir := filepath.Join(root, ".bingo")
+	testutil.Ok(t, os.MkdirAll(dir, os.ModePerm))
+	testutil.Ok(t, ioutil.WriteFile(filepath.Join(dir, "protoc-gen-go.mod"), []byte("module _\n\n// bingo:bindir ../protoc/bin\n\nrequire google.golang.org/protobuf v1.31.0 // cmd/protoc-gen-go\n"), os.ModePerm))
+
+	d, err := Open(context.Background(), dir, Options{Helpers: bingo.HelpersConfig{GOBIN: "/gobin"}})
+	testutil.Ok(t, err)
+
+	tools, err := d.List()
+	testutil.Ok(t, err)
+	testutil.Equals(t, 1, len(tools))
+	testutil.Equals(t, []ToolVersion{
+		{Version: "v1.31.0", ModFile: "protoc-gen-go.mod", BinaryPath: filepath.Join(root, "protoc", "bin", "protoc-gen-go-v1.31.0")},
+	}, tools[0].Versions)
+}
@@ -0,0 +1,106 @@
+// Copyright (c) Bartłomiej Płotka @bwplotka
+// Licensed under the Apache License 2.0.
+
+package bingo
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// BootstrapShellFile is a name of generated POSIX shell bootstrap script in mod directory.
+	BootstrapShellFile = "bootstrap.sh"
+	// BootstrapPowerShellFile is a name of generated PowerShell bootstrap script in mod directory.
+	BootstrapPowerShellFile = "bootstrap.ps1"
+
+	bingoModulePath = "github.com/bwplotka/bingo"
+)
+
+var bootstrapTemplatesByFile = map[string]string{
+	BootstrapShellFile: `#!/usr/bin/env sh
+# Auto generated bootstrap script managed by https://github.com/bwplotka/bingo {{ .Version }}. DO NOT EDIT.
+# It installs pinned (or the latest) bingo version (requires only Go 1.21+) and runs 'bingo get' with given arguments to install all pinned tools.
+set -e
+
+cd "$(dirname "$0")/{{ .RootDir }}"
+GO="${GO:-go}"
+tmp="$(mktemp -d)"
+trap 'rm -rf "${tmp}"' EXIT
+
+GOBIN="${tmp}" "${GO}" install {{ .BingoPackage }}@{{ .BingoVersion }}
+"${tmp}/bingo" get -moddir {{ .RelModDir }} "$@"
+`,
+	BootstrapPowerShellFile: `# Auto generated bootstrap script managed by https://github.com/bwplotka/bingo {{ .Version }}. DO NOT EDIT.
+# It installs pinned (or the latest) bingo version (requires only Go 1.21+) and runs 'bingo get' with given arguments to install all pinned tools.
+$ErrorActionPreference = "Stop"
+
+Set-Location (Join-Path $PSScriptRoot "{{ .RootDir }}")
+$goCmd = if ($env:GO) { $env:GO } else { "go" }
+$tmp = Join-Path ([System.IO.Path]::GetTempPath()) ([System.IO.Path]::GetRandomFileName())
+New-Item -ItemType Directory -Path $tmp | Out-Null
+$oldGobin = $env:GOBIN
+try {
+	$env:GOBIN = $tmp
+	& $goCmd install {{ .BingoPackage }}@{{ .BingoVersion }}
+	if ($LASTEXITCODE -ne 0) { exit $LASTEXITCODE }
+	$env:GOBIN = $oldGobin
+	& (Join-Path $tmp "bingo") get -moddir {{ .RelModDir }} @args
+	if ($LASTEXITCODE -ne 0) { exit $LASTEXITCODE }
+} finally {
+	$env:GOBIN = $oldGobin
+	Remove-Item -Recurse -Force $tmp
+}
+`,
+}
+
+// GenBootstrap generates bootstrap scripts in mod directory that install bingo and run 'bingo get' for contributors who
+// only have Go installed. If bingo is pinned as one of the tools, pinned version is installed, otherwise given version, or
+// the latest release if given version is not a release (e.g development build).
+// Relative mod directory has to be within the current directory, which is assumed to be a project root.
+func GenBootstrap(relModDir, version string, pkgs []PackageRenderable) error {
+	relModDir = filepath.Clean(relModDir)
+	if filepath.IsAbs(relModDir) || relModDir == "." || strings.HasPrefix(relModDir, "..") {
+		return errors.Errorf("bootstrap scripts require mod directory to be a sub directory of the current directory, got %v", relModDir)
+	}
+
+	data := struct {
+		Version      string
+		RelModDir    string
+		RootDir      string
+		BingoPackage string
+		BingoVersion string
+	}{
+		Version:   version,
+		RelModDir: filepath.ToSlash(relModDir),
+		RootDir:   strings.TrimSuffix(strings.Repeat("../", len(strings.Split(filepath.ToSlash(relModDir), "/"))), "/"),
+	}
+	data.BingoPackage, data.BingoVersion = bingoInstallTarget(version, pkgs)
+
+	for f, tmpl := range bootstrapTemplatesByFile {
+		t, err := template.New(f).Parse(tmpl)
+		if err != nil {
+			return errors.Wrapf(err, "parse template %v", f)
+		}
+		b := &bytes.Buffer{}
+		if err := t.Execute(b, data); err != nil {
+			return errors.Wrapf(err, "execute template %v", f)
+		}
+
+		out := filepath.Join(relModDir, f)
+		if err := WriteFileIfChanged(out, b.Bytes()); err != nil {
+			return errors.Wrap(err, f)
+		}
+		if f == BootstrapShellFile {
+			if err := os.Chmod(out, 0755); err != nil {
+				return errors.Wrap(err, "chmod")
+			}
+		}
+	}
+	return nil
+}
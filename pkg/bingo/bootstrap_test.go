@@ -0,0 +1,58 @@
+// Copyright (c) Bartłomiej Płotka @bwplotka
+// Licensed under the Apache License 2.0.
+
+package bingo
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/efficientgo/tools/core/pkg/testutil"
+)
+
+func TestGenBootstrap(t *testing.T) {
+	tmpDir, err := ioutil.TempDir(os.TempDir(), "bingo-bootstrap")
+	testutil.Ok(t, err)
+	t.Cleanup(func() { testutil.Ok(t, os.RemoveAll(tmpDir)) })
+
+	wd, err := os.Getwd()
+	testutil.Ok(t, err)
+	testutil.Ok(t, os.Chdir(tmpDir))
+	t.Cleanup(func() { testutil.Ok(t, os.Chdir(wd)) })
+
+	testutil.NotOk(t, GenBootstrap("..", "v0.0.0-test", nil))
+	testutil.NotOk(t, GenBootstrap(".", "v0.0.0-test", nil))
+
+	relModDir := filepath.Join("tools", ".bingo")
+	testutil.Ok(t, os.MkdirAll(relModDir, os.ModePerm))
+	testutil.Ok(t, GenBootstrap(relModDir, "v0.0.0-test", nil))
+
+	b, err := ioutil.ReadFile(filepath.Join(relModDir, BootstrapShellFile))
+	testutil.Ok(t, err)
+	testutil.Assert(t, strings.Contains(string(b), `cd "$(dirname "$0")/../.."`), string(b))
+	// Development builds cannot be installed, so the latest release is.
+	testutil.Assert(t, strings.Contains(string(b), `"${GO}" install github.com/bwplotka/bingo@latest`), string(b))
+	testutil.Assert(t, strings.Contains(string(b), `"${tmp}/bingo" get -moddir tools/.bingo "$@"`), string(b))
+	s, err := os.Stat(filepath.Join(relModDir, BootstrapShellFile))
+	testutil.Ok(t, err)
+	testutil.Assert(t, s.Mode()&0100 != 0, "bootstrap.sh should be executable")
+
+	testutil.Ok(t, GenBootstrap(relModDir, "v0.5.0", nil))
+	b, err = ioutil.ReadFile(filepath.Join(relModDir, BootstrapShellFile))
+	testutil.Ok(t, err)
+	testutil.Assert(t, strings.Contains(string(b), `"${GO}" install github.com/bwplotka/bingo@v0.5.0`), string(b))
+
+	// Pinned bingo version is used if any.
+	testutil.Ok(t, GenBootstrap(relModDir, "v0.0.0-test", []PackageRenderable{{
+		Name:        "bingo",
+		PackagePath: "github.com/bwplotka/bingo",
+		Versions:    []PackageVersionRenderable{{Version: "v0.4.0"}},
+	}}))
+	b, err = ioutil.ReadFile(filepath.Join(relModDir, BootstrapPowerShellFile))
+	testutil.Ok(t, err)
+	testutil.Assert(t, strings.Contains(string(b), `& $goCmd install github.com/bwplotka/bingo@v0.4.0`), string(b))
+	testutil.Assert(t, strings.Contains(string(b), `get -moddir tools/.bingo @args`), string(b))
+}
@@ -0,0 +1,162 @@
+// Copyright (c) Bartłomiej Płotka @bwplotka
+// Licensed under the Apache License 2.0.
+
+package bingo
+
+import (
+	"debug/buildinfo"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime/debug"
+	"strings"
+
+	"github.com/Masterminds/semver"
+	"github.com/pkg/errors"
+)
+
+// recordedBuildFlags are go build flags recorded in binary build info, so we can compare them with pinned ones.
+var recordedBuildFlags = map[string]struct{}{
+	"-asan":      {},
+	"-asmflags":  {},
+	"-buildmode": {},
+	"-compiler":  {},
+	"-gcflags":   {},
+	"-ldflags":   {},
+	"-msan":      {},
+	"-race":      {},
+	"-tags":      {},
+	"-trimpath":  {},
+}
+
+// BinaryBuildInfo describes how installed binary of pinned tool version was built.
+type BinaryBuildInfo struct {
+	// Installed is false if binary does not exist in GOBIN. Other fields are empty then.
+	Installed bool
+	GoVersion string
+	// Revision is a VCS revision binary was built from, if stamped.
+	Revision string
+	// Mismatches describes differences between how binary was built and how it would be built now, e.g
+	// "built with go1.20.3, current go1.21.0".
+	Mismatches []string
+}
+
+// LoadBuildInfo reads build info of installed binaries of all tool versions from given gobin (or tool's BinDir) and compares it with
+// given current Go version and pinned build env vars and flags. Results are set in BuildInfo of each version.
+func (pkgs PackageRenderables) LoadBuildInfo(gobin string, goVersion *semver.Version) {
+	for i, p := range pkgs {
+		for j, v := range p.Versions {
+			binPath := filepath.Join(p.InstallDir(gobin), p.ArtifactName(v.Version))
+			pkgs[i].Versions[j].BuildInfo = readBinaryBuildInfo(binPath, p.PackagePath, p.ModPath, v.Version, goVersion, p.BuildEnvVars, p.BuildFlags)
+		}
+	}
+}
+
+func readBinaryBuildInfo(binPath, pkgPath, modPath, version string, goVersion *semver.Version, buildEnvVars, buildFlags []string) *BinaryBuildInfo {
+	if _, err := os.Stat(binPath); err != nil {
+		return &BinaryBuildInfo{}
+	}
+	ret := &BinaryBuildInfo{Installed: true}
+	info, err := buildinfo.ReadFile(binPath)
+	if err != nil {
+		ret.Mismatches = append(ret.Mismatches, fmt.Sprintf("cannot read build info: %v", err))
+		return ret
+	}
+	ret.GoVersion = info.GoVersion
+
+	settings := map[string]string{}
+	for _, s := range info.Settings {
+		settings[s.Key] = s.Value
+	}
+	ret.Revision = settings["vcs.revision"]
+
+	if err := verifyBuiltFrom(info, pkgPath, modPath, version); err != nil {
+		ret.Mismatches = append(ret.Mismatches, err.Error())
+	}
+
+	if built, err := semver.NewVersion(strings.TrimPrefix(strings.Fields(info.GoVersion)[0], "go")); err == nil && goVersion != nil && !built.Equal(goVersion) {
+		ret.Mismatches = append(ret.Mismatches, fmt.Sprintf("built with %s, current go%s", info.GoVersion, goVersion.Original()))
+	}
+	for _, e := range buildEnvVars {
+		k, v, _ := strings.Cut(e, "=")
+		if built, ok := settings[k]; ok && built != v {
+			ret.Mismatches = append(ret.Mismatches, fmt.Sprintf("built with %s=%s, pinned %s", k, built, e))
+		}
+	}
+	for _, f := range buildFlags {
+		k, v, ok := strings.Cut(f, "=")
+		if _, recorded := recordedBuildFlags[k]; !recorded {
+			continue
+		}
+		if !ok {
+			v = "true"
+		}
+		built, ok := settings[k]
+		if !ok {
+			ret.Mismatches = append(ret.Mismatches, fmt.Sprintf("built without %s", f))
+			continue
+		}
+		if built != v {
+			ret.Mismatches = append(ret.Mismatches, fmt.Sprintf("built with %s=%s, pinned %s", k, built, f))
+		}
+	}
+	return ret
+}
+
+// builtModule returns module providing main package of binary with given build info: main module for binaries built with
+// 'go install <package>@<version>', or dependency for binaries built by bingo from the tool's mod file (with main module '_').
+func builtModule(info *buildinfo.BuildInfo, modPath string) *debug.Module {
+	if info.Main.Path == modPath {
+		return &info.Main
+	}
+	for _, d := range info.Deps {
+		if d.Path == modPath {
+			return d
+		}
+	}
+	return nil
+}
+
+// verifyBuiltFrom returns error if binary with given build info was not built from given package of given module in one of
+// given versions.
+func verifyBuiltFrom(info *buildinfo.BuildInfo, pkgPath, modPath string, versions ...string) error {
+	if info.Path != pkgPath {
+		return errors.Errorf("built from %s, pinned %s", info.Path, pkgPath)
+	}
+	m := builtModule(info, modPath)
+	if m == nil {
+		return errors.Errorf("built without module %s", modPath)
+	}
+	if m.Replace != nil && m.Replace.Version == "" {
+		// Replaced with local directory, so version is not known.
+		return nil
+	}
+	for _, v := range versions {
+		if m.Version == v || (m.Replace != nil && m.Replace.Version == v) {
+			return nil
+		}
+	}
+	return errors.Errorf("built from %s@%s, pinned %s", modPath, m.Version, strings.Join(versions, ", "))
+}
+
+// VerifyBinary returns error if binary at given path was not built from given package of given module in one of given
+// versions, e.g because it was overwritten by 'go install <package>@latest' outside of bingo.
+func VerifyBinary(binPath, pkgPath, modPath string, versions ...string) error {
+	info, err := buildinfo.ReadFile(binPath)
+	if err != nil {
+		return errors.Wrap(err, "read build info")
+	}
+	return verifyBuiltFrom(info, pkgPath, modPath, versions...)
+}
+
+// status returns short description of binary build info for humans.
+func (b *BinaryBuildInfo) status() string {
+	switch {
+	case !b.Installed:
+		return "not installed"
+	case len(b.Mismatches) > 0:
+		return "mismatch: " + strings.Join(b.Mismatches, "; ")
+	default:
+		return "ok"
+	}
+}
@@ -0,0 +1,65 @@
+// Copyright (c) Bartłomiej Płotka @bwplotka
+// Licensed under the Apache License 2.0.
+
+package bingo
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"testing"
+
+	"github.com/Masterminds/semver"
+	"github.com/efficientgo/tools/core/pkg/testutil"
+)
+
+func TestReadBinaryBuildInfo(t *testing.T) {
+	testBinary, err := os.Executable()
+	testutil.Ok(t, err)
+	info, ok := debug.ReadBuildInfo()
+	testutil.Assert(t, ok)
+
+	t.Run("not installed", func(t *testing.T) {
+		b := readBinaryBuildInfo(filepath.Join(t.TempDir(), "faillint-v1.5.0"), "github.com/fatih/faillint", "github.com/fatih/faillint", "v1.5.0", nil, nil, nil)
+		testutil.Equals(t, &BinaryBuildInfo{}, b)
+		testutil.Equals(t, "not installed", b.status())
+	})
+	t.Run("matching", func(t *testing.T) {
+		current, err := semver.NewVersion(strings.Fields(strings.TrimPrefix(runtime.Version(), "go"))[0])
+		if err != nil {
+			t.Skip("development Go version", runtime.Version())
+		}
+		b := readBinaryBuildInfo(testBinary, info.Path, info.Main.Path, info.Main.Version, current, []string{"GOOS=" + runtime.GOOS, "OTHER=1"}, []string{"-v"})
+		testutil.Equals(t, runtime.Version(), b.GoVersion)
+		testutil.Equals(t, 0, len(b.Mismatches), "%v", b.Mismatches)
+		testutil.Equals(t, "ok", b.status())
+	})
+	t.Run("mismatches", func(t *testing.T) {
+		b := readBinaryBuildInfo(testBinary, info.Path, info.Main.Path, "v0.0.1", semver.MustParse("1.14.0"), []string{"GOOS=plan9"}, []string{"-tags=nope"})
+		testutil.Assert(t, b.Installed)
+		testutil.Equals(t, []string{
+			"built from " + info.Main.Path + "@" + info.Main.Version + ", pinned v0.0.1",
+			"built with " + runtime.Version() + ", current go1.14.0",
+			"built with GOOS=" + runtime.GOOS + ", pinned GOOS=plan9",
+			"built without -tags=nope",
+		}, b.Mismatches)
+	})
+}
+
+func TestVerifyBinary(t *testing.T) {
+	testBinary, err := os.Executable()
+	testutil.Ok(t, err)
+	info, ok := debug.ReadBuildInfo()
+	testutil.Assert(t, ok)
+
+	testutil.Ok(t, VerifyBinary(testBinary, info.Path, info.Main.Path, "v0.0.1", info.Main.Version))
+	// E.g overwritten by 'go install <package>@latest'.
+	err = VerifyBinary(testBinary, info.Path, info.Main.Path, "v0.0.1")
+	testutil.NotOk(t, err)
+	testutil.Equals(t, "built from "+info.Main.Path+"@"+info.Main.Version+", pinned v0.0.1", err.Error())
+	testutil.NotOk(t, VerifyBinary(testBinary, "github.com/fatih/faillint", info.Main.Path, info.Main.Version))
+	testutil.NotOk(t, VerifyBinary(testBinary, info.Path, "github.com/fatih/faillint", "v1.5.0"))
+	testutil.NotOk(t, VerifyBinary(filepath.Join(t.TempDir(), "faillint"), "github.com/fatih/faillint", "github.com/fatih/faillint", "v1.5.0"))
+}
@@ -0,0 +1,39 @@
+// Copyright (c) Bartłomiej Płotka @bwplotka
+// Licensed under the Apache License 2.0.
+
+package bingo
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	// ErrNotInstalled matches (with errors.Is) errors returned when tool referenced by name is not pinned.
+	ErrNotInstalled = errors.New("tool not installed")
+	// ErrNonMainPackage matches (with errors.Is) errors returned when target package is not a main package, so nothing can be built.
+	ErrNonMainPackage = errors.New("non-main package")
+	// ErrPolicyViolation matches (with errors.Is) errors returned when tool depends on modules disallowed by ModulePolicy.
+	ErrPolicyViolation = errors.New("module policy violation")
+	// ErrNotApproved matches (with errors.Is) errors returned when tool version is not approved by Registry.
+	ErrNotApproved = errors.New("not approved by registry")
+)
+
+// ResolveError is returned when module and version of a target package could not be resolved, e.g. because it does not exist.
+type ResolveError struct {
+	// Module is a target package path with optional version that failed to resolve.
+	Module string
+	Err    error
+	// ProxyChain describes which entries of GOPROXY chain were tried and with what result, if GOPROXY is a chain.
+	ProxyChain string
+}
+
+func (e *ResolveError) Error() string {
+	if e.ProxyChain != "" {
+		return fmt.Sprintf("resolve %s: %v; GOPROXY chain: %s", e.Module, e.Err, e.ProxyChain)
+	}
+	return fmt.Sprintf("resolve %s: %v", e.Module, e.Err)
+}
+
+func (e *ResolveError) Unwrap() error { return e.Err }
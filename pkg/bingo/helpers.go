@@ -4,22 +4,149 @@
 package bingo
 
 import (
+	"bytes"
+	"go/format"
+	"io/ioutil"
 	"os"
+	"path"
 	"path/filepath"
+	"strings"
 	"text/template"
 
 	"github.com/pkg/errors"
+	"golang.org/x/mod/semver"
 )
 
-// RemoveHelpers deletes helpers from mod directory.
-func RemoveHelpers(modDir string) error {
-	for ext := range templatesByFileExt {
-		v := "variables." + ext
-		if ext == "mk" {
-			// Exception: for backward compatibility.
-			v = "Variables.mk"
+// TemplatesDir is a directory inside mod directory where user can put <generated file>.tmpl files
+// (e.g templates/Variables.mk.tmpl) that override built-in templates for generated files.
+const TemplatesDir = "templates"
+
+// BinPathMode defines how paths to binaries are rendered in generated helper files.
+type BinPathMode string
+
+const (
+	// GOBINBinPathMode renders paths relative to GOBIN resolved on machine where helpers are used.
+	GOBINBinPathMode = BinPathMode("gobin")
+	// AbsoluteBinPathMode renders absolute paths with GOBIN resolved during generation.
+	AbsoluteBinPathMode = BinPathMode("absolute")
+	// RelocatableBinPathMode renders paths relative to BINGO_BIN variable, which defaults to GOBIN, but can be overridden.
+	RelocatableBinPathMode = BinPathMode("relocatable")
+	// LocalBinPathMode renders paths relative to BINGO_BIN variable, which defaults to project-local LocalBinDir inside
+	// mod directory, where binaries are installed instead of GOBIN. Avoids version collisions between projects.
+	LocalBinPathMode = BinPathMode("local")
+)
+
+// LocalBinDir is a directory inside mod directory where binaries are installed in LocalBinPathMode. It's ignored by
+// generated .gitignore.
+const LocalBinDir = "bin"
+
+// HasLocalBinDir returns true if given mod directory has LocalBinDir, so tools are installed there instead of GOBIN.
+func HasLocalBinDir(modDir string) bool {
+	fi, err := os.Stat(filepath.Join(modDir, LocalBinDir))
+	return err == nil && fi.IsDir()
+}
+
+// GenPolicy defines when generated file is written.
+type GenPolicy string
+
+const (
+	// AlwaysGenPolicy (re)generates file on every bingo get.
+	AlwaysGenPolicy = GenPolicy("always")
+	// IfMissingGenPolicy generates file only if it does not exist, so it can be customized afterwards.
+	IfMissingGenPolicy = GenPolicy("if-missing")
+	// NeverGenPolicy never generates (or removes) file.
+	NeverGenPolicy = GenPolicy("never")
+)
+
+// GitignoreFile is a name of generated .gitignore file in mod directory.
+const GitignoreFile = ".gitignore"
+
+// ParseGenPolicies parses comma separated <generated file>=<policy> pairs (e.g "README.md=if-missing,.gitignore=never").
+func ParseGenPolicies(s string) (map[string]GenPolicy, error) {
+	ret := map[string]GenPolicy{}
+	if s == "" {
+		return ret, nil
+	}
+	for _, kv := range strings.Split(s, ",") {
+		split := strings.SplitN(kv, "=", 2)
+		if len(split) != 2 {
+			return nil, errors.Errorf("expected <file>=<policy>, got %q", kv)
+		}
+		f, p := strings.TrimSpace(split[0]), GenPolicy(strings.TrimSpace(split[1]))
+		if _, ok := templatesByFile[f]; !ok && f != readmeFile && f != GitignoreFile {
+			return nil, errors.Errorf("%q is not a generated file", f)
+		}
+		switch p {
+		case AlwaysGenPolicy, IfMissingGenPolicy, NeverGenPolicy:
+		default:
+			return nil, errors.Errorf("unknown generation policy %q for %v; expected one of %v, %v, %v", p, f, AlwaysGenPolicy, IfMissingGenPolicy, NeverGenPolicy)
+		}
+		ret[f] = p
+	}
+	return ret, nil
+}
+
+// HelpersConfig configures generation of helper files.
+type HelpersConfig struct {
+	// BinPathMode defines how paths to binaries are rendered. Defaults to GOBINBinPathMode.
+	BinPathMode BinPathMode
+	// GOBIN is an absolute path where binaries are installed. Required for AbsoluteBinPathMode.
+	GOBIN string
+
+	// GoPackage is a package name used in generated variables.go. Defaults to "bingo".
+	GoPackage string
+	// GoBuildConstraint is an optional build constraint (e.g "tools") added to generated variables.go.
+	GoBuildConstraint string
+	// GoOutFile is a path to generated variables.go. Defaults to <relModDir>/variables.go.
+	GoOutFile string
+
+	// GenPolicies are generation policies by generated file name. Files not specified use AlwaysGenPolicy.
+	GenPolicies map[string]GenPolicy
+}
+
+// ShouldGenerate returns true if given generated file should be written to out path according to its GenPolicy.
+func (c HelpersConfig) ShouldGenerate(f, out string) (bool, error) {
+	switch c.GenPolicies[f] {
+	case NeverGenPolicy:
+		return false, nil
+	case IfMissingGenPolicy:
+		if _, err := os.Stat(out); err != nil {
+			if os.IsNotExist(err) {
+				return true, nil
+			}
+			return false, err
 		}
-		if err := os.RemoveAll(filepath.Join(modDir, v)); err != nil {
+		return false, nil
+	}
+	return true, nil
+}
+
+func (c HelpersConfig) outFile(relModDir, f string) string {
+	if f == goVariablesFile && c.GoOutFile != "" {
+		return c.GoOutFile
+	}
+	return filepath.Join(relModDir, f)
+}
+
+// RemovedHelpers returns paths of helpers (including generated README) RemoveHelpers deletes from given mod directory.
+func (c HelpersConfig) RemovedHelpers(modDir string) []string {
+	var files []string
+	for f := range templatesByFile {
+		if c.GenPolicies[f] == NeverGenPolicy {
+			continue
+		}
+		files = append(files, c.outFile(modDir, f))
+	}
+	if c.GenPolicies[readmeFile] != NeverGenPolicy {
+		files = append(files, c.outFile(modDir, readmeFile))
+	}
+	return files
+}
+
+// RemoveHelpers deletes helpers from mod directory.
+func RemoveHelpers(modDir string, cfg HelpersConfig) error {
+	for _, f := range cfg.RemovedHelpers(modDir) {
+		if err := os.RemoveAll(f); err != nil {
 			return err
 		}
 	}
@@ -29,50 +156,192 @@ func RemoveHelpers(modDir string) error {
 // GenHelpers generates helpers to allows reliable binaries use. Regenerate if needed.
 // It is expected to have at least one mod file.
 // TODO(bwplotka): Allow installing those optionally?
-func GenHelpers(relModDir, version string, pkgs []PackageRenderable) error {
-	for ext, tmpl := range templatesByFileExt {
-		v := "variables." + ext
-		if ext == "mk" {
-			// Exception: for backward compatibility.
-			v = "Variables.mk"
+func GenHelpers(relModDir, version string, pkgs []PackageRenderable, cfg HelpersConfig) error {
+	rendered, err := RenderHelpers(relModDir, version, pkgs, cfg)
+	if err != nil {
+		return err
+	}
+	for out, content := range rendered {
+		if err := os.MkdirAll(filepath.Dir(out), os.ModePerm); err != nil {
+			return errors.Wrapf(err, "create directory for %v", out)
 		}
-		if err := genHelper(v, tmpl, relModDir, version, pkgs); err != nil {
-			return errors.Wrap(err, v)
+		if err := WriteFileIfChanged(out, content); err != nil {
+			return err
 		}
 	}
 	return nil
 }
 
+// RenderHelpers renders helpers GenHelpers would write, without writing them. It returns their content by output path.
+func RenderHelpers(relModDir, version string, pkgs []PackageRenderable, cfg HelpersConfig) (map[string][]byte, error) {
+	if err := PackageRenderables(pkgs).ValidateEnvVarNames(); err != nil {
+		return nil, err
+	}
+
+	data := templateData{
+		Version:           version,
+		MainPackages:      pkgs,
+		RelModDir:         filepath.ToSlash(relModDir),
+		GoPackage:         cfg.GoPackage,
+		GoBuildConstraint: cfg.GoBuildConstraint,
+	}
+	if data.GoPackage == "" {
+		data.GoPackage = "bingo"
+	}
+	switch cfg.BinPathMode {
+	case GOBINBinPathMode, "":
+		data.BinPathMode, data.MakeBinDir, data.EnvBinDir = GOBINBinPathMode, "$(GOBIN)", "${GOBIN}"
+	case AbsoluteBinPathMode:
+		if !filepath.IsAbs(cfg.GOBIN) {
+			return nil, errors.Errorf("%v bin path mode requires absolute GOBIN path, got %q", cfg.BinPathMode, cfg.GOBIN)
+		}
+		data.BinPathMode, data.MakeBinDir, data.EnvBinDir = AbsoluteBinPathMode, filepath.ToSlash(cfg.GOBIN), filepath.ToSlash(cfg.GOBIN)
+	case RelocatableBinPathMode:
+		data.BinPathMode, data.MakeBinDir, data.EnvBinDir = RelocatableBinPathMode, "$(BINGO_BIN)", "${BINGO_BIN}"
+	case LocalBinPathMode:
+		data.BinPathMode, data.MakeBinDir, data.EnvBinDir = LocalBinPathMode, "$(BINGO_BIN)", "${BINGO_BIN}"
+		// variables.env does not know its location, so it assumes it's sourced from the directory bingo was run in.
+		data.EnvLocalBinDir = path.Join("$(pwd)", filepath.ToSlash(relModDir), LocalBinDir)
+		if filepath.IsAbs(relModDir) {
+			data.EnvLocalBinDir = filepath.ToSlash(filepath.Join(relModDir, LocalBinDir))
+		}
+	default:
+		return nil, errors.Errorf("unknown bin path mode %q", cfg.BinPathMode)
+	}
+	for _, p := range pkgs {
+		if p.EnvVarName == "BINGO" {
+			data.BingoPinned = true
+		}
+	}
+	data.BingoPackage, data.BingoVersion = bingoInstallTarget(version, pkgs)
+
+	rendered := map[string][]byte{}
+	for f, tmpl := range templatesByFile {
+		if err := renderHelper(rendered, f, tmpl, relModDir, cfg, data); err != nil {
+			return nil, errors.Wrap(err, f)
+		}
+	}
+	if err := renderHelper(rendered, readmeFile, readmeTemplate, relModDir, cfg, data); err != nil {
+		return nil, errors.Wrap(err, readmeFile)
+	}
+	return rendered, nil
+}
+
+// bingoInstallTarget returns package path and version of bingo to install: pinned bingo, if any, given version (e.g of
+// bingo generating files) if it's a release, or the latest release otherwise, as development builds cannot be installed.
+func bingoInstallTarget(version string, pkgs []PackageRenderable) (pkgPath, v string) {
+	for _, p := range pkgs {
+		if p.Name == "bingo" && len(p.Versions) == 1 {
+			return p.PackagePath, p.Versions[0].Version
+		}
+	}
+	if semver.IsValid(version) && semver.Prerelease(version) == "" && semver.Build(version) == "" {
+		return bingoModulePath, version
+	}
+	return bingoModulePath, "latest"
+}
+
 type templateData struct {
 	Version      string
 	GobinPath    string
 	MainPackages []PackageRenderable
 	RelModDir    string
+	// BingoPinned is true if bingo itself is one of the pinned tools (under BINGO variable).
+	BingoPinned bool
+	// BingoPackage and BingoVersion are package path and version of bingo installed under BINGO variable if it's not pinned.
+	BingoPackage string
+	BingoVersion string
+
+	GoPackage         string
+	GoBuildConstraint string
+
+	BinPathMode BinPathMode
+	// MakeBinDir and EnvBinDir are directories with binaries, as rendered in Makefile and shell respectively.
+	MakeBinDir string
+	EnvBinDir  string
+	// EnvLocalBinDir is LocalBinDir as rendered in shell, set in LocalBinPathMode.
+	EnvLocalBinDir string
 }
 
-func genHelper(f, tmpl, relModDir, version string, pkgs []PackageRenderable) error {
-	t, err := template.New(f).Parse(tmpl)
+// MakeBinDirOf returns directory with binaries of given tool, as rendered in Makefile.
+func (d templateData) MakeBinDirOf(p PackageRenderable) string {
+	switch {
+	case p.BinDir == "":
+		return d.MakeBinDir
+	case path.IsAbs(p.BinDir) || filepath.IsAbs(filepath.FromSlash(p.BinDir)):
+		return p.BinDir
+	}
+	return "$(abspath $(BINGO_DIR)/" + p.BinDir + ")"
+}
+
+// EnvBinDirOf returns directory with binaries of given tool, as rendered in shell. Like for LocalBinPathMode, directory
+// relative to mod directory assumes variables.env is sourced from the directory bingo was run in.
+func (d templateData) EnvBinDirOf(p PackageRenderable) string {
+	switch {
+	case p.BinDir == "":
+		return d.EnvBinDir
+	case path.IsAbs(p.BinDir) || filepath.IsAbs(filepath.FromSlash(p.BinDir)):
+		return p.BinDir
+	case path.IsAbs(d.RelModDir) || filepath.IsAbs(filepath.FromSlash(d.RelModDir)):
+		return path.Join(d.RelModDir, p.BinDir)
+	}
+	return path.Join("$(pwd)", d.RelModDir, p.BinDir)
+}
+
+// userTemplate returns template from TemplatesDir that overrides given built-in one, if exists.
+func userTemplate(relModDir, f, builtIn string) (string, error) {
+	b, err := ioutil.ReadFile(filepath.Join(relModDir, TemplatesDir, f+".tmpl"))
 	if err != nil {
-		return errors.Wrap(err, "parse template")
+		if os.IsNotExist(err) {
+			return builtIn, nil
+		}
+		return "", errors.Wrap(err, "read user template")
 	}
+	return string(b), nil
+}
 
-	data := templateData{
-		Version:      version,
-		MainPackages: pkgs,
+// renderHelper renders given generated file into given map by its output path, if it should be generated.
+func renderHelper(rendered map[string][]byte, f, tmpl, relModDir string, cfg HelpersConfig, data templateData) (err error) {
+	out := cfg.outFile(relModDir, f)
+	if ok, err := cfg.ShouldGenerate(f, out); err != nil || !ok {
+		return err
 	}
 
-	fb, err := os.Create(filepath.Join(relModDir, f))
+	tmpl, err = userTemplate(relModDir, f, tmpl)
 	if err != nil {
-		return errors.Wrap(err, "create")
+		return err
 	}
-	defer func() {
-		if cerr := fb.Close(); cerr != nil {
-			if err != nil {
-				err = errors.Wrapf(err, "additionally error on close: %v", cerr)
-				return
-			}
-			err = cerr
+
+	t, err := template.New(f).Parse(tmpl)
+	if err != nil {
+		return errors.Wrap(err, "parse template")
+	}
+
+	b := &bytes.Buffer{}
+	if err := t.Execute(b, data); err != nil {
+		return errors.Wrap(err, "execute template")
+	}
+	// User templates can be checked out with CRLF line endings (e.g on Windows), but output has to be the same everywhere.
+	content := bytes.ReplaceAll(b.Bytes(), []byte("\r\n"), []byte("\n"))
+	if filepath.Ext(out) == ".go" {
+		if content, err = format.Source(content); err != nil {
+			return errors.Wrap(err, "format generated Go code")
 		}
-	}()
-	return t.Execute(fb, data)
+	}
+
+	rendered[out] = content
+	return nil
+}
+
+// WriteFileIfChanged writes content to the given file only if it does not exist or its content differs,
+// so unchanged files are not touched (e.g their modification time stays the same).
+func WriteFileIfChanged(file string, content []byte) error {
+	existing, err := ioutil.ReadFile(file)
+	if err == nil && bytes.Equal(existing, content) {
+		return nil
+	}
+	if err != nil && !os.IsNotExist(err) {
+		return errors.Wrapf(err, "read %v", file)
+	}
+	return ioutil.WriteFile(file, content, 0666)
 }
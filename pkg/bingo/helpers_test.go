@@ -0,0 +1,378 @@
+// Copyright (c) Bartłomiej Płotka @bwplotka
+// Licensed under the Apache License 2.0.
+
+package bingo
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/efficientgo/tools/core/pkg/testutil"
+)
+
+func TestGenHelpers_UserTemplates(t *testing.T) {
+	tmpDir, err := ioutil.TempDir(os.TempDir(), "bingo-helpers")
+	testutil.Ok(t, err)
+	t.Cleanup(func() { testutil.Ok(t, os.RemoveAll(tmpDir)) })
+
+	testutil.Ok(t, os.MkdirAll(filepath.Join(tmpDir, TemplatesDir), os.ModePerm))
+	testutil.Ok(t, ioutil.WriteFile(
+		filepath.Join(tmpDir, TemplatesDir, "variables.env.tmpl"),
+		[]byte(`{{ range .MainPackages }}export {{ .EnvVarName }}={{ .PackagePath }}{{ end }}`),
+		os.ModePerm,
+	))
+
+	pkgs := []PackageRenderable{{
+		Name:        "faillint",
+		ModPath:     "github.com/fatih/faillint",
+		PackagePath: "github.com/fatih/faillint",
+		EnvVarName:  "FAILLINT",
+		Versions:    []PackageVersionRenderable{{Version: "v1.5.0", ModFile: "faillint.mod"}},
+	}}
+	testutil.Ok(t, GenHelpers(tmpDir, "v0.0.0-test", pkgs, HelpersConfig{}))
+
+	expectContent(t, "export FAILLINT=github.com/fatih/faillint", filepath.Join(tmpDir, "variables.env"))
+
+	// Built-in templates are used for files without user template.
+	b, err := ioutil.ReadFile(filepath.Join(tmpDir, "Variables.mk"))
+	testutil.Ok(t, err)
+	testutil.Assert(t, len(b) > 0)
+	_, err = os.Stat(filepath.Join(tmpDir, "README.md"))
+	testutil.Ok(t, err)
+}
+
+func TestGenHelpers_GoVariables(t *testing.T) {
+	tmpDir, err := ioutil.TempDir(os.TempDir(), "bingo-helpers")
+	testutil.Ok(t, err)
+	t.Cleanup(func() { testutil.Ok(t, os.RemoveAll(tmpDir)) })
+
+	pkgs := []PackageRenderable{
+		{
+			Name:        "f2",
+			ModPath:     "github.com/fatih/faillint",
+			PackagePath: "github.com/fatih/faillint",
+			EnvVarName:  "F2_ARRAY",
+			Versions: []PackageVersionRenderable{
+				{Version: "v1.5.0", ModFile: "f2.mod"},
+				{Version: "v1.2.0", ModFile: "f2.1.mod"},
+			},
+		},
+		{
+			Name:        "faillint",
+			ModPath:     "github.com/fatih/faillint",
+			PackagePath: "github.com/fatih/faillint",
+			EnvVarName:  "FAILLINT",
+			Versions:    []PackageVersionRenderable{{Version: "v1.5.0", ModFile: "faillint.mod"}},
+		},
+		{
+			Name:         "plugin",
+			ModPath:      "github.com/org/plugin",
+			PackagePath:  "github.com/org/plugin",
+			EnvVarName:   "PLUGIN",
+			BuildEnvVars: []string{"GOOS=wasip1", "GOARCH=wasm"},
+			Versions:     []PackageVersionRenderable{{Version: "v0.1.0", ModFile: "plugin.mod"}},
+		},
+	}
+	cfg := HelpersConfig{
+		GoPackage:         "tools",
+		GoBuildConstraint: "tools",
+		GoOutFile:         filepath.Join(tmpDir, "internal", "tools", "bingo.go"),
+	}
+	testutil.Ok(t, GenHelpers(tmpDir, "v0.0.0-test", pkgs, cfg))
+
+	expectContent(t, `// Code generated by https://github.com/bwplotka/bingo v0.0.0-test. DO NOT EDIT.
+
+//go:build tools
+
+package tools
+
+import (
+	"os"
+	"runtime"
+)
+
+// All tools are designed to be build inside $GOBIN. Each tool has constants with its binary name (or names for tools pinned
+// in many versions), package path, module path, version(s) and bingo mod file name(s) relative to the bingo mod directory.
+// Binary names do not include executable suffix, as this file is shared by all platforms; add GOEXE when running them.
+// Names of WebAssembly tools (built with GOARCH=wasm) include .wasm suffix and do not need GOEXE.
+
+// GOEXE is suffix of executable names on the platform the code runs on (".exe" on Windows), unless overridden by GOEXE
+// environment variable, the same way bingo names installed binaries.
+var GOEXE = func() string {
+	if goexe, ok := os.LookupEnv("GOEXE"); ok {
+		return goexe
+	}
+	if runtime.GOOS == "windows" {
+		return ".exe"
+	}
+	return ""
+}()
+
+// f2 tool pinned in many versions.
+const (
+	F2_ARRAY_PACKAGE = "github.com/fatih/faillint"
+	F2_ARRAY_MODULE  = "github.com/fatih/faillint"
+)
+
+var (
+	F2_ARRAY           = []string{"f2-v1.5.0", "f2-v1.2.0"}
+	F2_ARRAY_VERSIONS  = []string{"v1.5.0", "v1.2.0"}
+	F2_ARRAY_MOD_FILES = []string{"f2.mod", "f2.1.mod"}
+)
+
+// faillint tool.
+const (
+	FAILLINT          = "faillint-v1.5.0"
+	FAILLINT_PACKAGE  = "github.com/fatih/faillint"
+	FAILLINT_MODULE   = "github.com/fatih/faillint"
+	FAILLINT_VERSION  = "v1.5.0"
+	FAILLINT_MOD_FILE = "faillint.mod"
+)
+
+// plugin tool.
+const (
+	PLUGIN          = "plugin-v0.1.0.wasm"
+	PLUGIN_PACKAGE  = "github.com/org/plugin"
+	PLUGIN_MODULE   = "github.com/org/plugin"
+	PLUGIN_VERSION  = "v0.1.0"
+	PLUGIN_MOD_FILE = "plugin.mod"
+)
+`, cfg.GoOutFile)
+	_, err = os.Stat(filepath.Join(tmpDir, "variables.go"))
+	testutil.Assert(t, os.IsNotExist(err))
+
+	_, err = os.Stat(filepath.Join(tmpDir, readmeFile))
+	testutil.Ok(t, err)
+
+	testutil.Ok(t, RemoveHelpers(tmpDir, cfg))
+	_, err = os.Stat(cfg.GoOutFile)
+	testutil.Assert(t, os.IsNotExist(err))
+	_, err = os.Stat(filepath.Join(tmpDir, readmeFile))
+	testutil.Assert(t, os.IsNotExist(err))
+}
+
+func TestGenHelpers_GenPolicies(t *testing.T) {
+	tmpDir, err := ioutil.TempDir(os.TempDir(), "bingo-helpers")
+	testutil.Ok(t, err)
+	t.Cleanup(func() { testutil.Ok(t, os.RemoveAll(tmpDir)) })
+
+	_, err = ParseGenPolicies("README.md=sometimes")
+	testutil.NotOk(t, err)
+	_, err = ParseGenPolicies("main.go=never")
+	testutil.NotOk(t, err)
+
+	policies, err := ParseGenPolicies("README.md=if-missing,variables.go=never")
+	testutil.Ok(t, err)
+	testutil.Equals(t, map[string]GenPolicy{readmeFile: IfMissingGenPolicy, goVariablesFile: NeverGenPolicy}, policies)
+
+	testutil.Ok(t, ioutil.WriteFile(filepath.Join(tmpDir, readmeFile), []byte("custom"), os.ModePerm))
+	pkgs := []PackageRenderable{{
+		Name:        "faillint",
+		ModPath:     "github.com/fatih/faillint",
+		PackagePath: "github.com/fatih/faillint",
+		EnvVarName:  "FAILLINT",
+		Versions:    []PackageVersionRenderable{{Version: "v1.5.0", ModFile: "faillint.mod"}},
+	}}
+	testutil.Ok(t, GenHelpers(tmpDir, "v0.0.0-test", pkgs, HelpersConfig{GenPolicies: policies}))
+
+	expectContent(t, "custom", filepath.Join(tmpDir, readmeFile))
+	_, err = os.Stat(filepath.Join(tmpDir, goVariablesFile))
+	testutil.Assert(t, os.IsNotExist(err))
+	_, err = os.Stat(filepath.Join(tmpDir, "variables.env"))
+	testutil.Ok(t, err)
+
+	testutil.Ok(t, RemoveHelpers(tmpDir, HelpersConfig{GenPolicies: map[string]GenPolicy{readmeFile: NeverGenPolicy}}))
+	expectContent(t, "custom", filepath.Join(tmpDir, readmeFile))
+	_, err = os.Stat(filepath.Join(tmpDir, "variables.env"))
+	testutil.Assert(t, os.IsNotExist(err))
+}
+
+func TestGenHelpers_UnchangedFilesNotWritten(t *testing.T) {
+	tmpDir, err := ioutil.TempDir(os.TempDir(), "bingo-helpers")
+	testutil.Ok(t, err)
+	t.Cleanup(func() { testutil.Ok(t, os.RemoveAll(tmpDir)) })
+
+	pkgs := []PackageRenderable{{
+		Name:        "faillint",
+		ModPath:     "github.com/fatih/faillint",
+		PackagePath: "github.com/fatih/faillint",
+		EnvVarName:  "FAILLINT",
+		Versions:    []PackageVersionRenderable{{Version: "v1.5.0", ModFile: "faillint.mod"}},
+	}}
+	testutil.Ok(t, GenHelpers(tmpDir, "v0.0.0-test", pkgs, HelpersConfig{}))
+
+	// Move modification time to the past, so we can detect any write.
+	past := time.Now().Add(-1 * time.Hour).Truncate(time.Second)
+	for _, f := range []string{readmeFile, goVariablesFile, "Variables.mk", "variables.env"} {
+		testutil.Ok(t, os.Chtimes(filepath.Join(tmpDir, f), past, past))
+	}
+
+	testutil.Ok(t, GenHelpers(tmpDir, "v0.0.0-test", pkgs, HelpersConfig{}))
+	for _, f := range []string{readmeFile, goVariablesFile, "Variables.mk", "variables.env"} {
+		s, err := os.Stat(filepath.Join(tmpDir, f))
+		testutil.Ok(t, err)
+		testutil.Assert(t, s.ModTime().Equal(past), "%v was rewritten", f)
+	}
+
+	testutil.Ok(t, GenHelpers(tmpDir, "v0.0.0-test2", pkgs, HelpersConfig{}))
+	s, err := os.Stat(filepath.Join(tmpDir, "Variables.mk"))
+	testutil.Ok(t, err)
+	testutil.Assert(t, !s.ModTime().Equal(past), "Variables.mk was expected to be rewritten")
+}
+
+func TestGenHelpers_LocalBinPathMode(t *testing.T) {
+	t.Setenv("GOEXE", "")
+	tmpDir := t.TempDir()
+	testutil.Equals(t, false, HasLocalBinDir(tmpDir))
+	testutil.Ok(t, os.MkdirAll(filepath.Join(tmpDir, LocalBinDir), os.ModePerm))
+	testutil.Equals(t, true, HasLocalBinDir(tmpDir))
+
+	wd, err := os.Getwd()
+	testutil.Ok(t, err)
+	testutil.Ok(t, os.Chdir(tmpDir))
+	t.Cleanup(func() { _ = os.Chdir(wd) })
+
+	pkgs := []PackageRenderable{{
+		Name:        "faillint",
+		ModPath:     "github.com/fatih/faillint",
+		PackagePath: "github.com/fatih/faillint",
+		EnvVarName:  "FAILLINT",
+		Versions:    []PackageVersionRenderable{{Version: "v1.5.0", ModFile: "faillint.mod"}},
+	}}
+	testutil.Ok(t, os.MkdirAll(".bingo", os.ModePerm))
+	testutil.Ok(t, GenHelpers(".bingo", "v0.0.0-test", pkgs, HelpersConfig{BinPathMode: LocalBinPathMode}))
+
+	b, err := ioutil.ReadFile(filepath.Join(".bingo", "Variables.mk"))
+	testutil.Ok(t, err)
+	testutil.Assert(t, strings.Contains(string(b), "\nBINGO_BIN ?= $(abspath $(BINGO_DIR))/bin\n"), string(b))
+	testutil.Assert(t, strings.Contains(string(b), "\nFAILLINT := $(BINGO_BIN)/faillint-v1.5.0$(GOEXE)\n"), string(b))
+
+	b, err = ioutil.ReadFile(filepath.Join(".bingo", "variables.env"))
+	testutil.Ok(t, err)
+	testutil.Assert(t, strings.Contains(string(b), "\nBINGO_BIN=${BINGO_BIN:=$(pwd)/.bingo/bin}\n"), string(b))
+	testutil.Assert(t, strings.Contains(string(b), "\nFAILLINT=\"${BINGO_BIN}/faillint-v1.5.0${GOEXE}\"\n"), string(b))
+}
+
+func TestGenHelpers_Aliases(t *testing.T) {
+	t.Setenv("GOEXE", "")
+	tmpDir := t.TempDir()
+
+	pkgs := []PackageRenderable{{
+		Name:        "kubectl",
+		ModPath:     "k8s.io/kubectl",
+		PackagePath: "k8s.io/kubectl",
+		EnvVarName:  "KUBECTL",
+		Versions:    []PackageVersionRenderable{{Version: "v0.29.0", ModFile: "kubectl.mod"}},
+		Aliases:     aliasRenderables([]string{"k"}),
+	}}
+	testutil.Ok(t, GenHelpers(tmpDir, "v0.0.0-test", pkgs, HelpersConfig{}))
+
+	b, err := ioutil.ReadFile(filepath.Join(tmpDir, "Variables.mk"))
+	testutil.Ok(t, err)
+	testutil.Assert(t, strings.Contains(string(b), "\nK := $(KUBECTL)\n"), string(b))
+
+	b, err = ioutil.ReadFile(filepath.Join(tmpDir, "variables.env"))
+	testutil.Ok(t, err)
+	testutil.Assert(t, strings.Contains(string(b), "\nK=\"${KUBECTL}\"\n"), string(b))
+}
+
+func TestGenHelpers_GoFlags(t *testing.T) {
+	t.Setenv("GOEXE", "")
+	tmpDir := t.TempDir()
+
+	pkgs := []PackageRenderable{{
+		Name:        "buf",
+		ModPath:     "github.com/bufbuild/buf",
+		PackagePath: "github.com/bufbuild/buf/cmd/buf",
+		EnvVarName:  "BUF",
+		Versions:    []PackageVersionRenderable{{Version: "v0.1.0", ModFile: "buf.mod"}},
+		GoFlags:     "-tags=netgo -trimpath",
+	}}
+	testutil.Ok(t, GenHelpers(tmpDir, "v0.0.0-test", pkgs, HelpersConfig{}))
+
+	b, err := ioutil.ReadFile(filepath.Join(tmpDir, "Variables.mk"))
+	testutil.Ok(t, err)
+	testutil.Assert(t, strings.Contains(string(b), "@cd $(BINGO_DIR) && GOFLAGS='-tags=netgo -trimpath' $(GO) build -mod=mod -modfile=buf.mod"), string(b))
+}
+
+func TestGenHelpers_BinDir(t *testing.T) {
+	t.Setenv("GOEXE", "")
+	tmpDir := t.TempDir()
+
+	pkgs := []PackageRenderable{
+		{
+			Name:        "protoc-gen-go",
+			ModPath:     "google.golang.org/protobuf",
+			PackagePath: "google.golang.org/protobuf/cmd/protoc-gen-go",
+			EnvVarName:  "PROTOC_GEN_GO",
+			Versions:    []PackageVersionRenderable{{Version: "v1.31.0", ModFile: "protoc-gen-go.mod"}},
+			BinDir:      "../third_party/protoc/bin",
+		},
+		{
+			Name:        "buf",
+			ModPath:     "github.com/bufbuild/buf",
+			PackagePath: "github.com/bufbuild/buf/cmd/buf",
+			EnvVarName:  "BUF",
+			Versions:    []PackageVersionRenderable{{Version: "v0.1.0", ModFile: "buf.mod"}},
+		},
+	}
+	testutil.Ok(t, GenHelpers(tmpDir, "v0.0.0-test", pkgs, HelpersConfig{}))
+
+	b, err := ioutil.ReadFile(filepath.Join(tmpDir, "Variables.mk"))
+	testutil.Ok(t, err)
+	testutil.Assert(t, strings.Contains(string(b), "PROTOC_GEN_GO := $(abspath $(BINGO_DIR)/../third_party/protoc/bin)/protoc-gen-go-v1.31.0$(GOEXE)\n"), string(b))
+	testutil.Assert(t, strings.Contains(string(b), "@mkdir -p $(abspath $(BINGO_DIR)/../third_party/protoc/bin)\n"), string(b))
+	testutil.Assert(t, strings.Contains(string(b), "BUF := $(GOBIN)/buf-v0.1.0$(GOEXE)\n"), string(b))
+
+	b, err = ioutil.ReadFile(filepath.Join(tmpDir, "variables.env"))
+	testutil.Ok(t, err)
+	// Mod directory is absolute, so is the tool's directory.
+	testutil.Assert(t, strings.Contains(string(b), `PROTOC_GEN_GO="`+filepath.ToSlash(filepath.Join(tmpDir, "../third_party/protoc/bin"))+`/protoc-gen-go-v1.31.0${GOEXE}"`), string(b))
+	testutil.Assert(t, strings.Contains(string(b), `BUF="${GOBIN}/buf-v0.1.0${GOEXE}"`), string(b))
+}
+
+func TestGenHelpers_BingoInstallVersion(t *testing.T) {
+	tmpDir := t.TempDir()
+	pkgs := []PackageRenderable{{
+		Name:        "faillint",
+		ModPath:     "github.com/fatih/faillint",
+		PackagePath: "github.com/fatih/faillint",
+		EnvVarName:  "FAILLINT",
+		Versions:    []PackageVersionRenderable{{Version: "v1.5.0", ModFile: "faillint.mod"}},
+	}}
+
+	// Development builds cannot be installed, so the latest release is.
+	testutil.Ok(t, GenHelpers(tmpDir, "v0.5.0-dev", pkgs, HelpersConfig{}))
+	b, err := ioutil.ReadFile(filepath.Join(tmpDir, "Variables.mk"))
+	testutil.Ok(t, err)
+	testutil.Assert(t, strings.Contains(string(b), "\nBINGO := $(GOBIN)/bingo-latest$(GOEXE)\n"), string(b))
+	testutil.Assert(t, strings.Contains(string(b), " install github.com/bwplotka/bingo@latest "), string(b))
+
+	testutil.Ok(t, GenHelpers(tmpDir, "v0.5.0", pkgs, HelpersConfig{}))
+	b, err = ioutil.ReadFile(filepath.Join(tmpDir, "Variables.mk"))
+	testutil.Ok(t, err)
+	testutil.Assert(t, strings.Contains(string(b), " install github.com/bwplotka/bingo@v0.5.0 "), string(b))
+
+	// Pinned bingo is installed, if it's not under BINGO variable already (e.g because of variable prefix).
+	pkgs = append(pkgs, PackageRenderable{
+		Name:        "bingo",
+		ModPath:     "github.com/bwplotka/bingo",
+		PackagePath: "github.com/bwplotka/bingo",
+		EnvVarName:  "TOOL_BINGO",
+		Versions:    []PackageVersionRenderable{{Version: "v0.4.0", ModFile: "bingo.mod"}},
+	})
+	testutil.Ok(t, GenHelpers(tmpDir, "v0.5.0-dev", pkgs, HelpersConfig{}))
+	b, err = ioutil.ReadFile(filepath.Join(tmpDir, "Variables.mk"))
+	testutil.Ok(t, err)
+	testutil.Assert(t, strings.Contains(string(b), " install github.com/bwplotka/bingo@v0.4.0 "), string(b))
+
+	pkgs[1].EnvVarName = "BINGO"
+	testutil.Ok(t, GenHelpers(tmpDir, "v0.5.0-dev", pkgs, HelpersConfig{}))
+	b, err = ioutil.ReadFile(filepath.Join(tmpDir, "Variables.mk"))
+	testutil.Ok(t, err)
+	testutil.Assert(t, !strings.Contains(string(b), "$(GO) install"), string(b))
+}
@@ -0,0 +1,86 @@
+// Copyright (c) Bartłomiej Płotka @bwplotka
+// Licensed under the Apache License 2.0.
+
+package bingo
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// LabelsCommand sets labels of the tool, e.g `// bingo:labels stage=release,team=platform`.
+const LabelsCommand = "bingo:labels"
+
+var labelKeyRegexp = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._/-]*$`)
+
+// Labels are arbitrary key-value pairs attached to a tool (e.g team=platform), used to select tools for bulk operations.
+type Labels map[string]string
+
+// ParseLabels parses comma separated key=value pairs (e.g "team=platform,stage=release"). Value can be empty (e.g "team=").
+func ParseLabels(s string) (Labels, error) {
+	l := Labels{}
+	for _, kv := range strings.Split(s, ",") {
+		kv = strings.TrimSpace(kv)
+		if kv == "" {
+			continue
+		}
+		k, v, ok := strings.Cut(kv, "=")
+		if !ok {
+			return nil, errors.Errorf("label %q is not in key=value format", kv)
+		}
+		k, v = strings.TrimSpace(k), strings.TrimSpace(v)
+		if !labelKeyRegexp.MatchString(k) {
+			return nil, errors.Errorf("invalid label key %q; allowed characters [A-Za-z0-9._/-]", k)
+		}
+		if strings.ContainsAny(v, ", ") {
+			return nil, errors.Errorf("invalid label value %q; commas and spaces are not allowed", v)
+		}
+		l[k] = v
+	}
+	return l, nil
+}
+
+// String returns labels in the same format as parsed by ParseLabels, sorted by key.
+func (l Labels) String() string {
+	keys := make([]string, 0, len(l))
+	for k := range l {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	kvs := make([]string, 0, len(keys))
+	for _, k := range keys {
+		kvs = append(kvs, k+"="+l[k])
+	}
+	return strings.Join(kvs, ",")
+}
+
+// Merge returns copy of labels with given ones added or replaced. Labels with empty value are removed.
+func (l Labels) Merge(other Labels) Labels {
+	ret := Labels{}
+	for k, v := range l {
+		ret[k] = v
+	}
+	for k, v := range other {
+		if v == "" {
+			delete(ret, k)
+			continue
+		}
+		ret[k] = v
+	}
+	return ret
+}
+
+// Matches returns true if labels have all of given selector labels. Selector label with empty value requires label to
+// be missing.
+func (l Labels) Matches(selector Labels) bool {
+	for k, v := range selector {
+		if l[k] != v {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,38 @@
+// Copyright (c) Bartłomiej Płotka @bwplotka
+// Licensed under the Apache License 2.0.
+
+package bingo
+
+import (
+	"testing"
+
+	"github.com/efficientgo/tools/core/pkg/testutil"
+)
+
+func TestParseLabels(t *testing.T) {
+	l, err := ParseLabels(" team=platform, stage=release,owner=")
+	testutil.Ok(t, err)
+	testutil.Equals(t, Labels{"team": "platform", "stage": "release", "owner": ""}, l)
+	testutil.Equals(t, "owner=,stage=release,team=platform", l.String())
+
+	l, err = ParseLabels("")
+	testutil.Ok(t, err)
+	testutil.Equals(t, Labels{}, l)
+
+	for _, invalid := range []string{"team", "=platform", "te am=platform", "team=plat form"} {
+		_, err := ParseLabels(invalid)
+		testutil.NotOk(t, err, invalid)
+	}
+}
+
+func TestLabels_MergeAndMatches(t *testing.T) {
+	l := Labels{"team": "platform", "stage": "release"}
+	testutil.Equals(t, Labels{"team": "docs", "tier": "1"}, l.Merge(Labels{"team": "docs", "stage": "", "tier": "1"}))
+	testutil.Equals(t, Labels{"team": "platform", "stage": "release"}, l)
+
+	testutil.Assert(t, l.Matches(nil))
+	testutil.Assert(t, l.Matches(Labels{"team": "platform"}))
+	testutil.Assert(t, l.Matches(Labels{"team": "platform", "owner": ""}))
+	testutil.Assert(t, !l.Matches(Labels{"team": "docs"}))
+	testutil.Assert(t, !l.Matches(Labels{"stage": ""}))
+}
@@ -4,43 +4,162 @@
 package bingo
 
 import (
+	"bufio"
+	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
 	"io"
 	"io/ioutil"
-	"log"
 	"os"
+	"path"
 	"path/filepath"
+	"regexp"
+	"runtime"
 	"sort"
+	"strconv"
 	"strings"
+	"sync"
 	"text/tabwriter"
+	"text/template"
+	"time"
 
 	"github.com/bwplotka/bingo/pkg/envars"
+	"github.com/bwplotka/bingo/pkg/logging"
 	"github.com/bwplotka/bingo/pkg/runner"
 	"github.com/efficientgo/tools/core/pkg/errcapture"
 	"github.com/efficientgo/tools/core/pkg/merrors"
 	"github.com/pkg/errors"
 	"golang.org/x/mod/modfile"
 	"golang.org/x/mod/module"
+	"golang.org/x/mod/semver"
 )
 
 const (
 	// FakeRootModFileName is a name for fake go module that we have to maintain, until https://github.com/bwplotka/bingo/issues/20 is fixed.
 	FakeRootModFileName = "go.mod"
+	// ConstraintsModFileName is a name of optional mod file in mod directory, with replace, exclude and require statements
+	// applied to mod files of all tools on every bingo get (e.g forcing patched version of dependency org-wide).
+	ConstraintsModFileName = "constraints.mod"
 
 	NoReplaceCommand = "bingo:no_replace_fetch"
+	// VarNameCommand allows to override variable name generated for the tool (e.g `// bingo:var_name LINT`).
+	VarNameCommand = "bingo:var_name"
+	// DescriptionCommand describes what the tool is for, shown by bingo list (e.g `// bingo:description Go linters runner`).
+	DescriptionCommand = "bingo:description"
+	// FrozenCommand marks the tool as held at its pinned version, so it's skipped by bulk updates (e.g `bingo get -u`).
+	// Argument is a reason, e.g `// bingo:frozen generated code is committed`.
+	FrozenCommand = "bingo:frozen"
+	// GoFlagsCommand sets GOFLAGS for go commands building the tool (e.g `// bingo:goflags -tags=netgo -trimpath`), as
+	// flags changing builds are ignored in ambient GOFLAGS environment variable.
+	GoFlagsCommand = "bingo:goflags"
+	// GenerateCommand makes bingo run go generate before building the tool, for tools that only build with generated files
+	// (e.g embedded assets). Optional argument is a list of package patterns relative to tool's module root, e.g
+	// `// bingo:generate ./internal/assets`; the tool's package by default.
+	GenerateCommand = "bingo:generate"
+	// SumCommand records h1 hash of the tool's module content (as in go.sum) at resolution time, e.g
+	// `// bingo:sum h1:VjXZpyU3t1ONTS1ym7PWQAROy8yrqHQjbB0tFaq8rI4=`, for integrity checks without the tool's go.sum.
+	SumCommand = "bingo:sum"
+	// BinDirCommand sets directory the tool is installed into instead of GOBIN, for tools that have to live in a specific
+	// place (e.g protoc plugins next to protoc). Argument is a slash separated path relative to mod directory, or absolute
+	// one, e.g `// bingo:bindir ../third_party/protoc/bin`.
+	BinDirCommand = "bingo:bindir"
+	// DedupeCommand marks replace statement added by bingo dedupe to align version of dependency shared with other tools
+	// (e.g `replace golang.org/x/sys v0.1.0 => golang.org/x/sys v0.2.0 // bingo:dedupe`).
+	DedupeCommand = "bingo:dedupe"
+	// ConstraintCommand marks replace and exclude statements applied from ConstraintsModFileName.
+	ConstraintCommand = "bingo:constraint"
+	// SplitFromCommand marks pins moved by bingo split from array of versions of another tool, e.g
+	// `// bingo:split_from golangci-lint`, so pinning the same package under both names is not reported as duplicate.
+	SplitFromCommand = "bingo:split_from"
 
 	PackageRenderablesPrintHeader = "Name\tBinary Name\tPackage @ Version\tBuild EnvVars\tBuild Flags\n" +
 		"----\t-----------\t-----------------\t-------------\t-----------\n"
 )
 
-// NameFromModFile returns binary name from module file path.
+// NameFromModFile returns binary name from module file path. Only numeric suffix is an array index (e.g "buf.2.mod"), so
+// names with dots are kept (e.g "foo.v2" for "foo.v2.mod" and "foo.v2.1.mod").
 func NameFromModFile(modFile string) (name string, oneOfMany bool) {
-	n := strings.Split(strings.TrimSuffix(filepath.Base(modFile), ".mod"), ".")
-	if len(n) > 1 {
-		oneOfMany = true
+	name = strings.TrimSuffix(filepath.Base(modFile), ".mod")
+	if i := strings.LastIndex(name, "."); i > 0 {
+		if _, err := strconv.Atoi(name[i+1:]); err == nil {
+			return name[:i], true
+		}
+	}
+	return name, false
+}
+
+// ModFileNumber returns number of array mod file of given tool (e.g 2 for "buf.2.mod" of buf), 0 for its first one
+// (e.g "buf.mod"). It returns false if file is not mod file of given tool, e.g "buf.v2.mod" of another tool, "buf.v2".
+func ModFileNumber(name, modFile string) (int, bool) {
+	rest := strings.TrimSuffix(filepath.Base(modFile), ".mod")
+	if rest == name {
+		return 0, true
 	}
-	return n[0], oneOfMany
+	if !strings.HasPrefix(rest, name+".") {
+		return 0, false
+	}
+	n, err := strconv.Atoi(strings.TrimPrefix(rest, name+"."))
+	if err != nil || n < 0 {
+		return 0, false
+	}
+	return n, true
+}
+
+// GOEXE returns suffix of executable names: GOEXE env variable if set, ".exe" if GOOS env variable (or current OS, if
+// not set) is windows, empty otherwise.
+func GOEXE() string {
+	if goexe, ok := os.LookupEnv("GOEXE"); ok {
+		return goexe
+	}
+	goos := os.Getenv("GOOS")
+	if goos == "" {
+		goos = runtime.GOOS
+	}
+	if goos == "windows" {
+		return ".exe"
+	}
+	return ""
+}
+
+// ToolBinDir returns directory the tool with given BinDirCommand argument is installed into: given gobin if argument is
+// empty, the argument resolved against given mod directory otherwise.
+func ToolBinDir(modDir, gobin, binDir string) string {
+	switch {
+	case binDir == "":
+		return gobin
+	case filepath.IsAbs(filepath.FromSlash(binDir)):
+		return filepath.FromSlash(binDir)
+	}
+	return filepath.Join(modDir, filepath.FromSlash(binDir))
+}
+
+// BinaryName returns name of the binary of given tool version installed in GOBIN, e.g "faillint-v1.5.0" or
+// "faillint-v1.5.0.exe" on Windows.
+func BinaryName(name, version string) string {
+	return name + "-" + version + GOEXE()
+}
+
+// WasmSuffix is a suffix of WebAssembly modules built for tools with GOARCH=wasm build env var (e.g plugins for wasm
+// runtimes built with GOOS=js or GOOS=wasip1).
+const WasmSuffix = ".wasm"
+
+// IsWasm returns true if given build env vars make go build WebAssembly module instead of executable.
+func IsWasm(buildEnvs []string) bool {
+	v, ok := envars.EnvSlice(buildEnvs).Lookup("GOARCH")
+	return ok && v == "wasm"
+}
+
+// ArtifactName returns name of the artifact of given tool version built with given build env vars: BinaryName, or
+// e.g "plugin-v1.0.0.wasm" for WebAssembly tools (see IsWasm).
+func ArtifactName(name, version string, buildEnvs []string) string {
+	if IsWasm(buildEnvs) {
+		return name + "-" + version + WasmSuffix
+	}
+	return BinaryName(name, version)
 }
 
 // A Package (for clients, a bingo.Package) is defined by a module path, package relative path and version pair.
@@ -70,7 +189,8 @@ func (m Package) String() string {
 
 // Path returns a full package path.
 func (m Package) Path() string {
-	return filepath.Join(m.Module.Path, m.RelPath)
+	// Package paths are slash separated on every platform.
+	return path.Join(m.Module.Path, m.RelPath)
 }
 
 // ModFile represents bingo tool .mod file.
@@ -82,6 +202,12 @@ type ModFile struct {
 
 	directPackage       *Package
 	autoReplaceDisabled bool
+	commands            map[string]string
+	// requireNote and requireBefore are human-added comments of the direct require line: text after metadata in its
+	// suffix comment (e.g `// cmd/tool // pinned due to regression in v1.60`) and comment block above it. Both are
+	// preserved by SetDirectRequire.
+	requireNote   string
+	requireBefore []modfile.Comment
 }
 
 // OpenModFile opens bingo mod file.
@@ -101,23 +227,81 @@ func OpenModFile(modFile string) (_ *ModFile, err error) {
 	if err := mf.Reload(); err != nil {
 		return nil, err
 	}
+	if _, err := mf.addMetaIfMissing(); err != nil {
+		return nil, err
+	}
+	return mf, nil
+}
+
+// ReadModFile parses bingo mod file read only, so it's never changed (e.g by Close flushing normalized content) and can
+// be read concurrently. Flush on returned file fails and Close is a no-op.
+func ReadModFile(modFile string) (*ModFile, error) {
+	b, err := ioutil.ReadFile(modFile)
+	if err != nil {
+		return nil, err
+	}
+	m, err := ParseModFileOrReader(modFile, bytes.NewReader(b))
+	if err != nil {
+		return nil, err
+	}
+	mf := &ModFile{filename: modFile}
+	if err := mf.load(m); err != nil {
+		return nil, err
+	}
+	return mf, nil
+}
 
-	if err := onModHeaderComments(mf.m, func(comments *modfile.Comments) error {
+// addMetaIfMissing puts bingo meta comment in front of module statement suffix comments, if missing. It returns true
+// if it was added.
+func (mf *ModFile) addMetaIfMissing() (added bool, _ error) {
+	return added, onModHeaderComments(mf.m, func(comments *modfile.Comments) error {
 		if err := errOnMetaMissing(comments); err != nil {
-			mf.m.Module.Syntax.Suffix = append(mf.m.Module.Syntax.Suffix, modfile.Comment{Suffix: true, Token: metaComment})
-			return nil
+			comments.Suffix = append([]modfile.Comment{{Suffix: true, Token: metaComment}}, comments.Suffix...)
+			added = true
 		}
 		return nil
-	}); err != nil {
-		return nil, err
+	})
+}
+
+// FormatModFile returns given content of bingo mod file in canonical form, the same as bingo get writes: with bingo meta
+// comment, only the first direct require statement with normalized metadata comment, formatted as go.mod files are.
+// It also returns human readable descriptions of added or dropped content, e.g extra require statements.
+func FormatModFile(modFile string, content []byte) (canonical []byte, changes []string, err error) {
+	m, err := ParseModFileOrReader(modFile, bytes.NewReader(content))
+	if err != nil {
+		return nil, nil, err
 	}
-	return mf, nil
+	var requires []module.Version
+	for _, r := range m.Require {
+		requires = append(requires, r.Mod)
+	}
+
+	mf := &ModFile{filename: modFile}
+	if err := mf.load(m); err != nil {
+		return nil, nil, err
+	}
+	added, err := mf.addMetaIfMissing()
+	if err != nil {
+		return nil, nil, err
+	}
+	if added {
+		changes = append(changes, "added bingo meta comment to module statement")
+	}
+	kept := false
+	for _, r := range requires {
+		if !kept && mf.directPackage != nil && r == mf.directPackage.Module {
+			kept = true
+			continue
+		}
+		changes = append(changes, fmt.Sprintf("dropped require %v", r))
+	}
+	return modfile.Format(mf.m.Syntax), changes, nil
 }
 
 // CreateFromExistingOrNew creates and opens new bingo enhanced module file.
 // If existing file exists and is not malformed it copies this as the source, otherwise completely new is created.
 // It's a caller responsibility to Close the file when not using anymore.
-func CreateFromExistingOrNew(ctx context.Context, r *runner.Runner, logger *log.Logger, existingFile, modFile string) (*ModFile, error) {
+func CreateFromExistingOrNew(ctx context.Context, r *runner.Runner, logger logging.Logger, existingFile, modFile string) (*ModFile, error) {
 	if err := os.RemoveAll(modFile); err != nil {
 		return nil, errors.Wrap(err, "rm")
 	}
@@ -129,17 +313,13 @@ func CreateFromExistingOrNew(ctx context.Context, r *runner.Runner, logger *log.
 		}
 		if err == nil {
 			// Only use existing mod file on successful parse.
-			o, err := OpenModFile(existingFile)
-			if err == nil {
-				if err := o.Close(); err != nil {
-					return nil, err
-				}
+			if _, err := ReadModFile(existingFile); err == nil {
 				if err := copyFile(existingFile, modFile); err != nil {
 					return nil, err
 				}
 				return OpenModFile(modFile)
 			}
-			logger.Printf("bingo tool module file %v is malformed; it will be recreated; err: %v\n", existingFile, err)
+			logger.Warn("bingo tool module file is malformed; it will be recreated", "file", existingFile, "err", err)
 		}
 	}
 
@@ -188,8 +368,68 @@ func (mf *ModFile) AutoReplaceDisabled() bool {
 	return mf.autoReplaceDisabled
 }
 
-// Close flushes changes and closes file.
+// Command returns argument of the given bingo command (e.g "bingo:var_name") placed as a comment in the mod file, if any.
+func (mf *ModFile) Command(cmd string) (string, bool) {
+	arg, ok := mf.commands[cmd]
+	return arg, ok
+}
+
+// SetCommand sets argument of the given bingo command (e.g "bingo:description") as a comment in the mod file, replacing
+// existing one. Empty argument removes the command. It's caller responsibility to Flush all changes.
+func (mf *ModFile) SetCommand(cmd, arg string) {
+	token := "// " + cmd
+	if arg != "" {
+		token += " " + arg
+	}
+
+	found := false
+	stmts := mf.m.Syntax.Stmt[:0]
+	for _, e := range mf.m.Syntax.Stmt {
+		comments := e.Comment()
+		for _, cs := range []*[]modfile.Comment{&comments.Before, &comments.After} {
+			kept := (*cs)[:0]
+			for _, c := range *cs {
+				if existing, _, ok := parseCommand(c.Token); ok && existing == cmd {
+					if arg == "" || found {
+						continue
+					}
+					c.Token = token
+					found = true
+				}
+				kept = append(kept, c)
+			}
+			*cs = kept
+		}
+		if b, ok := e.(*modfile.CommentBlock); ok && len(b.Before) == 0 && len(b.After) == 0 {
+			// Drop empty block left after removing command.
+			continue
+		}
+		stmts = append(stmts, e)
+	}
+	mf.m.Syntax.Stmt = stmts
+
+	if !found && arg != "" {
+		// Put it just below module statement.
+		i := 0
+		if len(stmts) > 0 && mf.m.Module != nil && stmts[0] == mf.m.Module.Syntax {
+			i = 1
+		}
+		block := &modfile.CommentBlock{Comments: modfile.Comments{Before: []modfile.Comment{{Token: token}}}}
+		mf.m.Syntax.Stmt = append(stmts[:i:i], append([]modfile.Expr{block}, stmts[i:]...)...)
+	}
+
+	if arg == "" {
+		delete(mf.commands, cmd)
+		return
+	}
+	mf.commands[cmd] = arg
+}
+
+// Close flushes changes and closes file. It's a no-op for read only file (see ReadModFile).
 func (mf *ModFile) Close() error {
+	if mf.f == nil {
+		return nil
+	}
 	return merrors.New(mf.Flush(), mf.f.Close()).Err()
 }
 
@@ -198,35 +438,35 @@ func (mf *ModFile) Reload() (err error) {
 		return errors.Wrap(err, "seek")
 	}
 
-	mf.m, err = ParseModFileOrReader(mf.filename, mf.f)
+	m, err := ParseModFileOrReader(mf.filename, mf.f)
 	if err != nil {
 		return err
 	}
+	return mf.load(m)
+}
 
+// load sets parsed mod file, loads bingo commands and direct package from it and trims all require statements except
+// the direct one.
+func (mf *ModFile) load(m *modfile.File) error {
+	mf.m = m
 	mf.autoReplaceDisabled = false
+	mf.commands = map[string]string{}
 	for _, e := range mf.m.Syntax.Stmt {
-		for _, c := range e.Comment().Before {
-			if strings.Contains(c.Token, NoReplaceCommand) {
-				mf.autoReplaceDisabled = true
-				break
-			}
-		}
-		for _, c := range e.Comment().After {
-			if strings.Contains(c.Token, NoReplaceCommand) {
-				mf.autoReplaceDisabled = true
-				break
-			}
-		}
-		for _, c := range e.Comment().Suffix {
-			if strings.Contains(c.Token, NoReplaceCommand) {
-				mf.autoReplaceDisabled = true
-				break
+		for _, comments := range [][]modfile.Comment{e.Comment().Before, e.Comment().After, e.Comment().Suffix} {
+			for _, c := range comments {
+				if strings.Contains(c.Token, NoReplaceCommand) {
+					mf.autoReplaceDisabled = true
+				}
+				if cmd, arg, ok := parseCommand(c.Token); ok {
+					mf.commands[cmd] = arg
+				}
 			}
 		}
 	}
 
 	// We expect just one direct import if any.
 	mf.directPackage = nil
+	mf.requireNote, mf.requireBefore = "", nil
 	for _, r := range mf.m.Require {
 		if r.Indirect {
 			continue
@@ -234,8 +474,11 @@ func (mf *ModFile) Reload() (err error) {
 
 		mf.directPackage = &Package{Module: r.Mod}
 		if len(r.Syntax.Suffix) > 0 {
-			mf.directPackage.RelPath, mf.directPackage.BuildEnvs, mf.directPackage.BuildFlags = parseDirectPackageMeta(strings.Trim(r.Syntax.Suffix[0].Token[3:], "\n"))
+			var meta string
+			meta, mf.requireNote = splitRequireComment(r.Syntax.Suffix[0].Token)
+			mf.directPackage.RelPath, mf.directPackage.BuildEnvs, mf.directPackage.BuildFlags = parseDirectPackageMeta(meta)
 		}
+		mf.requireBefore = append(mf.requireBefore, r.Syntax.Before...)
 		break
 	}
 	// Remove rest.
@@ -246,6 +489,27 @@ func (mf *ModFile) Reload() (err error) {
 	return nil
 }
 
+// parseCommand parses comment in form of `// bingo:<command> <argument>`.
+func parseCommand(token string) (cmd string, arg string, ok bool) {
+	t := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(token), "//"))
+	if !strings.HasPrefix(t, "bingo:") {
+		return "", "", false
+	}
+	s := strings.SplitN(t, " ", 2)
+	if len(s) == 1 {
+		return s[0], "", true
+	}
+	return s[0], strings.TrimSpace(s[1]), true
+}
+
+// splitRequireComment splits suffix comment of the direct require line into metadata (relative package path, build
+// env vars and flags) and human-added note, separated by " // ", e.g `// cmd/tool -tags=x // pinned due to regression`.
+func splitRequireComment(token string) (meta, note string) {
+	t := strings.Trim(strings.TrimPrefix(strings.TrimSpace(token), "//"), "\n")
+	meta, note, _ = strings.Cut(" "+t, " // ")
+	return strings.TrimSpace(meta), strings.TrimSpace(note)
+}
+
 func parseDirectPackageMeta(line string) (relPath string, buildEnv []string, buildFlags []string) {
 	elem := strings.Split(line, " ")
 	for i, l := range elem {
@@ -254,7 +518,11 @@ func parseDirectPackageMeta(line string) (relPath string, buildEnv []string, bui
 		}
 
 		if l[0] == '-' {
-			buildFlags = elem[i:]
+			for _, f := range elem[i:] {
+				if f != "" {
+					buildFlags = append(buildFlags, f)
+				}
+			}
 			break
 		}
 
@@ -273,6 +541,9 @@ func (mf *ModFile) DirectPackage() *Package {
 
 // Flush saves all changes made to parsed syntax and reloads the parsed file.
 func (mf *ModFile) Flush() error {
+	if mf.f == nil {
+		return errors.Errorf("%v: cannot flush mod file opened read only", mf.filename)
+	}
 	newB := modfile.Format(mf.m.Syntax)
 	if err := mf.f.Truncate(0); err != nil {
 		return errors.Wrap(err, "truncate")
@@ -287,6 +558,7 @@ func (mf *ModFile) Flush() error {
 }
 
 // SetDirectRequire removes all require statements and set to the given one. It supports package level versioning.
+// Human-added comments of the previous direct require line (note after metadata and comment block above it) are kept.
 // It's caller responsibility to Flush all changes.
 func (mf *ModFile) SetDirectRequire(target Package) (err error) {
 	mf.dropAllRequire()
@@ -300,10 +572,18 @@ func (mf *ModFile) SetDirectRequire(target Package) (err error) {
 	meta = append(meta, target.BuildEnvs...)
 	meta = append(meta, target.BuildFlags...)
 
+	token := "//"
 	if len(meta) > 0 {
-		r := mf.m.Require[0]
-		r.Syntax.Suffix = append(r.Syntax.Suffix[:0], modfile.Comment{Suffix: true, Token: "// " + strings.Join(meta, " ")})
+		token += " " + strings.Join(meta, " ")
+	}
+	if mf.requireNote != "" {
+		token += " // " + mf.requireNote
+	}
+	r := mf.m.Require[0]
+	if token != "//" {
+		r.Syntax.Suffix = append(r.Syntax.Suffix[:0], modfile.Comment{Suffix: true, Token: token})
 	}
+	r.Syntax.Before = append(r.Syntax.Before[:0], mf.requireBefore...)
 
 	mf.m.Cleanup()
 	mf.directPackage = &target
@@ -320,15 +600,121 @@ func (mf *ModFile) dropAllRequire() {
 	mf.m.Require = mf.m.Require[:0]
 }
 
+// IsLocalReplace returns true if given replace statement points to local directory (e.g "replace foo => ../foo"),
+// not to other module.
+func IsLocalReplace(r *modfile.Replace) bool {
+	return r.New.Version == "" && modfile.IsDirectoryPath(r.New.Path)
+}
+
+// LocalReplaces returns replace statements pointing to local directories. Relative directories are relative to
+// the mod file directory.
+func (mf *ModFile) LocalReplaces() []*modfile.Replace {
+	var local []*modfile.Replace
+	for _, r := range mf.m.Replace {
+		if r.Syntax != nil && IsLocalReplace(r) {
+			local = append(local, r)
+		}
+	}
+	return local
+}
+
+// IsToolModFile returns true if given mod file in mod directory pins a tool, so it's not FakeRootModFileName,
+// ConstraintsModFileName or temporary file.
+func IsToolModFile(modFile string) bool {
+	base := filepath.Base(modFile)
+	return strings.HasSuffix(base, ".mod") && base != FakeRootModFileName && base != ConstraintsModFileName && !strings.HasSuffix(base, "tmp.mod")
+}
+
+// hasLineCommand returns true if given statement line has given bingo command in its suffix comment.
+func hasLineCommand(line *modfile.Line, cmd string) bool {
+	if line == nil {
+		return false
+	}
+	for _, c := range line.Suffix {
+		if lc, _, ok := parseCommand(c.Token); ok && lc == cmd {
+			return true
+		}
+	}
+	return false
+}
+
+// setLineCommand sets given bingo command as suffix comment of given statement line.
+func setLineCommand(line *modfile.Line, cmd string) {
+	if line != nil {
+		line.Suffix = []modfile.Comment{{Suffix: true, Token: "// " + cmd}}
+	}
+}
+
+// IsDedupeReplace returns true if given replace statement was added by bingo dedupe (see DedupeCommand).
+func IsDedupeReplace(r *modfile.Replace) bool {
+	return hasLineCommand(r.Syntax, DedupeCommand)
+}
+
+// DedupeReplaces returns replace statements added by bingo dedupe.
+func (mf *ModFile) DedupeReplaces() []*modfile.Replace {
+	var dedupe []*modfile.Replace
+	for _, r := range mf.m.Replace {
+		if IsDedupeReplace(r) {
+			dedupe = append(dedupe, r)
+		}
+	}
+	return dedupe
+}
+
+// SetDedupeReplaces removes all replace statements added by bingo dedupe and adds given ones, marked with DedupeCommand.
+// It's caller responsibility to Flush all changes.
+func (mf *ModFile) SetDedupeReplaces(target ...*modfile.Replace) error {
+	for _, r := range mf.DedupeReplaces() {
+		if err := mf.m.DropReplace(r.Old.Path, r.Old.Version); err != nil {
+			return err
+		}
+	}
+	mf.m.Cleanup()
+	for _, r := range target {
+		if err := mf.m.AddReplace(r.Old.Path, r.Old.Version, r.New.Path, r.New.Version); err != nil {
+			return err
+		}
+		for _, added := range mf.m.Replace {
+			if added.Old == r.Old {
+				setLineCommand(added.Syntax, DedupeCommand)
+			}
+		}
+	}
+	mf.m.Cleanup()
+	return nil
+}
+
 // SetReplace removes all replace statements and set to the given ones.
+// Replaces to local directories are kept, as they are always added by user (e.g for tools developed in sibling
+// directories) and take precedence over given ones. Given replaces to local directories are skipped, as they are
+// relative to other module and cannot be reproduced. Replaces added by bingo dedupe are kept too, unless given ones
+// replace the same module.
 // It's caller responsibility to Flush all changes.
 func (mf *ModFile) SetReplace(target ...*modfile.Replace) (err error) {
+	replaced := map[string]struct{}{}
+	for _, r := range target {
+		replaced[r.Old.Path] = struct{}{}
+	}
+	local := map[string]struct{}{}
 	for _, r := range mf.m.Replace {
+		if r.Syntax == nil {
+			continue
+		}
+		if IsLocalReplace(r) {
+			local[r.Old.Path] = struct{}{}
+			continue
+		}
+		if _, ok := replaced[r.Old.Path]; !ok && IsDedupeReplace(r) {
+			continue
+		}
 		if err := mf.m.DropReplace(r.Old.Path, r.Old.Version); err != nil {
 			return err
 		}
 	}
 	for _, r := range target {
+		if _, ok := local[r.Old.Path]; ok || IsLocalReplace(r) {
+			continue
+		}
 		if err := mf.m.AddReplace(r.Old.Path, r.Old.Version, r.New.Path, r.New.Version); err != nil {
 			return err
 		}
@@ -337,6 +723,155 @@ func (mf *ModFile) SetReplace(target ...*modfile.Replace) (err error) {
 	return nil
 }
 
+// SetLocalReplace replaces given module version (all versions if empty) with given local directory, relative to the
+// mod file directory or absolute. It's caller responsibility to Flush all changes.
+func (mf *ModFile) SetLocalReplace(oldPath, oldVers, dir string) error {
+	if !modfile.IsDirectoryPath(dir) {
+		return errors.Errorf("replacement %q of %v is not a local directory; relative directory has to start with ./ or ../", dir, oldPath)
+	}
+	for _, r := range mf.m.Replace {
+		if r.Syntax != nil && r.Old.Path == oldPath && oldVers == "" && r.Old.Version != "" {
+			if err := mf.m.DropReplace(r.Old.Path, r.Old.Version); err != nil {
+				return err
+			}
+		}
+	}
+	if err := mf.m.AddReplace(oldPath, oldVers, dir, ""); err != nil {
+		return err
+	}
+	mf.m.Cleanup()
+	return nil
+}
+
+// ParseLocalReplaces parses comma separated replaces of modules with local directories (e.g
+// "github.com/org/foo=../foo").
+func ParseLocalReplaces(s string) ([]*modfile.Replace, error) {
+	var replaces []*modfile.Replace
+	for _, r := range strings.Split(s, ",") {
+		r = strings.TrimSpace(r)
+		if r == "" {
+			continue
+		}
+		oldPath, dir, ok := strings.Cut(r, "=")
+		oldPath, dir = strings.TrimSpace(oldPath), strings.TrimSpace(dir)
+		if !ok || oldPath == "" || dir == "" {
+			return nil, errors.Errorf("invalid replace %q; expected <module>=<directory>", r)
+		}
+		if err := module.CheckImportPath(oldPath); err != nil {
+			return nil, errors.Wrapf(err, "invalid replace %q", r)
+		}
+		if !modfile.IsDirectoryPath(dir) {
+			return nil, errors.Errorf("invalid replace %q; directory has to be absolute or start with ./ or ../", r)
+		}
+		replaces = append(replaces, &modfile.Replace{Old: module.Version{Path: oldPath}, New: module.Version{Path: dir}})
+	}
+	return replaces, nil
+}
+
+// Constraints are replace and exclude statements from ConstraintsModFileName, applied to mod files of all tools.
+type Constraints struct {
+	Replace []*modfile.Replace
+	Exclude []*modfile.Exclude
+}
+
+// ReadConstraints returns constraints from ConstraintsModFileName in given mod directory, or nil if there is no such
+// file. Require statements pin all versions of required module to the given version, so they are returned as replaces.
+func ReadConstraints(modDir string) (*Constraints, error) {
+	f := filepath.Join(modDir, ConstraintsModFileName)
+	m, err := ParseModFileOrReader(f, nil)
+	if err != nil {
+		if os.IsNotExist(errors.Cause(err)) {
+			return nil, nil
+		}
+		return nil, errors.Wrapf(err, "%v", f)
+	}
+	c := &Constraints{Replace: m.Replace, Exclude: m.Exclude}
+	for _, r := range m.Require {
+		c.Replace = append(c.Replace, &modfile.Replace{Old: module.Version{Path: r.Mod.Path}, New: r.Mod})
+	}
+	return c, nil
+}
+
+// SetConstraints removes statements applied from constraints before and applies given ones, marked with
+// ConstraintCommand. Given replaces take precedence over other replaces of the same module, except replaces with local
+// directories. Constraints of the tool's own module are skipped, so they never change the pinned version. Nil
+// constraints only remove applied ones. It's caller responsibility to Flush all changes.
+func (mf *ModFile) SetConstraints(c *Constraints) error {
+	for _, r := range mf.m.Replace {
+		if hasLineCommand(r.Syntax, ConstraintCommand) {
+			if err := mf.m.DropReplace(r.Old.Path, r.Old.Version); err != nil {
+				return err
+			}
+		}
+	}
+	for _, e := range mf.m.Exclude {
+		if hasLineCommand(e.Syntax, ConstraintCommand) {
+			if err := mf.m.DropExclude(e.Mod.Path, e.Mod.Version); err != nil {
+				return err
+			}
+		}
+	}
+	mf.m.Cleanup()
+	if c == nil {
+		return nil
+	}
+
+	local := map[string]struct{}{}
+	for _, r := range mf.LocalReplaces() {
+		local[r.Old.Path] = struct{}{}
+	}
+	for _, r := range c.Replace {
+		if _, ok := local[r.Old.Path]; ok || mf.directPackage != nil && r.Old.Path == mf.directPackage.Module.Path {
+			continue
+		}
+		// Constraint for all versions of module overrides all its replaces.
+		for _, existing := range mf.m.Replace {
+			if existing.Syntax != nil && existing.Old.Path == r.Old.Path && (r.Old.Version == "" || existing.Old.Version == r.Old.Version) {
+				if err := mf.m.DropReplace(existing.Old.Path, existing.Old.Version); err != nil {
+					return err
+				}
+			}
+		}
+		if err := mf.m.AddReplace(r.Old.Path, r.Old.Version, r.New.Path, r.New.Version); err != nil {
+			return err
+		}
+		for _, added := range mf.m.Replace {
+			if added.Old == r.Old {
+				setLineCommand(added.Syntax, ConstraintCommand)
+			}
+		}
+	}
+	for _, e := range c.Exclude {
+		if err := mf.m.AddExclude(e.Mod.Path, e.Mod.Version); err != nil {
+			return err
+		}
+		for _, added := range mf.m.Exclude {
+			if added.Mod == e.Mod {
+				setLineCommand(added.Syntax, ConstraintCommand)
+			}
+		}
+	}
+	mf.m.Cleanup()
+	return nil
+}
+
+// SetExclude removes all exclude statements and set to the given ones.
+// It's caller responsibility to Flush all changes.
+func (mf *ModFile) SetExclude(target ...*modfile.Exclude) (err error) {
+	for _, e := range mf.m.Exclude {
+		if err := mf.m.DropExclude(e.Mod.Path, e.Mod.Version); err != nil {
+			return err
+		}
+	}
+	for _, e := range target {
+		if err := mf.m.AddExclude(e.Mod.Path, e.Mod.Version); err != nil {
+			return err
+		}
+	}
+	mf.m.Cleanup()
+	return nil
+}
+
 // ParseModFileOrReader parses any module file or reader allowing to read it's content.
 func ParseModFileOrReader(modFile string, r io.Reader) (*modfile.File, error) {
 	b, err := readAllFileOrReader(modFile, r)
@@ -361,11 +896,10 @@ func readAllFileOrReader(file string, r io.Reader) (b []byte, err error) {
 // ModDirectPackage return the first direct package from bingo enhanced module file. The package suffix (if any) is
 // encoded in the line comment, in the same line as module and version.
 func ModDirectPackage(modFile string) (pkg Package, err error) {
-	mf, err := OpenModFile(modFile)
+	mf, err := ReadModFile(modFile)
 	if err != nil {
 		return Package{}, err
 	}
-	defer errcapture.Do(&err, mf.Close, "close")
 
 	if mf.directPackage == nil {
 		return Package{}, errors.Errorf("no direct package found in %s; empty module?", mf.filename)
@@ -390,6 +924,25 @@ func ModIndirectModules(modFile string) (mods []module.Version, err error) {
 	return mods, nil
 }
 
+// ModuleSum returns h1 hash of given module version content from given go.sum file, or empty string if there is none
+// (e.g module is replaced with local directory).
+func ModuleSum(sumFile, modPath, version string) (_ string, err error) {
+	f, err := os.Open(sumFile)
+	if err != nil {
+		return "", err
+	}
+	defer errcapture.Do(&err, f.Close, "close")
+
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		fields := strings.Fields(s.Text())
+		if len(fields) == 3 && fields[0] == modPath && fields[1] == version {
+			return fields[2], nil
+		}
+	}
+	return "", s.Err()
+}
+
 const metaComment = "// Auto generated by https://github.com/bwplotka/bingo. DO NOT EDIT"
 
 func onModHeaderComments(m *modfile.File, f func(*modfile.Comments) error) error {
@@ -421,6 +974,14 @@ func errOnMetaMissing(comments *modfile.Comments) error {
 type PackageVersionRenderable struct {
 	Version string
 	ModFile string
+	// ModFileHash is a hex encoded SHA256 of the mod file content.
+	ModFileHash string
+	// ModTime is a modification time of the mod file.
+	ModTime time.Time
+	// Sum is h1 hash of the tool's module content recorded in mod file via SumCommand, if any.
+	Sum string
+	// BuildInfo describes installed binary. Nil unless loaded with PackageRenderables.LoadBuildInfo.
+	BuildInfo *BinaryBuildInfo
 }
 
 // PackageRenderable is used in variables.go. Modify with care.
@@ -433,6 +994,46 @@ type PackageRenderable struct {
 
 	BuildFlags   []string
 	BuildEnvVars []string
+	// GoFlags are GOFLAGS for go commands building the tool, set via GoFlagsCommand in mod file.
+	GoFlags string
+	// Description describes what the tool is for, set via DescriptionCommand in mod file.
+	Description string
+	// Labels are set via LabelsCommand in mod file.
+	Labels Labels
+	// Frozen is true if tool is held at its pinned version via FrozenCommand in mod file, with optional FrozenReason.
+	Frozen       bool
+	FrozenReason string
+	// Aliases are extra names of the tool linked to the same binary, set via AliasesCommand in mod file.
+	Aliases []AliasRenderable
+	// BinDir is a directory the tool is installed into instead of GOBIN, set via BinDirCommand in mod file.
+	BinDir string
+
+	// binDirPath is BinDir resolved against mod directory.
+	binDirPath string
+	// envVarNameOverridden is true if EnvVarName was explicitly set in mod file via VarNameCommand.
+	envVarNameOverridden bool
+	// splitFrom is name of the tool this one was split from, set via SplitFromCommand in mod file.
+	splitFrom string
+}
+
+// InstallDir returns directory the tool is installed into: its BinDir resolved against mod directory, if set, given
+// gobin otherwise.
+func (p PackageRenderable) InstallDir(gobin string) string {
+	if p.binDirPath != "" {
+		return p.binDirPath
+	}
+	return gobin
+}
+
+// Wasm returns true if the tool is built as WebAssembly module (see IsWasm). Such tools are not linked, as they are not
+// executable on their own.
+func (p PackageRenderable) Wasm() bool {
+	return IsWasm(p.BuildEnvVars)
+}
+
+// ArtifactName returns name of the binary (or WebAssembly module) of given version of the tool (see ArtifactName).
+func (p PackageRenderable) ArtifactName(version string) string {
+	return ArtifactName(p.Name, version, p.BuildEnvVars)
 }
 
 func (p PackageRenderable) ToPackages() []Package {
@@ -458,7 +1059,26 @@ func (pkgs PackageRenderables) PrintTab(target string, w io.Writer) error {
 	tw.Init(w, 1, 8, 1, '\t', tabwriter.AlignRight)
 	defer func() { _ = tw.Flush() }()
 
-	_, _ = fmt.Fprint(tw, PackageRenderablesPrintHeader)
+	// Optional columns are shown only if any tool has them.
+	var columns, underlines []string
+	withDescription := pkgs.hasDescription()
+	if withDescription {
+		columns, underlines = append(columns, "Description"), append(underlines, "-----------")
+	}
+	withLabels := pkgs.hasLabels()
+	if withLabels {
+		columns, underlines = append(columns, "Labels"), append(underlines, "------")
+	}
+	withBuildInfo := pkgs.hasBuildInfo()
+	if withBuildInfo {
+		columns, underlines = append(columns, "Go Version", "Revision", "Build Status"), append(underlines, "----------", "--------", "------------")
+	}
+	header := PackageRenderablesPrintHeader
+	if len(columns) > 0 {
+		header = strings.Replace(header, "Build Flags\n", "Build Flags\t"+strings.Join(columns, "\t")+"\n", 1)
+		header = strings.Replace(header, "-----------\n", "-----------\t"+strings.Join(underlines, "\t")+"\n", 1)
+	}
+	_, _ = fmt.Fprint(tw, header)
 	for _, p := range pkgs {
 		if target != "" && p.Name != target {
 			continue
@@ -466,11 +1086,20 @@ func (pkgs PackageRenderables) PrintTab(target string, w io.Writer) error {
 		for _, v := range p.Versions {
 			fields := []string{
 				p.Name,
-				p.Name + "-" + v.Version,
+				p.ArtifactName(v.Version),
 				p.PackagePath + "@" + v.Version,
 				strings.Join(p.BuildEnvVars, " "),
 				strings.Join(p.BuildFlags, " "),
 			}
+			if withDescription {
+				fields = append(fields, orDash(p.Description))
+			}
+			if withLabels {
+				fields = append(fields, orDash(p.Labels.String()))
+			}
+			if withBuildInfo && v.BuildInfo != nil {
+				fields = append(fields, orDash(v.BuildInfo.GoVersion), orDash(v.BuildInfo.Revision), v.BuildInfo.status())
+			}
 			_, _ = fmt.Fprintln(tw, strings.Join(fields, "\t"))
 		}
 		if target != "" {
@@ -484,46 +1113,348 @@ func (pkgs PackageRenderables) PrintTab(target string, w io.Writer) error {
 	return nil
 }
 
-// ListPinnedMainPackages lists all bingo pinned binaries (Go main packages) in the same order as seen in the filesystem.
-func ListPinnedMainPackages(logger *log.Logger, modDir string, remMalformed bool) (pkgs PackageRenderables, _ error) {
+func (pkgs PackageRenderables) hasDescription() bool {
+	for _, p := range pkgs {
+		if p.Description != "" {
+			return true
+		}
+	}
+	return false
+}
+
+func (pkgs PackageRenderables) hasLabels() bool {
+	for _, p := range pkgs {
+		if len(p.Labels) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func (pkgs PackageRenderables) hasBuildInfo() bool {
+	for _, p := range pkgs {
+		for _, v := range p.Versions {
+			if v.BuildInfo != nil {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+type pinnedBuildInfo struct {
+	Installed  bool     `json:"installed"`
+	GoVersion  string   `json:"goVersion,omitempty"`
+	Revision   string   `json:"revision,omitempty"`
+	Mismatches []string `json:"mismatches,omitempty"`
+}
+
+type pinnedVersion struct {
+	Version     string           `json:"version"`
+	ModFile     string           `json:"modFile"`
+	BinaryName  string           `json:"binaryName"`
+	BinaryPath  string           `json:"binaryPath"`
+	ModFileHash string           `json:"modFileHash,omitempty"`
+	Sum         string           `json:"sum,omitempty"`
+	BuildInfo   *pinnedBuildInfo `json:"buildInfo,omitempty"`
+}
+
+type pinnedTool struct {
+	Name         string          `json:"name"`
+	ModPath      string          `json:"modPath"`
+	PackagePath  string          `json:"packagePath"`
+	Description  string          `json:"description,omitempty"`
+	Labels       Labels          `json:"labels,omitempty"`
+	Frozen       bool            `json:"frozen,omitempty"`
+	FrozenReason string          `json:"frozenReason,omitempty"`
+	Aliases      []string        `json:"aliases,omitempty"`
+	EnvVarName   string          `json:"envVarName"`
+	BuildFlags   []string        `json:"buildFlags"`
+	BuildEnvVars []string        `json:"buildEnvVars"`
+	GoFlags      string          `json:"goFlags,omitempty"`
+	BinDir       string          `json:"binDir,omitempty"`
+	Versions     []pinnedVersion `json:"versions"`
+}
+
+// pinnedTools returns all or one (if target is not empty) pinned tools for structured outputs. Binary paths are rendered within given gobin,
+// unless tool has its own BinDir.
+func (pkgs PackageRenderables) pinnedTools(target, gobin string) ([]pinnedTool, error) {
+	tools := []pinnedTool{}
+	for _, p := range pkgs {
+		if target != "" && p.Name != target {
+			continue
+		}
+		t := pinnedTool{
+			Name:         p.Name,
+			ModPath:      p.ModPath,
+			PackagePath:  p.PackagePath,
+			Description:  p.Description,
+			Labels:       p.Labels,
+			Frozen:       p.Frozen,
+			FrozenReason: p.FrozenReason,
+			Aliases:      p.AliasNames(),
+			EnvVarName:   p.EnvVarName,
+			BuildFlags:   append([]string{}, p.BuildFlags...),
+			BuildEnvVars: append([]string{}, p.BuildEnvVars...),
+			GoFlags:      p.GoFlags,
+			BinDir:       p.BinDir,
+		}
+		for _, v := range p.Versions {
+			pv := pinnedVersion{
+				Version:     v.Version,
+				ModFile:     v.ModFile,
+				BinaryName:  p.ArtifactName(v.Version),
+				BinaryPath:  filepath.Join(p.InstallDir(gobin), p.ArtifactName(v.Version)),
+				ModFileHash: v.ModFileHash,
+				Sum:         v.Sum,
+			}
+			if b := v.BuildInfo; b != nil {
+				pv.BuildInfo = &pinnedBuildInfo{Installed: b.Installed, GoVersion: b.GoVersion, Revision: b.Revision, Mismatches: b.Mismatches}
+			}
+			t.Versions = append(t.Versions, pv)
+		}
+		tools = append(tools, t)
+	}
+	if target != "" && len(tools) == 0 {
+		return nil, errors.Errorf("Pinned tool %s not found", target)
+	}
+	return tools, nil
+}
+
+// PrintJSON prints all or one (if target is not empty) pinned tools as JSON array. Binary paths are rendered within given gobin.
+func (pkgs PackageRenderables) PrintJSON(target, gobin string, w io.Writer) error {
+	tools, err := pkgs.pinnedTools(target, gobin)
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(tools)
+}
+
+// PrintYAML prints all or one (if target is not empty) pinned tools as YAML sequence with the same fields as PrintJSON.
+func (pkgs PackageRenderables) PrintYAML(target, gobin string, w io.Writer) error {
+	tools, err := pkgs.pinnedTools(target, gobin)
+	if err != nil {
+		return err
+	}
+	if len(tools) == 0 {
+		_, err = fmt.Fprintln(w, "[]")
+		return err
+	}
+
+	// Double-quoted Go strings are valid YAML scalars, so we can avoid depending on YAML library.
+	yamlList := func(items []string) string {
+		q := make([]string, 0, len(items))
+		for _, i := range items {
+			q = append(q, strconv.Quote(i))
+		}
+		return "[" + strings.Join(q, ", ") + "]"
+	}
+	b := &strings.Builder{}
+	for _, t := range tools {
+		_, _ = fmt.Fprintf(b, "- name: %s\n", strconv.Quote(t.Name))
+		_, _ = fmt.Fprintf(b, "  modPath: %s\n", strconv.Quote(t.ModPath))
+		_, _ = fmt.Fprintf(b, "  packagePath: %s\n", strconv.Quote(t.PackagePath))
+		if t.Description != "" {
+			_, _ = fmt.Fprintf(b, "  description: %s\n", strconv.Quote(t.Description))
+		}
+		if len(t.Labels) > 0 {
+			kvs := make([]string, 0, len(t.Labels))
+			for _, kv := range strings.Split(t.Labels.String(), ",") {
+				k, v, _ := strings.Cut(kv, "=")
+				kvs = append(kvs, strconv.Quote(k)+": "+strconv.Quote(v))
+			}
+			_, _ = fmt.Fprintf(b, "  labels: {%s}\n", strings.Join(kvs, ", "))
+		}
+		if t.Frozen {
+			_, _ = fmt.Fprintln(b, "  frozen: true")
+			if t.FrozenReason != "" {
+				_, _ = fmt.Fprintf(b, "  frozenReason: %s\n", strconv.Quote(t.FrozenReason))
+			}
+		}
+		if len(t.Aliases) > 0 {
+			_, _ = fmt.Fprintf(b, "  aliases: %s\n", yamlList(t.Aliases))
+		}
+		_, _ = fmt.Fprintf(b, "  envVarName: %s\n", strconv.Quote(t.EnvVarName))
+		_, _ = fmt.Fprintf(b, "  buildFlags: %s\n", yamlList(t.BuildFlags))
+		_, _ = fmt.Fprintf(b, "  buildEnvVars: %s\n", yamlList(t.BuildEnvVars))
+		if t.GoFlags != "" {
+			_, _ = fmt.Fprintf(b, "  goFlags: %s\n", strconv.Quote(t.GoFlags))
+		}
+		if t.BinDir != "" {
+			_, _ = fmt.Fprintf(b, "  binDir: %s\n", strconv.Quote(t.BinDir))
+		}
+		_, _ = fmt.Fprintln(b, "  versions:")
+		for _, v := range t.Versions {
+			_, _ = fmt.Fprintf(b, "    - version: %s\n", strconv.Quote(v.Version))
+			_, _ = fmt.Fprintf(b, "      modFile: %s\n", strconv.Quote(v.ModFile))
+			_, _ = fmt.Fprintf(b, "      binaryName: %s\n", strconv.Quote(v.BinaryName))
+			_, _ = fmt.Fprintf(b, "      binaryPath: %s\n", strconv.Quote(v.BinaryPath))
+			if v.ModFileHash != "" {
+				_, _ = fmt.Fprintf(b, "      modFileHash: %s\n", strconv.Quote(v.ModFileHash))
+			}
+			if v.Sum != "" {
+				_, _ = fmt.Fprintf(b, "      sum: %s\n", strconv.Quote(v.Sum))
+			}
+			if bi := v.BuildInfo; bi != nil {
+				_, _ = fmt.Fprintln(b, "      buildInfo:")
+				_, _ = fmt.Fprintf(b, "        installed: %v\n", bi.Installed)
+				if bi.GoVersion != "" {
+					_, _ = fmt.Fprintf(b, "        goVersion: %s\n", strconv.Quote(bi.GoVersion))
+				}
+				if bi.Revision != "" {
+					_, _ = fmt.Fprintf(b, "        revision: %s\n", strconv.Quote(bi.Revision))
+				}
+				if len(bi.Mismatches) > 0 {
+					_, _ = fmt.Fprintf(b, "        mismatches: %s\n", yamlList(bi.Mismatches))
+				}
+			}
+		}
+	}
+	_, err = fmt.Fprint(w, b.String())
+	return err
+}
+
+// PrintCSV prints all or one (if target is not empty) pinned tools as CSV with header, one row per pinned version.
+// Build env vars and flags are space separated.
+func (pkgs PackageRenderables) PrintCSV(target, gobin string, w io.Writer) error {
+	tools, err := pkgs.pinnedTools(target, gobin)
+	if err != nil {
+		return err
+	}
+	cw := csv.NewWriter(w)
+	_ = cw.Write([]string{"name", "binary_name", "package_path", "version", "mod_path", "env_var_name", "mod_file", "binary_path", "build_env_vars", "build_flags"})
+	for _, t := range tools {
+		for _, v := range t.Versions {
+			_ = cw.Write([]string{
+				t.Name, v.BinaryName, t.PackagePath, v.Version, t.ModPath, t.EnvVarName, v.ModFile, v.BinaryPath,
+				strings.Join(t.BuildEnvVars, " "), strings.Join(t.BuildFlags, " "),
+			})
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// pinTemplateData is data of one pinned tool version rendered by list template: all fields of the tool and of the
+// version, as in PrintJSON, e.g {{.Name}}, {{.Version}} or {{.BinaryPath}}.
+type pinTemplateData struct {
+	pinnedTool
+	pinnedVersion
+}
+
+// ParseListTemplate parses template rendered by PrintTemplate for each pinned tool version. Like in 'go list -f', 'join'
+// function joins list with given separator, e.g {{join .BuildFlags " "}}.
+func ParseListTemplate(text string) (*template.Template, error) {
+	return template.New("list").Funcs(template.FuncMap{"join": strings.Join}).Parse(text)
+}
+
+// PrintTemplate prints all or one (if target is not empty) pinned tools, executing given template (see ParseListTemplate)
+// for each pinned version, followed by new line. Binary paths are rendered within given gobin.
+func (pkgs PackageRenderables) PrintTemplate(target, gobin string, tmpl *template.Template, w io.Writer) error {
+	tools, err := pkgs.pinnedTools(target, gobin)
+	if err != nil {
+		return err
+	}
+	b := &bytes.Buffer{}
+	for _, t := range tools {
+		for _, v := range t.Versions {
+			if err := tmpl.Execute(b, pinTemplateData{pinnedTool: t, pinnedVersion: v}); err != nil {
+				return errors.Wrapf(err, "execute template for %s@%s", t.Name, v.Version)
+			}
+			b.WriteString("\n")
+		}
+	}
+	_, err = w.Write(b.Bytes())
+	return err
+}
+
+// ListPinnedMainPackages lists all bingo pinned binaries (Go main packages) sorted by name, with array versions in order
+// of their mod files.
+// Mod files are parsed concurrently and read only.
+func ListPinnedMainPackages(logger logging.Logger, modDir string, remMalformed bool) (pkgs PackageRenderables, _ error) {
 	modFiles, err := filepath.Glob(filepath.Join(modDir, "*.mod"))
 	if err != nil {
 		return nil, err
 	}
-ModLoop:
+	var toolModFiles []string
 	for _, f := range modFiles {
-		if filepath.Base(f) == FakeRootModFileName {
-			continue
+		if filepath.Base(f) != FakeRootModFileName && filepath.Base(f) != ConstraintsModFileName {
+			toolModFiles = append(toolModFiles, f)
 		}
+	}
 
-		pkg, err := ModDirectPackage(f)
-		if err != nil {
+ModLoop:
+	for _, parsed := range parseModFiles(toolModFiles) {
+		f, pkg, cmds, hash := parsed.modFile, parsed.pkg, parsed.cmds, parsed.hash
+		if parsed.err != nil {
+			return nil, parsed.err
+		}
+		if parsed.malformedErr != nil {
 			if remMalformed {
-				logger.Printf("found malformed module file %v, removing due to error: %v\n", f, err)
+				logger.Warn("found malformed module file, removing it", "file", f, "err", parsed.malformedErr)
 				if err := os.RemoveAll(strings.TrimSuffix(f, ".") + "*"); err != nil {
 					return nil, err
 				}
 			}
 			continue
 		}
+		st := parsed.stat
 
 		name, _ := NameFromModFile(f)
-		varName := strings.ReplaceAll(strings.ReplaceAll(strings.ToUpper(name), ".", "_"), "-", "_")
+		varName, overridden := cmds[VarNameCommand]
+		if !overridden || varName == "" {
+			varName, overridden = envVarName(name), false
+		}
+		description := cmds[DescriptionCommand]
+		labels, err := ParseLabels(cmds[LabelsCommand])
+		if err != nil {
+			logger.Warn("ignoring malformed labels", "file", f, "err", err)
+		}
+		if len(labels) == 0 {
+			labels = nil
+		}
+		frozenReason, frozen := cmds[FrozenCommand]
+		goFlags := cmds[GoFlagsCommand]
+		aliases, err := ParseAliases(cmds[AliasesCommand])
+		if err != nil {
+			logger.Warn("ignoring malformed aliases", "file", f, "err", err)
+		}
 		for i, p := range pkgs {
 			if p.Name == name {
-				pkgs[i].EnvVarName = varName + "_ARRAY"
-				// Preserve order. Unfortunately first array mod file has no number, so it's last.
-				if filepath.Base(f) == p.Name+".mod" {
-					pkgs[i].Versions = append([]PackageVersionRenderable{{
-						Version: pkg.Module.Version,
-						ModFile: filepath.Base(f),
-					}}, pkgs[i].Versions...)
-					continue ModLoop
+				if description != "" {
+					pkgs[i].Description = description
+				}
+				if labels != nil {
+					pkgs[i].Labels = pkgs[i].Labels.Merge(labels)
+				}
+				if frozen {
+					pkgs[i].Frozen, pkgs[i].FrozenReason = true, frozenReason
+				}
+				if len(aliases) > 0 && len(pkgs[i].Aliases) == 0 {
+					pkgs[i].Aliases = aliasRenderables(aliases)
+				}
+				switch {
+				case overridden:
+					pkgs[i].EnvVarName = varName
+					pkgs[i].envVarNameOverridden = true
+				case !p.envVarNameOverridden:
+					pkgs[i].EnvVarName = varName + arrayEnvVarNameSuffix
 				}
-
 				pkgs[i].Versions = append(pkgs[i].Versions, PackageVersionRenderable{
-					Version: pkg.Module.Version,
-					ModFile: filepath.Base(f),
+					Version:     pkg.Module.Version,
+					ModFile:     filepath.Base(f),
+					ModFileHash: hash,
+					ModTime:     st.ModTime(),
+					Sum:         cmds[SumCommand],
 				})
 				continue ModLoop
 			}
@@ -531,19 +1462,322 @@ ModLoop:
 		pkgs = append(pkgs, PackageRenderable{
 			Name: name,
 			Versions: []PackageVersionRenderable{
-				{Version: pkg.Module.Version, ModFile: filepath.Base(f)},
+				{Version: pkg.Module.Version, ModFile: filepath.Base(f), ModFileHash: hash, ModTime: st.ModTime(), Sum: cmds[SumCommand]},
 			},
 			BuildFlags:   pkg.BuildFlags,
 			BuildEnvVars: pkg.BuildEnvs,
+			GoFlags:      goFlags,
+			Description:  description,
+			Labels:       labels,
+			Frozen:       frozen,
+			FrozenReason: frozenReason,
+			Aliases:      aliasRenderables(aliases),
+			BinDir:       cmds[BinDirCommand],
 
 			EnvVarName:  varName,
 			PackagePath: pkg.Path(),
 			ModPath:     pkg.Module.Path,
+
+			envVarNameOverridden: overridden,
+			splitFrom:            cmds[SplitFromCommand],
+		})
+		if binDir := pkgs[len(pkgs)-1].BinDir; binDir != "" {
+			if pkgs[len(pkgs)-1].binDirPath, err = filepath.Abs(ToolBinDir(modDir, "", binDir)); err != nil {
+				return nil, errors.Wrap(err, "abs bin dir")
+			}
+		}
+	}
+
+	// Sort explicitly, so generated output does not depend on file system: tools by name and array versions by mod
+	// file number (first array mod file has no number).
+	sort.SliceStable(pkgs, func(i, j int) bool { return pkgs[i].Name < pkgs[j].Name })
+	for _, p := range pkgs {
+		sort.SliceStable(p.Versions, func(i, j int) bool {
+			ni, _ := ModFileNumber(p.Name, p.Versions[i].ModFile)
+			nj, _ := ModFileNumber(p.Name, p.Versions[j].ModFile)
+			return ni < nj
 		})
 	}
 	return pkgs, nil
 }
 
+// parsedModFile is a result of parsing a tool mod file by parseModFiles.
+type parsedModFile struct {
+	modFile string
+	pkg     Package
+	cmds    map[string]string
+	hash    string
+	stat    os.FileInfo
+
+	// malformedErr is set if mod file cannot be parsed or has no direct package, err on other failures.
+	malformedErr, err error
+}
+
+// parseModFiles reads and parses given tool mod files read only, concurrently. Results are in the same order as files.
+func parseModFiles(modFiles []string) []parsedModFile {
+	parsed := make([]parsedModFile, len(modFiles))
+	sem := make(chan struct{}, runtime.GOMAXPROCS(0))
+	wg := sync.WaitGroup{}
+	for i, f := range modFiles {
+		parsed[i].modFile = f
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(p *parsedModFile) {
+			defer func() { <-sem; wg.Done() }()
+			p.parse()
+		}(&parsed[i])
+	}
+	wg.Wait()
+	return parsed
+}
+
+func (p *parsedModFile) parse() {
+	st, err := os.Stat(p.modFile)
+	if err != nil {
+		p.err = err
+		return
+	}
+	b, err := ioutil.ReadFile(p.modFile)
+	if err != nil {
+		p.err = errors.Wrapf(err, "read %v", p.modFile)
+		return
+	}
+	h := sha256.Sum256(b)
+	p.hash, p.stat = hex.EncodeToString(h[:]), st
+
+	m, err := ParseModFileOrReader(p.modFile, bytes.NewReader(b))
+	if err != nil {
+		p.malformedErr = err
+		return
+	}
+	mf := &ModFile{filename: p.modFile}
+	if err := mf.load(m); err != nil {
+		p.malformedErr = err
+		return
+	}
+	if mf.directPackage == nil {
+		p.malformedErr = errors.Errorf("no direct package found in %s; empty module?", p.modFile)
+		return
+	}
+	p.pkg, p.cmds = *mf.directPackage, mf.commands
+}
+
+// ModDirHash returns stable hash of all tools' mod files in given mod directory, so it changes only if pins change.
+// Extra strings (e.g GOOS or Go version) are included in the hash too.
+func ModDirHash(modDir string, extra ...string) (string, error) {
+	modFiles, err := filepath.Glob(filepath.Join(modDir, "*.mod"))
+	if err != nil {
+		return "", err
+	}
+	sort.Strings(modFiles)
+
+	h := sha256.New()
+	for _, f := range modFiles {
+		base := filepath.Base(f)
+		if base == FakeRootModFileName || strings.HasSuffix(base, "tmp.mod") {
+			continue
+		}
+		b, err := ioutil.ReadFile(f)
+		if err != nil {
+			return "", errors.Wrapf(err, "read %v", f)
+		}
+		_, _ = fmt.Fprintf(h, "%s %d\n", base, len(b))
+		_, _ = h.Write(b)
+	}
+	for _, e := range extra {
+		_, _ = fmt.Fprintf(h, "%s\n", e)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+const arrayEnvVarNameSuffix = "_ARRAY"
+
+// envVarName returns default variable name for the tool name.
+func envVarName(name string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(strings.ToUpper(name), ".", "_"), "-", "_")
+}
+
+var envVarNameRe = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// ApplyEnvVarNaming adds given prefix and suffix to all variable names that were not explicitly set via
+// VarNameCommand. Suffix is added before "_ARRAY" suffix of array tools.
+func (pkgs PackageRenderables) ApplyEnvVarNaming(prefix, suffix string) {
+	for i, p := range pkgs {
+		if p.envVarNameOverridden {
+			continue
+		}
+		if strings.HasSuffix(p.EnvVarName, arrayEnvVarNameSuffix) && len(p.Versions) > 1 {
+			pkgs[i].EnvVarName = prefix + strings.TrimSuffix(p.EnvVarName, arrayEnvVarNameSuffix) + suffix + arrayEnvVarNameSuffix
+			continue
+		}
+		pkgs[i].EnvVarName = prefix + p.EnvVarName + suffix
+	}
+	for i := range pkgs {
+		for j, a := range pkgs[i].Aliases {
+			pkgs[i].Aliases[j].EnvVarName = prefix + a.EnvVarName + suffix
+		}
+	}
+}
+
+// goexeVarName is a name of variable with executable suffix defined by generated helpers.
+const goexeVarName = "GOEXE"
+
+// ValidateEnvVarNames returns error if any variable name is not a valid variable name or collides with another tool's
+// variable name or variables defined by generated helpers.
+func (pkgs PackageRenderables) ValidateEnvVarNames() error {
+	byName := map[string]string{}
+	for _, p := range pkgs {
+		if !envVarNameRe.MatchString(p.EnvVarName) {
+			return errors.Errorf("tool %v has invalid variable name %q; set different one using // %s <NAME> comment in its mod file", p.Name, p.EnvVarName, VarNameCommand)
+		}
+		if p.EnvVarName == goexeVarName {
+			return errors.Errorf("tool %v has variable name %q reserved for executable suffix in generated helpers; set different one using // %s <NAME> comment in its mod file", p.Name, p.EnvVarName, VarNameCommand)
+		}
+		if other, ok := byName[p.EnvVarName]; ok {
+			return errors.Errorf("tools %v and %v have the same variable name %q; set different one using // %s <NAME> comment in one of their mod files", other, p.Name, p.EnvVarName, VarNameCommand)
+		}
+		byName[p.EnvVarName] = p.Name
+	}
+	for _, p := range pkgs {
+		for _, a := range p.Aliases {
+			if !envVarNameRe.MatchString(a.EnvVarName) || a.EnvVarName == goexeVarName {
+				return errors.Errorf("alias %v of tool %v has invalid variable name %q", a.Name, p.Name, a.EnvVarName)
+			}
+			if other, ok := byName[a.EnvVarName]; ok {
+				return errors.Errorf("alias %v of tool %v has the same variable name %q as %v", a.Name, p.Name, a.EnvVarName, other)
+			}
+			byName[a.EnvVarName] = p.Name + " alias " + a.Name
+		}
+	}
+	return nil
+}
+
+// Orders of tools supported by SortRenderablesBy.
+const (
+	SortByName    = "name"
+	SortByVersion = "version"
+	SortByModule  = "module"
+	SortByUpdated = "updated"
+)
+
+// SortRenderablesBy sorts tools like SortRenderables, then by given order: SortByName, SortByVersion (latest pinned
+// version, lowest first), SortByModule (module path) or SortByUpdated (most recently modified mod file first).
+func SortRenderablesBy(pkgs []PackageRenderable, by string) error {
+	SortRenderables(pkgs)
+	switch by {
+	case SortByName:
+	case SortByVersion:
+		sort.SliceStable(pkgs, func(i, j int) bool {
+			return semver.Compare(pkgs[i].latestVersion(), pkgs[j].latestVersion()) < 0
+		})
+	case SortByModule:
+		sort.SliceStable(pkgs, func(i, j int) bool { return pkgs[i].ModPath < pkgs[j].ModPath })
+	case SortByUpdated:
+		sort.SliceStable(pkgs, func(i, j int) bool { return pkgs[i].updated().After(pkgs[j].updated()) })
+	default:
+		return errors.Errorf("unknown sort order %q; expected one of %q, %q, %q or %q", by, SortByName, SortByVersion, SortByModule, SortByUpdated)
+	}
+	return nil
+}
+
+func (p PackageRenderable) latestVersion() string {
+	var latest string
+	for _, v := range p.Versions {
+		if latest == "" || semver.Compare(v.Version, latest) > 0 {
+			latest = v.Version
+		}
+	}
+	return latest
+}
+
+func (p PackageRenderable) updated() time.Time {
+	var updated time.Time
+	for _, v := range p.Versions {
+		if v.ModTime.After(updated) {
+			updated = v.ModTime
+		}
+	}
+	return updated
+}
+
+// Filter returns tools with name matching given regexp, module path matching given pattern and labels matching given
+// selector. Pattern is in go list format, so "..." matches any string, e.g "github.com/golangci/..." matches
+// github.com/golangci and all modules under it. Nil regexp, empty pattern and empty selector match all tools.
+func (pkgs PackageRenderables) Filter(name *regexp.Regexp, modulePattern string, selector Labels) PackageRenderables {
+	var modRe *regexp.Regexp
+	if modulePattern != "" {
+		modRe = modulePatternRegexp(modulePattern)
+	}
+	ret := PackageRenderables{}
+	for _, p := range pkgs {
+		if name != nil && !name.MatchString(p.Name) {
+			continue
+		}
+		if modRe != nil && !modRe.MatchString(p.ModPath) {
+			continue
+		}
+		if !p.Labels.Matches(selector) {
+			continue
+		}
+		ret = append(ret, p)
+	}
+	return ret
+}
+
+// Page returns given page (starting from 1) of tools, with at most limit tools per page. Zero limit returns all tools.
+func (pkgs PackageRenderables) Page(limit, page int) PackageRenderables {
+	if limit <= 0 {
+		return pkgs
+	}
+	start := (page - 1) * limit
+	if page < 1 || start >= len(pkgs) {
+		return PackageRenderables{}
+	}
+	end := start + limit
+	if end > len(pkgs) {
+		end = len(pkgs)
+	}
+	return pkgs[start:end]
+}
+
+// DuplicatePin is a package pinned under more than one tool name (e.g golangci-lint and lint), so upgrades of its pins
+// diverge silently.
+type DuplicatePin struct {
+	PackagePath string
+	Names       []string
+}
+
+// Duplicates returns packages pinned under more than one tool name, sorted by package path. Tools split from others
+// (see SplitFromCommand) are pinned separately on purpose, so they are not counted.
+func (pkgs PackageRenderables) Duplicates() []DuplicatePin {
+	names := map[string][]string{}
+	for _, p := range pkgs {
+		if p.splitFrom != "" {
+			continue
+		}
+		names[p.PackagePath] = append(names[p.PackagePath], p.Name)
+	}
+	var dups []DuplicatePin
+	for path, n := range names {
+		if len(n) < 2 {
+			continue
+		}
+		sort.Strings(n)
+		dups = append(dups, DuplicatePin{PackagePath: path, Names: n})
+	}
+	sort.Slice(dups, func(i, j int) bool { return dups[i].PackagePath < dups[j].PackagePath })
+	return dups
+}
+
+func modulePatternRegexp(pattern string) *regexp.Regexp {
+	re := strings.ReplaceAll(regexp.QuoteMeta(pattern), `\.\.\.`, `.*`)
+	// Like in go list, "x/..." matches "x" too.
+	if strings.HasSuffix(re, `/.*`) {
+		re = strings.TrimSuffix(re, `/.*`) + `(/.*)?`
+	}
+	return regexp.MustCompile("^" + re + "$")
+}
+
 func SortRenderables(pkgs []PackageRenderable) {
 	for _, p := range pkgs {
 		sort.Slice(p.Versions, func(i, j int) bool {
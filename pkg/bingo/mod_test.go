@@ -4,16 +4,20 @@
 package bingo
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"io/ioutil"
-	"log"
+	"log/slog"
 	"os"
 	"path/filepath"
+	"regexp"
 	"testing"
+	"time"
 
 	"github.com/bwplotka/bingo/pkg/runner"
 	"github.com/efficientgo/tools/core/pkg/testutil"
+	"golang.org/x/mod/modfile"
 	"golang.org/x/mod/module"
 )
 
@@ -26,35 +30,35 @@ func TestCreateFromExistingOrNew(t *testing.T) {
 	testutil.Ok(t, err)
 	t.Cleanup(func() { testutil.Ok(t, os.RemoveAll(tmpDir)) })
 
-	logger := log.New(os.Stderr, "", 0)
+	logger := slog.New(slog.NewTextHandler(os.Stderr, nil))
 	r, err := runner.NewRunner(context.TODO(), logger, false, "go")
 	testutil.Ok(t, err)
 
 	t.Run("create new and close should create empty mod file with basic autogenerated meta", func(t *testing.T) {
-		f, err := CreateFromExistingOrNew(context.TODO(), r, log.New(os.Stderr, "", 0), "non_existing.mod", "test.mod")
+		f, err := CreateFromExistingOrNew(context.TODO(), r, slog.New(slog.NewTextHandler(os.Stderr, nil)), filepath.Join(tmpDir, "non_existing.mod"), filepath.Join(tmpDir, "test.mod"))
 		testutil.Ok(t, err)
 		testutil.Ok(t, f.Close())
 
 		expectContent(t, fmt.Sprintf(`module _ // Auto generated by https://github.com/bwplotka/bingo. DO NOT EDIT
 
 go %s
-`, goVersion(r)), "test.mod")
+`, goVersion(r)), filepath.Join(tmpDir, "test.mod"))
 	})
 	t.Run("create new and close should work and produce same output", func(t *testing.T) {
-		f, err := CreateFromExistingOrNew(context.TODO(), r, log.New(os.Stderr, "", 0), "test.mod", "test2.mod")
+		f, err := CreateFromExistingOrNew(context.TODO(), r, slog.New(slog.NewTextHandler(os.Stderr, nil)), filepath.Join(tmpDir, "test.mod"), filepath.Join(tmpDir, "test2.mod"))
 		testutil.Ok(t, err)
 		testutil.Ok(t, f.Close())
 		expectContent(t, fmt.Sprintf(`module _ // Auto generated by https://github.com/bwplotka/bingo. DO NOT EDIT
 
 go %s
-`, goVersion(r)), "test.mod")
+`, goVersion(r)), filepath.Join(tmpDir, "test.mod"))
 		expectContent(t, fmt.Sprintf(`module _ // Auto generated by https://github.com/bwplotka/bingo. DO NOT EDIT
 
 go %s
-`, goVersion(r)), "test2.mod")
+`, goVersion(r)), filepath.Join(tmpDir, "test2.mod"))
 	})
 	t.Run("create new and set direct require should work", func(t *testing.T) {
-		f, err := CreateFromExistingOrNew(context.TODO(), r, log.New(os.Stderr, "", 0), "", "test3.mod")
+		f, err := CreateFromExistingOrNew(context.TODO(), r, slog.New(slog.NewTextHandler(os.Stderr, nil)), "", filepath.Join(tmpDir, "test3.mod"))
 		testutil.Ok(t, err)
 		testutil.Ok(t, f.SetDirectRequire(Package{Module: module.Version{Path: "github.com/yolo/best/v100", Version: "v100.0.0"}, RelPath: "thebest"}))
 		testutil.Equals(t, Package{Module: module.Version{Path: "github.com/yolo/best/v100", Version: "v100.0.0"}, RelPath: "thebest"}, *f.DirectPackage())
@@ -64,10 +68,10 @@ go %s
 go %s
 
 require github.com/yolo/best/v100 v100.0.0 // thebest
-`, goVersion(r)), "test3.mod")
+`, goVersion(r)), filepath.Join(tmpDir, "test3.mod"))
 	})
 	t.Run("create new and set direct require2 should work", func(t *testing.T) {
-		f, err := CreateFromExistingOrNew(context.TODO(), r, log.New(os.Stderr, "", 0), "", "test4.mod")
+		f, err := CreateFromExistingOrNew(context.TODO(), r, slog.New(slog.NewTextHandler(os.Stderr, nil)), "", filepath.Join(tmpDir, "test4.mod"))
 		testutil.Ok(t, err)
 		testutil.Ok(t, f.SetDirectRequire(Package{Module: module.Version{Path: "github.com/yolo/best/v100", Version: "v100.0.0"}}))
 		testutil.Equals(t, Package{Module: module.Version{Path: "github.com/yolo/best/v100", Version: "v100.0.0"}}, *f.DirectPackage())
@@ -77,10 +81,10 @@ require github.com/yolo/best/v100 v100.0.0 // thebest
 go %s
 
 require github.com/yolo/best/v100 v100.0.0
-`, goVersion(r)), "test4.mod")
+`, goVersion(r)), filepath.Join(tmpDir, "test4.mod"))
 	})
 	t.Run("copy and set direct require to something else", func(t *testing.T) {
-		f, err := CreateFromExistingOrNew(context.TODO(), r, log.New(os.Stderr, "", 0), "test3.mod", "test5.mod")
+		f, err := CreateFromExistingOrNew(context.TODO(), r, slog.New(slog.NewTextHandler(os.Stderr, nil)), filepath.Join(tmpDir, "test3.mod"), filepath.Join(tmpDir, "test5.mod"))
 		testutil.Ok(t, err)
 		testutil.Equals(t, Package{Module: module.Version{Path: "github.com/yolo/best/v100", Version: "v100.0.0"}, RelPath: "thebest"}, *f.DirectPackage())
 		testutil.Ok(t, f.Flush())
@@ -89,7 +93,7 @@ require github.com/yolo/best/v100 v100.0.0
 go %s
 
 require github.com/yolo/best/v100 v100.0.0 // thebest
-`, goVersion(r)), "test5.mod")
+`, goVersion(r)), filepath.Join(tmpDir, "test5.mod"))
 
 		testutil.Ok(t, f.SetDirectRequire(Package{Module: module.Version{Path: "github.com/yolo/not-best", Version: "v1"}}))
 		testutil.Equals(t, Package{Module: module.Version{Path: "github.com/yolo/not-best", Version: "v1"}}, *f.DirectPackage())
@@ -99,7 +103,7 @@ require github.com/yolo/best/v100 v100.0.0 // thebest
 go %s
 
 require github.com/yolo/not-best v1
-`, goVersion(r)), "test5.mod")
+`, goVersion(r)), filepath.Join(tmpDir, "test5.mod"))
 	})
 }
 
@@ -270,3 +274,652 @@ require github.com/prometheus/prometheus v2.4.3+incompatible // CGO_ENABLED=1 GO
 		testutil.Equals(t, testFile, mf.FileName())
 	})
 }
+
+func TestListPinnedMainPackages_EnvVarNames(t *testing.T) {
+	tmpDir, err := ioutil.TempDir(os.TempDir(), "bingo-list")
+	testutil.Ok(t, err)
+	t.Cleanup(func() { testutil.Ok(t, os.RemoveAll(tmpDir)) })
+
+	for f, content := range map[string]string{
+		"foo-bar.mod": `module _ // Auto generated by https://github.com/bwplotka/bingo. DO NOT EDIT
+
+go 1.14
+
+require github.com/yolo/foo-bar v1.0.0
+`,
+		"foo_bar.mod": `module _ // Auto generated by https://github.com/bwplotka/bingo. DO NOT EDIT
+
+go 1.14
+
+// bingo:var_name FOO_UNDERSCORE_BAR
+
+require github.com/yolo/foo_bar v1.0.0
+`,
+		"arr.mod": `module _ // Auto generated by https://github.com/bwplotka/bingo. DO NOT EDIT
+
+go 1.14
+
+require github.com/yolo/arr v1.0.0
+`,
+		"arr.1.mod": `module _ // Auto generated by https://github.com/bwplotka/bingo. DO NOT EDIT
+
+go 1.14
+
+require github.com/yolo/arr v1.1.0
+`,
+	} {
+		testutil.Ok(t, ioutil.WriteFile(filepath.Join(tmpDir, f), []byte(content), os.ModePerm))
+	}
+
+	pkgs, err := ListPinnedMainPackages(slog.New(slog.NewTextHandler(os.Stderr, nil)), tmpDir, false)
+	testutil.Ok(t, err)
+	SortRenderables(pkgs)
+	testutil.Ok(t, pkgs.ValidateEnvVarNames())
+	testutil.Equals(t, []string{"ARR_ARRAY", "FOO_BAR", "FOO_UNDERSCORE_BAR"}, []string{pkgs[0].EnvVarName, pkgs[1].EnvVarName, pkgs[2].EnvVarName})
+
+	pkgs.ApplyEnvVarNaming("TOOL_", "_BIN")
+	testutil.Ok(t, pkgs.ValidateEnvVarNames())
+	testutil.Equals(t, []string{"TOOL_ARR_BIN_ARRAY", "TOOL_FOO_BAR_BIN", "FOO_UNDERSCORE_BAR"}, []string{pkgs[0].EnvVarName, pkgs[1].EnvVarName, pkgs[2].EnvVarName})
+
+	pkgs[2].EnvVarName = "TOOL_FOO_BAR_BIN"
+	testutil.NotOk(t, pkgs.ValidateEnvVarNames())
+
+	// GOEXE is defined by generated helpers.
+	pkgs[2].EnvVarName = "GOEXE"
+	testutil.NotOk(t, pkgs.ValidateEnvVarNames())
+}
+
+func TestListPinnedMainPackages_Order(t *testing.T) {
+	tmpDir := t.TempDir()
+	for f, version := range map[string]string{"buf.mod": "v1.0.0", "buf.2.mod": "v1.2.0", "buf.10.mod": "v1.10.0", "buf-x.mod": "v0.1.0", "a.mod": "v0.2.0"} {
+		testutil.Ok(t, ioutil.WriteFile(filepath.Join(tmpDir, f), []byte("module _ // Auto generated by https://github.com/bwplotka/bingo. DO NOT EDIT\n\ngo 1.14\n\nrequire github.com/yolo/buf "+version+" // cmd/buf\n"), os.ModePerm))
+	}
+
+	pkgs, err := ListPinnedMainPackages(slog.New(slog.NewTextHandler(ioutil.Discard, nil)), tmpDir, false)
+	testutil.Ok(t, err)
+	testutil.Equals(t, []string{"a", "buf", "buf-x"}, []string{pkgs[0].Name, pkgs[1].Name, pkgs[2].Name})
+	var versions []string
+	for _, v := range pkgs[1].Versions {
+		versions = append(versions, v.Version)
+	}
+	testutil.Equals(t, []string{"v1.0.0", "v1.2.0", "v1.10.0"}, versions)
+	testutil.Equals(t, "github.com/yolo/buf/cmd/buf", pkgs[1].PackagePath)
+}
+
+func TestListPinnedMainPackages_DottedNameOrder(t *testing.T) {
+	tmpDir := t.TempDir()
+	for f, version := range map[string]string{"buf.mod": "v1.0.0", "buf.1.mod": "v1.1.0", "buf.v2.mod": "v1.20.0", "buf.v2.10.mod": "v1.30.0", "buf.v2.2.mod": "v1.22.0"} {
+		testutil.Ok(t, ioutil.WriteFile(filepath.Join(tmpDir, f), []byte("module _ // Auto generated by https://github.com/bwplotka/bingo. DO NOT EDIT\n\ngo 1.14\n\nrequire github.com/yolo/buf "+version+" // cmd/buf\n"), os.ModePerm))
+	}
+
+	pkgs, err := ListPinnedMainPackages(slog.New(slog.NewTextHandler(ioutil.Discard, nil)), tmpDir, false)
+	testutil.Ok(t, err)
+	testutil.Equals(t, 2, len(pkgs))
+	testutil.Equals(t, []string{"buf", "buf.v2"}, []string{pkgs[0].Name, pkgs[1].Name})
+	for i, expected := range [][]string{{"v1.0.0", "v1.1.0"}, {"v1.20.0", "v1.22.0", "v1.30.0"}} {
+		var versions []string
+		for _, v := range pkgs[i].Versions {
+			versions = append(versions, v.Version)
+		}
+		testutil.Equals(t, expected, versions)
+	}
+}
+
+func TestModFileNumber(t *testing.T) {
+	for _, tcase := range []struct {
+		name, modFile string
+		expected      int
+		ok            bool
+	}{
+		{name: "buf", modFile: "buf.mod", expected: 0, ok: true},
+		{name: "buf", modFile: ".bingo/buf.2.mod", expected: 2, ok: true},
+		{name: "buf", modFile: "buf.v2.mod"},
+		{name: "buf", modFile: "buf.v2.1.mod"},
+		{name: "buf.v2", modFile: "buf.v2.mod", expected: 0, ok: true},
+		{name: "buf.v2", modFile: "buf.v2.1.mod", expected: 1, ok: true},
+		{name: "buf.v2", modFile: "buf.mod"},
+	} {
+		t.Run(tcase.name+" "+tcase.modFile, func(t *testing.T) {
+			n, ok := ModFileNumber(tcase.name, tcase.modFile)
+			testutil.Equals(t, tcase.ok, ok)
+			testutil.Equals(t, tcase.expected, n)
+		})
+	}
+}
+
+func TestListPinnedMainPackages_ReadOnly(t *testing.T) {
+	tmpDir := t.TempDir()
+	content := "module _\n\ngo 1.14\n\nrequire github.com/yolo/foo v1.0.0 //  cmd/foo\nrequire github.com/yolo/bar v1.0.0\n"
+	for i := 0; i < 20; i++ {
+		testutil.Ok(t, ioutil.WriteFile(filepath.Join(tmpDir, fmt.Sprintf("foo%d.mod", i)), []byte(content), os.ModePerm))
+	}
+	testutil.Ok(t, ioutil.WriteFile(filepath.Join(tmpDir, "malformed.mod"), []byte("require ("), os.ModePerm))
+
+	pkgs, err := ListPinnedMainPackages(slog.New(slog.NewTextHandler(ioutil.Discard, nil)), tmpDir, false)
+	testutil.Ok(t, err)
+	testutil.Equals(t, 20, len(pkgs))
+	for _, p := range pkgs {
+		testutil.Equals(t, "github.com/yolo/foo/cmd/foo", p.PackagePath)
+	}
+	for i := 0; i < 20; i++ {
+		b, err := ioutil.ReadFile(filepath.Join(tmpDir, fmt.Sprintf("foo%d.mod", i)))
+		testutil.Ok(t, err)
+		testutil.Equals(t, content, string(b))
+	}
+
+	mf, err := ReadModFile(filepath.Join(tmpDir, "foo0.mod"))
+	testutil.Ok(t, err)
+	testutil.Equals(t, "v1.0.0", mf.DirectPackage().Module.Version)
+	testutil.NotOk(t, mf.Flush())
+	testutil.Ok(t, mf.Close())
+}
+
+func TestPackageRenderables_Page(t *testing.T) {
+	pkgs := PackageRenderables{{Name: "a"}, {Name: "b"}, {Name: "c"}}
+	testutil.Equals(t, pkgs, pkgs.Page(0, 1))
+	testutil.Equals(t, PackageRenderables{{Name: "a"}, {Name: "b"}}, pkgs.Page(2, 1))
+	testutil.Equals(t, PackageRenderables{{Name: "c"}}, pkgs.Page(2, 2))
+	testutil.Equals(t, PackageRenderables{}, pkgs.Page(2, 3))
+	testutil.Equals(t, PackageRenderables{}, pkgs.Page(2, 0))
+}
+
+func TestModDirHash(t *testing.T) {
+	tmpDir, err := ioutil.TempDir(os.TempDir(), "bingo-mod")
+	testutil.Ok(t, err)
+	t.Cleanup(func() { testutil.Ok(t, os.RemoveAll(tmpDir)) })
+
+	testutil.Ok(t, ioutil.WriteFile(filepath.Join(tmpDir, "a.mod"), []byte("module _\n"), os.ModePerm))
+	testutil.Ok(t, ioutil.WriteFile(filepath.Join(tmpDir, "b.mod"), []byte("module _\n"), os.ModePerm))
+	h1, err := ModDirHash(tmpDir)
+	testutil.Ok(t, err)
+
+	// Fake go.mod, tmp mod files and non mod files do not change hash.
+	testutil.Ok(t, ioutil.WriteFile(filepath.Join(tmpDir, FakeRootModFileName), []byte("module _\n"), os.ModePerm))
+	testutil.Ok(t, ioutil.WriteFile(filepath.Join(tmpDir, "a-e2d3tmp.mod"), []byte("module _\n"), os.ModePerm))
+	testutil.Ok(t, ioutil.WriteFile(filepath.Join(tmpDir, "a.sum"), []byte("sum"), os.ModePerm))
+	h, err := ModDirHash(tmpDir)
+	testutil.Ok(t, err)
+	testutil.Equals(t, h1, h)
+
+	h, err = ModDirHash(tmpDir, "linux", "amd64")
+	testutil.Ok(t, err)
+	testutil.Assert(t, h1 != h, "extra strings should change hash")
+
+	testutil.Ok(t, ioutil.WriteFile(filepath.Join(tmpDir, "b.mod"), []byte("module _\n\nrequire a v1.0.0\n"), os.ModePerm))
+	h, err = ModDirHash(tmpDir)
+	testutil.Ok(t, err)
+	testutil.Assert(t, h1 != h, "changed mod file should change hash")
+}
+
+func TestPackageRenderables_PrintJSON(t *testing.T) {
+	pkgs := PackageRenderables{
+		{
+			Name:         "faillint",
+			ModPath:      "github.com/fatih/faillint",
+			PackagePath:  "github.com/fatih/faillint",
+			EnvVarName:   "FAILLINT",
+			BuildEnvVars: []string{"CGO_ENABLED=0"},
+			Versions:     []PackageVersionRenderable{{Version: "v1.5.0", ModFile: "faillint.mod", Sum: "h1:abc="}},
+		},
+		{Name: "buf", Versions: []PackageVersionRenderable{{Version: "v0.1.0", ModFile: "buf.mod"}}},
+	}
+
+	b := &bytes.Buffer{}
+	testutil.NotOk(t, pkgs.PrintJSON("nope", "/gobin", b))
+
+	testutil.Ok(t, pkgs.PrintJSON("faillint", "/gobin", b))
+	testutil.Equals(t, `[
+  {
+    "name": "faillint",
+    "modPath": "github.com/fatih/faillint",
+    "packagePath": "github.com/fatih/faillint",
+    "envVarName": "FAILLINT",
+    "buildFlags": [],
+    "buildEnvVars": [
+      "CGO_ENABLED=0"
+    ],
+    "versions": [
+      {
+        "version": "v1.5.0",
+        "modFile": "faillint.mod",
+        "binaryName": "faillint-v1.5.0",
+        "binaryPath": "/gobin/faillint-v1.5.0",
+        "sum": "h1:abc="
+      }
+    ]
+  }
+]
+`, b.String())
+}
+
+func TestPackageRenderables_PrintYAMLAndCSV(t *testing.T) {
+	pkgs := PackageRenderables{
+		{
+			Name:         "faillint",
+			ModPath:      "github.com/fatih/faillint",
+			PackagePath:  "github.com/fatih/faillint",
+			EnvVarName:   "FAILLINT",
+			BuildEnvVars: []string{"CGO_ENABLED=0"},
+			BuildFlags:   []string{"-tags=a,b"},
+			Versions:     []PackageVersionRenderable{{Version: "v1.5.0", ModFile: "faillint.mod"}},
+		},
+	}
+
+	b := &bytes.Buffer{}
+	testutil.Ok(t, pkgs.PrintYAML("", "/gobin", b))
+	testutil.Equals(t, `- name: "faillint"
+  modPath: "github.com/fatih/faillint"
+  packagePath: "github.com/fatih/faillint"
+  envVarName: "FAILLINT"
+  buildFlags: ["-tags=a,b"]
+  buildEnvVars: ["CGO_ENABLED=0"]
+  versions:
+    - version: "v1.5.0"
+      modFile: "faillint.mod"
+      binaryName: "faillint-v1.5.0"
+      binaryPath: "/gobin/faillint-v1.5.0"
+`, b.String())
+
+	b.Reset()
+	testutil.Ok(t, PackageRenderables{}.PrintYAML("", "/gobin", b))
+	testutil.Equals(t, "[]\n", b.String())
+
+	b.Reset()
+	testutil.Ok(t, pkgs.PrintCSV("", "/gobin", b))
+	testutil.Equals(t, `name,binary_name,package_path,version,mod_path,env_var_name,mod_file,binary_path,build_env_vars,build_flags
+faillint,faillint-v1.5.0,github.com/fatih/faillint,v1.5.0,github.com/fatih/faillint,FAILLINT,faillint.mod,/gobin/faillint-v1.5.0,CGO_ENABLED=0,"-tags=a,b"
+`, b.String())
+}
+
+func TestPackageRenderables_PrintTemplate(t *testing.T) {
+	pkgs := PackageRenderables{
+		{
+			Name:        "faillint",
+			ModPath:     "github.com/fatih/faillint",
+			PackagePath: "github.com/fatih/faillint",
+			EnvVarName:  "FAILLINT",
+			BuildFlags:  []string{"-tags=a", "-trimpath"},
+			Versions: []PackageVersionRenderable{
+				{Version: "v1.4.0", ModFile: "faillint.1.mod"},
+				{Version: "v1.5.0", ModFile: "faillint.2.mod"},
+			},
+		},
+	}
+
+	tmpl, err := ParseListTemplate(`{{.Name}} {{.Version}} {{.BinaryPath}} {{join .BuildFlags " "}}`)
+	testutil.Ok(t, err)
+	b := &bytes.Buffer{}
+	testutil.Ok(t, pkgs.PrintTemplate("", "/gobin", tmpl, b))
+	testutil.Equals(t, `faillint v1.4.0 /gobin/faillint-v1.4.0 -tags=a -trimpath
+faillint v1.5.0 /gobin/faillint-v1.5.0 -tags=a -trimpath
+`, b.String())
+
+	tmpl, err = ParseListTemplate(`{{.NoSuchField}}`)
+	testutil.Ok(t, err)
+	testutil.NotOk(t, pkgs.PrintTemplate("", "/gobin", tmpl, b))
+
+	_, err = ParseListTemplate(`{{.Name`)
+	testutil.NotOk(t, err)
+}
+
+func TestPackageRenderables_Filter(t *testing.T) {
+	pkgs := PackageRenderables{
+		{Name: "golangci-lint", ModPath: "github.com/golangci/golangci-lint", Labels: Labels{"team": "platform", "stage": "lint"}},
+		{Name: "misspell", ModPath: "github.com/golangci/misspell", Labels: Labels{"team": "docs"}},
+		{Name: "golangci", ModPath: "github.com/golangci"},
+		{Name: "faillint", ModPath: "github.com/fatih/faillint"},
+	}
+	names := func(pkgs PackageRenderables) (ret []string) {
+		for _, p := range pkgs {
+			ret = append(ret, p.Name)
+		}
+		return ret
+	}
+
+	testutil.Equals(t, []string{"golangci-lint", "misspell", "golangci", "faillint"}, names(pkgs.Filter(nil, "", nil)))
+	testutil.Equals(t, []string{"golangci-lint", "golangci"}, names(pkgs.Filter(regexp.MustCompile("^(?:golangci.*)$"), "", nil)))
+	testutil.Equals(t, []string{"golangci-lint", "misspell", "golangci"}, names(pkgs.Filter(nil, "github.com/golangci/...", nil)))
+	testutil.Equals(t, []string{"misspell"}, names(pkgs.Filter(nil, "github.com/.../misspell", nil)))
+	testutil.Equals(t, []string{"golangci-lint"}, names(pkgs.Filter(regexp.MustCompile("^(?:golangci.*)$"), "github.com/golangci/golangci-...", nil)))
+	testutil.Equals(t, 0, len(pkgs.Filter(nil, "github.com/golangci/golangci", nil)))
+	testutil.Equals(t, []string{"golangci-lint"}, names(pkgs.Filter(nil, "", Labels{"team": "platform"})))
+	testutil.Equals(t, []string{"golangci", "faillint"}, names(pkgs.Filter(nil, "", Labels{"team": ""})))
+	testutil.Equals(t, 0, len(pkgs.Filter(nil, "", Labels{"team": "platform", "stage": "release"})))
+}
+
+func TestSortRenderablesBy(t *testing.T) {
+	now := time.Now()
+	newPkgs := func() PackageRenderables {
+		return PackageRenderables{
+			{Name: "faillint", ModPath: "github.com/fatih/faillint", Versions: []PackageVersionRenderable{{Version: "v1.10.0", ModTime: now.Add(-time.Hour)}}},
+			{Name: "buf", ModPath: "github.com/bufbuild/buf", Versions: []PackageVersionRenderable{
+				{Version: "v0.2.0", ModTime: now.Add(-2 * time.Hour)},
+				{Version: "v0.1.0", ModTime: now},
+			}},
+			{Name: "goimports", ModPath: "bitbucket.org/x/tools", Versions: []PackageVersionRenderable{{Version: "v1.9.0", ModTime: now.Add(time.Hour)}}},
+		}
+	}
+	names := func(pkgs PackageRenderables) (ret []string) {
+		for _, p := range pkgs {
+			ret = append(ret, p.Name)
+		}
+		return ret
+	}
+
+	for _, tcase := range []struct {
+		by       string
+		expected []string
+	}{
+		{by: SortByName, expected: []string{"buf", "faillint", "goimports"}},
+		{by: SortByVersion, expected: []string{"buf", "goimports", "faillint"}},
+		{by: SortByModule, expected: []string{"goimports", "buf", "faillint"}},
+		{by: SortByUpdated, expected: []string{"goimports", "buf", "faillint"}},
+	} {
+		t.Run(tcase.by, func(t *testing.T) {
+			pkgs := newPkgs()
+			testutil.Ok(t, SortRenderablesBy(pkgs, tcase.by))
+			testutil.Equals(t, tcase.expected, names(pkgs))
+			for _, p := range pkgs {
+				if p.Name == "buf" {
+					testutil.Equals(t, "v0.1.0", p.Versions[0].Version)
+				}
+			}
+		})
+	}
+	testutil.NotOk(t, SortRenderablesBy(newPkgs(), "size"))
+}
+
+func TestModFile_SetCommand(t *testing.T) {
+	testFile := filepath.Join(t.TempDir(), "test.mod")
+	testutil.Ok(t, ioutil.WriteFile(testFile, []byte(`module _ // Auto generated by https://github.com/bwplotka/bingo. DO NOT EDIT
+
+go 1.14
+
+require github.com/fatih/faillint v1.5.0
+`), os.ModePerm))
+
+	mf, err := OpenModFile(testFile)
+	testutil.Ok(t, err)
+	t.Cleanup(func() { _ = mf.Close() })
+
+	expect := func(content string) {
+		t.Helper()
+		testutil.Ok(t, mf.Flush())
+		b, err := ioutil.ReadFile(testFile)
+		testutil.Ok(t, err)
+		testutil.Equals(t, content, string(b))
+	}
+
+	mf.SetCommand(DescriptionCommand, "Checks forbidden imports")
+	expect(`module _ // Auto generated by https://github.com/bwplotka/bingo. DO NOT EDIT
+
+// bingo:description Checks forbidden imports
+
+go 1.14
+
+require github.com/fatih/faillint v1.5.0
+`)
+	arg, ok := mf.Command(DescriptionCommand)
+	testutil.Assert(t, ok)
+	testutil.Equals(t, "Checks forbidden imports", arg)
+
+	mf.SetCommand(DescriptionCommand, "Go imports linter")
+	expect(`module _ // Auto generated by https://github.com/bwplotka/bingo. DO NOT EDIT
+
+// bingo:description Go imports linter
+
+go 1.14
+
+require github.com/fatih/faillint v1.5.0
+`)
+
+	mf.SetCommand(DescriptionCommand, "")
+	expect(`module _ // Auto generated by https://github.com/bwplotka/bingo. DO NOT EDIT
+
+go 1.14
+
+require github.com/fatih/faillint v1.5.0
+`)
+	_, ok = mf.Command(DescriptionCommand)
+	testutil.Assert(t, !ok)
+}
+
+func TestModFile_RequireComments(t *testing.T) {
+	testFile := filepath.Join(t.TempDir(), "test.mod")
+	testutil.Ok(t, ioutil.WriteFile(testFile, []byte(`module _ // Auto generated by https://github.com/bwplotka/bingo. DO NOT EDIT
+
+go 1.14
+
+// Do not bump, see https://github.com/golangci/golangci-lint/issues/1.
+require github.com/golangci/golangci-lint v1.59.0 // cmd/golangci-lint -ldflags=-X=main.url=https://example.com // pinned due to regression in v1.60
+`), os.ModePerm))
+
+	mf, err := OpenModFile(testFile)
+	testutil.Ok(t, err)
+	t.Cleanup(func() { _ = mf.Close() })
+
+	pkg := *mf.DirectPackage()
+	testutil.Equals(t, "cmd/golangci-lint", pkg.RelPath)
+	testutil.Equals(t, []string{"-ldflags=-X=main.url=https://example.com"}, pkg.BuildFlags)
+
+	pkg.Module.Version = "v1.59.1"
+	testutil.Ok(t, mf.SetDirectRequire(pkg))
+	testutil.Ok(t, mf.Flush())
+	expectContent(t, `module _ // Auto generated by https://github.com/bwplotka/bingo. DO NOT EDIT
+
+go 1.14
+
+// Do not bump, see https://github.com/golangci/golangci-lint/issues/1.
+require github.com/golangci/golangci-lint v1.59.1 // cmd/golangci-lint -ldflags=-X=main.url=https://example.com // pinned due to regression in v1.60
+`, testFile)
+
+	// Note is kept even without metadata.
+	pkg.RelPath, pkg.BuildFlags = "", nil
+	testutil.Ok(t, mf.SetDirectRequire(pkg))
+	testutil.Ok(t, mf.Flush())
+	expectContent(t, `module _ // Auto generated by https://github.com/bwplotka/bingo. DO NOT EDIT
+
+go 1.14
+
+// Do not bump, see https://github.com/golangci/golangci-lint/issues/1.
+require github.com/golangci/golangci-lint v1.59.1 // // pinned due to regression in v1.60
+`, testFile)
+	testutil.Equals(t, "", mf.DirectPackage().RelPath)
+}
+
+func TestModFile_SetExclude(t *testing.T) {
+	testFile := filepath.Join(t.TempDir(), "test.mod")
+	testutil.Ok(t, ioutil.WriteFile(testFile, []byte(`module _ // Auto generated by https://github.com/bwplotka/bingo. DO NOT EDIT
+
+go 1.14
+
+exclude github.com/old/broken v0.1.0
+
+require github.com/fatih/faillint v1.5.0
+`), os.ModePerm))
+
+	mf, err := OpenModFile(testFile)
+	testutil.Ok(t, err)
+	t.Cleanup(func() { _ = mf.Close() })
+
+	testutil.Ok(t, mf.SetExclude(
+		&modfile.Exclude{Mod: module.Version{Path: "github.com/broken/mod", Version: "v1.2.0"}},
+		&modfile.Exclude{Mod: module.Version{Path: "github.com/broken/mod", Version: "v1.2.1"}},
+	))
+	testutil.Ok(t, mf.Flush())
+	expectContent(t, `module _ // Auto generated by https://github.com/bwplotka/bingo. DO NOT EDIT
+
+go 1.14
+
+require github.com/fatih/faillint v1.5.0
+
+exclude (
+	github.com/broken/mod v1.2.0
+	github.com/broken/mod v1.2.1
+)
+`, testFile)
+}
+
+func TestModFile_LocalReplace(t *testing.T) {
+	testFile := filepath.Join(t.TempDir(), "test.mod")
+	testutil.Ok(t, ioutil.WriteFile(testFile, []byte(`module _ // Auto generated by https://github.com/bwplotka/bingo. DO NOT EDIT
+
+go 1.14
+
+replace github.com/org/tool => ../tool
+
+replace github.com/old/dep => github.com/old/dep v0.1.0
+
+require github.com/org/tool v0.0.0-00010101000000-000000000000
+`), os.ModePerm))
+
+	mf, err := OpenModFile(testFile)
+	testutil.Ok(t, err)
+	t.Cleanup(func() { _ = mf.Close() })
+
+	testutil.Equals(t, 1, len(mf.LocalReplaces()))
+	testutil.Equals(t, "../tool", mf.LocalReplaces()[0].New.Path)
+
+	// Fetched replaces do not override local ones and local fetched ones are skipped.
+	testutil.Ok(t, mf.SetReplace(
+		&modfile.Replace{Old: module.Version{Path: "github.com/org/tool"}, New: module.Version{Path: "github.com/fork/tool", Version: "v1.0.0"}},
+		&modfile.Replace{Old: module.Version{Path: "github.com/org/api"}, New: module.Version{Path: "./api"}},
+		&modfile.Replace{Old: module.Version{Path: "github.com/new/dep"}, New: module.Version{Path: "github.com/new/dep", Version: "v0.2.0"}},
+	))
+	testutil.Ok(t, mf.SetLocalReplace("github.com/org/lib", "", "../lib"))
+	testutil.NotOk(t, mf.SetLocalReplace("github.com/org/lib", "", "lib"))
+	testutil.Ok(t, mf.Flush())
+	expectContent(t, `module _ // Auto generated by https://github.com/bwplotka/bingo. DO NOT EDIT
+
+go 1.14
+
+replace github.com/org/tool => ../tool
+
+require github.com/org/tool v0.0.0-00010101000000-000000000000
+
+replace github.com/new/dep => github.com/new/dep v0.2.0
+
+replace github.com/org/lib => ../lib
+`, testFile)
+}
+
+func TestModFile_SetConstraints(t *testing.T) {
+	modDir := t.TempDir()
+	c, err := ReadConstraints(modDir)
+	testutil.Ok(t, err)
+	testutil.Assert(t, c == nil, "no constraints expected without constraints file")
+
+	testutil.Ok(t, ioutil.WriteFile(filepath.Join(modDir, ConstraintsModFileName), []byte(`module _
+
+require (
+	golang.org/x/net v0.17.0
+	github.com/org/tool v1.0.0
+)
+
+replace github.com/old/dep v0.1.0 => github.com/fork/dep v0.1.1
+
+exclude github.com/bad/dep v0.3.0
+`), os.ModePerm))
+	c, err = ReadConstraints(modDir)
+	testutil.Ok(t, err)
+
+	testFile := filepath.Join(modDir, "tool.mod")
+	testutil.Ok(t, ioutil.WriteFile(testFile, []byte(`module _ // Auto generated by https://github.com/bwplotka/bingo. DO NOT EDIT
+
+go 1.14
+
+replace golang.org/x/net => golang.org/x/net v0.1.0
+
+replace github.com/old/dep => ../dep
+
+require github.com/org/tool v0.5.0
+`), os.ModePerm))
+	mf, err := OpenModFile(testFile)
+	testutil.Ok(t, err)
+	t.Cleanup(func() { _ = mf.Close() })
+
+	// Constraints override fetched replaces, but not local ones nor the tool's own module. Applying again changes nothing.
+	testutil.Ok(t, mf.SetConstraints(c))
+	testutil.Ok(t, mf.SetConstraints(c))
+	testutil.Ok(t, mf.Flush())
+	expectContent(t, `module _ // Auto generated by https://github.com/bwplotka/bingo. DO NOT EDIT
+
+go 1.14
+
+replace github.com/old/dep => ../dep
+
+require github.com/org/tool v0.5.0
+
+replace golang.org/x/net => golang.org/x/net v0.17.0 // bingo:constraint
+
+exclude github.com/bad/dep v0.3.0 // bingo:constraint
+`, testFile)
+
+	testutil.Ok(t, mf.SetConstraints(nil))
+	testutil.Ok(t, mf.Flush())
+	expectContent(t, `module _ // Auto generated by https://github.com/bwplotka/bingo. DO NOT EDIT
+
+go 1.14
+
+replace github.com/old/dep => ../dep
+
+require github.com/org/tool v0.5.0
+`, testFile)
+
+	testutil.Equals(t, false, IsToolModFile(filepath.Join(modDir, ConstraintsModFileName)))
+	testutil.Equals(t, true, IsToolModFile(testFile))
+}
+
+func TestParseLocalReplaces(t *testing.T) {
+	replaces, err := ParseLocalReplaces("github.com/org/tool=../tool, github.com/org/lib=/src/lib")
+	testutil.Ok(t, err)
+	testutil.Equals(t, []*modfile.Replace{
+		{Old: module.Version{Path: "github.com/org/tool"}, New: module.Version{Path: "../tool"}},
+		{Old: module.Version{Path: "github.com/org/lib"}, New: module.Version{Path: "/src/lib"}},
+	}, replaces)
+
+	for _, invalid := range []string{"github.com/org/tool", "github.com/org/tool=tool", "=../tool", "github.com/org/tool=github.com/fork/tool"} {
+		_, err := ParseLocalReplaces(invalid)
+		testutil.NotOk(t, err, invalid)
+	}
+}
+
+func TestFormatModFile(t *testing.T) {
+	canonical := `module _ // Auto generated by https://github.com/bwplotka/bingo. DO NOT EDIT
+
+go 1.14
+
+require github.com/golangci/golangci-lint v1.26.0 // cmd/golangci-lint CGO_ENABLED=0 -tags=netgo -v
+`
+	b, changes, err := FormatModFile("golangci-lint.mod", []byte(canonical))
+	testutil.Ok(t, err)
+	testutil.Equals(t, canonical, string(b))
+	testutil.Equals(t, 0, len(changes))
+
+	b, changes, err = FormatModFile("golangci-lint.mod", []byte(`module _
+
+go   1.14
+require   github.com/golangci/golangci-lint v1.26.0 //   cmd/golangci-lint   CGO_ENABLED=0  -tags=netgo   -v
+require github.com/golangci/golangci-lint v1.27.0
+`))
+	testutil.Ok(t, err)
+	testutil.Equals(t, canonical, string(b))
+	testutil.Equals(t, []string{"added bingo meta comment to module statement", "dropped require github.com/golangci/golangci-lint@v1.27.0"}, changes)
+
+	_, _, err = FormatModFile("golangci-lint.mod", []byte("require ("))
+	testutil.NotOk(t, err)
+}
+
+func TestBinaryName(t *testing.T) {
+	t.Setenv("GOEXE", "")
+	testutil.Equals(t, "faillint-v1.5.0", BinaryName("faillint", "v1.5.0"))
+
+	t.Setenv("GOEXE", ".exe")
+	testutil.Equals(t, "faillint-v1.5.0.exe", BinaryName("faillint", "v1.5.0"))
+
+	testutil.Ok(t, os.Unsetenv("GOEXE"))
+	t.Setenv("GOOS", "windows")
+	testutil.Equals(t, ".exe", GOEXE())
+	t.Setenv("GOOS", "linux")
+	testutil.Equals(t, "", GOEXE())
+}
@@ -0,0 +1,190 @@
+// Copyright (c) Bartłomiej Płotka @bwplotka
+// Licensed under the Apache License 2.0.
+
+package bingo
+
+import (
+	"bufio"
+	"io"
+	"strings"
+
+	"github.com/pkg/errors"
+	"golang.org/x/mod/module"
+)
+
+// PolicyExceptionCommand documents modules the tool is allowed to depend on despite module policy, with mandatory reason,
+// e.g `// bingo:policy_exception github.com/hashicorp/hcl@v1.0.0,golang.org/x/exp reviewed in SEC-123`.
+const PolicyExceptionCommand = "bingo:policy_exception"
+
+// ParseYAMLLists parses the subset of YAML with given keys mapped to lists of values, either inline ('deny: [a, b]') or one
+// '- value' per line, and '#' comments. Values can be quoted.
+func ParseYAMLLists(r io.Reader, keys ...string) (map[string][]string, error) {
+	var (
+		ret = map[string][]string{}
+		key string
+	)
+	known := func(line int) error {
+		for _, k := range keys {
+			if k == key {
+				return nil
+			}
+		}
+		return errors.Errorf("line %d: unknown key %q; expected one of %s", line, key, strings.Join(keys, ", "))
+	}
+
+	s := bufio.NewScanner(r)
+	for line := 1; s.Scan(); line++ {
+		l := s.Text()
+		if i := strings.Index(l, "#"); i >= 0 {
+			l = l[:i]
+		}
+		l = strings.TrimSpace(l)
+		if l == "" {
+			continue
+		}
+
+		if strings.HasPrefix(l, "-") {
+			if key == "" {
+				return nil, errors.Errorf("line %d: list item without key", line)
+			}
+			ret[key] = append(ret[key], strings.Trim(strings.TrimSpace(l[1:]), `"'`))
+			continue
+		}
+
+		i := strings.Index(l, ":")
+		if i <= 0 {
+			return nil, errors.Errorf("line %d: expected 'key:' or '- value', got %q", line, l)
+		}
+		key = strings.TrimSpace(l[:i])
+		if err := known(line); err != nil {
+			return nil, err
+		}
+		inline := strings.TrimSpace(l[i+1:])
+		if inline == "" {
+			continue
+		}
+		if !strings.HasPrefix(inline, "[") || !strings.HasSuffix(inline, "]") {
+			return nil, errors.Errorf("line %d: expected list, e.g '%s: [a, b]', got %q", line, key, inline)
+		}
+		for _, v := range strings.Split(inline[1:len(inline)-1], ",") {
+			if v = strings.Trim(strings.TrimSpace(v), `"'`); v != "" {
+				ret[key] = append(ret[key], v)
+			}
+		}
+	}
+	return ret, s.Err()
+}
+
+// ModulePolicy defines modules pinned tools may depend on, e.g to ban typosquats or known-bad releases org-wide.
+// Rules are module path patterns with optional exact version: '<path>' matches only given module, '<path>/...' given module
+// and all modules below it, e.g 'github.com/evil/...' or 'github.com/org/lib@v1.2.3'.
+type ModulePolicy struct {
+	// Allow lists allowed modules. If empty, all modules except denied ones are allowed.
+	Allow []string
+	// Deny lists disallowed modules.
+	Deny []string
+}
+
+// ParseModulePolicy parses module policy file with 'allow' and 'deny' lists (see ParseYAMLLists), e.g:
+//
+//	deny:
+//	  - github.com/sirupsen/logrus@v1.9.1 # Broken release.
+//	  - github.com/sirupsen/Logrus/...    # Typosquat.
+func ParseModulePolicy(r io.Reader) (ModulePolicy, error) {
+	lists, err := ParseYAMLLists(r, "allow", "deny")
+	if err != nil {
+		return ModulePolicy{}, err
+	}
+	p := ModulePolicy{Allow: lists["allow"], Deny: lists["deny"]}
+	for _, rule := range append(append([]string{}, p.Allow...), p.Deny...) {
+		if err := validateModuleRule(rule); err != nil {
+			return ModulePolicy{}, err
+		}
+	}
+	return p, nil
+}
+
+func validateModuleRule(rule string) error {
+	path, version, _ := strings.Cut(rule, "@")
+	if err := module.CheckImportPath(strings.TrimSuffix(path, "/...")); err != nil {
+		return errors.Wrapf(err, "invalid module rule %q", rule)
+	}
+	if version != "" && !strings.HasPrefix(version, "v") {
+		return errors.Errorf("invalid module rule %q; version has to start with 'v'", rule)
+	}
+	return nil
+}
+
+// matchModuleRule returns true if given module version matches given rule (see ModulePolicy).
+func matchModuleRule(rule string, m module.Version) bool {
+	path, version, _ := strings.Cut(rule, "@")
+	if version != "" && version != m.Version {
+		return false
+	}
+	if prefix := strings.TrimSuffix(path, "/..."); prefix != path {
+		return m.Path == prefix || strings.HasPrefix(m.Path, prefix+"/")
+	}
+	return m.Path == path
+}
+
+// PolicyViolation is a module disallowed by ModulePolicy.
+type PolicyViolation struct {
+	Module module.Version
+	// Rule is a deny rule module matched, empty if module is not allowed by any allow rule.
+	Rule string
+}
+
+func (v PolicyViolation) String() string {
+	if v.Rule == "" {
+		return v.Module.String() + " (not allowed)"
+	}
+	return v.Module.String() + " (denied by " + v.Rule + ")"
+}
+
+// Check returns modules from given ones violating the policy, except those matching given exceptions (rules in the same
+// format, e.g from PolicyExceptionCommand).
+func (p ModulePolicy) Check(mods []module.Version, exceptions []string) []PolicyViolation {
+	var ret []PolicyViolation
+ModLoop:
+	for _, m := range mods {
+		for _, e := range exceptions {
+			if matchModuleRule(e, m) {
+				continue ModLoop
+			}
+		}
+		for _, d := range p.Deny {
+			if matchModuleRule(d, m) {
+				ret = append(ret, PolicyViolation{Module: m, Rule: d})
+				continue ModLoop
+			}
+		}
+		if len(p.Allow) == 0 {
+			continue
+		}
+		for _, a := range p.Allow {
+			if matchModuleRule(a, m) {
+				continue ModLoop
+			}
+		}
+		ret = append(ret, PolicyViolation{Module: m})
+	}
+	return ret
+}
+
+// ParsePolicyExceptions parses argument of PolicyExceptionCommand: comma separated module rules followed by reason.
+func ParsePolicyExceptions(arg string) (rules []string, reason string, err error) {
+	list, reason, _ := strings.Cut(strings.TrimSpace(arg), " ")
+	if reason = strings.TrimSpace(reason); reason == "" {
+		return nil, "", errors.Errorf("policy exception %q has no reason; expected '<module>[@<version>][,...] <reason>'", arg)
+	}
+	for _, r := range strings.Split(list, ",") {
+		if r = strings.TrimSpace(r); r == "" {
+			continue
+		}
+		if err := validateModuleRule(r); err != nil {
+			return nil, "", err
+		}
+		rules = append(rules, r)
+	}
+	return rules, reason, nil
+}
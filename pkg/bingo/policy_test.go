@@ -0,0 +1,66 @@
+// Copyright (c) Bartłomiej Płotka @bwplotka
+// Licensed under the Apache License 2.0.
+
+package bingo
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/efficientgo/tools/core/pkg/testutil"
+	"golang.org/x/mod/module"
+)
+
+func TestParseModulePolicy(t *testing.T) {
+	p, err := ParseModulePolicy(strings.NewReader(`# Org-wide policy.
+deny:
+  - github.com/sirupsen/logrus@v1.9.1 # Broken release.
+  - "github.com/evil/..."
+allow: [github.com/..., golang.org/x/...]
+`))
+	testutil.Ok(t, err)
+	testutil.Equals(t, ModulePolicy{
+		Allow: []string{"github.com/...", "golang.org/x/..."},
+		Deny:  []string{"github.com/sirupsen/logrus@v1.9.1", "github.com/evil/..."},
+	}, p)
+
+	for _, malformed := range []string{"- github.com/a/b\n", "exceptions:\n  - github.com/a/b\n", "deny: github.com/a/b\n", "deny: [github.com/a/b@1.0.0]\n", "deny: [\"github.com/a b\"]\n"} {
+		_, err := ParseModulePolicy(strings.NewReader(malformed))
+		testutil.NotOk(t, err, malformed)
+	}
+}
+
+func TestModulePolicy_Check(t *testing.T) {
+	mods := []module.Version{
+		{Path: "github.com/fatih/faillint", Version: "v1.5.0"},
+		{Path: "github.com/sirupsen/logrus", Version: "v1.9.1"},
+		{Path: "github.com/evil", Version: "v0.1.0"},
+		{Path: "github.com/evil/lib", Version: "v0.2.0"},
+		{Path: "github.com/evilcorp/lib", Version: "v0.2.0"},
+		{Path: "gopkg.in/yaml.v2", Version: "v2.4.0"},
+	}
+	p := ModulePolicy{Deny: []string{"github.com/sirupsen/logrus@v1.9.1", "github.com/evil/..."}}
+	testutil.Equals(t, []PolicyViolation{
+		{Module: mods[1], Rule: "github.com/sirupsen/logrus@v1.9.1"},
+		{Module: mods[2], Rule: "github.com/evil/..."},
+		{Module: mods[3], Rule: "github.com/evil/..."},
+	}, p.Check(mods, nil))
+	testutil.Equals(t, []PolicyViolation{{Module: mods[2], Rule: "github.com/evil/..."}}, p.Check(mods, []string{"github.com/sirupsen/logrus", "github.com/evil/lib@v0.2.0"}))
+	testutil.Equals(t, 0, len(p.Check([]module.Version{{Path: "github.com/sirupsen/logrus", Version: "v1.9.3"}}, nil)))
+
+	p.Allow = []string{"github.com/..."}
+	testutil.Equals(t, []PolicyViolation{{Module: mods[5]}}, p.Check(mods, []string{"github.com/sirupsen/logrus", "github.com/evil/..."}))
+	testutil.Equals(t, "gopkg.in/yaml.v2@v2.4.0 (not allowed)", PolicyViolation{Module: mods[5]}.String())
+}
+
+func TestParsePolicyExceptions(t *testing.T) {
+	rules, reason, err := ParsePolicyExceptions("github.com/evil/lib@v0.2.0,golang.org/x/exp/...  reviewed in SEC-123")
+	testutil.Ok(t, err)
+	testutil.Equals(t, []string{"github.com/evil/lib@v0.2.0", "golang.org/x/exp/..."}, rules)
+	testutil.Equals(t, "reviewed in SEC-123", reason)
+
+	_, _, err = ParsePolicyExceptions("github.com/evil/lib@v0.2.0")
+	testutil.NotOk(t, err)
+	_, _, err = ParsePolicyExceptions("github.com/evil/lib@0.2.0 typo")
+	testutil.NotOk(t, err)
+}
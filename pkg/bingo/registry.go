@@ -0,0 +1,179 @@
+// Copyright (c) Bartłomiej Płotka @bwplotka
+// Licensed under the Apache License 2.0.
+
+package bingo
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"golang.org/x/mod/semver"
+)
+
+// RegistryEntry is a tool approved by organization registry.
+type RegistryEntry struct {
+	// Path is a package path of the tool, or module path approving all tools in the module.
+	Path string
+	// Versions is a comma separated list of comparisons versions of the tool have to satisfy, e.g ">= 1.55, < 2" (see
+	// parseVersionConstraint). Empty or "*" allows any version.
+	Versions string
+
+	constraint []versionComparison
+}
+
+// versionComparison compares version with given semver version using given operator, e.g ">=" and "v1.55".
+type versionComparison struct {
+	op, version string
+}
+
+// parseVersionConstraint parses comma separated comparisons in '<operator> <version>' format, where operator is one of
+// '=', '!=', '>', '>=', '<', '<=' ('=' if omitted) and version is semver with optional 'v' prefix, minor and patch, e.g
+// ">= 1.55, < 2" or "v1.5.0".
+func parseVersionConstraint(s string) ([]versionComparison, error) {
+	var ret []versionComparison
+	for _, c := range strings.Split(s, ",") {
+		c = strings.TrimSpace(c)
+		op := strings.TrimRight(c[:len(c)-len(strings.TrimLeft(c, "=!<>"))], " ")
+		switch op {
+		case "":
+			op = "="
+		case "=", "!=", ">", ">=", "<", "<=":
+		default:
+			return nil, errors.Errorf("unknown operator %q in %q", op, c)
+		}
+		v := strings.TrimSpace(strings.TrimLeft(c, "=!<>"))
+		if !strings.HasPrefix(v, "v") {
+			v = "v" + v
+		}
+		if !semver.IsValid(v) {
+			return nil, errors.Errorf("invalid version in %q", c)
+		}
+		ret = append(ret, versionComparison{op: op, version: v})
+	}
+	return ret, nil
+}
+
+// Allows returns true if given version satisfies the entry's version constraint.
+func (e RegistryEntry) Allows(version string) bool {
+	if len(e.constraint) > 0 && !semver.IsValid(version) {
+		return false
+	}
+	for _, c := range e.constraint {
+		cmp := semver.Compare(version, c.version)
+		ok := false
+		switch c.op {
+		case "=":
+			ok = cmp == 0
+		case "!=":
+			ok = cmp != 0
+		case ">":
+			ok = cmp > 0
+		case ">=":
+			ok = cmp >= 0
+		case "<":
+			ok = cmp < 0
+		case "<=":
+			ok = cmp <= 0
+		}
+		if !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// Registry is an organization registry of approved tools and version ranges they can be pinned in.
+type Registry struct {
+	entries []RegistryEntry
+}
+
+// ParseRegistry parses registry content. It's either JSON object or the flat subset of YAML ('<path>: <constraint>' per
+// line, '#' comments), mapping package or module paths of approved tools to version constraints, e.g:
+//
+//	github.com/golangci/golangci-lint/cmd/golangci-lint: ">= 1.55, < 2"
+//	golang.org/x/tools: "*"
+func ParseRegistry(b []byte) (*Registry, error) {
+	versions := map[string]string{}
+	if t := bytes.TrimSpace(b); bytes.HasPrefix(t, []byte("{")) {
+		if err := json.Unmarshal(t, &versions); err != nil {
+			return nil, errors.Wrap(err, "parse JSON")
+		}
+	} else {
+		s := bufio.NewScanner(bytes.NewReader(b))
+		for line := 1; s.Scan(); line++ {
+			l := strings.TrimSpace(s.Text())
+			if l == "" || strings.HasPrefix(l, "#") {
+				continue
+			}
+			// Paths have no colons, while constraints may contain them only quoted.
+			i := strings.Index(l, ":")
+			if i <= 0 {
+				return nil, errors.Errorf("line %d: expected '<path>: <versions>', got %q", line, l)
+			}
+			v := strings.TrimSpace(l[i+1:])
+			if strings.HasPrefix(v, `"`) {
+				end := strings.LastIndex(v, `"`)
+				unquoted, err := strconv.Unquote(v[:end+1])
+				if err != nil {
+					return nil, errors.Wrapf(err, "line %d: unquote %s", line, v)
+				}
+				v = unquoted
+			} else if c := strings.Index(v, "#"); c >= 0 {
+				v = strings.TrimSpace(v[:c])
+			}
+			versions[strings.Trim(strings.TrimSpace(l[:i]), `"`)] = v
+		}
+		if err := s.Err(); err != nil {
+			return nil, err
+		}
+	}
+
+	r := &Registry{}
+	for p, v := range versions {
+		e := RegistryEntry{Path: p, Versions: v}
+		if v != "" && v != "*" {
+			c, err := parseVersionConstraint(v)
+			if err != nil {
+				return nil, errors.Wrapf(err, "invalid versions %q of %v", v, p)
+			}
+			e.constraint = c
+		}
+		r.entries = append(r.entries, e)
+	}
+	sort.Slice(r.entries, func(i, j int) bool { return r.entries[i].Path < r.entries[j].Path })
+	return r, nil
+}
+
+// Lookup returns the most specific registry entry approving tool with given package path, from module with given path.
+func (r *Registry) Lookup(pkgPath, modPath string) (RegistryEntry, bool) {
+	var (
+		found RegistryEntry
+		ok    bool
+	)
+	for _, e := range r.entries {
+		if e.Path != pkgPath && e.Path != modPath && !strings.HasPrefix(pkgPath, e.Path+"/") {
+			continue
+		}
+		if !ok || len(e.Path) > len(found.Path) {
+			found, ok = e, true
+		}
+	}
+	return found, ok
+}
+
+// Check returns error if tool with given package path, from module with given path, is not approved in given version.
+func (r *Registry) Check(pkgPath, modPath, version string) error {
+	e, ok := r.Lookup(pkgPath, modPath)
+	if !ok {
+		return errors.Errorf("%s is not approved by registry", pkgPath)
+	}
+	if !e.Allows(version) {
+		return errors.Errorf("%s@%s is not approved by registry; allowed versions: %s", pkgPath, version, e.Versions)
+	}
+	return nil
+}
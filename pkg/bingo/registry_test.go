@@ -0,0 +1,53 @@
+// Copyright (c) Bartłomiej Płotka @bwplotka
+// Licensed under the Apache License 2.0.
+
+package bingo
+
+import (
+	"testing"
+
+	"github.com/efficientgo/tools/core/pkg/testutil"
+)
+
+func TestParseRegistry(t *testing.T) {
+	for _, tcase := range []struct {
+		name    string
+		content string
+	}{
+		{
+			name: "yaml",
+			content: `# Approved tools.
+github.com/golangci/golangci-lint/cmd/golangci-lint: ">= 1.55, < 2" # Security reviewed.
+golang.org/x/tools: "*"
+github.com/golangci/golangci-lint: >= 1.54, < 1.55
+`,
+		},
+		{
+			name: "json",
+			content: `{"github.com/golangci/golangci-lint/cmd/golangci-lint": ">= 1.55, < 2", "golang.org/x/tools": "*",
+"github.com/golangci/golangci-lint": "1.54.1"}`,
+		},
+	} {
+		t.Run(tcase.name, func(t *testing.T) {
+			r, err := ParseRegistry([]byte(tcase.content))
+			testutil.Ok(t, err)
+
+			// The most specific entry wins.
+			testutil.Ok(t, r.Check("github.com/golangci/golangci-lint/cmd/golangci-lint", "github.com/golangci/golangci-lint", "v1.55.2"))
+			testutil.NotOk(t, r.Check("github.com/golangci/golangci-lint/cmd/golangci-lint", "github.com/golangci/golangci-lint", "v1.54.1"))
+			testutil.NotOk(t, r.Check("github.com/golangci/golangci-lint/cmd/golangci-lint", "github.com/golangci/golangci-lint", "v2.0.0"))
+			testutil.Ok(t, r.Check("github.com/golangci/golangci-lint/cmd/other", "github.com/golangci/golangci-lint", "v1.54.1"))
+			testutil.Ok(t, r.Check("golang.org/x/tools/cmd/goimports", "golang.org/x/tools", "v0.0.0-20210112230658-8b4aab62c064"))
+			testutil.NotOk(t, r.Check("github.com/fatih/faillint", "github.com/fatih/faillint", "v1.5.0"))
+
+			e, ok := r.Lookup("github.com/golangci/golangci-lint/cmd/golangci-lint", "github.com/golangci/golangci-lint")
+			testutil.Assert(t, ok)
+			testutil.Equals(t, ">= 1.55, < 2", e.Versions)
+		})
+	}
+
+	for _, malformed := range []string{"github.com/fatih/faillint\n", "github.com/fatih/faillint: \">= x\"\n", "github.com/fatih/faillint: ~1.5\n", "{\"github.com/fatih/faillint\": 1}"} {
+		_, err := ParseRegistry([]byte(malformed))
+		testutil.NotOk(t, err, malformed)
+	}
+}
@@ -0,0 +1,92 @@
+// Copyright (c) Bartłomiej Płotka @bwplotka
+// Licensed under the Apache License 2.0.
+
+package bingo
+
+import (
+	"bufio"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/efficientgo/tools/core/pkg/errcapture"
+	"github.com/pkg/errors"
+)
+
+// StatsDir is a directory in mod directory where stats of tool binaries built on this machine are recorded. It's ignored
+// by git, as builds differ between machines.
+const StatsDir = "stats"
+
+// BuildStats describes the last build of tool binary on this machine.
+type BuildStats struct {
+	// Duration is how long go build took.
+	Duration time.Duration `json:"duration"`
+	// Dependencies is a number of modules, other than tool's module, the binary was built from.
+	Dependencies int       `json:"dependencies"`
+	BuiltAt      time.Time `json:"builtAt"`
+}
+
+func buildStatsFile(modDir, name, version string) string {
+	return filepath.Join(modDir, StatsDir, name+"-"+version+".json")
+}
+
+// WriteBuildStats records stats of the last build of given tool version.
+func WriteBuildStats(modDir, name, version string, s BuildStats) error {
+	if err := os.MkdirAll(filepath.Join(modDir, StatsDir), os.ModePerm); err != nil {
+		return errors.Wrap(err, "create stats dir")
+	}
+	b, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(buildStatsFile(modDir, name, version), b, os.ModePerm)
+}
+
+// ReadBuildStats returns stats of the last build of given tool version. It returns nil if the build was not recorded,
+// e.g binary was built by older bingo version or linked from store.
+func ReadBuildStats(modDir, name, version string) (*BuildStats, error) {
+	b, err := ioutil.ReadFile(buildStatsFile(modDir, name, version))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	s := &BuildStats{}
+	if err := json.Unmarshal(b, s); err != nil {
+		return nil, errors.Wrapf(err, "parse build stats of %s-%s", name, version)
+	}
+	return s, nil
+}
+
+// CountSumModules returns number of modules in given go.sum file with content hash (so needed for build, not only for
+// module graph), other than given module path.
+func CountSumModules(sumFile, exclude string) (_ int, err error) {
+	f, err := os.Open(sumFile)
+	if err != nil {
+		return 0, err
+	}
+	defer errcapture.Do(&err, f.Close, "close")
+
+	mods := map[string]struct{}{}
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		fields := strings.Fields(s.Text())
+		if len(fields) != 3 || strings.HasSuffix(fields[1], "/go.mod") || fields[0] == exclude {
+			continue
+		}
+		mods[fields[0]] = struct{}{}
+	}
+	return len(mods), s.Err()
+}
+
+// RenameBuildStats moves recorded stats of given tool version to new tool name, if there are any.
+func RenameBuildStats(modDir, name, newName, version string) error {
+	if err := os.Rename(buildStatsFile(modDir, name, version), buildStatsFile(modDir, newName, version)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+// Copyright (c) Bartłomiej Płotka @bwplotka
+// Licensed under the Apache License 2.0.
+
+package bingo
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/efficientgo/tools/core/pkg/testutil"
+)
+
+func TestBuildStats(t *testing.T) {
+	modDir := t.TempDir()
+	s, err := ReadBuildStats(modDir, "buf", "v0.2.0")
+	testutil.Ok(t, err)
+	testutil.Assert(t, s == nil, "not recorded build expected")
+
+	exp := BuildStats{Duration: 2 * time.Second, Dependencies: 3, BuiltAt: time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC)}
+	testutil.Ok(t, WriteBuildStats(modDir, "buf", "v0.2.0", exp))
+	s, err = ReadBuildStats(modDir, "buf", "v0.2.0")
+	testutil.Ok(t, err)
+	testutil.Equals(t, exp, *s)
+
+	sum := filepath.Join(modDir, "buf.sum")
+	testutil.Ok(t, ioutil.WriteFile(sum, []byte(`github.com/bufbuild/buf v0.2.0 h1:buf=
+github.com/bufbuild/buf v0.2.0/go.mod h1:abc=
+github.com/pkg/errors v0.9.1 h1:abc=
+github.com/pkg/errors v0.9.1/go.mod h1:abc=
+golang.org/x/mod v0.3.0/go.mod h1:abc=
+golang.org/x/sys v0.1.0 h1:abc=
+golang.org/x/sys v0.2.0 h1:abc=
+`), os.ModePerm))
+	n, err := CountSumModules(sum, "github.com/bufbuild/buf")
+	testutil.Ok(t, err)
+	testutil.Equals(t, 2, n)
+
+	h, err := ModuleSum(sum, "github.com/bufbuild/buf", "v0.2.0")
+	testutil.Ok(t, err)
+	testutil.Equals(t, "h1:buf=", h)
+	h, err = ModuleSum(sum, "golang.org/x/mod", "v0.3.0")
+	testutil.Ok(t, err)
+	testutil.Equals(t, "", h)
+}
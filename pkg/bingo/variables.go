@@ -3,16 +3,42 @@
 
 package bingo
 
+const (
+	readmeFile      = "README.md"
+	goVariablesFile = "variables.go"
+)
+
 var (
-	templatesByFileExt = map[string]string{
+	// templatesByFile are built-in templates for generated helper files, keyed by generated file name.
+	templatesByFile = map[string]string{
 		// TODO(bwplotka): We might want to play with better escaping to allow spaces in dir names.
 		// TODO(bwplotka): We get first binary as an example. It does not work if first one is array.
-		"mk": `# Auto generated binary variables helper managed by https://github.com/bwplotka/bingo {{ .Version }}. DO NOT EDIT.
+		"Variables.mk": `# Auto generated binary variables helper managed by https://github.com/bwplotka/bingo {{ .Version }}. DO NOT EDIT.
 # All tools are designed to be build inside $GOBIN.
 BINGO_DIR := $(dir $(lastword $(MAKEFILE_LIST)))
 GOPATH ?= $(shell go env GOPATH)
 GOBIN  ?= $(firstword $(subst :, ,${GOPATH}))/bin
+GOEXE  ?= $(shell go env GOEXE)
 GO     ?= $(shell which go)
+{{- if eq .BinPathMode "relocatable" }}
+# BINGO_BIN is a directory with installed tools. Override it if tools were (or should be) installed in a different place.
+BINGO_BIN ?= $(GOBIN)
+{{- else if eq .BinPathMode "local" }}
+# BINGO_BIN is a project-local directory with installed tools. Override it if tools were (or should be) installed in a different place.
+BINGO_BIN ?= $(abspath $(BINGO_DIR))/bin
+{{- end }}
+# Stamp files mark which content of tool's mod file the binary was built from. Tool is rebuilt only if the content changes.
+BINGO_STAMP_DIR ?= {{ .MakeBinDir }}/.bingo-stamps
+{{- if not .BingoPinned }}
+
+# BINGO variable points to pinned bingo version or, if bingo is not pinned, to bingo version that generated this file (the
+# latest one for development builds). It is installed (requires Go 1.21+) if missing, so you can use e.g "$(BINGO) get"
+# in your Makefile even if bingo is not installed.
+BINGO := {{ .MakeBinDir }}/bingo-{{ .BingoVersion }}$(GOEXE)
+$(BINGO):
+	@echo "(re)installing $(BINGO)"
+	@tmp=$$(mktemp -d) && GOBIN=$$tmp $(GO) install {{ .BingoPackage }}@{{ .BingoVersion }} && mv $$tmp/bingo$(GOEXE) $(BINGO) && rm -rf $$tmp
+{{- end }}
 
 # Below generated variables ensure that every time a tool under each variable is invoked, the correct version
 # will be used; reinstalling only if needed.
@@ -27,16 +53,26 @@ GO     ?= $(shell which go)
 #	@$({{ with (index .MainPackages 0) }}{{ .EnvVarName }}{{ end }}) <flags/args..>
 #
 {{- range $p := .MainPackages }}
-{{ $p.EnvVarName }} :={{- range $p.Versions }} $(GOBIN)/{{ $p.Name }}-{{ .Version }}{{- end }}
-$({{ $p.EnvVarName }}):{{- range $p.Versions }} $(BINGO_DIR)/{{ .ModFile }}{{- end }}
+{{ $p.EnvVarName }} :={{- range $p.Versions }} {{ $.MakeBinDirOf $p }}/{{ $p.Name }}-{{ .Version }}{{ if $p.Wasm }}.wasm{{ else }}$(GOEXE){{ end }}{{- end }}
+$({{ $p.EnvVarName }}):{{- range $p.Versions }} $(BINGO_STAMP_DIR)/{{ $p.Name }}-{{ .Version }}.{{ .ModFileHash }}{{- end }}
 	@# Install binary/ries using Go 1.14+ build command. This is using bwplotka/bingo-controlled, separate go module with pinned dependencies.
+{{- if $p.BinDir }}
+	@mkdir -p {{ $.MakeBinDirOf $p }}
+{{- end }}
+{{- range $p.Versions }}
+	@echo "(re)installing {{ $.MakeBinDirOf $p }}/{{ $p.Name }}-{{ .Version }}{{ if $p.Wasm }}.wasm{{ else }}$(GOEXE){{ end }}"
+	@cd $(BINGO_DIR) && {{ range $p.BuildEnvVars }}{{ . }} {{ end }}{{ if $p.GoFlags }}GOFLAGS='{{ $p.GoFlags }}' {{ end }}$(GO) build {{ range $p.BuildFlags }}{{ . }} {{ end }}-mod=mod -modfile={{ .ModFile }} -o={{ $.MakeBinDirOf $p }}/{{ $p.Name }}-{{ .Version }}{{ if $p.Wasm }}.wasm{{ else }}$(GOEXE){{ end }} "{{ $p.PackagePath }}"
+{{- end }}
 {{- range $p.Versions }}
-	@echo "(re)installing $(GOBIN)/{{ $p.Name }}-{{ .Version }}"
-	@cd $(BINGO_DIR) && {{ range $p.BuildEnvVars }}{{ . }} {{ end }}$(GO) build {{ range $p.BuildFlags }}{{ . }} {{ end }}-mod=mod -modfile={{ .ModFile }} -o=$(GOBIN)/{{ $p.Name }}-{{ .Version }} "{{ $p.PackagePath }}"
+$(BINGO_STAMP_DIR)/{{ $p.Name }}-{{ .Version }}.{{ .ModFileHash }}:
+	@mkdir -p $(BINGO_STAMP_DIR) && rm -f $(BINGO_STAMP_DIR)/{{ $p.Name }}-{{ .Version }}.* && touch $@
+{{- end }}
+{{- range $p.Aliases }}
+{{ .EnvVarName }} := $({{ $p.EnvVarName }})
 {{- end }}
 {{ end}}
 `,
-		"env": `# Auto generated binary variables helper managed by https://github.com/bwplotka/bingo {{ .Version }}. DO NOT EDIT.
+		"variables.env": `# Auto generated binary variables helper managed by https://github.com/bwplotka/bingo {{ .Version }}. DO NOT EDIT.
 # All tools are designed to be build inside $GOBIN.
 # Those variables will work only until 'bingo get' was invoked, or if tools were installed via Makefile's Variables.mk.
 GOBIN=${GOBIN:=$(go env GOBIN)}
@@ -44,10 +80,95 @@ GOBIN=${GOBIN:=$(go env GOBIN)}
 if [ -z "$GOBIN" ]; then
 	GOBIN="$(go env GOPATH)/bin"
 fi
+GOEXE=${GOEXE:=$(go env GOEXE)}
+{{- if eq .BinPathMode "relocatable" }}
+
+# BINGO_BIN is a directory with installed tools. Override it if tools were installed in a different place.
+BINGO_BIN=${BINGO_BIN:=${GOBIN}}
+{{- else if eq .BinPathMode "local" }}
+
+# BINGO_BIN is a project-local directory with installed tools. Source this file from the project root or override it.
+BINGO_BIN=${BINGO_BIN:={{ .EnvLocalBinDir }}}
+{{- end }}
 
 {{range $p := .MainPackages }}
-{{ $p.EnvVarName }}="{{- range $i, $v := $p.Versions }}{{- if ne $i 0}} {{ end }}${GOBIN}/{{ $p.Name }}-{{ $v.Version }}{{- end }}"
+{{ $p.EnvVarName }}="{{- range $i, $v := $p.Versions }}{{- if ne $i 0}} {{ end }}{{ $.EnvBinDirOf $p }}/{{ $p.Name }}-{{ $v.Version }}{{ if $p.Wasm }}.wasm{{ else }}${GOEXE}{{ end }}{{- end }}"
+{{- range $p.Aliases }}
+{{ .EnvVarName }}="${ {{- $p.EnvVarName -}} }"
+{{- end }}
 {{ end}}
+`,
+		"variables.go": `// Code generated by https://github.com/bwplotka/bingo {{ .Version }}. DO NOT EDIT.
+{{- if .GoBuildConstraint }}
+
+//go:build {{ .GoBuildConstraint }}
+{{- end }}
+
+package {{ .GoPackage }}
+
+import (
+	"os"
+	"runtime"
+)
+
+// All tools are designed to be build inside $GOBIN. Each tool has constants with its binary name (or names for tools pinned
+// in many versions), package path, module path, version(s) and bingo mod file name(s) relative to the bingo mod directory.
+// Binary names do not include executable suffix, as this file is shared by all platforms; add GOEXE when running them.
+// Names of WebAssembly tools (built with GOARCH=wasm) include .wasm suffix and do not need GOEXE.
+
+// GOEXE is suffix of executable names on the platform the code runs on (".exe" on Windows), unless overridden by GOEXE
+// environment variable, the same way bingo names installed binaries.
+var GOEXE = func() string {
+	if goexe, ok := os.LookupEnv("GOEXE"); ok {
+		return goexe
+	}
+	if runtime.GOOS == "windows" {
+		return ".exe"
+	}
+	return ""
+}()
+{{- range $p := .MainPackages }}{{ $ext := "" }}{{ if $p.Wasm }}{{ $ext = ".wasm" }}{{ end }}
+{{- if eq (len $p.Versions) 1 }}{{ $v := index $p.Versions 0 }}
+
+// {{ $p.Name }} tool.
+const (
+	{{ $p.EnvVarName }}          = {{ printf "%q" (print $p.Name "-" $v.Version $ext) }}
+	{{ $p.EnvVarName }}_PACKAGE  = {{ printf "%q" $p.PackagePath }}
+	{{ $p.EnvVarName }}_MODULE   = {{ printf "%q" $p.ModPath }}
+	{{ $p.EnvVarName }}_VERSION  = {{ printf "%q" $v.Version }}
+	{{ $p.EnvVarName }}_MOD_FILE = {{ printf "%q" $v.ModFile }}
+)
+{{- else }}
+
+// {{ $p.Name }} tool pinned in many versions.
+const (
+	{{ $p.EnvVarName }}_PACKAGE = {{ printf "%q" $p.PackagePath }}
+	{{ $p.EnvVarName }}_MODULE  = {{ printf "%q" $p.ModPath }}
+)
+
+var (
+	{{ $p.EnvVarName }}           = []string{ {{- range $i, $v := $p.Versions }}{{- if ne $i 0}}, {{ end }}{{ printf "%q" (print $p.Name "-" $v.Version $ext) }}{{- end }}}
+	{{ $p.EnvVarName }}_VERSIONS  = []string{ {{- range $i, $v := $p.Versions }}{{- if ne $i 0}}, {{ end }}{{ printf "%q" $v.Version }}{{- end }}}
+	{{ $p.EnvVarName }}_MOD_FILES = []string{ {{- range $i, $v := $p.Versions }}{{- if ne $i 0}}, {{ end }}{{ printf "%q" $v.ModFile }}{{- end }}}
+)
+{{- end }}
+{{- end }}
 `,
 	}
+
+	readmeTemplate = `# Project Development Dependencies.
+
+This is directory which stores Go modules with pinned buildable package that is used within this repository, managed by https://github.com/bwplotka/bingo.
+
+* Run ` + "`" + "bingo get" + "`" + ` to install all tools having each own module file in this directory.
+* Run ` + "`" + "bingo get <tool>" + "`" + ` to install <tool> that have own module file in this directory.
+* For Makefile: Make sure to put ` + "`" + "include {{ .RelModDir }}/Variables.mk" + "`" + ` in your Makefile, then use $(<upper case tool name>) variable where <tool> is the {{ .RelModDir }}/<tool>.mod.
+* For shell: Run ` + "`" + "source {{ .RelModDir }}/variables.env" + "`" + ` to source all environment variable for each tool.
+* For go: Import ` + "`" + "{{ .RelModDir }}/variables.go" + "`" + ` to for variable names.
+* See https://github.com/bwplotka/bingo or -h on how to add, remove or change binaries dependencies.
+
+## Requirements
+
+* Go 1.14+
+`
 )
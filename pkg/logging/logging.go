@@ -0,0 +1,56 @@
+// Copyright (c) Bartłomiej Płotka @bwplotka
+// Licensed under the Apache License 2.0.
+
+// Package logging defines minimal logger accepted by bingo packages, so embedders can route bingo logs into their own
+// logging stack. *slog.Logger implements it.
+package logging
+
+import "log/slog"
+
+// Logger logs messages with alternating key-value pairs, the same way as *slog.Logger does.
+type Logger interface {
+	Debug(msg string, args ...any)
+	Info(msg string, args ...any)
+	Warn(msg string, args ...any)
+	Error(msg string, args ...any)
+}
+
+// Discard is a Logger that logs nothing.
+var Discard Logger = discard{}
+
+type discard struct{}
+
+func (discard) Debug(string, ...any) {}
+func (discard) Info(string, ...any)  {}
+func (discard) Warn(string, ...any)  {}
+func (discard) Error(string, ...any) {}
+
+// OrDiscard returns given logger, or Discard if it's nil.
+func OrDiscard(l Logger) Logger {
+	if l == nil {
+		return Discard
+	}
+	return l
+}
+
+// With returns Logger that adds given key-value pairs to every message.
+func With(l Logger, args ...any) Logger {
+	if sl, ok := l.(*slog.Logger); ok {
+		return sl.With(args...)
+	}
+	return withLogger{l: l, args: args}
+}
+
+type withLogger struct {
+	l    Logger
+	args []any
+}
+
+func (w withLogger) with(args []any) []any {
+	return append(append(make([]any, 0, len(w.args)+len(args)), w.args...), args...)
+}
+
+func (w withLogger) Debug(msg string, args ...any) { w.l.Debug(msg, w.with(args)...) }
+func (w withLogger) Info(msg string, args ...any)  { w.l.Info(msg, w.with(args)...) }
+func (w withLogger) Warn(msg string, args ...any)  { w.l.Warn(msg, w.with(args)...) }
+func (w withLogger) Error(msg string, args ...any) { w.l.Error(msg, w.with(args)...) }
@@ -0,0 +1,53 @@
+// Copyright (c) Bartłomiej Płotka @bwplotka
+// Licensed under the Apache License 2.0.
+
+package logging
+
+import (
+	"bytes"
+	"fmt"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/efficientgo/tools/core/pkg/testutil"
+)
+
+type recordLogger struct {
+	lines []string
+}
+
+func (r *recordLogger) log(level, msg string, args []any) {
+	r.lines = append(r.lines, strings.TrimSpace(fmt.Sprintln(append([]any{level, msg}, args...)...)))
+}
+
+func (r *recordLogger) Debug(msg string, args ...any) { r.log("debug", msg, args) }
+func (r *recordLogger) Info(msg string, args ...any)  { r.log("info", msg, args) }
+func (r *recordLogger) Warn(msg string, args ...any)  { r.log("warn", msg, args) }
+func (r *recordLogger) Error(msg string, args ...any) { r.log("error", msg, args) }
+
+func TestWith(t *testing.T) {
+	t.Run("custom logger", func(t *testing.T) {
+		r := &recordLogger{}
+		l := With(With(r, "tool", "faillint"), "phase", "get")
+		l.Info("done", "took", 1)
+		l.Warn("slow")
+		testutil.Equals(t, []string{"info done tool faillint phase get took 1", "warn slow tool faillint phase get"}, r.lines)
+	})
+	t.Run("slog", func(t *testing.T) {
+		b := &bytes.Buffer{}
+		l := With(slog.New(slog.NewTextHandler(b, &slog.HandlerOptions{
+			ReplaceAttr: func(_ []string, a slog.Attr) slog.Attr {
+				if a.Key == slog.TimeKey {
+					return slog.Attr{}
+				}
+				return a
+			},
+		})), "tool", "faillint")
+		l.Info("done")
+		testutil.Equals(t, "level=INFO msg=done tool=faillint\n", b.String())
+	})
+	t.Run("discard", func(t *testing.T) {
+		OrDiscard(nil).Error("nothing")
+	})
+}
@@ -0,0 +1,165 @@
+// Copyright (c) Bartłomiej Płotka @bwplotka
+// Licensed under the Apache License 2.0.
+
+package runner
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+
+	"golang.org/x/mod/module"
+)
+
+// proxyRequestTimeout limits every request made when diagnosing GOPROXY chain.
+const proxyRequestTimeout = 10 * time.Second
+
+// ProxyEntry is an element of GOPROXY chain.
+type ProxyEntry struct {
+	// Proxy is proxy URL, "direct" or "off".
+	Proxy string
+	// FallbackOnError is true if the entry is followed by '|', so go falls back to the next one on any error. Otherwise
+	// (',') it falls back only on 404 and 410 responses.
+	FallbackOnError bool
+}
+
+// ParseGOPROXY parses GOPROXY value into chain of entries.
+func ParseGOPROXY(goproxy string) []ProxyEntry {
+	var (
+		ret   []ProxyEntry
+		start int
+	)
+	for i := 0; i <= len(goproxy); i++ {
+		if i < len(goproxy) && goproxy[i] != ',' && goproxy[i] != '|' {
+			continue
+		}
+		if p := strings.TrimSpace(goproxy[start:i]); p != "" {
+			ret = append(ret, ProxyEntry{Proxy: p, FallbackOnError: i < len(goproxy) && goproxy[i] == '|'})
+		}
+		start = i + 1
+	}
+	return ret
+}
+
+// ProxyAttempt is a result of querying one entry of GOPROXY chain for a module.
+type ProxyAttempt struct {
+	ProxyEntry
+	// Status is HTTP status (e.g "502 Bad Gateway") or error of the request. Empty if the entry was not tried.
+	Status string
+	// Found is true if proxy serves the module.
+	Found bool
+}
+
+// ProxyChain describes how go walks GOPROXY chain for a module.
+type ProxyChain struct {
+	Attempts []ProxyAttempt
+	// Direct is true if go fell back to fetching module directly from its version control repository.
+	Direct bool
+}
+
+func (c ProxyChain) String() string {
+	s := make([]string, 0, len(c.Attempts))
+	for _, a := range c.Attempts {
+		switch {
+		case a.Proxy == "direct" && c.Direct:
+			s = append(s, "direct (fallback occurred)")
+		case a.Status == "":
+			s = append(s, a.Proxy+" (not tried)")
+		default:
+			s = append(s, fmt.Sprintf("%s (%s)", a.Proxy, a.Status))
+		}
+	}
+	return strings.Join(s, " -> ")
+}
+
+// DiagnoseProxyChain queries each entry of given GOPROXY chain for module providing given package, the same way go does:
+// next entry is tried only if previous one did not find the module ('404 Not Found' or '410 Gone') or, for entries followed
+// by '|', failed in any way. As module path is not known, module paths from the longest one are tried.
+func DiagnoseProxyChain(ctx context.Context, client *http.Client, goproxy, pkgPath string) ProxyChain {
+	var c ProxyChain
+	for _, e := range ParseGOPROXY(goproxy) {
+		c.Attempts = append(c.Attempts, ProxyAttempt{ProxyEntry: e})
+	}
+	for i := range c.Attempts {
+		a := &c.Attempts[i]
+		switch a.Proxy {
+		case "direct":
+			a.Status, c.Direct = "fallback occurred", true
+			return c
+		case "off":
+			a.Status = "module fetching disabled"
+			return c
+		}
+
+		var notFound bool
+		a.Status, a.Found, notFound = queryProxy(ctx, client, a.Proxy, pkgPath)
+		if a.Found || (!notFound && !a.FallbackOnError) {
+			return c
+		}
+	}
+	return c
+}
+
+// queryProxy asks given proxy for versions of module paths providing given package, from the longest one. It returns status of
+// the first response that is not 404 or 410 (or the last one, if all are), true if proxy serves any of them and true if proxy
+// answered it does not have any of them.
+func queryProxy(ctx context.Context, client *http.Client, proxy, pkgPath string) (status string, found, notFound bool) {
+	for modPath := pkgPath; modPath != "." && modPath != "/"; modPath = path.Dir(modPath) {
+		escaped, err := module.EscapePath(modPath)
+		if err != nil {
+			return err.Error(), false, false
+		}
+		var code int
+		status, code, err = getStatus(ctx, client, strings.TrimSuffix(proxy, "/")+"/"+escaped+"/@v/list")
+		if err != nil {
+			return err.Error(), false, false
+		}
+		switch code {
+		case http.StatusOK:
+			return status + " for " + modPath, true, false
+		case http.StatusNotFound, http.StatusGone:
+		default:
+			return status, false, false
+		}
+	}
+	return status, false, true
+}
+
+func getStatus(ctx context.Context, client *http.Client, url string) (string, int, error) {
+	ctx, cancel := context.WithTimeout(ctx, proxyRequestTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return "", 0, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", 0, err
+	}
+	_ = resp.Body.Close()
+	return resp.Status, resp.StatusCode, nil
+}
+
+// MatchModulePatterns returns true if given module path matches any of given comma separated glob patterns of path
+// prefixes, e.g GOPRIVATE or GONOPROXY value.
+func MatchModulePatterns(patterns, modPath string) bool {
+	for _, p := range strings.Split(patterns, ",") {
+		p = strings.TrimSuffix(strings.TrimSpace(p), "/")
+		if p == "" {
+			continue
+		}
+		elems := strings.Split(modPath, "/")
+		n := strings.Count(p, "/") + 1
+		if len(elems) < n {
+			continue
+		}
+		if ok, _ := path.Match(p, strings.Join(elems[:n], "/")); ok {
+			return true
+		}
+	}
+	return false
+}
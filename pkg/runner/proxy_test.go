@@ -0,0 +1,73 @@
+// Copyright (c) Bartłomiej Płotka @bwplotka
+// Licensed under the Apache License 2.0.
+
+package runner
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/efficientgo/tools/core/pkg/testutil"
+)
+
+func TestParseGOPROXY(t *testing.T) {
+	testutil.Equals(t, []ProxyEntry{
+		{Proxy: "https://a.example.com", FallbackOnError: true},
+		{Proxy: "https://proxy.golang.org"},
+		{Proxy: "direct"},
+	}, ParseGOPROXY("https://a.example.com|https://proxy.golang.org,direct"))
+	testutil.Equals(t, []ProxyEntry{{Proxy: "off"}}, ParseGOPROXY("off"))
+}
+
+func TestDiagnoseProxyChain(t *testing.T) {
+	broken := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer broken.Close()
+	empty := httptest.NewServer(http.NotFoundHandler())
+	defer empty.Close()
+	serving := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/github.com/!org/tool/@v/list" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte("v1.0.0\n"))
+	}))
+	defer serving.Close()
+
+	ctx := context.Background()
+	for _, tcase := range []struct {
+		goproxy  string
+		expected string
+	}{
+		{
+			goproxy:  broken.URL + "," + serving.URL + ",direct",
+			expected: broken.URL + " (502 Bad Gateway) -> " + serving.URL + " (not tried) -> direct (not tried)",
+		},
+		{
+			goproxy:  broken.URL + "|" + serving.URL + ",direct",
+			expected: broken.URL + " (502 Bad Gateway) -> " + serving.URL + " (200 OK for github.com/Org/tool) -> direct (not tried)",
+		},
+		{
+			goproxy:  empty.URL + ",direct",
+			expected: empty.URL + " (404 Not Found) -> direct (fallback occurred)",
+		},
+		{
+			goproxy:  empty.URL + ",off",
+			expected: empty.URL + " (404 Not Found) -> off (module fetching disabled)",
+		},
+	} {
+		t.Run(tcase.goproxy, func(t *testing.T) {
+			testutil.Equals(t, tcase.expected, DiagnoseProxyChain(ctx, http.DefaultClient, tcase.goproxy, "github.com/Org/tool/cmd/tool").String())
+		})
+	}
+}
+
+func TestMatchModulePatterns(t *testing.T) {
+	testutil.Equals(t, true, MatchModulePatterns("*.corp.example.com,github.com/org", "github.com/org/tool/cmd/tool"))
+	testutil.Equals(t, true, MatchModulePatterns("*.corp.example.com", "git.corp.example.com/tool"))
+	testutil.Equals(t, false, MatchModulePatterns("github.com/org", "github.com/other/tool"))
+	testutil.Equals(t, false, MatchModulePatterns("", "github.com/org/tool"))
+}
@@ -8,15 +8,16 @@ import (
 	"context"
 	"fmt"
 	"io"
-	"log"
 	"os"
 	"os/exec"
-	"path/filepath"
 	"regexp"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/Masterminds/semver"
 	"github.com/bwplotka/bingo/pkg/envars"
+	"github.com/bwplotka/bingo/pkg/logging"
 	"github.com/bwplotka/bingo/pkg/version"
 	"github.com/pkg/errors"
 )
@@ -26,10 +27,58 @@ type Runner struct {
 	goCmd    string
 	insecure bool
 
-	verbose   bool
-	goVersion *semver.Version
+	verbose    bool
+	debug      bool
+	isolateEnv bool
+	goVersion  *semver.Version
 
-	logger *log.Logger
+	logger   logging.Logger
+	stream   *syncWriter
+	executor Executor
+	// enforcedEnv overrides ambient environment variables of all commands.
+	enforcedEnv envars.EnvSlice
+	// commands, if set, gets every executed command line (see PrintCommands).
+	commands *syncWriter
+
+	goFlagsWarning sync.Once
+}
+
+// Executor executes commands for Runner. Inject a fake one (e.g. ExecutorFunc) with NewRunnerWithExecutor to test code
+// running go commands without Go toolchain.
+type Executor interface {
+	// Exec runs given command with arguments in dir (current directory if empty) and full environment, writing both
+	// stdout and stderr to output. Runner reports errors other than *exec.ExitError with the full command line.
+	Exec(ctx context.Context, output io.Writer, env []string, dir string, command string, args ...string) error
+}
+
+// ExecutorFunc adapts function to Executor.
+type ExecutorFunc func(ctx context.Context, output io.Writer, env []string, dir string, command string, args ...string) error
+
+func (f ExecutorFunc) Exec(ctx context.Context, output io.Writer, env []string, dir string, command string, args ...string) error {
+	return f(ctx, output, env, dir, command, args...)
+}
+
+// cancelWaitDelay is how long command can take to exit after interrupt on context cancellation, before it's killed.
+const cancelWaitDelay = 5 * time.Second
+
+// osExecutor executes commands as OS processes.
+type osExecutor struct{}
+
+func (osExecutor) Exec(ctx context.Context, output io.Writer, env []string, dir string, command string, args ...string) error {
+	cmd := exec.CommandContext(ctx, command, args...)
+	// Interrupt instead of kill on cancellation, so go stops its own subprocesses (compilers, VCS fetches) and cleans up.
+	cmd.Cancel = func() error {
+		if err := cmd.Process.Signal(os.Interrupt); err != nil {
+			return cmd.Process.Kill()
+		}
+		return nil
+	}
+	cmd.WaitDelay = cancelWaitDelay
+	cmd.Dir = dir
+	cmd.Env = env
+	cmd.Stdout = output
+	cmd.Stderr = output
+	return cmd.Run()
 }
 
 var versionRegexp = regexp.MustCompile(`go?([0-9]+)(\.[0-9]+)?(\.[0-9]+)?`)
@@ -56,12 +105,24 @@ func isSupportedVersion(v *semver.Version) error {
 }
 
 // NewRunner checks Go version compatibility then returns Runner.
-func NewRunner(ctx context.Context, logger *log.Logger, insecure bool, goCmd string) (*Runner, error) {
+// Nil logger discards all logs.
+func NewRunner(ctx context.Context, logger logging.Logger, insecure bool, goCmd string) (*Runner, error) {
+	return NewRunnerWithExecutor(ctx, logger, insecure, goCmd, nil)
+}
+
+// NewRunnerWithExecutor is like NewRunner, but runs all commands (including go version check) with given executor.
+// Nil executor runs commands as OS processes.
+func NewRunnerWithExecutor(ctx context.Context, logger logging.Logger, insecure bool, goCmd string, executor Executor) (*Runner, error) {
+	logger = logging.OrDiscard(logger)
+	if executor == nil {
+		executor = osExecutor{}
+	}
 	output := &bytes.Buffer{}
 	r := &Runner{
 		goCmd:    goCmd,
 		insecure: insecure,
 		logger:   logger,
+		executor: executor,
 	}
 
 	if err := r.execGo(ctx, output, nil, "", "", "version"); err != nil {
@@ -85,6 +146,35 @@ func (r *Runner) Verbose() {
 	r.verbose = true
 }
 
+// Debug enables logging extra env variables, working directory and duration of every executed command.
+func (r *Runner) Debug() {
+	r.debug = true
+}
+
+// IsolateEnv makes commands run with minimal environment: only ambient variables needed to locate and fetch modules and
+// run go (see isolatedEnvVars) are passed, so builds are not affected by e.g CGO_CFLAGS or GOOS exported by the user.
+func (r *Runner) IsolateEnv() {
+	r.isolateEnv = true
+}
+
+// EnforceEnv sets given environment variables (e.g GOVCS=*:off) for all commands, overriding ambient ones, so project
+// module fetch policies apply regardless of user's environment. Environment variables given to With still take precedence.
+func (r *Runner) EnforceEnv(kvs ...string) {
+	r.enforcedEnv = append(r.enforcedEnv, kvs...)
+}
+
+// Stream enables writing output of commands fetching and building tools to given writer as it arrives, line by line.
+// Lines are prefixed with the prefix of the runnable (see Runnable.WithPrefix). Output is still captured for errors.
+func (r *Runner) Stream(w io.Writer) {
+	r.stream = &syncWriter{w: w}
+}
+
+// PrintCommands makes every executed command printed to given writer before it runs, as shell command line with working
+// directory and environment variables differing from ambient ones, so failing step can be reproduced manually.
+func (r *Runner) PrintCommands(w io.Writer) {
+	r.commands = &syncWriter{w: w}
+}
+
 var cmdsSupportingModFileArg = map[string]struct{}{
 	"init":    {},
 	"get":     {},
@@ -109,16 +199,41 @@ func (r *Runner) execGo(ctx context.Context, output io.Writer, e envars.EnvSlice
 	return r.exec(ctx, output, e, cd, r.goCmd, args...)
 }
 
-func (r *Runner) exec(ctx context.Context, output io.Writer, e envars.EnvSlice, cd string, command string, args ...string) error {
-	cmd := exec.CommandContext(ctx, command, args...)
-	cmd.Dir = filepath.Join(cmd.Dir, cd)
+func (r *Runner) exec(ctx context.Context, output io.Writer, e envars.EnvSlice, cd string, command string, args ...string) (err error) {
+	if r.debug {
+		start := time.Now()
+		extraEnv := append(append([]string{}, e...), "GO111MODULE=on")
+		defer func() {
+			r.logger.Debug("exec finished", "cmd", command+" "+strings.Join(args, " "), "dir", cd,
+				"extraEnv", strings.Join(extraEnv, " "), "durationSeconds", time.Since(start).Seconds(), "err", err)
+		}()
+	}
+	environ := envars.EnvSlice(os.Environ())
+	ambient := append(envars.EnvSlice{}, environ...)
+	if r.isolateEnv {
+		ambient = isolatedEnv(ambient)
+	}
+	if goflags, ok := ambient.Lookup("GOFLAGS"); ok {
+		kept, stripped := SanitizeGoFlags(goflags)
+		if len(stripped) > 0 {
+			r.goFlagsWarning.Do(func() {
+				r.logger.Warn("ignoring GOFLAGS environment variable flags that break or change pinned tools builds; persist them per tool with 'bingo get -goflags' if needed",
+					"flags", strings.Join(stripped, " "))
+			})
+			ambient.Set("GOFLAGS=" + kept)
+		}
+	}
+	ambient = envars.MergeEnvSlices(ambient, r.enforcedEnv...)
 	// TODO(bwplotka): Might be surprising, let's return err when this env variable is altered.
-	e = envars.MergeEnvSlices(os.Environ(), e...)
+	e = envars.MergeEnvSlices(ambient, e...)
 	e.Set("GO111MODULE=on")
-	cmd.Env = e
-	cmd.Stdout = output
-	cmd.Stderr = output
-	if err := cmd.Run(); err != nil {
+	if r.commands != nil {
+		_, _ = fmt.Fprintln(r.commands, commandLine(environ, e, r.isolateEnv, cd, command, args...))
+	}
+	if err := r.executor.Exec(ctx, output, e, cd, command, args...); err != nil {
+		if ctx.Err() != nil {
+			return errors.Wrapf(ctx.Err(), "command '%s %s' interrupted", command, strings.Join(args, " "))
+		}
 		if _, ok := err.(*exec.ExitError); ok {
 			if r.verbose {
 				return errors.Errorf("error while running command '%s %s'; err: %v", command, strings.Join(args, " "), err)
@@ -127,8 +242,100 @@ func (r *Runner) exec(ctx context.Context, output io.Writer, e envars.EnvSlice,
 		}
 		return errors.Errorf("error while running command '%s %s'; err: %v", command, strings.Join(args, " "), err)
 	}
-	if r.verbose {
-		r.logger.Printf("exec '%s %s'\n", command, strings.Join(args, " "))
+	if !r.debug {
+		r.logger.Debug("exec", "cmd", command+" "+strings.Join(args, " "))
+	}
+	return nil
+}
+
+// commandLine returns shell command line running given command with given arguments in given directory (current one if empty)
+// and environment. Only environment variables differing from given ambient ones are set, unless environment is isolated.
+func commandLine(ambient, env envars.EnvSlice, isolated bool, dir, command string, args ...string) string {
+	var b strings.Builder
+	if dir != "" {
+		b.WriteString("cd " + shellQuote(dir) + " && ")
+	}
+	if isolated {
+		b.WriteString("env -i ")
+	}
+	for _, kv := range env {
+		k, v, _ := strings.Cut(kv, "=")
+		if a, ok := ambient.Lookup(k); !isolated && ok && a == v {
+			continue
+		}
+		b.WriteString(k + "=" + shellQuote(v) + " ")
+	}
+	b.WriteString(shellQuote(command))
+	for _, a := range args {
+		b.WriteString(" " + shellQuote(a))
+	}
+	return b.String()
+}
+
+// shellQuote returns given string quoted for POSIX shell, if needed.
+func shellQuote(s string) string {
+	if s != "" && strings.Trim(s, "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789_-+=/.,:@%") == "" {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// isolatedEnvVars are the only ambient environment variables passed to commands with isolated environment (see
+// Runner.IsolateEnv). Names are upper case, as environment variables are case insensitive on Windows.
+var isolatedEnvVars = map[string]struct{}{
+	"HOME": {}, "GOPATH": {}, "GOMODCACHE": {}, "GOCACHE": {}, "PATH": {},
+	"GOPROXY": {}, "GONOPROXY": {}, "GOPRIVATE": {}, "GOSUMDB": {}, "GONOSUMDB": {}, "GOINSECURE": {},
+	"HTTP_PROXY": {}, "HTTPS_PROXY": {}, "NO_PROXY": {},
+	// Needed for go to find home, cache and temporary directories on Windows.
+	"USERPROFILE": {}, "LOCALAPPDATA": {}, "APPDATA": {}, "SYSTEMROOT": {}, "TMP": {}, "TEMP": {}, "TMPDIR": {},
+}
+
+// isolatedEnv returns given environment variables without those not in isolatedEnvVars.
+func isolatedEnv(env envars.EnvSlice) (isolated envars.EnvSlice) {
+	for _, kv := range env {
+		k, _, _ := strings.Cut(kv, "=")
+		if _, ok := isolatedEnvVars[strings.ToUpper(k)]; ok {
+			isolated = append(isolated, kv)
+		}
+	}
+	return isolated
+}
+
+// ambientGoFlagsDenied are flags that, set in ambient GOFLAGS environment variable, break go commands run by bingo
+// (e.g -mod=vendor with separate mod files) or silently change what pinned tools are built from (e.g -tags).
+var ambientGoFlagsDenied = map[string]struct{}{
+	"mod": {}, "modfile": {}, "overlay": {}, "tags": {}, "buildmode": {},
+	"race": {}, "msan": {}, "asan": {}, "ldflags": {}, "gcflags": {}, "asmflags": {},
+}
+
+// goFlagName returns name of given flag, e.g "mod" for "-mod=vendor".
+func goFlagName(flag string) string {
+	name, _, _ := strings.Cut(strings.TrimLeft(flag, "-"), "=")
+	return name
+}
+
+// SanitizeGoFlags returns given GOFLAGS without flags breaking or changing go commands run by bingo, and stripped flags.
+func SanitizeGoFlags(goflags string) (kept string, stripped []string) {
+	var k []string
+	for _, f := range strings.Fields(goflags) {
+		if _, denied := ambientGoFlagsDenied[goFlagName(f)]; denied {
+			stripped = append(stripped, f)
+			continue
+		}
+		k = append(k, f)
+	}
+	return strings.Join(k, " "), stripped
+}
+
+// ValidateToolGoFlags returns error if given GOFLAGS persisted for a tool contain flags managed by bingo itself.
+func ValidateToolGoFlags(goflags string) error {
+	for _, f := range strings.Fields(goflags) {
+		if !strings.HasPrefix(f, "-") {
+			return errors.Errorf("invalid GOFLAGS %q; flags have to start with -", f)
+		}
+		if n := goFlagName(f); n == "mod" || n == "modfile" {
+			return errors.Errorf("GOFLAGS flag %q is managed by bingo and cannot be set", f)
+		}
 	}
 	return nil
 }
@@ -140,6 +347,10 @@ type Runnable interface {
 	Build(pkg, out string, args ...string) error
 	GoEnv(args ...string) (string, error)
 	ModDownload() error
+	// Generate runs 'go generate' for given packages.
+	Generate(packages ...string) error
+	// WithPrefix returns Runnable which prefixes streamed output lines with given prefix (e.g. tool name).
+	WithPrefix(prefix string) Runnable
 }
 
 type runnable struct {
@@ -149,6 +360,7 @@ type runnable struct {
 	modFile      string
 	dir          string
 	extraEnvVars envars.EnvSlice
+	prefix       string
 }
 
 // ModInit runs `go mod init` against separate go modules files if any.
@@ -172,6 +384,27 @@ func (r *Runner) With(ctx context.Context, modFile string, dir string, extraEnvV
 	return ru
 }
 
+func (r *runnable) WithPrefix(prefix string) Runnable {
+	ru := *r
+	ru.prefix = prefix
+	return &ru
+}
+
+// execGoStreamed is like Runner.execGo, but also streams output if enabled.
+func (r *runnable) execGoStreamed(output io.Writer, modFile string, args ...string) (err error) {
+	if r.r.stream == nil {
+		return r.r.execGo(r.ctx, output, r.extraEnvVars, r.dir, modFile, args...)
+	}
+
+	pw := &prefixWriter{w: r.r.stream, prefix: r.prefix}
+	defer func() {
+		if ferr := pw.Flush(); ferr != nil && err == nil {
+			err = ferr
+		}
+	}()
+	return r.r.execGo(r.ctx, io.MultiWriter(output, pw), r.extraEnvVars, r.dir, modFile, args...)
+}
+
 type GetUpdatePolicy string
 
 const (
@@ -217,7 +450,7 @@ func (r *runnable) GetD(update GetUpdatePolicy, packages ...string) (string, err
 	}
 
 	out := &bytes.Buffer{}
-	if err := r.r.execGo(r.ctx, out, r.extraEnvVars, r.dir, r.modFile, append(args, packages...)...); err != nil {
+	if err := r.execGoStreamed(out, r.modFile, append(args, packages...)...); err != nil {
 		return "", errors.Wrap(err, out.String())
 	}
 	return strings.Trim(out.String(), "\n"), nil
@@ -227,13 +460,24 @@ func (r *runnable) GetD(update GetUpdatePolicy, packages ...string) (string, err
 func (r *runnable) Build(pkg, out string, args ...string) error {
 	args = append([]string{"build", "-o=" + out}, args...)
 	output := &bytes.Buffer{}
-	if err := r.r.execGo(r.ctx, output, r.extraEnvVars, r.dir, r.modFile, append(args, pkg)...); err != nil {
-		return errors.Wrap(err, output.String())
+	if err := r.execGoStreamed(output, r.modFile, append(args, pkg)...); err != nil {
+		return &BuildError{Output: output.String(), Err: err}
 	}
 
-	trimmed := strings.TrimSpace(output.String())
-	if r.r.verbose && trimmed != "" {
-		r.r.logger.Println(trimmed)
+	if r.r.stream != nil {
+		return nil
+	}
+	if trimmed := strings.TrimSpace(output.String()); trimmed != "" {
+		r.r.logger.Debug("go build output", "output", trimmed)
+	}
+	return nil
+}
+
+// Generate runs 'go generate' for given packages, in the module of runnable's directory.
+func (r *runnable) Generate(packages ...string) error {
+	out := &bytes.Buffer{}
+	if err := r.execGoStreamed(out, r.modFile, append([]string{"generate"}, packages...)...); err != nil {
+		return errors.Wrap(err, out.String())
 	}
 	return nil
 }
@@ -247,13 +491,77 @@ func (r *runnable) ModDownload() error {
 	args = append(args, fmt.Sprintf("-modfile=%s", r.modFile))
 
 	out := &bytes.Buffer{}
-	if err := r.r.execGo(r.ctx, out, r.extraEnvVars, r.dir, r.modFile, args...); err != nil {
+	if err := r.execGoStreamed(out, r.modFile, args...); err != nil {
 		return errors.Wrap(err, out.String())
 	}
 
-	trimmed := strings.TrimSpace(out.String())
-	if r.r.verbose && trimmed != "" {
-		r.r.logger.Println(trimmed)
+	if r.r.stream != nil {
+		return nil
+	}
+	if trimmed := strings.TrimSpace(out.String()); trimmed != "" {
+		r.r.logger.Debug("go mod download output", "output", trimmed)
 	}
 	return nil
 }
+
+// BuildError is returned when go build fails, with output of the build.
+type BuildError struct {
+	Output string
+	Err    error
+}
+
+func (e *BuildError) Error() string { return e.Output + ": " + e.Err.Error() }
+
+func (e *BuildError) Unwrap() error { return e.Err }
+
+// syncWriter is io.Writer safe to use by many concurrently running commands.
+type syncWriter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func (w *syncWriter) Write(b []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.w.Write(b)
+}
+
+// prefixWriter writes complete lines, prefixed with "[<prefix>] " if prefix is not empty, to the underlying writer.
+// Incomplete line is kept until next write or Flush.
+type prefixWriter struct {
+	w      io.Writer
+	prefix string
+	buf    []byte
+}
+
+func (p *prefixWriter) Write(b []byte) (int, error) {
+	p.buf = append(p.buf, b...)
+	for {
+		i := bytes.IndexByte(p.buf, '\n')
+		if i < 0 {
+			return len(b), nil
+		}
+		if err := p.writeLine(p.buf[:i+1]); err != nil {
+			return 0, err
+		}
+		p.buf = p.buf[i+1:]
+	}
+}
+
+func (p *prefixWriter) writeLine(line []byte) error {
+	if p.prefix != "" {
+		line = append([]byte("["+p.prefix+"] "), line...)
+	}
+	_, err := p.w.Write(line)
+	return err
+}
+
+// Flush writes remaining incomplete line, if any.
+func (p *prefixWriter) Flush() error {
+	if len(p.buf) == 0 {
+		return nil
+	}
+	err := p.writeLine(append(p.buf, '\n'))
+	p.buf = nil
+	return err
+}
@@ -4,8 +4,16 @@
 package runner
 
 import (
+	"bytes"
+	"context"
+	"io"
+	"log/slog"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/bwplotka/bingo/pkg/envars"
 	"github.com/efficientgo/tools/core/pkg/merrors"
 	"github.com/efficientgo/tools/core/pkg/testutil"
 	"github.com/pkg/errors"
@@ -54,3 +62,191 @@ func TestParseAndIsSupportedVersion(t *testing.T) {
 		})
 	}
 }
+
+func TestRunner_DebugLogging(t *testing.T) {
+	b := &bytes.Buffer{}
+	r, err := NewRunner(context.Background(), slog.New(slog.NewTextHandler(b, &slog.HandlerOptions{Level: slog.LevelDebug})), false, "go")
+	testutil.Ok(t, err)
+	testutil.Assert(t, strings.Contains(b.String(), `msg=exec cmd="go version"`), b.String())
+
+	b.Reset()
+	r.Debug()
+	_, err = r.With(context.Background(), "", "", envars.EnvSlice{"GOFLAGS=-mod=mod"}).GoEnv("GOOS")
+	testutil.Ok(t, err)
+	testutil.Assert(t, strings.Contains(b.String(), `msg="exec finished" cmd="go env GOOS"`), b.String())
+	testutil.Assert(t, strings.Contains(b.String(), `extraEnv="GOFLAGS=-mod=mod GO111MODULE=on" durationSeconds=`), b.String())
+}
+
+func TestPrefixWriter(t *testing.T) {
+	b := &bytes.Buffer{}
+	w := &prefixWriter{w: b, prefix: "faillint"}
+	_, err := w.Write([]byte("go: downloading a v1.0.0\ngo: down"))
+	testutil.Ok(t, err)
+	testutil.Equals(t, "[faillint] go: downloading a v1.0.0\n", b.String())
+
+	_, err = w.Write([]byte("loading b v1.0.0\nbuild failed"))
+	testutil.Ok(t, err)
+	testutil.Ok(t, w.Flush())
+	testutil.Equals(t, "[faillint] go: downloading a v1.0.0\n[faillint] go: downloading b v1.0.0\n[faillint] build failed\n", b.String())
+}
+
+func TestRunner_Stream(t *testing.T) {
+	r, err := NewRunner(context.Background(), nil, false, "go")
+	testutil.Ok(t, err)
+
+	b := &bytes.Buffer{}
+	r.Stream(b)
+	err = r.With(context.Background(), "", t.TempDir(), nil).WithPrefix("faillint").Build("./not-existing", filepath.Join(t.TempDir(), "out"))
+	testutil.NotOk(t, err)
+	var buildErr *BuildError
+	testutil.Assert(t, errors.As(err, &buildErr), "expected BuildError, got %v", err)
+	testutil.Assert(t, strings.HasPrefix(b.String(), "[faillint] "), b.String())
+	// Output is still captured in the error.
+	testutil.Assert(t, strings.Contains(err.Error(), strings.TrimPrefix(strings.SplitN(b.String(), "\n", 2)[0], "[faillint] ")), err.Error())
+}
+
+func TestRunner_Cancel(t *testing.T) {
+	r, err := NewRunner(context.Background(), nil, false, "go")
+	testutil.Ok(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err = r.exec(ctx, &bytes.Buffer{}, nil, "", "sleep", "10")
+	testutil.Assert(t, errors.Is(err, context.Canceled), "unexpected error %v", err)
+
+	ctx, cancel = context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	err = r.exec(ctx, &bytes.Buffer{}, nil, "", "sleep", "10")
+	testutil.Assert(t, errors.Is(err, context.DeadlineExceeded), "unexpected error %v", err)
+	testutil.Assert(t, time.Since(start) < 5*time.Second, "command was not interrupted promptly")
+}
+
+func TestSanitizeGoFlags(t *testing.T) {
+	kept, stripped := SanitizeGoFlags("-mod=vendor -trimpath -tags=netgo --modfile=x.mod -buildvcs=false -race")
+	testutil.Equals(t, "-trimpath -buildvcs=false", kept)
+	testutil.Equals(t, []string{"-mod=vendor", "-tags=netgo", "--modfile=x.mod", "-race"}, stripped)
+
+	kept, stripped = SanitizeGoFlags("-trimpath")
+	testutil.Equals(t, "-trimpath", kept)
+	testutil.Equals(t, 0, len(stripped))
+
+	testutil.Ok(t, ValidateToolGoFlags("-tags=netgo -trimpath"))
+	testutil.NotOk(t, ValidateToolGoFlags("-mod=vendor"))
+	testutil.NotOk(t, ValidateToolGoFlags("-modfile=x.mod"))
+	testutil.NotOk(t, ValidateToolGoFlags("netgo"))
+}
+
+func TestRunner_AmbientGoFlags(t *testing.T) {
+	t.Setenv("GOFLAGS", "-mod=vendor -trimpath")
+
+	var env []string
+	fakeGo := ExecutorFunc(func(_ context.Context, output io.Writer, e []string, _, _ string, args ...string) error {
+		env = e
+		if args[0] == "version" {
+			_, err := io.WriteString(output, "go version go1.21.0 linux/amd64\n")
+			return err
+		}
+		return nil
+	})
+	b := &bytes.Buffer{}
+	r, err := NewRunnerWithExecutor(context.Background(), slog.New(slog.NewTextHandler(b, nil)), false, "go", fakeGo)
+	testutil.Ok(t, err)
+
+	goflags, _ := envars.EnvSlice(env).Lookup("GOFLAGS")
+	testutil.Equals(t, "-trimpath", goflags)
+	testutil.Assert(t, strings.Contains(b.String(), `flags="-mod=vendor"`), b.String())
+
+	// Tool's own GOFLAGS take precedence.
+	_, err = r.With(context.Background(), "", "", envars.EnvSlice{"GOFLAGS=-tags=netgo"}).GoEnv("GOOS")
+	testutil.Ok(t, err)
+	goflags, _ = envars.EnvSlice(env).Lookup("GOFLAGS")
+	testutil.Equals(t, "-tags=netgo", goflags)
+	testutil.Equals(t, 1, strings.Count(b.String(), "ignoring GOFLAGS"))
+}
+
+func TestRunner_IsolateEnv(t *testing.T) {
+	t.Setenv("HOME", "/home/test")
+	t.Setenv("GOPROXY", "https://proxy.example.com")
+	t.Setenv("CGO_CFLAGS", "-O3")
+	t.Setenv("GOOS", "plan9")
+
+	var env []string
+	fakeGo := ExecutorFunc(func(_ context.Context, output io.Writer, e []string, _, _ string, args ...string) error {
+		env = e
+		if args[0] == "version" {
+			_, err := io.WriteString(output, "go version go1.21.0 linux/amd64\n")
+			return err
+		}
+		return nil
+	})
+	r, err := NewRunnerWithExecutor(context.Background(), nil, false, "go", fakeGo)
+	testutil.Ok(t, err)
+	r.IsolateEnv()
+
+	_, err = r.With(context.Background(), "", "", envars.EnvSlice{"CGO_ENABLED=1"}).GoEnv("GOOS")
+	testutil.Ok(t, err)
+	for k, expected := range map[string]string{"HOME": "/home/test", "GOPROXY": "https://proxy.example.com", "CGO_ENABLED": "1", "GO111MODULE": "on"} {
+		v, ok := envars.EnvSlice(env).Lookup(k)
+		testutil.Assert(t, ok, "expected %v in %v", k, env)
+		testutil.Equals(t, expected, v)
+	}
+	for _, k := range []string{"CGO_CFLAGS", "GOOS"} {
+		_, ok := envars.EnvSlice(env).Lookup(k)
+		testutil.Assert(t, !ok, "unexpected %v in %v", k, env)
+	}
+}
+
+func TestRunner_EnforceEnv(t *testing.T) {
+	t.Setenv("GOVCS", "*:all")
+
+	var env []string
+	fakeGo := ExecutorFunc(func(_ context.Context, output io.Writer, e []string, _, _ string, args ...string) error {
+		env = e
+		if args[0] == "version" {
+			_, err := io.WriteString(output, "go version go1.21.0 linux/amd64\n")
+			return err
+		}
+		return nil
+	})
+	r, err := NewRunnerWithExecutor(context.Background(), nil, false, "go", fakeGo)
+	testutil.Ok(t, err)
+	r.EnforceEnv("GOVCS=github.com:git,*:off", "GOPROXY=https://proxy.example.com")
+
+	_, err = r.With(context.Background(), "", "", envars.EnvSlice{"GOPROXY=off"}).GoEnv("GOVCS")
+	testutil.Ok(t, err)
+	govcs, _ := envars.EnvSlice(env).Lookup("GOVCS")
+	testutil.Equals(t, "github.com:git,*:off", govcs)
+	// Explicit env variables of command (e.g for offline build) still take precedence.
+	goproxy, _ := envars.EnvSlice(env).Lookup("GOPROXY")
+	testutil.Equals(t, "off", goproxy)
+}
+
+func TestRunner_PrintCommands(t *testing.T) {
+	t.Setenv("GO111MODULE", "on")
+	t.Setenv("GOFLAGS", "-mod=vendor -v")
+
+	fakeGo := ExecutorFunc(func(_ context.Context, output io.Writer, _ []string, _, _ string, args ...string) error {
+		if args[0] == "version" {
+			_, err := io.WriteString(output, "go version go1.21.0 linux/amd64\n")
+			return err
+		}
+		return nil
+	})
+	r, err := NewRunnerWithExecutor(context.Background(), nil, false, "go", fakeGo)
+	testutil.Ok(t, err)
+
+	b := &bytes.Buffer{}
+	r.PrintCommands(b)
+	r.EnforceEnv("GOPROXY=https://proxy.example.com")
+	testutil.Ok(t, r.With(context.Background(), "faillint.mod", "/project/.bingo", envars.EnvSlice{"CGO_ENABLED=1"}).
+		Build("github.com/fatih/faillint", "/go/bin/faillint-v1.5.0", "-tags=a b"))
+	testutil.Equals(t, "cd /project/.bingo && CGO_ENABLED=1 GOFLAGS=-v GOPROXY=https://proxy.example.com "+
+		"go build -modfile=faillint.mod -o=/go/bin/faillint-v1.5.0 '-tags=a b' github.com/fatih/faillint\n", b.String())
+}
+
+func TestShellQuote(t *testing.T) {
+	testutil.Equals(t, "-modfile=a.mod", shellQuote("-modfile=a.mod"))
+	testutil.Equals(t, "''", shellQuote(""))
+	testutil.Equals(t, `'it'\''s'`, shellQuote("it's"))
+}
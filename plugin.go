@@ -0,0 +1,131 @@
+// Copyright (c) Bartłomiej Płotka @bwplotka
+// Licensed under the Apache License 2.0.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/bwplotka/bingo/internal/getter"
+	"github.com/bwplotka/bingo/pkg/bingo"
+	"github.com/bwplotka/bingo/pkg/envars"
+	"github.com/bwplotka/bingo/pkg/version"
+	"github.com/pkg/errors"
+)
+
+// pluginPrefix is a prefix of executables on PATH that extend bingo with new commands, e.g bingo-audit for bingo audit.
+const pluginPrefix = "bingo-"
+
+// versionedBinaryRegexp matches suffixes of versioned bingo binaries (e.g bingo-v0.4.3) installed in GOBIN, which are not plugins.
+var versionedBinaryRegexp = regexp.MustCompile(`^v[0-9]`)
+
+// isPluginName returns true if given command name can be dispatched to a plugin.
+func isPluginName(name string) bool {
+	return name != "" && !strings.HasPrefix(name, "-") && !versionedBinaryRegexp.MatchString(name)
+}
+
+// lookPlugin returns path to plugin executable for given command name.
+func lookPlugin(name string) (string, error) {
+	if !isPluginName(name) {
+		return "", errors.Errorf("%q is not a valid plugin name", name)
+	}
+	return exec.LookPath(pluginPrefix + name)
+}
+
+// listPlugins returns names of all plugins found on PATH.
+func listPlugins() []string {
+	seen := map[string]struct{}{}
+	var names []string
+	for _, dir := range filepath.SplitList(os.Getenv("PATH")) {
+		files, err := ioutil.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, f := range files {
+			name := strings.TrimPrefix(f.Name(), pluginPrefix)
+			if name == f.Name() || f.IsDir() || f.Mode()&0111 == 0 || !isPluginName(name) {
+				continue
+			}
+			if _, ok := seen[name]; ok {
+				continue
+			}
+			seen[name] = struct{}{}
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// pluginEnv returns environment variables passed to plugins:
+//   - BINGO_BIN: path to running bingo binary.
+//   - BINGO_VERSION: version of running bingo.
+//   - BINGO_MODDIR: absolute path to mod directory (.bingo unless BINGO_MODDIR is set).
+//   - BINGO_GOBIN: absolute path where binaries are installed.
+//   - BINGO_LIST_JSON: pinned tools in the same format as bingo list -o json.
+func pluginEnv(logger *slog.Logger) (envars.EnvSlice, error) {
+	relModDir := os.Getenv("BINGO_MODDIR")
+	if relModDir == "" {
+		relModDir = ".bingo"
+	}
+	modDir, err := filepath.Abs(relModDir)
+	if err != nil {
+		return nil, errors.Wrap(err, "abs moddir")
+	}
+	gobinPath, err := filepath.Abs(getter.BinDir(modDir))
+	if err != nil {
+		return nil, errors.Wrap(err, "abs gobin")
+	}
+	pkgs, err := bingo.ListPinnedMainPackages(logger, modDir, false)
+	if err != nil {
+		return nil, errors.Wrap(err, "list pinned")
+	}
+	bingo.SortRenderables(pkgs)
+	list := &bytes.Buffer{}
+	if err := pkgs.PrintJSON("", gobinPath, list); err != nil {
+		return nil, errors.Wrap(err, "list pinned as JSON")
+	}
+	bin, err := os.Executable()
+	if err != nil {
+		return nil, errors.Wrap(err, "find bingo executable")
+	}
+
+	return envars.EnvSlice{
+		"BINGO_BIN=" + bin,
+		"BINGO_VERSION=" + version.Version,
+		"BINGO_MODDIR=" + modDir,
+		"BINGO_GOBIN=" + gobinPath,
+		"BINGO_LIST_JSON=" + strings.TrimSpace(list.String()),
+	}, nil
+}
+
+// runPlugin runs given plugin executable with given args, standard streams and environment extended with pluginEnv.
+// Non-zero plugin exit code is returned as exitCodeError.
+func runPlugin(ctx context.Context, logger *slog.Logger, path string, args []string) error {
+	e, err := pluginEnv(logger)
+	if err != nil {
+		return err
+	}
+
+	cmd := exec.CommandContext(ctx, path, args...)
+	cmd.Env = envars.MergeEnvSlices(os.Environ(), e...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok && exitErr.ExitCode() > 0 {
+			return exitCodeError{code: exitErr.ExitCode(), msg: "plugin " + filepath.Base(path) + " failed: " + err.Error()}
+		}
+		return errors.Wrapf(err, "run plugin %s", path)
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+// Copyright (c) Bartłomiej Płotka @bwplotka
+// Licensed under the Apache License 2.0.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/bwplotka/bingo/pkg/version"
+	"github.com/efficientgo/tools/core/pkg/testutil"
+	"github.com/pkg/errors"
+)
+
+func TestPlugins(t *testing.T) {
+	tmpDir := t.TempDir()
+	binDir := filepath.Join(tmpDir, "bin")
+	testutil.Ok(t, os.MkdirAll(binDir, os.ModePerm))
+	testutil.Ok(t, ioutil.WriteFile(filepath.Join(binDir, "bingo-hello"), []byte(`#!/bin/sh
+echo "$@" > "$BINGO_MODDIR/../args"
+echo "$BINGO_VERSION $BINGO_LIST_JSON" > "$BINGO_MODDIR/../env"
+exit $1
+`), 0755))
+	// Not executable or versioned bingo binaries are not plugins.
+	testutil.Ok(t, ioutil.WriteFile(filepath.Join(binDir, "bingo-readme"), []byte("not a plugin"), 0644))
+	testutil.Ok(t, ioutil.WriteFile(filepath.Join(binDir, "bingo-v0.4.3"), []byte("#!/bin/sh\n"), 0755))
+
+	modDir := filepath.Join(tmpDir, ".bingo")
+	testutil.Ok(t, os.MkdirAll(modDir, os.ModePerm))
+	testutil.Ok(t, ioutil.WriteFile(filepath.Join(modDir, "faillint.mod"), []byte("module _\n\nrequire github.com/fatih/faillint v1.5.0\n"), os.ModePerm))
+
+	t.Setenv("PATH", binDir)
+	t.Setenv("BINGO_MODDIR", modDir)
+
+	testutil.Equals(t, []string{"hello"}, listPlugins())
+	_, err := lookPlugin("readme")
+	testutil.NotOk(t, err)
+	_, err = lookPlugin("v0.4.3")
+	testutil.NotOk(t, err)
+	plugin, err := lookPlugin("hello")
+	testutil.Ok(t, err)
+
+	logger := slog.New(slog.NewTextHandler(ioutil.Discard, nil))
+	testutil.Ok(t, runPlugin(context.Background(), logger, plugin, []string{"0", "-x"}))
+
+	b, err := ioutil.ReadFile(filepath.Join(tmpDir, "args"))
+	testutil.Ok(t, err)
+	testutil.Equals(t, "0 -x\n", string(b))
+	b, err = ioutil.ReadFile(filepath.Join(tmpDir, "env"))
+	testutil.Ok(t, err)
+	env := strings.SplitN(strings.TrimSpace(string(b)), " ", 2)
+	testutil.Equals(t, version.Version, env[0])
+	var tools []struct {
+		Name string `json:"name"`
+	}
+	testutil.Ok(t, json.Unmarshal([]byte(env[1]), &tools))
+	testutil.Equals(t, 1, len(tools))
+	testutil.Equals(t, "faillint", tools[0].Name)
+
+	err = runPlugin(context.Background(), logger, plugin, []string{"3"})
+	testutil.NotOk(t, err)
+	ecErr, ok := errors.Cause(err).(exitCodeError)
+	testutil.Assert(t, ok, "expected exitCodeError, got %v", err)
+	testutil.Equals(t, 3, ecErr.code)
+}
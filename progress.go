@@ -0,0 +1,158 @@
+// Copyright (c) Bartłomiej Płotka @bwplotka
+// Licensed under the Apache License 2.0.
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/bwplotka/bingo/internal/getter"
+	"golang.org/x/term"
+)
+
+// isInteractive returns true if given file is a terminal that can render status line, so not a dumb terminal nor CI.
+func isInteractive(f *os.File) bool {
+	if os.Getenv("CI") != "" || os.Getenv("TERM") == "dumb" {
+		return false
+	}
+	return term.IsTerminal(int(f.Fd()))
+}
+
+// statusLine is io.Writer that keeps single, rewritable status line below everything written through it.
+type statusLine struct {
+	mu     sync.Mutex
+	w      io.Writer
+	status string
+}
+
+func (s *statusLine) Write(b []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.status != "" {
+		if _, err := io.WriteString(s.w, "\r\033[K"); err != nil {
+			return 0, err
+		}
+	}
+	n, err := s.w.Write(b)
+	if err != nil {
+		return n, err
+	}
+	if s.status != "" {
+		_, err = io.WriteString(s.w, s.status)
+	}
+	return n, err
+}
+
+// Set replaces status line with given one. Empty status clears it.
+func (s *statusLine) Set(status string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, _ = io.WriteString(s.w, "\r\033[K"+status)
+	s.status = status
+}
+
+// progress reports progress of getting tools as getter.Events. If status line is nil (e.g. not a terminal) it logs
+// each tool instead, if there are many.
+type progress struct {
+	getter.NoopEvents
+
+	logger *slog.Logger
+	status *statusLine
+
+	mu    sync.Mutex
+	total int
+	n     int
+	tool  string
+	phase string
+	start time.Time
+	stop  chan struct{}
+}
+
+func newProgress(logger *slog.Logger, status *statusLine) *progress {
+	return &progress{logger: logger, status: status}
+}
+
+// OnStart starts reporting progress of getting given number of tools.
+func (p *progress) OnStart(total int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.total = total
+	p.start = time.Now()
+	if p.status == nil || p.stop != nil {
+		return
+	}
+
+	// Refresh elapsed time.
+	p.stop = make(chan struct{})
+	go func(stop <-chan struct{}) {
+		t := time.NewTicker(time.Second)
+		defer t.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-t.C:
+				p.mu.Lock()
+				p.render()
+				p.mu.Unlock()
+			}
+		}
+	}(p.stop)
+}
+
+// OnToolStart marks given tool as currently processed.
+func (p *progress) OnToolStart(tool, _ string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.n++
+	p.tool = tool
+	p.phase = "get"
+	if p.status == nil {
+		if p.total > 1 {
+			p.logger.Info(fmt.Sprintf("[%d/%d] getting %s", p.n, p.total, tool))
+		}
+		return
+	}
+	p.render()
+}
+
+func (p *progress) OnResolveStart(string) { p.setPhase("resolve") }
+
+func (p *progress) OnBuildStart(string, string) { p.setPhase("install") }
+
+// OnFinish clears status line, if any.
+func (p *progress) OnFinish(error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.stop == nil {
+		return
+	}
+	close(p.stop)
+	p.stop = nil
+	p.status.Set("")
+}
+
+func (p *progress) setPhase(phase string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.phase = phase
+	p.render()
+}
+
+func (p *progress) render() {
+	if p.status == nil || p.tool == "" {
+		return
+	}
+	p.status.Set(fmt.Sprintf("[%d/%d] %s: %s (%s)", p.n, p.total, p.tool, p.phase, time.Since(p.start).Round(time.Second)))
+}
@@ -0,0 +1,59 @@
+// Copyright (c) Bartłomiej Płotka @bwplotka
+// Licensed under the Apache License 2.0.
+
+package main
+
+import (
+	"bytes"
+	"log/slog"
+	"testing"
+
+	"github.com/efficientgo/tools/core/pkg/testutil"
+)
+
+func TestStatusLine(t *testing.T) {
+	b := &bytes.Buffer{}
+	s := &statusLine{w: b}
+
+	_, err := s.Write([]byte("before\n"))
+	testutil.Ok(t, err)
+	s.Set("[1/2] faillint: get (0s)")
+	_, err = s.Write([]byte("Warning: x\n"))
+	testutil.Ok(t, err)
+	s.Set("")
+	testutil.Equals(t, "before\n"+
+		"\r\033[K[1/2] faillint: get (0s)"+
+		"\r\033[KWarning: x\n[1/2] faillint: get (0s)"+
+		"\r\033[K", b.String())
+}
+
+func TestProgress(t *testing.T) {
+	t.Run("plain", func(t *testing.T) {
+		b := &bytes.Buffer{}
+		p := newProgress(newLogger(b, textLogFormat, slog.LevelInfo, false), nil)
+		p.OnStart(2)
+		p.OnToolStart("faillint", "github.com/fatih/faillint")
+		p.OnBuildStart("faillint", "v1.5.0")
+		p.OnToolStart("buf", "github.com/bufbuild/buf/cmd/buf")
+		p.OnFinish(nil)
+		testutil.Equals(t, "[1/2] getting faillint\n[2/2] getting buf\n", b.String())
+
+		// Single tool is not worth reporting.
+		b.Reset()
+		p = newProgress(newLogger(b, textLogFormat, slog.LevelInfo, false), nil)
+		p.OnStart(1)
+		p.OnToolStart("faillint", "github.com/fatih/faillint")
+		p.OnFinish(nil)
+		testutil.Equals(t, "", b.String())
+	})
+	t.Run("status line", func(t *testing.T) {
+		b := &bytes.Buffer{}
+		s := &statusLine{w: b}
+		p := newProgress(newLogger(s, textLogFormat, slog.LevelInfo, false), s)
+		p.OnStart(2)
+		p.OnToolStart("faillint", "github.com/fatih/faillint")
+		p.OnBuildStart("faillint", "v1.5.0")
+		p.OnFinish(nil)
+		testutil.Equals(t, "\r\033[K[1/2] faillint: get (0s)\r\033[K[1/2] faillint: install (0s)\r\033[K", b.String())
+	})
+}
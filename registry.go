@@ -0,0 +1,83 @@
+// Copyright (c) Bartłomiej Płotka @bwplotka
+// Licensed under the Apache License 2.0.
+
+package main
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	"github.com/bwplotka/bingo/pkg/bingo"
+	"github.com/bwplotka/bingo/pkg/runner"
+	"github.com/efficientgo/tools/core/pkg/errcapture"
+	"github.com/pkg/errors"
+	"golang.org/x/mod/semver"
+)
+
+// Registry modes of bingo get.
+const (
+	registryWarnMode  = "warn"
+	registryBlockMode = "block"
+)
+
+// loadRegistry loads organization registry of approved tools from given http(s) URL or local file (see bingo.ParseRegistry).
+func loadRegistry(ctx context.Context, client *http.Client, location string) (_ *bingo.Registry, err error) {
+	if !strings.HasPrefix(location, "http://") && !strings.HasPrefix(location, "https://") {
+		b, err := ioutil.ReadFile(location)
+		if err != nil {
+			return nil, err
+		}
+		return bingo.ParseRegistry(b)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, location, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, errors.Wrap(err, "fetch registry")
+	}
+	defer errcapture.Do(&err, resp.Body.Close, "close response body")
+
+	if resp.StatusCode != http.StatusOK {
+		b, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, errors.Errorf("fetch registry: unexpected status %s: %s", resp.Status, strings.TrimSpace(string(b)))
+	}
+	b, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, errors.Wrap(err, "read registry")
+	}
+	return bingo.ParseRegistry(b)
+}
+
+// approvedUpdate returns the highest version of given module newer than given one and approved by given registry entry,
+// or empty string if there is none.
+func approvedUpdate(ctx context.Context, r *runner.Runner, modDir, modFile, modPath, version string, entry bingo.RegistryEntry) (string, error) {
+	out, err := r.With(ctx, filepath.Join(modDir, modFile), modDir, nil).List(
+		runner.NoUpdatePolicy, "-m", "-versions", "-f={{range .Versions}}{{.}} {{end}}", modPath,
+	)
+	if err != nil {
+		return "", err
+	}
+	update := ""
+	for _, v := range strings.Fields(out) {
+		if semver.Compare(v, version) <= 0 || (update != "" && semver.Compare(v, update) <= 0) || !entry.Allows(v) {
+			continue
+		}
+		update = v
+	}
+	return update, nil
+}
+
+// registryLookup returns entry of given tool in given registry, if registry is not nil and lists the tool.
+func registryLookup(registry *bingo.Registry, p bingo.PackageRenderable) (bingo.RegistryEntry, bool) {
+	if registry == nil {
+		return bingo.RegistryEntry{}, false
+	}
+	return registry.Lookup(p.PackagePath, p.ModPath)
+}
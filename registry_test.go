@@ -0,0 +1,62 @@
+// Copyright (c) Bartłomiej Płotka @bwplotka
+// Licensed under the Apache License 2.0.
+
+package main
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bwplotka/bingo/pkg/runner"
+	"github.com/efficientgo/tools/core/pkg/testutil"
+)
+
+func TestLoadRegistry(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/registry.json" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte(`{"github.com/fatih/faillint": ">= 1.5"}`))
+	}))
+	defer srv.Close()
+
+	ctx := context.Background()
+	reg, err := loadRegistry(ctx, srv.Client(), srv.URL+"/registry.json")
+	testutil.Ok(t, err)
+	testutil.Ok(t, reg.Check("github.com/fatih/faillint", "github.com/fatih/faillint", "v1.5.0"))
+
+	_, err = loadRegistry(ctx, srv.Client(), srv.URL+"/missing.json")
+	testutil.NotOk(t, err)
+
+	f := filepath.Join(t.TempDir(), "registry.yaml")
+	testutil.Ok(t, ioutil.WriteFile(f, []byte("github.com/fatih/faillint: \"< 1.5\"\n"), os.ModePerm))
+	reg, err = loadRegistry(ctx, srv.Client(), f)
+	testutil.Ok(t, err)
+	testutil.NotOk(t, reg.Check("github.com/fatih/faillint", "github.com/fatih/faillint", "v1.5.0"))
+
+	fakeGo := runner.ExecutorFunc(func(_ context.Context, output io.Writer, _ []string, _, _ string, args ...string) error {
+		if args[0] == "version" {
+			_, err := io.WriteString(output, "go version go1.21.0 linux/amd64\n")
+			return err
+		}
+		_, err := io.WriteString(output, "v1.3.0 v1.4.0 v1.4.1 v1.5.0 v1.6.0 \n")
+		return err
+	})
+	r, err := runner.NewRunnerWithExecutor(ctx, nil, false, "go", fakeGo)
+	testutil.Ok(t, err)
+	entry, ok := reg.Lookup("github.com/fatih/faillint", "github.com/fatih/faillint")
+	testutil.Assert(t, ok)
+	update, err := approvedUpdate(ctx, r, t.TempDir(), "faillint.mod", "github.com/fatih/faillint", "v1.3.0", entry)
+	testutil.Ok(t, err)
+	testutil.Equals(t, "v1.4.1", update)
+	update, err = approvedUpdate(ctx, r, t.TempDir(), "faillint.mod", "github.com/fatih/faillint", "v1.5.0", entry)
+	testutil.Ok(t, err)
+	testutil.Equals(t, "", update)
+}
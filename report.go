@@ -0,0 +1,146 @@
+// Copyright (c) Bartłomiej Płotka @bwplotka
+// Licensed under the Apache License 2.0.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"html/template"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/bwplotka/bingo/pkg/bingo"
+	"github.com/bwplotka/bingo/pkg/runner"
+	"github.com/pkg/errors"
+	"golang.org/x/mod/semver"
+)
+
+// Report formats of bingo report.
+const (
+	markdownReportFormat = "markdown"
+	htmlReportFormat     = "html"
+)
+
+// reportRow describes pinned tool in inventory report generated by bingo report.
+type reportRow struct {
+	Name        string
+	Description string
+	// Versions are all pinned versions of the tool, lowest first.
+	Versions []string
+	Module   string
+	// License is SPDX identifier of the tool's module license (see detectLicenses).
+	License string
+	// Updated is a date (YYYY-MM-DD) of the last change of the tool's mod files: the last commit changing them, or their
+	// modification time if they are not committed.
+	Updated string
+}
+
+// collectReport returns report rows of given tools pinned in given mod directory. Licenses are detected from module
+// sources, so it requires network access, unless modules are cached.
+func collectReport(ctx context.Context, r *runner.Runner, modDir string, pkgs bingo.PackageRenderables) ([]reportRow, error) {
+	rows := make([]reportRow, 0, len(pkgs))
+	for _, p := range pkgs {
+		row := reportRow{Name: p.Name, Description: p.Description, Module: p.ModPath}
+		latest := p.Versions[0]
+		for _, v := range p.Versions {
+			row.Versions = append(row.Versions, v.Version)
+			if semver.Compare(v.Version, latest.Version) > 0 {
+				latest = v
+			}
+			updated := v.ModTime.Format("2006-01-02")
+			if out, err := git(ctx, "log", "-1", "--format=%cs", "--", filepath.Join(modDir, v.ModFile)); err == nil && len(bytes.TrimSpace(out)) > 0 {
+				updated = string(bytes.TrimSpace(out))
+			}
+			if updated > row.Updated {
+				row.Updated = updated
+			}
+		}
+		sort.Slice(row.Versions, func(i, j int) bool { return semver.Compare(row.Versions[i], row.Versions[j]) < 0 })
+
+		dir, err := r.With(ctx, filepath.Join(modDir, latest.ModFile), modDir, nil).List(runner.NoUpdatePolicy, "-mod=mod", "-m", "-f={{.Dir}}", p.ModPath)
+		if err != nil {
+			return nil, errors.Wrapf(err, "find module source of %s", p.Name)
+		}
+		row.License = unknownLicense
+		if dir = strings.TrimSpace(dir); dir != "" {
+			licenses, err := detectLicenses(dir)
+			if err != nil {
+				return nil, errors.Wrapf(err, "detect license of %s", p.Name)
+			}
+			row.License = strings.Join(licenses, " OR ")
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+var htmlReportTmpl = template.Must(template.New("report").Parse(`<!-- Generated by bingo report. DO NOT EDIT. -->
+<table>
+  <thead>
+    <tr><th>Name</th><th>Description</th><th>Version</th><th>Module</th><th>License</th><th>Updated</th></tr>
+  </thead>
+  <tbody>
+{{- range . }}
+    <tr><td>{{ .Name }}</td><td>{{ .Description }}</td><td>{{ range $i, $v := .Versions }}{{ if $i }}, {{ end }}{{ $v }}{{ end }}</td><td>{{ .Module }}</td><td>{{ .License }}</td><td>{{ .Updated }}</td></tr>
+{{- end }}
+  </tbody>
+</table>
+`))
+
+// writeReport writes report with given rows in given format: markdown table or HTML table fragment, ready to be embedded
+// in docs site or wiki page.
+func writeReport(w io.Writer, rows []reportRow, format string) error {
+	if format == htmlReportFormat {
+		return htmlReportTmpl.Execute(w, rows)
+	}
+
+	cell := func(s string) string {
+		if s == "" {
+			return "-"
+		}
+		return strings.NewReplacer("|", `\|`, "\n", " ").Replace(s)
+	}
+	b := &bytes.Buffer{}
+	_, _ = fmt.Fprintln(b, "<!-- Generated by bingo report. DO NOT EDIT. -->")
+	_, _ = fmt.Fprintln(b)
+	_, _ = fmt.Fprintln(b, "| Name | Description | Version | Module | License | Updated |")
+	_, _ = fmt.Fprintln(b, "|------|-------------|---------|--------|---------|---------|")
+	for _, r := range rows {
+		_, _ = fmt.Fprintf(b, "| %s | %s | %s | %s | %s | %s |\n",
+			cell(r.Name), cell(r.Description), cell(strings.Join(r.Versions, ", ")), cell(r.Module), cell(r.License), cell(r.Updated))
+	}
+	_, err := w.Write(b.Bytes())
+	return err
+}
+
+// reportFormat returns report format for given output file: html for .html and .htm files, markdown otherwise (also
+// for stdout, if file is empty).
+func reportFormat(file string) string {
+	switch strings.ToLower(filepath.Ext(file)) {
+	case ".html", ".htm":
+		return htmlReportFormat
+	}
+	return markdownReportFormat
+}
+
+// writeReportFile writes report with given rows in given format to given file. File is rewritten only if its content
+// changes, so regenerating it on every get does not touch it needlessly.
+func writeReportFile(file string, rows []reportRow, format string) error {
+	b := &bytes.Buffer{}
+	if err := writeReport(b, rows, format); err != nil {
+		return err
+	}
+	if existing, err := ioutil.ReadFile(file); err == nil && bytes.Equal(existing, b.Bytes()) {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(file), os.ModePerm); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(file, b.Bytes(), 0666)
+}
@@ -0,0 +1,72 @@
+// Copyright (c) Bartłomiej Płotka @bwplotka
+// Licensed under the Apache License 2.0.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/bwplotka/bingo/pkg/bingo"
+	"github.com/bwplotka/bingo/pkg/runner"
+	"github.com/efficientgo/tools/core/pkg/testutil"
+)
+
+func TestReport(t *testing.T) {
+	// Mod directory outside of git repository, so modification times are used.
+	modDir, srcDir := t.TempDir(), t.TempDir()
+	testutil.Ok(t, ioutil.WriteFile(filepath.Join(srcDir, "LICENSE"), []byte("Permission is hereby granted, free of charge"), os.ModePerm))
+
+	fakeGo := runner.ExecutorFunc(func(_ context.Context, output io.Writer, _ []string, _, _ string, args ...string) error {
+		switch args[0] {
+		case "version":
+			_, err := io.WriteString(output, "go version go1.21.0 linux/amd64\n")
+			return err
+		case "list":
+			testutil.Equals(t, "-modfile="+filepath.Join(modDir, "faillint.1.mod"), args[1])
+			_, err := io.WriteString(output, srcDir+"\n")
+			return err
+		}
+		return nil
+	})
+	ctx := context.Background()
+	r, err := runner.NewRunnerWithExecutor(ctx, nil, false, "go", fakeGo)
+	testutil.Ok(t, err)
+
+	rows, err := collectReport(ctx, r, modDir, bingo.PackageRenderables{{
+		Name:        "faillint",
+		ModPath:     "github.com/fatih/faillint",
+		Description: "Report | unwanted imports",
+		Versions: []bingo.PackageVersionRenderable{
+			{Version: "v1.10.0", ModFile: "faillint.1.mod", ModTime: time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)},
+			{Version: "v1.5.0", ModFile: "faillint.mod", ModTime: time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)},
+		},
+	}})
+	testutil.Ok(t, err)
+	testutil.Equals(t, []reportRow{{
+		Name: "faillint", Description: "Report | unwanted imports", Versions: []string{"v1.5.0", "v1.10.0"},
+		Module: "github.com/fatih/faillint", License: "MIT", Updated: "2024-05-01",
+	}}, rows)
+
+	b := &bytes.Buffer{}
+	testutil.Ok(t, writeReport(b, rows, markdownReportFormat))
+	testutil.Equals(t, `<!-- Generated by bingo report. DO NOT EDIT. -->
+
+| Name | Description | Version | Module | License | Updated |
+|------|-------------|---------|--------|---------|---------|
+| faillint | Report \| unwanted imports | v1.5.0, v1.10.0 | github.com/fatih/faillint | MIT | 2024-05-01 |
+`, b.String())
+
+	rows[0].Description = "<b>linter</b>"
+	f := filepath.Join(t.TempDir(), "docs", "tools.html")
+	testutil.Ok(t, writeReportFile(f, rows, reportFormat(f)))
+	got, err := ioutil.ReadFile(f)
+	testutil.Ok(t, err)
+	testutil.Assert(t, bytes.Contains(got, []byte("<tr><td>faillint</td><td>&lt;b&gt;linter&lt;/b&gt;</td><td>v1.5.0, v1.10.0</td>")), string(got))
+}
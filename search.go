@@ -0,0 +1,112 @@
+// Copyright (c) Bartłomiej Płotka @bwplotka
+// Licensed under the Apache License 2.0.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/efficientgo/tools/core/pkg/errcapture"
+	"github.com/pkg/errors"
+)
+
+// defaultSearchURL is a base URL of pkg.go.dev API used by bingo search.
+const defaultSearchURL = "https://pkg.go.dev"
+
+// searchResult is a main package found by bingo search.
+type searchResult struct {
+	PackagePath string `json:"packagePath"`
+	ModulePath  string `json:"modulePath"`
+	// Version is the latest version of the module known to pkg.go.dev.
+	Version    string `json:"version"`
+	Synopsis   string `json:"synopsis,omitempty"`
+	ImportedBy int    `json:"importedBy"`
+}
+
+// searchResponse is a response of pkg.go.dev search API.
+type searchResponse struct {
+	Results []struct {
+		Name          string `json:"name"`
+		PackagePath   string `json:"packagePath"`
+		ModulePath    string `json:"modulePath"`
+		Version       string `json:"version"`
+		Synopsis      string `json:"synopsis"`
+		NumImportedBy int    `json:"numImportedBy"`
+	} `json:"results"`
+}
+
+// searchMainPackages queries pkg.go.dev API at given base URL for packages matching given term and returns up to limit
+// main ones, so tools that can be pinned.
+func searchMainPackages(ctx context.Context, client *http.Client, baseURL, term string, limit int) (_ []searchResult, err error) {
+	q := url.Values{}
+	q.Set("q", term)
+	// Ask for more, as only main packages are returned.
+	q.Set("limit", strconv.Itoa(limit*5))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSuffix(baseURL, "/")+"/v1/search?"+q.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, errors.Wrap(err, "query pkg.go.dev")
+	}
+	defer errcapture.Do(&err, resp.Body.Close, "close response body")
+
+	if resp.StatusCode != http.StatusOK {
+		b, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, errors.Errorf("query pkg.go.dev: unexpected status %s: %s", resp.Status, strings.TrimSpace(string(b)))
+	}
+	var r searchResponse
+	if err := json.NewDecoder(resp.Body).Decode(&r); err != nil {
+		return nil, errors.Wrap(err, "parse pkg.go.dev response")
+	}
+
+	results := []searchResult{}
+	for _, p := range r.Results {
+		if p.Name != "main" {
+			continue
+		}
+		results = append(results, searchResult{
+			PackagePath: p.PackagePath, ModulePath: p.ModulePath, Version: p.Version, Synopsis: p.Synopsis, ImportedBy: p.NumImportedBy,
+		})
+		if len(results) == limit {
+			break
+		}
+	}
+	return results, nil
+}
+
+// printSearchResults prints found packages as aligned table or, if asJSON is true, as JSON.
+func printSearchResults(w io.Writer, results []searchResult, asJSON bool) error {
+	if asJSON {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(results)
+	}
+	if len(results) == 0 {
+		_, err := fmt.Fprintln(w, "No main packages found.")
+		return err
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "Package\tModule\tLatest\tImported By")
+	_, _ = fmt.Fprintln(tw, "-------\t------\t------\t-----------")
+	for _, r := range results {
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\t%d\n", r.PackagePath, r.ModulePath, r.Version, r.ImportedBy)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintf(w, "\nPin one with: bingo get %s@%s\n", results[0].PackagePath, results[0].Version)
+	return err
+}
@@ -0,0 +1,57 @@
+// Copyright (c) Bartłomiej Płotka @bwplotka
+// Licensed under the Apache License 2.0.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/efficientgo/tools/core/pkg/testutil"
+)
+
+func TestSearchMainPackages(t *testing.T) {
+	var query string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		testutil.Equals(t, "/v1/search", r.URL.Path)
+		query = r.URL.Query().Get("q")
+		_, _ = w.Write([]byte(`{"results": [
+			{"name": "faillint", "packagePath": "github.com/fatih/faillint/faillint", "modulePath": "github.com/fatih/faillint", "version": "v1.15.0", "numImportedBy": 3},
+			{"name": "main", "packagePath": "github.com/fatih/faillint", "modulePath": "github.com/fatih/faillint", "version": "v1.15.0", "synopsis": "Report unwanted import path.", "numImportedBy": 0},
+			{"name": "main", "packagePath": "github.com/golangci/golangci-lint/cmd/golangci-lint", "modulePath": "github.com/golangci/golangci-lint", "version": "v1.64.8", "numImportedBy": 12}
+		]}`))
+	}))
+	defer srv.Close()
+
+	results, err := searchMainPackages(context.Background(), srv.Client(), srv.URL+"/", "import lint", 1)
+	testutil.Ok(t, err)
+	testutil.Equals(t, "import lint", query)
+	testutil.Equals(t, []searchResult{
+		{PackagePath: "github.com/fatih/faillint", ModulePath: "github.com/fatih/faillint", Version: "v1.15.0", Synopsis: "Report unwanted import path."},
+	}, results)
+
+	results, err = searchMainPackages(context.Background(), srv.Client(), srv.URL, "import lint", 10)
+	testutil.Ok(t, err)
+	testutil.Equals(t, 2, len(results))
+
+	b := &bytes.Buffer{}
+	testutil.Ok(t, printSearchResults(b, results, false))
+	testutil.Equals(t, `Package                                              Module                             Latest   Imported By
+-------                                              ------                             ------   -----------
+github.com/fatih/faillint                            github.com/fatih/faillint          v1.15.0  0
+github.com/golangci/golangci-lint/cmd/golangci-lint  github.com/golangci/golangci-lint  v1.64.8  12
+
+Pin one with: bingo get github.com/fatih/faillint@v1.15.0
+`, b.String())
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "rate limited", http.StatusTooManyRequests)
+	}))
+	defer failing.Close()
+	_, err = searchMainPackages(context.Background(), failing.Client(), failing.URL, "lint", 10)
+	testutil.NotOk(t, err)
+	testutil.Equals(t, "query pkg.go.dev: unexpected status 429 Too Many Requests: rate limited", err.Error())
+}
@@ -0,0 +1,59 @@
+// Copyright (c) Bartłomiej Płotka @bwplotka
+// Licensed under the Apache License 2.0.
+
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/module"
+)
+
+// findModuleRoot returns the root directory (the closest one with go.mod) and path of the module containing given directory.
+func findModuleRoot(dir string) (root, modPath string, _ error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", "", err
+	}
+	for d := dir; ; d = filepath.Dir(d) {
+		b, err := ioutil.ReadFile(filepath.Join(d, "go.mod"))
+		if err == nil {
+			if modPath = modfile.ModulePath(b); modPath == "" {
+				return "", "", errors.Errorf("no module path in %v", filepath.Join(d, "go.mod"))
+			}
+			return d, modPath, nil
+		}
+		if !os.IsNotExist(err) {
+			return "", "", err
+		}
+		if filepath.Dir(d) == d {
+			return "", "", errors.Errorf("no go.mod found in %v or any parent directory", dir)
+		}
+	}
+}
+
+// selfTarget returns given target of the tool that lives in the current repository (module containing given directory)
+// and replace of that module with its root directory, so the tool is always built from the local checkout. Target is
+// either package path within the module or path relative to the module root (e.g ./cmd/tool).
+func selfTarget(dir, target string) (string, *modfile.Replace, error) {
+	if strings.Contains(target, "@") {
+		return "", nil, errors.Errorf("%v: version cannot be set for tool built from the current repository", target)
+	}
+	root, modPath, err := findModuleRoot(dir)
+	if err != nil {
+		return "", nil, err
+	}
+	if target == "." || strings.HasPrefix(target, "./") {
+		target = path.Join(modPath, target)
+	}
+	if target != modPath && !strings.HasPrefix(target, modPath+"/") {
+		return "", nil, errors.Errorf("%v is not a package of the current repository module %v (%v)", target, modPath, root)
+	}
+	return target, &modfile.Replace{Old: module.Version{Path: modPath}, New: module.Version{Path: root}}, nil
+}
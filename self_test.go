@@ -0,0 +1,37 @@
+// Copyright (c) Bartłomiej Płotka @bwplotka
+// Licensed under the Apache License 2.0.
+
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/efficientgo/tools/core/pkg/testutil"
+)
+
+func TestSelfTarget(t *testing.T) {
+	root := t.TempDir()
+	testutil.Ok(t, ioutil.WriteFile(filepath.Join(root, "go.mod"), []byte("module github.com/myorg/myrepo\n\ngo 1.21\n"), os.ModePerm))
+	sub := filepath.Join(root, "cmd", "mytool")
+	testutil.Ok(t, os.MkdirAll(sub, os.ModePerm))
+
+	for _, dir := range []string{root, sub} {
+		target, r, err := selfTarget(dir, "github.com/myorg/myrepo/cmd/mytool")
+		testutil.Ok(t, err)
+		testutil.Equals(t, "github.com/myorg/myrepo/cmd/mytool", target)
+		testutil.Equals(t, "github.com/myorg/myrepo", r.Old.Path)
+		testutil.Equals(t, root, r.New.Path)
+	}
+
+	target, _, err := selfTarget(sub, "./cmd/mytool")
+	testutil.Ok(t, err)
+	testutil.Equals(t, "github.com/myorg/myrepo/cmd/mytool", target)
+
+	_, _, err = selfTarget(root, "github.com/myorg/other/cmd/mytool")
+	testutil.NotOk(t, err)
+	_, _, err = selfTarget(root, "github.com/myorg/myrepo/cmd/mytool@v1.0.0")
+	testutil.NotOk(t, err)
+}
@@ -0,0 +1,67 @@
+// Copyright (c) Bartłomiej Płotka @bwplotka
+// Licensed under the Apache License 2.0.
+
+package main
+
+import (
+	"context"
+	"io/ioutil"
+	"log/slog"
+	"os"
+	"path/filepath"
+
+	"github.com/bwplotka/bingo/internal/getter"
+	"github.com/bwplotka/bingo/pkg/bingo"
+	"github.com/bwplotka/bingo/pkg/runner"
+	"github.com/bwplotka/bingo/pkg/version"
+	"github.com/efficientgo/tools/core/pkg/errcapture"
+	"github.com/pkg/errors"
+)
+
+const bingoModulePath = "github.com/bwplotka/bingo"
+
+// selfUpdate installs given (or latest if empty) bingo version into GOBIN as bingo-<version> binary and links bingo to it,
+// the same way bingo get installs tools. Module is fetched by go, so its checksum is verified against checksum database
+// (unless disabled, e.g. with GOSUMDB=off).
+func selfUpdate(ctx context.Context, logger *slog.Logger, r *runner.Runner, ver string) (err error) {
+	target := bingoModulePath
+	if ver != "" && ver != "latest" {
+		target += "@" + ver
+	}
+
+	// Pin bingo in temporary mod dir, so project's pinned tools are not touched.
+	tmpModDir, err := ioutil.TempDir("", "bingo-self-update")
+	if err != nil {
+		return errors.Wrap(err, "create tmp mod dir")
+	}
+	defer errcapture.Do(&err, func() error { return os.RemoveAll(tmpModDir) }, "remove tmp mod dir")
+
+	c := getter.Config{Runner: r, ModDir: tmpModDir, RelModDir: tmpModDir, Link: true}
+	if err := getter.Get(ctx, logger, c, target); err != nil {
+		return errors.Wrapf(err, "get %s", target)
+	}
+
+	mf, err := bingo.ReadModFile(filepath.Join(tmpModDir, "bingo.mod"))
+	if err != nil {
+		return errors.Wrap(err, "read installed bingo mod file")
+	}
+
+	installed := mf.DirectPackage().Module.Version
+	binPath := filepath.Join(getter.GOBIN(), "bingo"+bingo.GOEXE())
+	if installed == version.Version {
+		logger.Info("bingo is up to date", "version", installed, "path", binPath)
+	} else {
+		logger.Info("bingo updated", "version", getter.VersionChange{From: version.Version, To: installed}, "path", binPath)
+	}
+
+	// Compare resolved paths, as bingo in GOBIN is a link to versioned binary.
+	exe, exeErr := os.Executable()
+	if exeErr == nil {
+		exe, exeErr = filepath.EvalSymlinks(exe)
+	}
+	linked, linkedErr := filepath.EvalSymlinks(binPath)
+	if exeErr == nil && linkedErr == nil && filepath.Dir(exe) != filepath.Dir(linked) {
+		logger.Warn("running bingo is not installed in GOBIN; make sure GOBIN is in PATH before it", "running", exe)
+	}
+	return nil
+}
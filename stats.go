@@ -0,0 +1,108 @@
+// Copyright (c) Bartłomiej Płotka @bwplotka
+// Licensed under the Apache License 2.0.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+	"time"
+
+	"github.com/bwplotka/bingo/pkg/bingo"
+	"github.com/pkg/errors"
+)
+
+// toolStats describes installed binary of pinned tool version, printed by bingo stats.
+type toolStats struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+	// SizeBytes is size of the installed binary. Nil if it's not installed.
+	SizeBytes *int64 `json:"sizeBytes,omitempty"`
+	// BuildSeconds and Dependencies describe the last build of the binary on this machine (see bingo.BuildStats).
+	// Nil if not recorded.
+	BuildSeconds *float64   `json:"buildSeconds,omitempty"`
+	Dependencies *int       `json:"dependencies,omitempty"`
+	BuiltAt      *time.Time `json:"builtAt,omitempty"`
+}
+
+// collectStats returns stats of all versions of given tools, installed in given gobin directory.
+func collectStats(modDir, gobin string, pkgs bingo.PackageRenderables) ([]toolStats, error) {
+	var stats []toolStats
+	for _, p := range pkgs {
+		for _, v := range p.Versions {
+			s := toolStats{Name: p.Name, Version: v.Version}
+			st, err := os.Stat(filepath.Join(p.InstallDir(gobin), p.ArtifactName(v.Version)))
+			if err == nil {
+				size := st.Size()
+				s.SizeBytes = &size
+			} else if !os.IsNotExist(err) {
+				return nil, errors.Wrapf(err, "stat binary of %s", p.Name)
+			}
+
+			b, err := bingo.ReadBuildStats(modDir, p.Name, v.Version)
+			if err != nil {
+				return nil, err
+			}
+			if b != nil {
+				secs := b.Duration.Seconds()
+				s.BuildSeconds = &secs
+				s.Dependencies = &b.Dependencies
+				s.BuiltAt = &b.BuiltAt
+			}
+			stats = append(stats, s)
+		}
+	}
+	return stats, nil
+}
+
+// printStats prints stats as aligned table with total size of installed binaries or, if asJSON is true, as JSON.
+func printStats(w io.Writer, stats []toolStats, asJSON bool) error {
+	if asJSON {
+		if stats == nil {
+			stats = []toolStats{}
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(stats)
+	}
+
+	var total int64
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "Name\tVersion\tSize\tBuild Time\tDependencies")
+	_, _ = fmt.Fprintln(tw, "----\t-------\t----\t----------\t------------")
+	for _, s := range stats {
+		size, build, deps := "not installed", "-", "-"
+		if s.SizeBytes != nil {
+			size = formatBytes(*s.SizeBytes)
+			total += *s.SizeBytes
+		}
+		if s.BuildSeconds != nil {
+			build = (time.Duration(*s.BuildSeconds * float64(time.Second))).Round(100 * time.Millisecond).String()
+			deps = fmt.Sprintf("%d", *s.Dependencies)
+		}
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", s.Name, s.Version, size, build, deps)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintf(w, "\nTotal size of installed binaries: %s\n", formatBytes(total))
+	return err
+}
+
+// formatBytes returns given number of bytes in human readable form, e.g "12.3 MiB".
+func formatBytes(b int64) string {
+	const unit = 1024
+	if b < unit {
+		return fmt.Sprintf("%d B", b)
+	}
+	div, exp := int64(unit), 0
+	for n := b / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(b)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,54 @@
+// Copyright (c) Bartłomiej Płotka @bwplotka
+// Licensed under the Apache License 2.0.
+
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/bwplotka/bingo/pkg/bingo"
+	"github.com/efficientgo/tools/core/pkg/testutil"
+)
+
+func TestCollectAndPrintStats(t *testing.T) {
+	modDir := t.TempDir()
+	gobin := t.TempDir()
+	testutil.Ok(t, ioutil.WriteFile(filepath.Join(gobin, bingo.BinaryName("buf", "v0.2.0")), make([]byte, 3*1024*1024), os.ModePerm))
+	testutil.Ok(t, ioutil.WriteFile(filepath.Join(gobin, bingo.BinaryName("faillint", "v1.5.0")), make([]byte, 512), os.ModePerm))
+	builtAt := time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC)
+	testutil.Ok(t, bingo.WriteBuildStats(modDir, "buf", "v0.2.0", bingo.BuildStats{Duration: 12340 * time.Millisecond, Dependencies: 42, BuiltAt: builtAt}))
+
+	pkgs := bingo.PackageRenderables{
+		{Name: "buf", Versions: []bingo.PackageVersionRenderable{{Version: "v0.1.0"}, {Version: "v0.2.0"}}},
+		{Name: "faillint", Versions: []bingo.PackageVersionRenderable{{Version: "v1.5.0"}}},
+	}
+	stats, err := collectStats(modDir, gobin, pkgs)
+	testutil.Ok(t, err)
+	testutil.Equals(t, 3, len(stats))
+	testutil.Assert(t, stats[0].SizeBytes == nil && stats[0].BuildSeconds == nil, "buf v0.1.0 is not installed nor built")
+	testutil.Equals(t, int64(3*1024*1024), *stats[1].SizeBytes)
+	testutil.Equals(t, 12.34, *stats[1].BuildSeconds)
+	testutil.Equals(t, 42, *stats[1].Dependencies)
+	testutil.Equals(t, builtAt, *stats[1].BuiltAt)
+	testutil.Assert(t, stats[2].BuildSeconds == nil, "faillint build is not recorded")
+
+	b := &bytes.Buffer{}
+	testutil.Ok(t, printStats(b, stats, false))
+	testutil.Equals(t, `Name      Version  Size           Build Time  Dependencies
+----      -------  ----           ----------  ------------
+buf       v0.1.0   not installed  -           -
+buf       v0.2.0   3.0 MiB        12.3s       42
+faillint  v1.5.0   512 B          -           -
+
+Total size of installed binaries: 3.0 MiB
+`, b.String())
+
+	b.Reset()
+	testutil.Ok(t, printStats(b, nil, true))
+	testutil.Equals(t, "[]\n", b.String())
+}
@@ -0,0 +1,148 @@
+// Copyright (c) Bartłomiej Płotka @bwplotka
+// Licensed under the Apache License 2.0.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/bwplotka/bingo/pkg/bingo"
+	"github.com/bwplotka/bingo/pkg/runner"
+	"github.com/pkg/errors"
+	"golang.org/x/mod/semver"
+)
+
+// listSummary is a summary of pinned tools printed by list -summary, for mod directories with too many tools to list.
+type listSummary struct {
+	Tools          int `json:"tools"`
+	PinnedVersions int `json:"pinnedVersions"`
+	ArrayTools     int `json:"arrayTools"`
+	FrozenTools    int `json:"frozenTools"`
+	// OutdatedTools is a number of tools with newer module version than the latest pinned one. Nil if not checked.
+	OutdatedTools *int `json:"outdatedTools,omitempty"`
+
+	// ByModuleHost and ByLabel are numbers of tools by module host (first element of module path, e.g github.com)
+	// and by label (e.g team=platform).
+	ByModuleHost map[string]int `json:"byModuleHost"`
+	ByLabel      map[string]int `json:"byLabel,omitempty"`
+}
+
+func summarize(pkgs bingo.PackageRenderables) listSummary {
+	s := listSummary{Tools: len(pkgs), ByModuleHost: map[string]int{}}
+	for _, p := range pkgs {
+		s.PinnedVersions += len(p.Versions)
+		if len(p.Versions) > 1 {
+			s.ArrayTools++
+		}
+		if p.Frozen {
+			s.FrozenTools++
+		}
+		host := strings.Split(p.ModPath, "/")[0]
+		if host == "" {
+			host = "unknown"
+		}
+		s.ByModuleHost[host]++
+		for k, v := range p.Labels {
+			if s.ByLabel == nil {
+				s.ByLabel = map[string]int{}
+			}
+			s.ByLabel[k+"="+v]++
+		}
+	}
+	return s
+}
+
+// countOutdated returns number of given tools with newer module version than the latest pinned one. If registry is not
+// nil, only newer versions it approves are considered for tools it lists. It requires network access, unless modules
+// are cached.
+func countOutdated(ctx context.Context, r *runner.Runner, modDir string, pkgs bingo.PackageRenderables, registry *bingo.Registry) (int, error) {
+	outdated := 0
+	for _, p := range pkgs {
+		latest := p.Versions[0]
+		for _, v := range p.Versions[1:] {
+			if semver.Compare(v.Version, latest.Version) > 0 {
+				latest = v
+			}
+		}
+		var (
+			update string
+			err    error
+		)
+		if entry, ok := registryLookup(registry, p); ok {
+			update, err = approvedUpdate(ctx, r, modDir, latest.ModFile, p.ModPath, latest.Version, entry)
+		} else {
+			update, err = moduleUpdate(ctx, r, modDir, latest.ModFile, p.ModPath)
+		}
+		if err != nil {
+			return 0, errors.Wrapf(err, "check updates of %s", p.Name)
+		}
+		if update != "" {
+			outdated++
+		}
+	}
+	return outdated, nil
+}
+
+// print prints summary as aligned text or, if asJSON is true, as JSON.
+func (s listSummary) print(w io.Writer, asJSON bool) error {
+	if asJSON {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(s)
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintf(tw, "Tools\t%d\n", s.Tools)
+	_, _ = fmt.Fprintf(tw, "Pinned versions\t%d\n", s.PinnedVersions)
+	_, _ = fmt.Fprintf(tw, "Array tools\t%d\n", s.ArrayTools)
+	_, _ = fmt.Fprintf(tw, "Frozen tools\t%d\n", s.FrozenTools)
+	if s.OutdatedTools != nil {
+		_, _ = fmt.Fprintf(tw, "Outdated tools\t%d\n", *s.OutdatedTools)
+	}
+	printCounts(tw, "Module Host", s.ByModuleHost)
+	printCounts(tw, "Label", s.ByLabel)
+	return tw.Flush()
+}
+
+// printCounts prints table of counts with given key header, most common first.
+func printCounts(w io.Writer, header string, counts map[string]int) {
+	if len(counts) == 0 {
+		return
+	}
+	keys := make([]string, 0, len(counts))
+	for k := range counts {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if counts[keys[i]] != counts[keys[j]] {
+			return counts[keys[i]] > counts[keys[j]]
+		}
+		return keys[i] < keys[j]
+	})
+	_, _ = fmt.Fprintf(w, "\n%s\tTools\n%s\t-----\n", header, strings.Repeat("-", len(header)))
+	for _, k := range keys {
+		_, _ = fmt.Fprintf(w, "%s\t%d\n", k, counts[k])
+	}
+}
+
+// printPageFooter prints which tools are shown on given page of list output, out of all, and how to see more.
+func printPageFooter(w io.Writer, limit, page, shown, all int) error {
+	if shown == 0 {
+		_, err := fmt.Fprintf(w, "\nNo tools on page %d of %d tools.\n", page, all)
+		return err
+	}
+	first := (page-1)*limit + 1
+	last := first + shown - 1
+	if last < all {
+		_, err := fmt.Fprintf(w, "\nShowing %d-%d of %d tools; use -page %d to see more.\n", first, last, all, page+1)
+		return err
+	}
+	_, err := fmt.Fprintf(w, "\nShowing %d-%d of %d tools.\n", first, last, all)
+	return err
+}
@@ -0,0 +1,64 @@
+// Copyright (c) Bartłomiej Płotka @bwplotka
+// Licensed under the Apache License 2.0.
+
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/bwplotka/bingo/pkg/bingo"
+	"github.com/efficientgo/tools/core/pkg/testutil"
+)
+
+func TestListSummary(t *testing.T) {
+	pkgs := bingo.PackageRenderables{
+		{Name: "buf", ModPath: "github.com/bufbuild/buf", Versions: []bingo.PackageVersionRenderable{{Version: "v0.1.0"}, {Version: "v0.2.0"}}, Labels: bingo.Labels{"team": "api"}},
+		{Name: "faillint", ModPath: "github.com/fatih/faillint", Versions: []bingo.PackageVersionRenderable{{Version: "v1.5.0"}}, Frozen: true},
+		{Name: "goimports", ModPath: "golang.org/x/tools", Versions: []bingo.PackageVersionRenderable{{Version: "v0.1.0"}}, Labels: bingo.Labels{"team": "api"}},
+	}
+	s := summarize(pkgs)
+	testutil.Equals(t, listSummary{
+		Tools: 3, PinnedVersions: 4, ArrayTools: 1, FrozenTools: 1,
+		ByModuleHost: map[string]int{"github.com": 2, "golang.org": 1},
+		ByLabel:      map[string]int{"team=api": 2},
+	}, s)
+
+	outdated := 1
+	s.OutdatedTools = &outdated
+	b := &bytes.Buffer{}
+	testutil.Ok(t, s.print(b, false))
+	testutil.Equals(t, `Tools            3
+Pinned versions  4
+Array tools      1
+Frozen tools     1
+Outdated tools   1
+
+Module Host  Tools
+-----------  -----
+github.com   2
+golang.org   1
+
+Label     Tools
+-----     -----
+team=api  2
+`, b.String())
+
+	b.Reset()
+	testutil.Ok(t, summarize(nil).print(b, true))
+	testutil.Equals(t, "{\n  \"tools\": 0,\n  \"pinnedVersions\": 0,\n  \"arrayTools\": 0,\n  \"frozenTools\": 0,\n  \"byModuleHost\": {}\n}\n", b.String())
+}
+
+func TestPrintPageFooter(t *testing.T) {
+	b := &bytes.Buffer{}
+	testutil.Ok(t, printPageFooter(b, 10, 2, 10, 25))
+	testutil.Equals(t, "\nShowing 11-20 of 25 tools; use -page 3 to see more.\n", b.String())
+
+	b.Reset()
+	testutil.Ok(t, printPageFooter(b, 10, 3, 5, 25))
+	testutil.Equals(t, "\nShowing 21-25 of 25 tools.\n", b.String())
+
+	b.Reset()
+	testutil.Ok(t, printPageFooter(b, 10, 4, 0, 25))
+	testutil.Equals(t, "\nNo tools on page 4 of 25 tools.\n", b.String())
+}
@@ -0,0 +1,397 @@
+// Copyright (c) Bartłomiej Płotka @bwplotka
+// Licensed under the Apache License 2.0.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/bwplotka/bingo/internal/getter"
+	"github.com/bwplotka/bingo/pkg/bingo"
+	"github.com/bwplotka/bingo/pkg/runner"
+	"github.com/efficientgo/tools/core/pkg/errcapture"
+	"github.com/pkg/errors"
+	"golang.org/x/term"
+)
+
+// uiRow is a single pinned version of a tool shown in bingo ui.
+type uiRow struct {
+	name     string
+	modPath  string
+	modFile  string
+	version  string
+	versions int
+	// installed is true if pinned binary exists in GOBIN.
+	installed bool
+	// update is a newer version of the module, if found while checking for updates.
+	update string
+	// vulns are IDs of known vulnerabilities of the pinned module version, if found while checking for updates.
+	vulns []string
+}
+
+// uiModel is a state of bingo ui, independent of the terminal.
+type uiModel struct {
+	rows    []uiRow
+	cursor  int
+	checked bool
+	message string
+	// deleting is true if delete of the tool under cursor awaits confirmation.
+	deleting bool
+}
+
+// UI actions returned by uiModel.handle.
+const (
+	uiNoAction = ""
+	uiQuit     = "quit"
+	uiUpgrade  = "upgrade"
+	uiRebuild  = "rebuild"
+	uiDelete   = "delete"
+	uiCheck    = "check"
+)
+
+// uiKey returns name of the key read from terminal in raw mode.
+func uiKey(b []byte) string {
+	switch string(b) {
+	case "\x1b[A", "k":
+		return "up"
+	case "\x1b[B", "j":
+		return "down"
+	case "\x03", "\x1b":
+		return "q"
+	}
+	return string(b)
+}
+
+// handle updates model for given key and returns action to perform, if any.
+func (m *uiModel) handle(key string) string {
+	if m.deleting {
+		m.deleting = false
+		if key == "y" {
+			return uiDelete
+		}
+		m.message = "Delete cancelled."
+		return uiNoAction
+	}
+
+	switch key {
+	case "q":
+		return uiQuit
+	case "up":
+		if m.cursor > 0 {
+			m.cursor--
+		}
+	case "down":
+		if m.cursor < len(m.rows)-1 {
+			m.cursor++
+		}
+	case "o":
+		return uiCheck
+	case "u", "r", "d":
+		if len(m.rows) == 0 {
+			return uiNoAction
+		}
+		row := m.rows[m.cursor]
+		switch key {
+		case "u":
+			if row.versions > 1 {
+				m.message = fmt.Sprintf("%s is pinned in many versions; upgrade it with 'bingo get %s@<version1>,<version2>'.", row.name, row.name)
+				return uiNoAction
+			}
+			return uiUpgrade
+		case "r":
+			return uiRebuild
+		case "d":
+			m.deleting = true
+			m.message = fmt.Sprintf("Delete all pinned versions of %s? [y/N]", row.name)
+		}
+	}
+	return uiNoAction
+}
+
+// selected returns name of the tool under cursor.
+func (m *uiModel) selected() string {
+	if len(m.rows) == 0 {
+		return ""
+	}
+	return m.rows[m.cursor].name
+}
+
+// setRows replaces rows, keeping the cursor on the same tool if possible.
+func (m *uiModel) setRows(rows []uiRow) {
+	name := m.selected()
+	m.rows = rows
+	m.cursor = 0
+	for i, r := range rows {
+		if r.name == name {
+			m.cursor = i
+			break
+		}
+	}
+	m.checked = false
+}
+
+// render returns screen content for terminal in raw mode.
+func (m *uiModel) render() string {
+	b := &bytes.Buffer{}
+	tw := tabwriter.NewWriter(b, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "  Name\tVersion\tInstalled\tUpdate\tVulnerabilities")
+	for i, r := range m.rows {
+		cursor := " "
+		if i == m.cursor {
+			cursor = ">"
+		}
+		installed := "yes"
+		if !r.installed {
+			installed = "no"
+		}
+		update, vulns := "-", "-"
+		if m.checked {
+			update = "up to date"
+			if r.update != "" {
+				update = "→ " + r.update
+			}
+			vulns = "none"
+			if len(r.vulns) > 0 {
+				vulns = "! " + strings.Join(r.vulns, ",")
+			}
+		}
+		_, _ = fmt.Fprintf(tw, "%s %s\t%s\t%s\t%s\t%s\n", cursor, r.name, r.version, installed, update, vulns)
+	}
+	_ = tw.Flush()
+	if len(m.rows) == 0 {
+		_, _ = fmt.Fprintln(b, "  No tools pinned. Use 'bingo get <package>' to pin one.")
+	}
+
+	_, _ = fmt.Fprintln(b)
+	_, _ = fmt.Fprintln(b, "↑/k ↓/j move  u upgrade  r rebuild  d delete  o check for updates and vulnerabilities  q quit")
+	if m.message != "" {
+		_, _ = fmt.Fprintln(b)
+		_, _ = fmt.Fprintln(b, m.message)
+	}
+	// Raw mode does not translate new lines to carriage return and line feed.
+	return strings.ReplaceAll(b.String(), "\n", "\r\n")
+}
+
+// uiRows returns rows for all pinned tools in given mod directory.
+func uiRows(logger *slog.Logger, modDir, gobinPath string) ([]uiRow, error) {
+	pkgs, err := bingo.ListPinnedMainPackages(logger, modDir, false)
+	if err != nil {
+		return nil, errors.Wrap(err, "list pinned")
+	}
+	var rows []uiRow
+	for _, p := range pkgs {
+		for _, v := range p.Versions {
+			_, err := os.Stat(filepath.Join(p.InstallDir(gobinPath), p.ArtifactName(v.Version)))
+			if err != nil && !os.IsNotExist(err) {
+				return nil, err
+			}
+			rows = append(rows, uiRow{
+				name:      p.Name,
+				modPath:   p.ModPath,
+				modFile:   v.ModFile,
+				version:   v.Version,
+				versions:  len(p.Versions),
+				installed: err == nil,
+			})
+		}
+	}
+	return rows, nil
+}
+
+// uiCheckUpdates sets newer module versions and known vulnerabilities of pinned ones, if any, on all rows. Vulnerabilities
+// are queried from OSV API at given base URL.
+func uiCheckUpdates(ctx context.Context, r *runner.Runner, client *http.Client, vulnURL, modDir string, rows []uiRow) error {
+	for i, row := range rows {
+		update, err := moduleUpdate(ctx, r, modDir, row.modFile, row.modPath)
+		if err != nil {
+			return errors.Wrapf(err, "check updates of %s", row.name)
+		}
+		rows[i].update = update
+
+		vulns, err := moduleVulns(ctx, client, vulnURL, row.modPath, row.version)
+		if err != nil {
+			return errors.Wrapf(err, "check vulnerabilities of %s", row.name)
+		}
+		rows[i].vulns = vulns
+	}
+	return nil
+}
+
+// defaultVulnURL is a base URL of OSV API (which includes Go vulnerability database) used by bingo ui.
+const defaultVulnURL = "https://api.osv.dev"
+
+// moduleVulns queries OSV API at given base URL for IDs of known vulnerabilities (e.g GO-2022-0001) of given module version.
+func moduleVulns(ctx context.Context, client *http.Client, baseURL, modPath, version string) (_ []string, err error) {
+	q, err := json.Marshal(map[string]interface{}{
+		"package": map[string]string{"name": modPath, "ecosystem": "Go"},
+		// OSV uses semantic versions without 'v' prefix.
+		"version": strings.TrimPrefix(version, "v"),
+	})
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimSuffix(baseURL, "/")+"/v1/query", bytes.NewReader(q))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, errors.Wrap(err, "query OSV")
+	}
+	defer errcapture.Do(&err, resp.Body.Close, "close response body")
+
+	if resp.StatusCode != http.StatusOK {
+		b, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, errors.Errorf("query OSV: unexpected status %s: %s", resp.Status, strings.TrimSpace(string(b)))
+	}
+	var r struct {
+		Vulns []struct {
+			ID string `json:"id"`
+		} `json:"vulns"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&r); err != nil {
+		return nil, errors.Wrap(err, "parse OSV response")
+	}
+	var ids []string
+	for _, v := range r.Vulns {
+		ids = append(ids, v.ID)
+	}
+	return ids, nil
+}
+
+// moduleUpdate returns newer version of given module pinned in given mod file, or empty string if there is none.
+// It requires network access, unless modules are cached.
+func moduleUpdate(ctx context.Context, r *runner.Runner, modDir, modFile, modPath string) (string, error) {
+	out, err := r.With(ctx, filepath.Join(modDir, modFile), modDir, nil).List(
+		runner.NoUpdatePolicy, "-m", "-u", "-f={{if .Update}}{{.Update.Version}}{{end}}", modPath,
+	)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(out), nil
+}
+
+// makeRaw puts given terminal into raw mode and returns function restoring previous mode.
+func makeRaw(in *os.File) (restore func() error, _ error) {
+	fd := int(in.Fd())
+	state, err := term.MakeRaw(fd)
+	if err != nil {
+		return nil, err
+	}
+	return func() error { return term.Restore(fd, state) }, nil
+}
+
+// uiApply performs given action on given tool the same way bingo get does, and regenerates helpers with given config and
+// variable name prefix and suffix.
+func uiApply(ctx context.Context, logger *slog.Logger, r *runner.Runner, modDir, relModDir string, helpersCfg bingo.HelpersConfig, varPrefix, varSuffix, action, name string) error {
+	cfg := getter.Config{Runner: r, ModDir: modDir, RelModDir: relModDir, Helpers: helpersCfg}
+	target := name
+	switch action {
+	case uiUpgrade:
+		cfg.Update = runner.UpdatePolicy
+	case uiDelete:
+		target = name + "@none"
+	}
+	if err := getter.Get(ctx, logger, cfg, target); err != nil {
+		return err
+	}
+	return getter.GenHelpers(logger, relModDir, helpersCfg, varPrefix, varSuffix)
+}
+
+// ui runs interactive terminal dashboard with all pinned tools and their updates and known vulnerabilities, allowing to upgrade, rebuild and delete them.
+// Changes are applied the same way as bingo get does, with helpers regenerated with given config (get options).
+func ui(ctx context.Context, r *runner.Runner, modDir, relModDir string, helpersCfg bingo.HelpersConfig, varPrefix, varSuffix string) (err error) {
+	in, out := os.Stdin, os.Stdout
+	if !term.IsTerminal(int(in.Fd())) || !term.IsTerminal(int(out.Fd())) {
+		return errors.New("ui requires interactive terminal")
+	}
+
+	// Logs would break the screen; keep them for messages instead.
+	logs := &bytes.Buffer{}
+	logger := newLogger(logs, textLogFormat, slog.LevelInfo, false)
+
+	m := &uiModel{}
+	rows, err := uiRows(logger, modDir, helpersCfg.GOBIN)
+	if err != nil {
+		return err
+	}
+	m.setRows(rows)
+
+	restore, err := makeRaw(in)
+	if err != nil {
+		return errors.Wrap(err, "make raw terminal")
+	}
+	defer errcapture.Do(&err, restore, "restore terminal")
+
+	draw := func() error {
+		_, err := fmt.Fprint(out, "\033[H\033[2J"+m.render())
+		return err
+	}
+
+	buf := make([]byte, 8)
+	for {
+		if err := draw(); err != nil {
+			return err
+		}
+		n, err := in.Read(buf)
+		if err != nil {
+			return errors.Wrap(err, "read key")
+		}
+
+		action := m.handle(uiKey(buf[:n]))
+		switch action {
+		case uiNoAction:
+			continue
+		case uiQuit:
+			_, err := fmt.Fprint(out, "\033[H\033[2J")
+			return err
+		case uiCheck:
+			m.message = "Checking for updates and vulnerabilities..."
+			if err := draw(); err != nil {
+				return err
+			}
+			m.message = "Checked for updates."
+			if err := uiCheckUpdates(ctx, r, http.DefaultClient, defaultVulnURL, modDir, m.rows); err != nil {
+				m.message = "Error: " + err.Error()
+				continue
+			}
+			m.checked = true
+			continue
+		}
+
+		name := m.selected()
+		m.message = fmt.Sprintf("Running %s of %s...", action, name)
+		if err := draw(); err != nil {
+			return err
+		}
+
+		logs.Reset()
+		m.message = fmt.Sprintf("Finished %s of %s.", action, name)
+		if err := uiApply(ctx, logger, r, modDir, relModDir, helpersCfg, varPrefix, varSuffix, action, name); err != nil {
+			m.message = "Error: " + err.Error()
+		} else if l := strings.TrimSpace(logs.String()); l != "" {
+			m.message += "\n" + l
+		}
+		if err := getter.CleanTmpFiles(modDir); err != nil {
+			return err
+		}
+
+		rows, err := uiRows(logger, modDir, helpersCfg.GOBIN)
+		if err != nil {
+			return err
+		}
+		m.setRows(rows)
+	}
+}
@@ -0,0 +1,133 @@
+// Copyright (c) Bartłomiej Płotka @bwplotka
+// Licensed under the Apache License 2.0.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/bwplotka/bingo/pkg/bingo"
+	"github.com/efficientgo/tools/core/pkg/testutil"
+)
+
+func TestUIModel(t *testing.T) {
+	m := &uiModel{}
+	m.setRows([]uiRow{
+		{name: "buf", version: "v0.1.0", versions: 2, installed: true},
+		{name: "buf", version: "v0.2.0", versions: 2},
+		{name: "faillint", version: "v1.4.0", versions: 1, installed: true, update: "v1.5.0", vulns: []string{"GO-2022-0001", "GO-2023-0002"}},
+	})
+
+	testutil.Equals(t, "up", uiKey([]byte("\x1b[A")))
+	testutil.Equals(t, "down", uiKey([]byte("j")))
+	testutil.Equals(t, "q", uiKey([]byte("\x03")))
+
+	testutil.Equals(t, uiNoAction, m.handle("up"))
+	testutil.Equals(t, 0, m.cursor)
+	testutil.Equals(t, uiNoAction, m.handle("u"))
+	testutil.Assert(t, strings.HasPrefix(m.message, "buf is pinned in many versions"), m.message)
+
+	testutil.Equals(t, uiNoAction, m.handle("down"))
+	testutil.Equals(t, uiNoAction, m.handle("down"))
+	testutil.Equals(t, uiNoAction, m.handle("down"))
+	testutil.Equals(t, 2, m.cursor)
+	testutil.Equals(t, uiUpgrade, m.handle("u"))
+	testutil.Equals(t, uiRebuild, m.handle("r"))
+	testutil.Equals(t, uiCheck, m.handle("o"))
+
+	testutil.Equals(t, uiNoAction, m.handle("d"))
+	testutil.Equals(t, "Delete all pinned versions of faillint? [y/N]", m.message)
+	testutil.Equals(t, uiNoAction, m.handle("n"))
+	testutil.Equals(t, "Delete cancelled.", m.message)
+	testutil.Equals(t, uiNoAction, m.handle("d"))
+	testutil.Equals(t, uiDelete, m.handle("y"))
+	testutil.Equals(t, uiQuit, m.handle("q"))
+
+	m.message = ""
+	m.checked = true
+	testutil.Equals(t, "  Name      Version  Installed  Update      Vulnerabilities\r\n"+
+		"  buf       v0.1.0   yes        up to date  none\r\n"+
+		"  buf       v0.2.0   no         up to date  none\r\n"+
+		"> faillint  v1.4.0   yes        → v1.5.0    ! GO-2022-0001,GO-2023-0002\r\n"+
+		"\r\n"+
+		"↑/k ↓/j move  u upgrade  r rebuild  d delete  o check for updates and vulnerabilities  q quit\r\n", m.render())
+
+	// Cursor stays on the same tool after reload.
+	m.setRows([]uiRow{
+		{name: "faillint", version: "v1.5.0", versions: 1, installed: true},
+		{name: "goimports", version: "v0.1.0", versions: 1},
+	})
+	testutil.Equals(t, 0, m.cursor)
+	testutil.Equals(t, false, m.checked)
+}
+
+func TestUIApply_KeepsHelpersConfig(t *testing.T) {
+	modDir := t.TempDir()
+	testutil.Ok(t, ioutil.WriteFile(filepath.Join(modDir, "faillint.mod"), []byte("module _\n\nrequire github.com/fatih/faillint v1.5.0\n"), os.ModePerm))
+	testutil.Ok(t, ioutil.WriteFile(filepath.Join(modDir, "goimports.mod"), []byte("module _\n\nrequire golang.org/x/tools v0.1.5 // cmd/goimports\n"), os.ModePerm))
+	logger := slog.New(slog.NewTextHandler(ioutil.Discard, nil))
+
+	goOut := filepath.Join(t.TempDir(), "tools.go")
+	helpersCfg := bingo.HelpersConfig{
+		BinPathMode: bingo.GOBINBinPathMode,
+		GOBIN:       t.TempDir(),
+		GoPackage:   "tools",
+		GoOutFile:   goOut,
+		GenPolicies: map[string]bingo.GenPolicy{"README.md": bingo.NeverGenPolicy},
+	}
+	testutil.Ok(t, uiApply(context.Background(), logger, nil, modDir, modDir, helpersCfg, "TOOL_", "", uiDelete, "goimports"))
+
+	_, err := os.Stat(filepath.Join(modDir, "goimports.mod"))
+	testutil.Assert(t, os.IsNotExist(err), "deleted tool mod file should be removed")
+	b, err := ioutil.ReadFile(goOut)
+	testutil.Ok(t, err)
+	testutil.Assert(t, strings.Contains(string(b), "package tools"), string(b))
+	b, err = ioutil.ReadFile(filepath.Join(modDir, "variables.env"))
+	testutil.Ok(t, err)
+	testutil.Assert(t, strings.Contains(string(b), "TOOL_FAILLINT="), string(b))
+	_, err = os.Stat(filepath.Join(modDir, "variables.go"))
+	testutil.Assert(t, os.IsNotExist(err), "variables.go should be generated only in configured path")
+	_, err = os.Stat(filepath.Join(modDir, "README.md"))
+	testutil.Assert(t, os.IsNotExist(err), "README.md should not be generated with never policy")
+}
+
+func TestModuleVulns(t *testing.T) {
+	var query map[string]interface{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		testutil.Equals(t, http.MethodPost, r.Method)
+		testutil.Equals(t, "/v1/query", r.URL.Path)
+		testutil.Ok(t, json.NewDecoder(r.Body).Decode(&query))
+		if query["version"] == "1.4.0" {
+			_, _ = w.Write([]byte(`{"vulns": [{"id": "GO-2022-0001", "summary": "Bad."}, {"id": "GO-2023-0002"}]}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer srv.Close()
+
+	vulns, err := moduleVulns(context.Background(), srv.Client(), srv.URL+"/", "github.com/fatih/faillint", "v1.4.0")
+	testutil.Ok(t, err)
+	testutil.Equals(t, []string{"GO-2022-0001", "GO-2023-0002"}, vulns)
+	testutil.Equals(t, map[string]interface{}{"name": "github.com/fatih/faillint", "ecosystem": "Go"}, query["package"])
+
+	vulns, err = moduleVulns(context.Background(), srv.Client(), srv.URL, "github.com/fatih/faillint", "v1.5.0")
+	testutil.Ok(t, err)
+	testutil.Equals(t, 0, len(vulns))
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+	}))
+	defer failing.Close()
+	_, err = moduleVulns(context.Background(), failing.Client(), failing.URL, "github.com/fatih/faillint", "v1.5.0")
+	testutil.NotOk(t, err)
+	testutil.Equals(t, "query OSV: unexpected status 503 Service Unavailable: unavailable", err.Error())
+}
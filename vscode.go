@@ -0,0 +1,127 @@
+// Copyright (c) Bartłomiej Płotka @bwplotka
+// Licensed under the Apache License 2.0.
+
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"log/slog"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/bwplotka/bingo/internal/getter"
+	"github.com/bwplotka/bingo/pkg/bingo"
+	"github.com/pkg/errors"
+)
+
+// vscodeSettingsFile is a VS Code workspace settings file, relative to project root.
+var vscodeSettingsFile = filepath.Join(".vscode", "settings.json")
+
+var (
+	// vscodeLintTools and vscodeFormatTools are tools supported by VS Code Go extension as go.lintTool and go.formatTool,
+	// in order of preference if many of them are pinned.
+	vscodeLintTools   = []string{"golangci-lint", "staticcheck", "revive", "golint"}
+	vscodeFormatTools = []string{"gofumpt", "goimports"}
+)
+
+// vscode links pinned tools to shims directory (as activate does) and prints VS Code Go extension settings pointing at them.
+// If write is true, settings are merged into VS Code workspace settings in the current directory (assumed to be project root) instead.
+func vscode(logger *slog.Logger, relModDir string, write bool, w io.Writer) error {
+	relModDir = filepath.Clean(relModDir)
+	if filepath.IsAbs(relModDir) || strings.HasPrefix(relModDir, "..") {
+		return errors.Errorf("VS Code settings require mod directory to be within the current directory, got %v", relModDir)
+	}
+	modDir, err := filepath.Abs(relModDir)
+	if err != nil {
+		return errors.Wrap(err, "abs")
+	}
+
+	pkgs, err := bingo.ListPinnedMainPackages(logger, modDir, false)
+	if err != nil {
+		return errors.Wrap(err, "list pinned")
+	}
+	gobinPath, err := filepath.Abs(getter.BinDir(modDir))
+	if err != nil {
+		return errors.Wrap(err, "abs gobin")
+	}
+	if err := linkShims(logger, filepath.Join(modDir, shimsDir), gobinPath, pkgs, nil); err != nil {
+		return errors.Wrap(err, "link shims")
+	}
+
+	settings := vscodeSettings(filepath.ToSlash(relModDir), pkgs)
+	if write {
+		existing, err := ioutil.ReadFile(vscodeSettingsFile)
+		if err != nil && !os.IsNotExist(err) {
+			return errors.Wrap(err, "read VS Code settings")
+		}
+		if settings, err = mergeVSCodeSettings(existing, settings); err != nil {
+			return err
+		}
+	}
+
+	b, err := json.MarshalIndent(settings, "", "\t")
+	if err != nil {
+		return errors.Wrap(err, "marshal")
+	}
+	b = append(b, '\n')
+	if !write {
+		_, err = w.Write(b)
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(vscodeSettingsFile), os.ModePerm); err != nil {
+		return errors.Wrap(err, "create .vscode directory")
+	}
+	return bingo.WriteFileIfChanged(vscodeSettingsFile, b)
+}
+
+// vscodeSettings returns VS Code Go extension settings that point alternate tools (and lint or format tool, if pinned) at shims of
+// pinned tools. Tools pinned in many versions are skipped.
+func vscodeSettings(relModDir string, pkgs bingo.PackageRenderables) map[string]interface{} {
+	alternateTools := map[string]interface{}{}
+	for _, p := range pkgs {
+		if len(p.Versions) != 1 {
+			continue
+		}
+		alternateTools[p.Name] = path.Join("${workspaceFolder}", relModDir, shimsDir, p.Name)
+	}
+
+	settings := map[string]interface{}{"go.alternateTools": alternateTools}
+	for key, tools := range map[string][]string{"go.lintTool": vscodeLintTools, "go.formatTool": vscodeFormatTools} {
+		for _, t := range tools {
+			if _, ok := alternateTools[t]; ok {
+				settings[key] = t
+				break
+			}
+		}
+	}
+	return settings
+}
+
+// mergeVSCodeSettings merges settings into existing VS Code settings content, preserving all other user's settings and alternate tools.
+func mergeVSCodeSettings(existing []byte, settings map[string]interface{}) (map[string]interface{}, error) {
+	merged := map[string]interface{}{}
+	if len(strings.TrimSpace(string(existing))) > 0 {
+		if err := json.Unmarshal(existing, &merged); err != nil {
+			return nil, errors.Wrapf(err, "parse %v; only plain JSON (without comments or trailing commas) can be merged, run without -w flag and copy printed settings manually instead", vscodeSettingsFile)
+		}
+	}
+	for k, v := range settings {
+		if k != "go.alternateTools" {
+			merged[k] = v
+			continue
+		}
+		alternateTools, _ := merged[k].(map[string]interface{})
+		if alternateTools == nil {
+			alternateTools = map[string]interface{}{}
+		}
+		for t, p := range v.(map[string]interface{}) {
+			alternateTools[t] = p
+		}
+		merged[k] = alternateTools
+	}
+	return merged, nil
+}
@@ -0,0 +1,44 @@
+// Copyright (c) Bartłomiej Płotka @bwplotka
+// Licensed under the Apache License 2.0.
+
+package main
+
+import (
+	"testing"
+
+	"github.com/bwplotka/bingo/pkg/bingo"
+	"github.com/efficientgo/tools/core/pkg/testutil"
+)
+
+func TestVSCodeSettings(t *testing.T) {
+	settings := vscodeSettings(".bingo", bingo.PackageRenderables{
+		{Name: "gopls", Versions: []bingo.PackageVersionRenderable{{Version: "v0.7.0"}}},
+		{Name: "golint", Versions: []bingo.PackageVersionRenderable{{Version: "v0.1.0"}}},
+		{Name: "staticcheck", Versions: []bingo.PackageVersionRenderable{{Version: "v0.2.0"}}},
+		{Name: "buf", Versions: []bingo.PackageVersionRenderable{{Version: "v0.1.0"}, {Version: "v0.2.0"}}},
+	})
+	testutil.Equals(t, map[string]interface{}{
+		"go.alternateTools": map[string]interface{}{
+			"gopls":       "${workspaceFolder}/.bingo/shims/gopls",
+			"golint":      "${workspaceFolder}/.bingo/shims/golint",
+			"staticcheck": "${workspaceFolder}/.bingo/shims/staticcheck",
+		},
+		"go.lintTool": "staticcheck",
+	}, settings)
+
+	_, err := mergeVSCodeSettings([]byte("{\n// Comment.\n}"), settings)
+	testutil.NotOk(t, err)
+
+	merged, err := mergeVSCodeSettings([]byte(`{"editor.tabSize": 4, "go.lintTool": "golint", "go.alternateTools": {"go": "/usr/bin/go"}}`), settings)
+	testutil.Ok(t, err)
+	testutil.Equals(t, map[string]interface{}{
+		"editor.tabSize": float64(4),
+		"go.alternateTools": map[string]interface{}{
+			"go":          "/usr/bin/go",
+			"gopls":       "${workspaceFolder}/.bingo/shims/gopls",
+			"golint":      "${workspaceFolder}/.bingo/shims/golint",
+			"staticcheck": "${workspaceFolder}/.bingo/shims/staticcheck",
+		},
+		"go.lintTool": "staticcheck",
+	}, merged)
+}
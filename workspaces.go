@@ -0,0 +1,135 @@
+// Copyright (c) Bartłomiej Płotka @bwplotka
+// Licensed under the Apache License 2.0.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/bwplotka/bingo/pkg/bingo"
+)
+
+// skippedWorkspaceDirs are directories never searched for mod directories.
+var skippedWorkspaceDirs = map[string]struct{}{
+	"node_modules": {},
+	"vendor":       {},
+	"testdata":     {},
+}
+
+// findModDirs returns all directories with given name (e.g ".bingo") and at least one mod file under given root, e.g
+// per component mod directories in monorepo. Returned paths are relative to the root and sorted. Other hidden
+// directories, vendor, node_modules and testdata directories are skipped.
+func findModDirs(root, name string) ([]string, error) {
+	var modDirs []string
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		if d.Name() == name {
+			if mods, err := filepath.Glob(filepath.Join(path, "*.mod")); err == nil && len(mods) > 0 {
+				rel, err := filepath.Rel(root, path)
+				if err != nil {
+					return err
+				}
+				modDirs = append(modDirs, rel)
+			}
+			return filepath.SkipDir
+		}
+		if path == root {
+			return nil
+		}
+		if _, ok := skippedWorkspaceDirs[d.Name()]; ok || strings.HasPrefix(d.Name(), ".") {
+			return filepath.SkipDir
+		}
+		return nil
+	})
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	sort.Strings(modDirs)
+	return modDirs, nil
+}
+
+// linkConflicts returns descriptions of tools pinned under the same name, but in different versions in different mod
+// directories. Their unversioned links (get -l) in shared GOBIN overwrite each other.
+func linkConflicts(modDirs []string, pkgsByModDir map[string]bingo.PackageRenderables) []string {
+	type pin struct{ modDir, versions string }
+	pinsByName := map[string][]pin{}
+	for _, d := range modDirs {
+		for _, p := range pkgsByModDir[d] {
+			pinsByName[p.Name] = append(pinsByName[p.Name], pin{modDir: d, versions: versions(p)})
+		}
+	}
+
+	var names []string
+	for n := range pinsByName {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+
+	var conflicts []string
+	for _, n := range names {
+		pins := pinsByName[n]
+		differ := false
+		for _, p := range pins[1:] {
+			if p.versions != pins[0].versions {
+				differ = true
+				break
+			}
+		}
+		if !differ {
+			continue
+		}
+		var s []string
+		for _, p := range pins {
+			s = append(s, fmt.Sprintf("%s@%s in %s", n, p.versions, p.modDir))
+		}
+		conflicts = append(conflicts, strings.Join(s, ", "))
+	}
+	return conflicts
+}
+
+// printWorkspaces prints given list outputs of many mod directories in given format: tables under mod directory names,
+// JSON object keyed by mod directory or YAML document per mod directory.
+func printWorkspaces(w io.Writer, format string, modDirs []string, outs map[string]*bytes.Buffer) error {
+	switch format {
+	case "json":
+		obj := map[string]json.RawMessage{}
+		for _, d := range modDirs {
+			obj[d] = outs[d].Bytes()
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(obj)
+	case "yaml":
+		for _, d := range modDirs {
+			if _, err := fmt.Fprintf(w, "--- # %s\n%s", d, outs[d].String()); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		for i, d := range modDirs {
+			if i > 0 {
+				if _, err := fmt.Fprintln(w); err != nil {
+					return err
+				}
+			}
+			if _, err := fmt.Fprintf(w, "%s:\n%s", d, outs[d].String()); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
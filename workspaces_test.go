@@ -0,0 +1,66 @@
+// Copyright (c) Bartłomiej Płotka @bwplotka
+// Licensed under the Apache License 2.0.
+
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bwplotka/bingo/pkg/bingo"
+	"github.com/efficientgo/tools/core/pkg/testutil"
+)
+
+func TestFindModDirs(t *testing.T) {
+	root := t.TempDir()
+	for _, d := range []string{".bingo", "services/b/.bingo", "services/a/.bingo", "services/c/.bingo", "vendor/x/.bingo", ".git/.bingo"} {
+		testutil.Ok(t, os.MkdirAll(filepath.Join(root, d), os.ModePerm))
+		if d == "services/c/.bingo" {
+			// No mod files.
+			continue
+		}
+		testutil.Ok(t, os.WriteFile(filepath.Join(root, d, "tool.mod"), []byte("module _"), 0666))
+	}
+
+	got, err := findModDirs(root, ".bingo")
+	testutil.Ok(t, err)
+	testutil.Equals(t, []string{".bingo", filepath.Join("services", "a", ".bingo"), filepath.Join("services", "b", ".bingo")}, got)
+
+	got, err = findModDirs(filepath.Join(root, "not-existing"), ".bingo")
+	testutil.Ok(t, err)
+	testutil.Equals(t, 0, len(got))
+}
+
+func TestLinkConflicts(t *testing.T) {
+	pkg := func(name, version string) bingo.PackageRenderable {
+		return bingo.PackageRenderable{Name: name, Versions: []bingo.PackageVersionRenderable{{Version: version}}}
+	}
+	modDirs := []string{".bingo", "a/.bingo", "b/.bingo"}
+	got := linkConflicts(modDirs, map[string]bingo.PackageRenderables{
+		".bingo":   {pkg("faillint", "v1.5.0"), pkg("goimports", "v0.1.0")},
+		"a/.bingo": {pkg("faillint", "v1.5.0"), pkg("goimports", "v0.2.0")},
+		"b/.bingo": {pkg("faillint", "v1.5.0")},
+	})
+	testutil.Equals(t, []string{"goimports@v0.1.0 in .bingo, goimports@v0.2.0 in a/.bingo"}, got)
+}
+
+func TestPrintWorkspaces(t *testing.T) {
+	modDirs := []string{".bingo", "a/.bingo"}
+	outs := func(a, b string) map[string]*bytes.Buffer {
+		return map[string]*bytes.Buffer{".bingo": bytes.NewBufferString(a), "a/.bingo": bytes.NewBufferString(b)}
+	}
+
+	b := &bytes.Buffer{}
+	testutil.Ok(t, printWorkspaces(b, "", modDirs, outs("table1\n", "table2\n")))
+	testutil.Equals(t, ".bingo:\ntable1\n\na/.bingo:\ntable2\n", b.String())
+
+	b.Reset()
+	testutil.Ok(t, printWorkspaces(b, "yaml", modDirs, outs("- name: a\n", "- name: b\n")))
+	testutil.Equals(t, "--- # .bingo\n- name: a\n--- # a/.bingo\n- name: b\n", b.String())
+
+	b.Reset()
+	testutil.Ok(t, printWorkspaces(b, "json", modDirs, outs(`[{"name":"a"}]`, `[]`)))
+	testutil.Equals(t, "{\n  \".bingo\": [\n    {\n      \"name\": \"a\"\n    }\n  ],\n  \"a/.bingo\": []\n}\n", b.String())
+}